@@ -0,0 +1,352 @@
+// Command clientgen writes the OpenAPI document of the API and generates the Go client
+// in pkg/client from it. It is run by go generate in pkg/client:
+//
+//	go generate ./pkg/client
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/valeriouberti/maestro/internal/openapi"
+	"github.com/valeriouberti/maestro/pkg/api"
+)
+
+// domainPkg is the import path of the domain types, which the client reuses
+const domainPkg = "github.com/valeriouberti/maestro/pkg/domain"
+
+func main() {
+	specPath := flag.String("spec", "../../api/openapi.json", "Path of the OpenAPI document to write")
+	outPath := flag.String("out", "client_gen.go", "Path of the generated Go file")
+	pkgName := flag.String("package", "client", "Package name of the generated Go file")
+	flag.Parse()
+
+	doc := api.OpenAPIDocument()
+
+	spec, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to encode OpenAPI document: %v", err)
+	}
+	if err := os.WriteFile(*specPath, append(spec, '\n'), 0o644); err != nil {
+		log.Fatalf("Failed to write OpenAPI document: %v", err)
+	}
+
+	src, err := generate(doc, *pkgName)
+	if err != nil {
+		log.Fatalf("Failed to generate client: %v", err)
+	}
+	if err := os.WriteFile(*outPath, src, 0o644); err != nil {
+		log.Fatalf("Failed to write client: %v", err)
+	}
+}
+
+// generator accumulates the generated source and the imports it needs
+type generator struct {
+	doc     *openapi.Document
+	buf     bytes.Buffer
+	imports map[string]bool
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// generate returns the formatted source of the client types and operations
+func generate(doc *openapi.Document, pkgName string) ([]byte, error) {
+	g := &generator{doc: doc, imports: make(map[string]bool)}
+
+	basePath := ""
+	if len(doc.Servers) > 0 {
+		basePath = doc.Servers[0].URL
+	}
+	g.printf("// BasePath is the path the API is served under\n")
+	g.printf("const BasePath = %q\n\n", basePath)
+
+	for _, name := range sortedKeys(doc.Components.Schemas) {
+		schema := doc.Components.Schemas[name]
+		if g.isExternal(schema) {
+			continue
+		}
+		g.structType(name, schema)
+	}
+
+	for _, path := range sortedKeys(doc.Paths) {
+		item := doc.Paths[path]
+		for _, method := range sortedKeys(item) {
+			if err := g.operation(method, path, item[method]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by clientgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\n", pkgName)
+	if len(g.imports) > 0 {
+		// Standard library imports first, as goimports groups them
+		var std, other []string
+		for _, path := range sortedKeys(g.imports) {
+			if first, _, _ := strings.Cut(path, "/"); strings.Contains(first, ".") {
+				other = append(other, path)
+			} else {
+				std = append(std, path)
+			}
+		}
+		fmt.Fprintf(&out, "import (\n")
+		for _, path := range std {
+			fmt.Fprintf(&out, "\t%q\n", path)
+		}
+		if len(std) > 0 && len(other) > 0 {
+			fmt.Fprintf(&out, "\n")
+		}
+		for _, path := range other {
+			fmt.Fprintf(&out, "\t%q\n", path)
+		}
+		fmt.Fprintf(&out, ")\n\n")
+	}
+	out.Write(g.buf.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated source: %w\n%s", err, out.Bytes())
+	}
+	return src, nil
+}
+
+// isExternal reports whether a component schema describes a type the client imports
+// rather than generates
+func (g *generator) isExternal(schema *openapi.Schema) bool {
+	return strings.HasPrefix(schema.GoType, domainPkg+".")
+}
+
+// structType generates the struct type of an object schema
+func (g *generator) structType(name string, schema *openapi.Schema) {
+	g.printf("// %s is generated from the %s schema\n", name, name)
+	g.printf("type %s struct {\n", name)
+	for _, property := range sortedKeys(schema.Properties) {
+		tag := property
+		if !slices.Contains(schema.Required, property) {
+			tag += ",omitempty"
+		}
+		g.printf("\t%s %s `json:%q`\n", goName(property, true), g.goType(schema.Properties[property]), tag)
+	}
+	g.printf("}\n\n")
+}
+
+// goType returns the Go type of a schema
+func (g *generator) goType(schema *openapi.Schema) string {
+	if name := schema.RefName(); name != "" {
+		if component := g.doc.Components.Schemas[name]; component != nil && g.isExternal(component) {
+			g.imports[domainPkg] = true
+			return "domain." + name
+		}
+		return name
+	}
+
+	var t string
+	switch {
+	case schema.GoType == "time.Time":
+		g.imports["time"] = true
+		t = "time.Time"
+	case schema.GoType == "encoding/json.RawMessage":
+		g.imports["encoding/json"] = true
+		return "json.RawMessage"
+	case schema.Type == "string":
+		t = "string"
+	case schema.Type == "boolean":
+		t = "bool"
+	case schema.Type == "integer" && schema.Format == "int32":
+		t = "int32"
+	case schema.Type == "integer":
+		t = "int64"
+	case schema.Type == "number" && schema.Format == "float":
+		t = "float32"
+	case schema.Type == "number":
+		t = "float64"
+	case schema.Type == "array":
+		return "[]" + g.goType(schema.Items)
+	case schema.Type == "object" && schema.AdditionalProperties != nil:
+		return "map[string]" + g.goType(schema.AdditionalProperties)
+	default:
+		return "any"
+	}
+
+	if schema.Nullable {
+		return "*" + t
+	}
+	return t
+}
+
+// operation generates the method of an operation, and the struct of its query parameters
+func (g *generator) operation(method, path string, op *openapi.Operation) error {
+	name := goName(op.OperationID, true)
+	g.imports["context"] = true
+
+	// Header parameters are set by options of the client, e.g. WithCluster
+	var pathParams, queryParams []openapi.Parameter
+	for _, param := range op.Parameters {
+		switch param.In {
+		case "path":
+			pathParams = append(pathParams, param)
+		case "query":
+			queryParams = append(queryParams, param)
+		}
+	}
+
+	paramsType := name + "Params"
+	if len(queryParams) > 0 {
+		g.printf("// %s are the query parameters of %s. Zero values are omitted.\n", paramsType, name)
+		g.printf("type %s struct {\n", paramsType)
+		for _, param := range queryParams {
+			if param.Description != "" {
+				g.printf("\t// %s\n", param.Description)
+			}
+			g.printf("\t%s %s\n", goName(param.Name, true), g.goType(param.Schema))
+		}
+		g.printf("}\n\n")
+	}
+
+	args := []string{"ctx context.Context"}
+	for _, param := range pathParams {
+		args = append(args, goName(param.Name, false)+" string")
+	}
+	if len(queryParams) > 0 {
+		args = append(args, "params *"+paramsType)
+	}
+	body := "nil"
+	if op.RequestBody != nil {
+		media, ok := op.RequestBody.Content[openapi.MediaTypeJSON]
+		if !ok {
+			return fmt.Errorf("operation %s has no JSON request body", op.OperationID)
+		}
+		args = append(args, "body "+g.goType(media.Schema))
+		body = "body"
+	}
+
+	// The successful response is the one that is not the default error response
+	var success openapi.Response
+	for status, response := range op.Responses {
+		if status != "default" {
+			success = response
+		}
+	}
+	download := false
+	result := ""
+	if _, ok := success.Content[openapi.MediaTypeBinary]; ok {
+		download = true
+		g.imports["io"] = true
+		result = "io.ReadCloser"
+	} else if media, ok := success.Content[openapi.MediaTypeJSON]; ok {
+		result = g.goType(media.Schema)
+	}
+
+	g.printf("// %s calls %s %s", name, strings.ToUpper(method), path)
+	if op.Summary != "" {
+		g.printf(": %s", op.Summary)
+	}
+	g.printf("\n")
+	switch {
+	case result == "":
+		g.printf("func (c *Client) %s(%s) error {\n", name, strings.Join(args, ", "))
+	case isRef(success):
+		g.printf("func (c *Client) %s(%s) (*%s, error) {\n", name, strings.Join(args, ", "), result)
+	default:
+		g.printf("func (c *Client) %s(%s) (%s, error) {\n", name, strings.Join(args, ", "), result)
+	}
+
+	query := "nil"
+	if len(queryParams) > 0 {
+		g.imports["net/url"] = true
+		query = "query"
+		g.printf("\tquery := url.Values{}\n")
+		g.printf("\tif params != nil {\n")
+		for _, param := range queryParams {
+			field := "params." + goName(param.Name, true)
+			if g.goType(param.Schema) == "string" {
+				g.printf("\t\tif %s != \"\" {\n\t\t\tquery.Set(%q, %s)\n\t\t}\n", field, param.Name, field)
+			} else {
+				g.imports["fmt"] = true
+				g.printf("\t\tif %s != 0 {\n\t\t\tquery.Set(%q, fmt.Sprint(%s))\n\t\t}\n", field, param.Name, field)
+			}
+		}
+		g.printf("\t}\n")
+	}
+
+	urlPath := g.pathExpr(path)
+	switch {
+	case download:
+		g.printf("\treturn c.download(ctx, %q, %s, %s)\n", strings.ToUpper(method), urlPath, query)
+	case result == "":
+		g.printf("\treturn c.do(ctx, %q, %s, %s, %s, nil)\n", strings.ToUpper(method), urlPath, query, body)
+	case isRef(success):
+		g.printf("\tvar out %s\n", result)
+		g.printf("\tif err := c.do(ctx, %q, %s, %s, %s, &out); err != nil {\n\t\treturn nil, err\n\t}\n", strings.ToUpper(method), urlPath, query, body)
+		g.printf("\treturn &out, nil\n")
+	default:
+		g.printf("\tvar out %s\n", result)
+		g.printf("\tif err := c.do(ctx, %q, %s, %s, %s, &out); err != nil {\n\t\treturn nil, err\n\t}\n", strings.ToUpper(method), urlPath, query, body)
+		g.printf("\treturn out, nil\n")
+	}
+	g.printf("}\n\n")
+	return nil
+}
+
+// pathExpr returns a Go expression building an OpenAPI path such as /topics/{topicName}
+// from the path parameter arguments
+func (g *generator) pathExpr(path string) string {
+	var parts []string
+	literal := ""
+	for _, segment := range strings.Split(path, "/")[1:] {
+		literal += "/"
+		if name, ok := strings.CutPrefix(segment, "{"); ok {
+			g.imports["net/url"] = true
+			parts = append(parts, fmt.Sprintf("%q", literal), "url.PathEscape("+goName(strings.TrimSuffix(name, "}"), false)+")")
+			literal = ""
+			continue
+		}
+		literal += segment
+	}
+	if literal != "" {
+		parts = append(parts, fmt.Sprintf("%q", literal))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// isRef reports whether the JSON content of a response refers to a component schema
+func isRef(response openapi.Response) bool {
+	media, ok := response.Content[openapi.MediaTypeJSON]
+	return ok && media.Schema.RefName() != ""
+}
+
+// goName turns a JSON property, parameter or operation ID into a Go identifier, exported
+// or not, spelling the ID initialism the Go way
+func goName(name string, exported bool) string {
+	if name == "" {
+		return name
+	}
+	if exported {
+		name = strings.ToUpper(name[:1]) + name[1:]
+	}
+	if base, ok := strings.CutSuffix(name, "Id"); ok {
+		name = base + "ID"
+	} else if base, ok := strings.CutSuffix(name, "Ids"); ok {
+		name = base + "IDs"
+	}
+	return name
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
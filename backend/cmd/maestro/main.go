@@ -2,109 +2,515 @@ package main
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/valeriouberti/maestro/internal/alerting"
+	"github.com/valeriouberti/maestro/internal/audit"
+	"github.com/valeriouberti/maestro/internal/auth"
+	"github.com/valeriouberti/maestro/internal/cli"
+	"github.com/valeriouberti/maestro/internal/clusters"
 	"github.com/valeriouberti/maestro/internal/config"
+	"github.com/valeriouberti/maestro/internal/export"
+	"github.com/valeriouberti/maestro/internal/jobs"
 	"github.com/valeriouberti/maestro/internal/kafka_client"
+	"github.com/valeriouberti/maestro/internal/lag"
+	"github.com/valeriouberti/maestro/internal/logging"
+	"github.com/valeriouberti/maestro/internal/policy"
+	"github.com/valeriouberti/maestro/internal/protection"
+	"github.com/valeriouberti/maestro/internal/rbac"
+	"github.com/valeriouberti/maestro/internal/replay"
+	"github.com/valeriouberti/maestro/internal/storage"
+	"github.com/valeriouberti/maestro/internal/templates"
+	"github.com/valeriouberti/maestro/internal/tracing"
 	"github.com/valeriouberti/maestro/pkg/api"
+	"github.com/valeriouberti/maestro/pkg/domain"
 )
 
 func main() {
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
-	log.Println("Starting Maestro Kafka Management Service")
+	// Without arguments, or with "serve", maestro runs the server; otherwise it is the command-line client
+	if len(os.Args) > 1 && os.Args[1] != "serve" {
+		os.Exit(cli.Run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+	}
 
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		fatal("Failed to load configuration", err)
 	}
 
-	if cfg.EnvironmentName == "production" {
+	// logLevel can be changed while the server runs through the admin API
+	logLevel, err := logging.Setup(logging.Options{
+		Level:  cfg.Server.LogLevel,
+		Format: cfg.Server.LogFormat,
+	})
+	if err != nil {
+		fatal("Failed to set up logging", err)
+	}
+	slog.Info("Starting Maestro Kafka Management Service", "environment", cfg.Server.Environment)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Enabled:     cfg.Tracing.Enabled,
+		Endpoint:    cfg.Tracing.Endpoint,
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		fatal("Failed to set up tracing", err)
+	}
+	// Deferred first so that the spans of everything shut down afterwards are exported
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Warn("Failed to flush traces", "error", err)
+		}
+	}()
+
+	if cfg.Server.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	r := gin.Default()
+	// Requests are logged by logging.Middleware rather than by gin's logger
+	r := gin.New()
+
+	// The registry forwards every Kafka operation to the cluster selected by the request
+	registry, err := clusters.NewRegistry(cfg)
+	if err != nil {
+		fatal("Failed to create Kafka clients", err)
+	}
+	defer registry.Close()
+	for _, cluster := range registry.List() {
+		if cluster.Demo {
+			slog.Info("Demo mode: the cluster is an in-memory Kafka cluster with sample data", "cluster", cluster.Name)
+		}
+	}
+	var kClient kafka_client.Client = registry
+
+	store, err := storage.Open(storage.Options{
+		Driver: cfg.Storage.Driver,
+		Path:   cfg.Storage.Path,
+	})
+	if err != nil {
+		fatal("Failed to open storage", err)
+	}
+	defer store.Close()
+
+	jobManager, err := jobs.NewManager(jobs.Options{
+		Store:          store,
+		Dir:            cfg.Jobs.Dir,
+		MaxConcurrent:  cfg.Jobs.MaxConcurrent,
+		Retention:      cfg.Jobs.Retention.Duration,
+		ClusterContext: clusters.WithName,
+	})
+	if err != nil {
+		fatal("Failed to create job manager", err)
+	}
+	jobManager.Register(export.JobType, export.NewRunner(kClient))
+	if cfg.Features.MessagePublishing && !cfg.Features.ReadOnly {
+		jobManager.Register(replay.JobType, replay.NewRunner(registry))
+	}
+	jobManager.Start()
+	defer jobManager.Shutdown()
+
+	// lagSampler stays nil when lag history is disabled, and its history is then unavailable
+	var lagSampler *lag.Sampler
+	if cfg.LagHistory.Enabled {
+		lagSampler = lag.NewSampler(lag.Options{
+			Store:     store,
+			Client:    kClient,
+			Clusters:  registry.List,
+			Interval:  cfg.LagHistory.Interval.Duration,
+			Retention: cfg.LagHistory.Retention.Duration,
+		})
+		lagSampler.Start()
+		defer lagSampler.Shutdown()
+	}
+
+	alertEngine, err := newAlertEngine(cfg, kClient, registry)
+	if err != nil {
+		fatal("Failed to configure alerting", err)
+	}
+	if alertEngine != nil {
+		alertEngine.Start()
+		defer alertEngine.Shutdown()
+	}
+
+	topicTemplates, err := newTopicTemplates(cfg, store)
+	if err != nil {
+		fatal("Failed to load topic templates", err)
+	}
+
+	authenticator, err := auth.New(auth.Options{
+		Methods: cfg.Auth.Methods,
+		OIDC: auth.OIDCOptions{
+			Issuer:        cfg.Auth.OIDC.Issuer,
+			Audience:      cfg.Auth.OIDC.Audience,
+			JWKSURL:       cfg.Auth.OIDC.JWKSURL,
+			PublicKeyFile: cfg.Auth.OIDC.PublicKeyFile,
+			UsernameClaim: cfg.Auth.OIDC.UsernameClaim,
+			GroupsClaim:   cfg.Auth.OIDC.GroupsClaim,
+		},
+		APIKeysFile:  cfg.Auth.APIKeysFile,
+		HtpasswdFile: cfg.Auth.HtpasswdFile,
+	})
+	if err != nil {
+		fatal("Failed to configure authentication", err)
+	}
+	if authenticator == nil {
+		slog.Warn("Authentication is disabled, set AUTH_METHODS to protect the API")
+	}
 
-	kClient, err := kafka_client.NewKafkaClient(cfg.KafkaBrokers)
+	var authorizer *rbac.Authorizer
+	if cfg.Auth.RBACPolicyFile != "" {
+		authorizer, err = rbac.LoadPolicyFile(cfg.Auth.RBACPolicyFile)
+		if err != nil {
+			fatal("Failed to load RBAC policy", err)
+		}
+	}
+
+	auditLogger, err := newAuditLogger(cfg, kClient, store)
 	if err != nil {
-		log.Fatalf("Failed to create Kafka client: %v", err)
+		fatal("Failed to create audit logger", err)
 	}
-	defer kClient.Close()
+	defer auditLogger.Close()
+
+	// applied is the configuration in effect: the settings read at startup with the
+	// cluster definitions of the last reload
+	var applied atomic.Pointer[config.Config]
+	applied.Store(cfg)
 
-	setupRoutes(r, kClient)
+	setupRoutes(r, cfg, applied.Load, logLevel, registry, jobManager, lagSampler, alertEngine, topicTemplates, authenticator, authorizer, auditLogger)
 
 	srv := &http.Server{
-		Addr:         ":" + cfg.ServerPort,
+		Addr:         ":" + cfg.Server.Port,
 		Handler:      r,
-		ReadTimeout:  cfg.ReadTimeout,
-		WriteTimeout: cfg.WriteTimeout,
+		ReadTimeout:  cfg.Server.ReadTimeout.Duration,
+		WriteTimeout: cfg.Server.WriteTimeout.Duration,
 		IdleTimeout:  120 * time.Second,
 	}
 
 	go func() {
-		log.Printf("Server listening on port %s", cfg.ServerPort)
-		if cfg.EnableTLS {
-			if err := srv.ListenAndServeTLS(cfg.CertFile, cfg.KeyFile); err != nil && err != http.ErrServerClosed {
-				log.Fatalf("Failed to start server: %v", err)
+		slog.Info("Server listening", "port", cfg.Server.Port, "tls", cfg.Server.TLS.Enabled)
+		if cfg.Server.TLS.Enabled {
+			if err := srv.ListenAndServeTLS(cfg.Server.TLS.CertFile, cfg.Server.TLS.KeyFile); err != nil && err != http.ErrServerClosed {
+				fatal("Failed to start server", err)
 			}
 		} else {
 			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-				log.Fatalf("Failed to start server: %v", err)
+				fatal("Failed to start server", err)
 			}
 		}
 	}()
 
+	reloadCtx, stopReload := context.WithCancel(context.Background())
+	defer stopReload()
+	reload := make(chan struct{}, 1)
+	if cfg.Path != "" && cfg.Server.ConfigWatchInterval.Duration > 0 {
+		go config.Watch(reloadCtx, cfg.Path, cfg.Server.ConfigWatchInterval.Duration, func() {
+			select {
+			case reload <- struct{}{}:
+			default:
+			}
+		})
+	}
+
+	// Secret references are resolved again periodically to pick up rotated credentials
+	refresh := make(chan struct{}, 1)
+	if interval := cfg.Secrets.RefreshInterval.Duration; interval > 0 {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-reloadCtx.Done():
+					return
+				case <-ticker.C:
+				}
+				select {
+				case refresh <- struct{}{}:
+				default:
+				}
+			}
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	for running := true; running; {
+		select {
+		case sig := <-quit:
+			if sig == syscall.SIGHUP {
+				reloadClusters(cfg, &applied, registry, false)
+			} else {
+				running = false
+			}
+		case <-reload:
+			reloadClusters(cfg, &applied, registry, false)
+		case <-refresh:
+			if applied.Load().HasSecretReferences() {
+				reloadClusters(cfg, &applied, registry, true)
+			}
+		}
+	}
 
-	log.Println("Shutting down server...")
+	slog.Info("Shutting down server")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		fatal("Server forced to shutdown", err)
+	}
+
+	slog.Info("Server exited successfully")
+}
+
+// fatal logs an error that prevents the server from running and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// reloadClusters reads the configuration again, resolving its secret references, and
+// applies its cluster definitions to the registry and to applied. Other settings only
+// take effect after a restart. A refresh of the secrets only logs clusters that changed.
+func reloadClusters(current *config.Config, applied *atomic.Pointer[config.Config], registry *clusters.Registry, refresh bool) {
+	if !refresh {
+		slog.Info("Reloading cluster definitions")
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		slog.Error("Failed to reload configuration, keeping the current clusters", "error", err)
+		return
+	}
+
+	changes, err := registry.Load(cfg)
+	if err != nil {
+		slog.Error("Failed to reload clusters, keeping the current ones", "error", err)
+		return
 	}
+	next := *current
+	next.Clusters = cfg.Clusters
+	applied.Store(&next)
 
-	log.Println("Server exited successfully")
+	if refresh {
+		if len(changes.Added)+len(changes.Updated)+len(changes.Removed) > 0 {
+			slog.Info("Secrets refreshed")
+			clusters.LogChanges(changes)
+		}
+		return
+	}
+	clusters.LogChanges(changes)
+
+	cfg.Clusters, cfg.Path = current.Clusters, current.Path
+	if !reflect.DeepEqual(cfg, current) {
+		slog.Warn("Settings other than the clusters changed; restart Maestro to apply them")
+	}
 }
 
 // setupRoutes configures all API routes
-func setupRoutes(r *gin.Engine, kClient *kafka_client.KafkaClient) {
-	r.Use(gin.Recovery())
-	r.Use(corsMiddleware())
+func setupRoutes(r *gin.Engine, cfg *config.Config, current func() *config.Config, logLevel *slog.LevelVar, registry *clusters.Registry, jobManager *jobs.Manager, lagSampler *lag.Sampler, alertEngine *alerting.Engine, topicTemplates *templates.Store, authenticator auth.Authenticator, authorizer *rbac.Authorizer, auditLogger *audit.Logger) {
+	r.Use(logging.Middleware(), tracing.Middleware(), gin.Recovery())
+	r.Use(corsMiddleware(cfg.Server.CORSAllowedOrigins))
 
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
 
-	apiGroup := r.Group("/api/v1")
-	{
-		apiGroup.GET("/clusters", api.GetClustersHandler(kClient))
-		apiGroup.GET("/topics", api.ListTopicsHandler(kClient))
-		apiGroup.GET("/topics/:topicName", api.GetTopicHandler(kClient))
-		apiGroup.POST("/topics", api.CreateTopicHandler(kClient))
-		apiGroup.DELETE("/topics/:topicName", api.DeleteTopicHandler(kClient))
-		apiGroup.PUT("/topics/:topicName/config", api.UpdateTopicConfigHandler(kClient))
-		apiGroup.GET("/topics/:topicName/messages", api.GetTopicMessagesHandler(kClient))
-		apiGroup.POST("/topics/:topicName/messages", api.PublishMessageHandler(kClient))
-		apiGroup.GET("/consumergroups", api.ListConsumerGroupsHandler(kClient))
-		apiGroup.GET("/consumergroups/:groupId", api.GetConsumerGroupHandler(kClient))
+	err := api.RegisterRoutes(r, api.Services{
+		Client:        registry,
+		Clusters:      registry,
+		Config:        current,
+		Features:      cfg.Features,
+		LogLevel:      logLevel,
+		Jobs:          jobManager,
+		Lag:           lagSampler,
+		Alerts:        alertEngine,
+		Templates:     topicTemplates,
+		Policies:      newTopicPolicies(cfg, registry),
+		Protected:     protection.New(cfg.ProtectedTopics.Patterns, cfg.ProtectedTopics.RiskyConfigs),
+		Authenticator: authenticator,
+		Authorizer:    authorizer,
+		Audit:         auditLogger,
+	})
+	if err != nil {
+		fatal("Failed to set up routes", err)
 	}
 }
 
-// corsMiddleware handles CORS for the API
-func corsMiddleware() gin.HandlerFunc {
+// newAuditLogger creates the audit logger writing to the configured sinks
+func newAuditLogger(cfg *config.Config, kClient kafka_client.Client, store storage.Store) (*audit.Logger, error) {
+	var sinks []audit.Sink
+	for _, name := range cfg.Audit.Sinks {
+		switch name {
+		case audit.SinkStore:
+			sinks = append(sinks, audit.NewStoreSink(store))
+		case audit.SinkFile:
+			sink, err := audit.NewFileSink(cfg.Audit.File)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+		case audit.SinkStdout:
+			sinks = append(sinks, audit.NewWriterSink(os.Stdout))
+		case audit.SinkKafka:
+			sinks = append(sinks, audit.NewKafkaSink(kClient, cfg.Audit.KafkaTopic))
+		}
+	}
+	return audit.NewLogger(sinks...), nil
+}
+
+// newTopicPolicies creates the checker of the configured topic policies
+func newTopicPolicies(cfg *config.Config, registry *clusters.Registry) *policy.Checker {
+	policies := make([]policy.TopicPolicy, 0, len(cfg.TopicPolicies))
+	for _, pc := range cfg.TopicPolicies {
+		p := policy.TopicPolicy{
+			Name:                 pc.Name,
+			Clusters:             pc.Clusters,
+			Groups:               pc.Groups,
+			Prefixes:             pc.Prefixes,
+			MinPartitions:        pc.MinPartitions,
+			MaxPartitions:        pc.MaxPartitions,
+			MinReplicationFactor: pc.MinReplicationFactor,
+			RequiredConfigs:      pc.RequiredConfigs,
+			BannedConfigs:        pc.BannedConfigs,
+		}
+		if pc.NamePattern != "" {
+			// The pattern was checked when the configuration was loaded
+			p.NamePattern = regexp.MustCompile(pc.NamePattern)
+		}
+		policies = append(policies, p)
+	}
+	return policy.NewChecker(policies, registry.Selected)
+}
+
+// newTopicTemplates creates the store of the configured and stored topic templates
+func newTopicTemplates(cfg *config.Config, store storage.Store) (*templates.Store, error) {
+	configured := make([]domain.TopicTemplate, 0, len(cfg.TopicTemplates))
+	for _, tc := range cfg.TopicTemplates {
+		configured = append(configured, domain.TopicTemplate{
+			Name:              tc.Name,
+			Description:       tc.Description,
+			TopicName:         tc.TopicName,
+			NumPartitions:     tc.NumPartitions,
+			ReplicationFactor: tc.ReplicationFactor,
+			Config:            tc.Config,
+		})
+	}
+	return templates.NewStore(store, configured)
+}
+
+// headerValues returns the resolved values of the headers of a notifier
+func headerValues(headers map[string]*config.Secret) map[string]string {
+	values := make(map[string]string, len(headers))
+	for name, header := range headers {
+		if header != nil {
+			values[name] = header.Value()
+		}
+	}
+	return values
+}
+
+// newAlertEngine creates the engine evaluating the configured alert rules, or nil when
+// there are none
+func newAlertEngine(cfg *config.Config, kClient kafka_client.Client, registry *clusters.Registry) (*alerting.Engine, error) {
+	if len(cfg.Alerting.Rules) == 0 {
+		return nil, nil
+	}
+
+	notifiers := make(map[string]alerting.Notifier, len(cfg.Alerting.Notifiers))
+	for _, nc := range cfg.Alerting.Notifiers {
+		var notifier alerting.Notifier
+		var err error
+		switch nc.Type {
+		case alerting.NotifierWebhook:
+			notifier, err = alerting.NewWebhookNotifier(alerting.WebhookOptions{
+				Name:     nc.Name,
+				URL:      nc.URL.Value(),
+				Headers:  headerValues(nc.Headers),
+				Template: nc.Template,
+			})
+		case alerting.NotifierSlack:
+			notifier, err = alerting.NewSlackNotifier(nc.Name, nc.URL.Value(), nc.Template)
+		case alerting.NotifierEmail:
+			notifier, err = alerting.NewEmailNotifier(alerting.EmailOptions{
+				Name:     nc.Name,
+				Host:     nc.SMTP.Host,
+				Port:     nc.SMTP.Port,
+				Username: nc.SMTP.Username.Value(),
+				Password: nc.SMTP.Password.Value(),
+				From:     nc.SMTP.From,
+				To:       nc.SMTP.To,
+				Template: nc.Template,
+			})
+		}
+		if err != nil {
+			return nil, err
+		}
+		notifiers[nc.Name] = notifier
+	}
+
+	rules := make([]alerting.Rule, 0, len(cfg.Alerting.Rules))
+	for _, rc := range cfg.Alerting.Rules {
+		severity := rc.Severity
+		if severity == "" {
+			severity = "warning"
+		}
+		rules = append(rules, alerting.Rule{
+			Name:           rc.Name,
+			Type:           rc.Type,
+			Clusters:       rc.Clusters,
+			Groups:         rc.Groups,
+			Topics:         rc.Topics,
+			Threshold:      rc.Threshold,
+			For:            rc.For.Duration,
+			RepeatInterval: rc.RepeatInterval.Duration,
+			Severity:       severity,
+			Notify:         rc.Notify,
+		})
+	}
+
+	return alerting.NewEngine(alerting.Options{
+		Client:    kClient,
+		Clusters:  registry.List,
+		Interval:  cfg.Alerting.Interval.Duration,
+		Rules:     rules,
+		Notifiers: notifiers,
+	}), nil
+}
+
+// corsMiddleware handles CORS for the API. Origins not in the allowlist get no
+// CORS headers, so browsers block their requests; "*" allows any origin.
+func corsMiddleware(allowedOrigins []string) gin.HandlerFunc {
+	allowAny := false
+	allowed := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			allowAny = true
+		}
+		allowed[strings.TrimSuffix(origin, "/")] = true
+	}
+
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		origin := c.GetHeader("Origin")
+		switch {
+		case allowAny:
+			c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		case origin != "" && allowed[origin]:
+			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+			c.Writer.Header().Add("Vary", "Origin")
+		}
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, "+auth.APIKeyHeader+", "+clusters.Header+", traceparent, tracestate")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusNoContent)
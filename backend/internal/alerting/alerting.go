@@ -0,0 +1,341 @@
+// Package alerting evaluates alert rules against the clusters and notifies webhooks,
+// Slack and email recipients when alerts fire and resolve.
+//
+// Rules on conditions, such as the lag of a consumer group, raise an alert per topic or
+// group the condition holds for. The alert is pending until the condition has held for
+// the duration of the rule, then fires, and resolves once the condition no longer holds.
+// Notifications are only sent on these transitions, and again every repeat interval of
+// the rule while the alert fires. Rules on events, the creation and deletion of topics,
+// notify every event once. Alerts are kept in memory: after a restart, alerts that
+// still hold fire again.
+package alerting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/valeriouberti/maestro/internal/clusters"
+	"github.com/valeriouberti/maestro/internal/kafka_client"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+const (
+	// notifyTimeout bounds a single attempt at sending a notification
+	notifyTimeout = 30 * time.Second
+	// notifyAttempts is how many times a notification is sent before it is given up
+	notifyAttempts = 3
+)
+
+// ErrUnknownNotifier is returned when testing a notifier that is not configured
+var ErrUnknownNotifier = errors.New("unknown notifier")
+
+// Rule is a condition on the topics or consumer groups of clusters
+type Rule struct {
+	Name string
+	// Type is one of the domain.Alert* rule types
+	Type string
+	// Clusters the rule applies to; all when empty
+	Clusters []string
+	// Groups and Topics are glob patterns selecting the groups and topics; all when empty
+	Groups string
+	Topics string
+	// Threshold is the value the lag or the number of under-replicated partitions must exceed
+	Threshold int64
+	// For is how long the condition must hold before the alert fires
+	For time.Duration
+	// RepeatInterval is how often a firing alert is notified again; zero notifies it once
+	RepeatInterval time.Duration
+	Severity       string
+	// Notify names the notifiers of the rule; all when empty
+	Notify []string
+}
+
+// Options configures an Engine
+type Options struct {
+	// Client reads the cluster selected by the context, see clusters.WithName
+	Client kafka_client.Client
+	// Clusters lists the clusters the rules are evaluated against
+	Clusters func() []domain.ClusterInfo
+	// Interval is the time between two evaluations of the rules
+	Interval  time.Duration
+	Rules     []Rule
+	Notifiers map[string]Notifier
+}
+
+// alertKey identifies an alert: there is at most one per rule, cluster and subject
+type alertKey struct {
+	rule    string
+	cluster string
+	subject string
+}
+
+// state is an alert that is pending or firing
+type state struct {
+	alert      domain.Alert
+	notifiedAt time.Time
+}
+
+// Engine evaluates alert rules periodically
+type Engine struct {
+	opts Options
+	ctx  context.Context
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+
+	mu     sync.Mutex
+	alerts map[alertKey]*state
+	// topics are the topics of every cluster at the last evaluation, to detect creations and deletions
+	topics map[string]map[string]bool
+}
+
+// NewEngine creates an Engine; evaluation begins with Start
+func NewEngine(opts Options) *Engine {
+	ctx, stop := context.WithCancel(context.Background())
+	return &Engine{
+		opts:   opts,
+		ctx:    ctx,
+		stop:   stop,
+		alerts: make(map[alertKey]*state),
+		topics: make(map[string]map[string]bool),
+	}
+}
+
+// Start evaluates the rules now and then at every interval
+func (e *Engine) Start() {
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		ticker := time.NewTicker(e.opts.Interval)
+		defer ticker.Stop()
+		for {
+			e.evaluate(time.Now())
+			select {
+			case <-e.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Shutdown stops the evaluation and waits for the notifications being sent
+func (e *Engine) Shutdown() {
+	e.stop()
+	e.wg.Wait()
+}
+
+// Alerts returns the pending and firing alerts, most recent first
+func (e *Engine) Alerts() []domain.Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	alerts := make([]domain.Alert, 0, len(e.alerts))
+	for _, st := range e.alerts {
+		alerts = append(alerts, st.alert)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		if !alerts[i].ActiveAt.Equal(alerts[j].ActiveAt) {
+			return alerts[i].ActiveAt.After(alerts[j].ActiveAt)
+		}
+		return alerts[i].Rule+alerts[i].Cluster+alerts[i].Subject < alerts[j].Rule+alerts[j].Cluster+alerts[j].Subject
+	})
+	return alerts
+}
+
+// TestNotifier sends a test notification to a notifier
+func (e *Engine) TestNotifier(ctx context.Context, name string) error {
+	notifier, ok := e.opts.Notifiers[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownNotifier, name)
+	}
+	now := time.Now().UTC()
+	return notifier.Notify(ctx, domain.Alert{
+		Rule:     "test",
+		Type:     "test",
+		Severity: "info",
+		Status:   domain.AlertFiring,
+		Summary:  "Test notification from Maestro",
+		ActiveAt: now,
+		FiredAt:  &now,
+	})
+}
+
+// evaluate evaluates every rule against every cluster
+func (e *Engine) evaluate(now time.Time) {
+	now = now.UTC()
+	infos := e.opts.Clusters()
+	names := make(map[string]bool, len(infos))
+	for _, info := range infos {
+		if e.ctx.Err() != nil {
+			return
+		}
+		names[info.Name] = true
+		e.evaluateCluster(info.Name, now)
+	}
+
+	// Forget the alerts of removed clusters
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for key := range e.alerts {
+		if !names[key.cluster] {
+			delete(e.alerts, key)
+		}
+	}
+	for cluster := range e.topics {
+		if !names[cluster] {
+			delete(e.topics, cluster)
+		}
+	}
+}
+
+func (e *Engine) evaluateCluster(cluster string, now time.Time) {
+	snap := newSnapshot(clusters.WithName(e.ctx, cluster), e.opts.Client)
+
+	var current map[string]bool
+	for _, rule := range e.opts.Rules {
+		if len(rule.Clusters) > 0 && !slices.Contains(rule.Clusters, cluster) {
+			continue
+		}
+
+		var findings []finding
+		var err error
+		switch rule.Type {
+		case domain.AlertTopicCreated, domain.AlertTopicDeleted:
+			if current == nil {
+				if current, err = snap.topicNames(); err != nil {
+					break
+				}
+			}
+			e.mu.Lock()
+			previous, known := e.topics[cluster]
+			e.mu.Unlock()
+			// The first evaluation of a cluster only records its topics
+			if known {
+				findings = topicEvents(rule, previous, current)
+			}
+		default:
+			findings, err = check(snap, rule)
+		}
+		if err != nil {
+			if e.ctx.Err() == nil {
+				slog.Warn("Failed to evaluate alert rule", "rule", rule.Name, "cluster", cluster, "error", err)
+			}
+			continue
+		}
+
+		if rule.Type == domain.AlertTopicCreated || rule.Type == domain.AlertTopicDeleted {
+			for _, f := range findings {
+				e.notify(rule, e.newAlert(rule, cluster, f, domain.AlertFiring, now))
+			}
+		} else {
+			e.update(rule, cluster, findings, now)
+		}
+	}
+
+	if current != nil {
+		e.mu.Lock()
+		e.topics[cluster] = current
+		e.mu.Unlock()
+	}
+}
+
+func (e *Engine) newAlert(rule Rule, cluster string, f finding, status string, now time.Time) domain.Alert {
+	alert := domain.Alert{
+		Rule:      rule.Name,
+		Type:      rule.Type,
+		Severity:  rule.Severity,
+		Cluster:   cluster,
+		Subject:   f.subject,
+		Status:    status,
+		Summary:   f.summary,
+		Value:     f.value,
+		Threshold: rule.Threshold,
+		ActiveAt:  now,
+	}
+	if status == domain.AlertFiring {
+		alert.FiredAt = &now
+	}
+	return alert
+}
+
+// update moves the alerts of a rule on a cluster through their states given the
+// subjects the condition holds for, and notifies the alerts that fire and resolve
+func (e *Engine) update(rule Rule, cluster string, findings []finding, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	holding := make(map[alertKey]bool, len(findings))
+	for _, f := range findings {
+		key := alertKey{rule.Name, cluster, f.subject}
+		holding[key] = true
+
+		st, ok := e.alerts[key]
+		if !ok {
+			st = &state{alert: e.newAlert(rule, cluster, f, domain.AlertPending, now)}
+			e.alerts[key] = st
+		}
+		st.alert.Value, st.alert.Summary = f.value, f.summary
+
+		switch {
+		case st.alert.Status == domain.AlertPending && now.Sub(st.alert.ActiveAt) >= rule.For:
+			st.alert.Status, st.alert.FiredAt = domain.AlertFiring, &now
+			st.notifiedAt = now
+			e.notify(rule, st.alert)
+		case st.alert.Status == domain.AlertFiring && rule.RepeatInterval > 0 && now.Sub(st.notifiedAt) >= rule.RepeatInterval:
+			st.notifiedAt = now
+			e.notify(rule, st.alert)
+		}
+	}
+
+	for key, st := range e.alerts {
+		if key.rule != rule.Name || key.cluster != cluster || holding[key] {
+			continue
+		}
+		delete(e.alerts, key)
+		// Pending alerts were never notified
+		if st.alert.Status == domain.AlertFiring {
+			st.alert.Status, st.alert.ResolvedAt = domain.AlertResolved, &now
+			e.notify(rule, st.alert)
+		}
+	}
+}
+
+// notify sends an alert to the notifiers of its rule in the background, retrying failures
+func (e *Engine) notify(rule Rule, alert domain.Alert) {
+	names := rule.Notify
+	if len(names) == 0 {
+		for name := range e.opts.Notifiers {
+			names = append(names, name)
+		}
+	}
+
+	for _, name := range names {
+		notifier := e.opts.Notifiers[name]
+		e.wg.Add(1)
+		go func() {
+			defer e.wg.Done()
+
+			var err error
+			for attempt := 1; attempt <= notifyAttempts; attempt++ {
+				ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+				err = notifier.Notify(ctx, alert)
+				cancel()
+				if err == nil {
+					slog.Info("Alert notified", "rule", alert.Rule, "cluster", alert.Cluster, "subject", alert.Subject, "status", alert.Status, "notifier", name)
+					return
+				}
+				if attempt < notifyAttempts {
+					time.Sleep(time.Duration(attempt) * time.Second)
+				}
+			}
+			slog.Error("Failed to send alert notification", "rule", alert.Rule, "cluster", alert.Cluster, "subject", alert.Subject, "notifier", name, "error", err)
+		}()
+	}
+}
@@ -0,0 +1,565 @@
+package alerting
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valeriouberti/maestro/internal/kafka_client/fake"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// request is a notification received by a receiver
+type request struct {
+	header http.Header
+	body   []byte
+}
+
+// newReceiver starts an HTTP server recording the notifications it receives
+func newReceiver(t *testing.T) (*httptest.Server, chan request) {
+	t.Helper()
+	received := make(chan request, 16)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- request{header: r.Header.Clone(), body: body}
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+// newTestEngine creates an engine evaluating rules against a fake cluster named
+// "primary" and notifying a webhook receiver
+func newTestEngine(t *testing.T, rules ...Rule) (*Engine, *fake.Cluster, chan request) {
+	t.Helper()
+	server, received := newReceiver(t)
+	webhook, err := NewWebhookNotifier(WebhookOptions{Name: "hook", URL: server.URL})
+	if err != nil {
+		t.Fatalf("NewWebhookNotifier: %v", err)
+	}
+
+	cluster := fake.New()
+	e := NewEngine(Options{
+		Client:    cluster,
+		Clusters:  func() []domain.ClusterInfo { return []domain.ClusterInfo{{Name: "primary"}} },
+		Interval:  time.Minute,
+		Rules:     rules,
+		Notifiers: map[string]Notifier{"hook": webhook},
+	})
+	t.Cleanup(e.Shutdown)
+	return e, cluster, received
+}
+
+// evaluate evaluates the rules at a time and returns the alerts notified
+func evaluate(t *testing.T, e *Engine, received chan request, now time.Time) []domain.Alert {
+	t.Helper()
+	e.evaluate(now)
+	// Notifications are sent in the background
+	e.wg.Wait()
+
+	var alerts []domain.Alert
+	for {
+		select {
+		case r := <-received:
+			var alert domain.Alert
+			if err := json.Unmarshal(r.body, &alert); err != nil {
+				t.Fatalf("invalid notification %s: %v", r.body, err)
+			}
+			alerts = append(alerts, alert)
+		default:
+			return alerts
+		}
+	}
+}
+
+// expectNotified fails the test unless exactly one alert with the status was notified
+func expectNotified(t *testing.T, alerts []domain.Alert, status string) domain.Alert {
+	t.Helper()
+	if len(alerts) != 1 || alerts[0].Status != status {
+		t.Fatalf("notified %+v, want one %s alert", alerts, status)
+	}
+	return alerts[0]
+}
+
+func TestAlertLifecycle(t *testing.T) {
+	e, cluster, received := newTestEngine(t, Rule{
+		Name:           "idle-consumers",
+		Type:           domain.AlertGroupEmpty,
+		Groups:         "billing*",
+		For:            2 * time.Minute,
+		RepeatInterval: 10 * time.Minute,
+		Severity:       "warning",
+	})
+	member := domain.ConsumerGroupMemberInfo{ClientID: "billing-1", ConsumerID: "billing-1-a", Host: "/10.0.0.1"}
+	cluster.AddConsumerGroup("billing", "Empty")
+	cluster.AddConsumerGroup("shipping", "Empty")
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// The condition holds: the alert is pending, and not notified
+	if alerts := evaluate(t, e, received, start); len(alerts) != 0 {
+		t.Fatalf("pending alert notified: %+v", alerts)
+	}
+	active := e.Alerts()
+	if len(active) != 1 || active[0].Status != domain.AlertPending || active[0].Subject != "billing" {
+		t.Fatalf("alerts = %+v, want billing pending", active)
+	}
+
+	if alerts := evaluate(t, e, received, start.Add(time.Minute)); len(alerts) != 0 {
+		t.Fatalf("alert notified before holding for the duration of the rule: %+v", alerts)
+	}
+
+	// The condition has held for the duration of the rule: the alert fires
+	fired := expectNotified(t, evaluate(t, e, received, start.Add(2*time.Minute)), domain.AlertFiring)
+	if fired.Rule != "idle-consumers" || fired.Cluster != "primary" || fired.Subject != "billing" || fired.Severity != "warning" {
+		t.Errorf("fired alert = %+v", fired)
+	}
+	if !fired.ActiveAt.Equal(start) || fired.FiredAt == nil || !fired.FiredAt.Equal(start.Add(2*time.Minute)) {
+		t.Errorf("fired alert times = active %v fired %v", fired.ActiveAt, fired.FiredAt)
+	}
+
+	// A firing alert is only notified again every repeat interval
+	if alerts := evaluate(t, e, received, start.Add(5*time.Minute)); len(alerts) != 0 {
+		t.Fatalf("alert notified again within the repeat interval: %+v", alerts)
+	}
+	expectNotified(t, evaluate(t, e, received, start.Add(12*time.Minute)), domain.AlertFiring)
+	if alerts := evaluate(t, e, received, start.Add(13*time.Minute)); len(alerts) != 0 {
+		t.Fatalf("alert notified again within the repeat interval: %+v", alerts)
+	}
+
+	// The condition no longer holds: the alert resolves
+	cluster.AddConsumerGroup("billing", "Stable", member)
+	resolved := expectNotified(t, evaluate(t, e, received, start.Add(14*time.Minute)), domain.AlertResolved)
+	if resolved.ResolvedAt == nil || !resolved.ResolvedAt.Equal(start.Add(14*time.Minute)) {
+		t.Errorf("resolved at %v", resolved.ResolvedAt)
+	}
+	if active := e.Alerts(); len(active) != 0 {
+		t.Fatalf("alerts after resolution = %+v, want none", active)
+	}
+
+	// A resolved alert starts over as pending
+	cluster.AddConsumerGroup("billing", "Empty")
+	if alerts := evaluate(t, e, received, start.Add(15*time.Minute)); len(alerts) != 0 {
+		t.Fatalf("pending alert notified: %+v", alerts)
+	}
+}
+
+func TestPendingAlertResolvesSilently(t *testing.T) {
+	e, cluster, received := newTestEngine(t, Rule{Name: "idle", Type: domain.AlertGroupEmpty, For: time.Minute})
+	cluster.AddConsumerGroup("billing", "Empty")
+	start := time.Now()
+
+	evaluate(t, e, received, start)
+	cluster.AddConsumerGroup("billing", "Stable", domain.ConsumerGroupMemberInfo{ClientID: "billing-1", ConsumerID: "billing-1-a"})
+	if alerts := evaluate(t, e, received, start.Add(30*time.Second)); len(alerts) != 0 {
+		t.Fatalf("never fired alert notified: %+v", alerts)
+	}
+	if active := e.Alerts(); len(active) != 0 {
+		t.Fatalf("alerts = %+v, want none", active)
+	}
+}
+
+func TestRepeatIntervalZeroNotifiesOnce(t *testing.T) {
+	e, cluster, received := newTestEngine(t, Rule{Name: "idle", Type: domain.AlertGroupEmpty})
+	cluster.AddConsumerGroup("billing", "Empty")
+	start := time.Now()
+
+	expectNotified(t, evaluate(t, e, received, start), domain.AlertFiring)
+	for _, after := range []time.Duration{time.Minute, time.Hour, 24 * time.Hour} {
+		if alerts := evaluate(t, e, received, start.Add(after)); len(alerts) != 0 {
+			t.Fatalf("alert notified again after %v: %+v", after, alerts)
+		}
+	}
+}
+
+func TestConsumerLagRule(t *testing.T) {
+	e, cluster, received := newTestEngine(t, Rule{Name: "lag", Type: domain.AlertConsumerLag, Topics: "orders", Threshold: 5})
+	ctx := context.Background()
+	if err := cluster.CreateTopic(ctx, domain.TopicInfo{Name: "orders", NumPartitions: 1, ReplicationFactor: 1}); err != nil {
+		t.Fatalf("CreateTopic: %v", err)
+	}
+	for i := range 10 {
+		if err := cluster.PublishMessage(ctx, "orders", 0, strconv.Itoa(i), "created", nil); err != nil {
+			t.Fatalf("PublishMessage: %v", err)
+		}
+	}
+	cluster.AddConsumerGroup("billing", "Empty")
+	if err := cluster.CommitOffset("billing", "orders", 0, 6); err != nil {
+		t.Fatalf("CommitOffset: %v", err)
+	}
+	start := time.Now()
+
+	// A lag of 4 is within the threshold
+	if alerts := evaluate(t, e, received, start); len(alerts) != 0 {
+		t.Fatalf("notified %+v below the threshold", alerts)
+	}
+
+	if err := cluster.CommitOffset("billing", "orders", 0, 2); err != nil {
+		t.Fatalf("CommitOffset: %v", err)
+	}
+	fired := expectNotified(t, evaluate(t, e, received, start.Add(time.Minute)), domain.AlertFiring)
+	if fired.Value != 8 || fired.Threshold != 5 || fired.Subject != "billing" {
+		t.Errorf("fired alert = %+v, want billing with a lag of 8", fired)
+	}
+
+	if err := cluster.CommitOffset("billing", "orders", 0, 10); err != nil {
+		t.Fatalf("CommitOffset: %v", err)
+	}
+	expectNotified(t, evaluate(t, e, received, start.Add(2*time.Minute)), domain.AlertResolved)
+}
+
+func TestTopicEvents(t *testing.T) {
+	e, cluster, received := newTestEngine(t,
+		Rule{Name: "created", Type: domain.AlertTopicCreated, Topics: "prod.*"},
+		Rule{Name: "deleted", Type: domain.AlertTopicDeleted},
+	)
+	ctx := context.Background()
+	if err := cluster.CreateTopic(ctx, domain.TopicInfo{Name: "prod.orders", NumPartitions: 1, ReplicationFactor: 1}); err != nil {
+		t.Fatalf("CreateTopic: %v", err)
+	}
+	start := time.Now()
+
+	// The first evaluation only records the topics
+	if alerts := evaluate(t, e, received, start); len(alerts) != 0 {
+		t.Fatalf("notified %+v on the first evaluation", alerts)
+	}
+
+	for _, name := range []string{"prod.payments", "test.payments"} {
+		if err := cluster.CreateTopic(ctx, domain.TopicInfo{Name: name, NumPartitions: 1, ReplicationFactor: 1}); err != nil {
+			t.Fatalf("CreateTopic: %v", err)
+		}
+	}
+	created := expectNotified(t, evaluate(t, e, received, start.Add(time.Minute)), domain.AlertFiring)
+	if created.Rule != "created" || created.Subject != "prod.payments" || created.Summary != "Topic prod.payments was created" {
+		t.Errorf("created alert = %+v", created)
+	}
+
+	if err := cluster.DeleteTopic(ctx, "prod.orders"); err != nil {
+		t.Fatalf("DeleteTopic: %v", err)
+	}
+	deleted := expectNotified(t, evaluate(t, e, received, start.Add(2*time.Minute)), domain.AlertFiring)
+	if deleted.Rule != "deleted" || deleted.Subject != "prod.orders" {
+		t.Errorf("deleted alert = %+v", deleted)
+	}
+
+	// Events are notified once
+	if alerts := evaluate(t, e, received, start.Add(3*time.Minute)); len(alerts) != 0 {
+		t.Fatalf("notified %+v without changes", alerts)
+	}
+}
+
+// testAlert is the alert the notifier tests send
+func testAlert(status string) domain.Alert {
+	active := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	fired := active.Add(2 * time.Minute)
+	alert := domain.Alert{
+		Rule:      "lag",
+		Type:      domain.AlertConsumerLag,
+		Severity:  "critical",
+		Cluster:   "primary",
+		Subject:   "billing",
+		Status:    status,
+		Summary:   `Consumer group billing is 8 messages behind (threshold 5)`,
+		Value:     8,
+		Threshold: 5,
+		ActiveAt:  active,
+		FiredAt:   &fired,
+	}
+	if status == domain.AlertResolved {
+		resolved := active.Add(time.Hour)
+		alert.ResolvedAt = &resolved
+	}
+	return alert
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	server, received := newReceiver(t)
+	ctx := context.Background()
+	alert := testAlert(domain.AlertFiring)
+
+	// Without a template the alert is sent as JSON
+	n, err := NewWebhookNotifier(WebhookOptions{Name: "hook", URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}})
+	if err != nil {
+		t.Fatalf("NewWebhookNotifier: %v", err)
+	}
+	if err := n.Notify(ctx, alert); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	r := <-received
+	if got := r.header.Get("Authorization"); got != "Bearer token" {
+		t.Errorf("Authorization = %q", got)
+	}
+	if got := r.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	var sent domain.Alert
+	if err := json.Unmarshal(r.body, &sent); err != nil {
+		t.Fatalf("invalid body %s: %v", r.body, err)
+	}
+	if sent.Rule != alert.Rule || sent.Summary != alert.Summary || sent.Value != alert.Value || !sent.FiredAt.Equal(*alert.FiredAt) {
+		t.Errorf("sent %+v, want %+v", sent, alert)
+	}
+
+	// A template renders the body; json embeds fields in JSON payloads
+	n, err = NewWebhookNotifier(WebhookOptions{
+		Name:     "hook",
+		URL:      server.URL,
+		Template: `{"title": {{json .Summary}}, "level": "{{upper .Severity}}", "resolved": {{if .ResolvedAt}}true{{else}}false{{end}}}`,
+	})
+	if err != nil {
+		t.Fatalf("NewWebhookNotifier: %v", err)
+	}
+	if err := n.Notify(ctx, alert); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	r = <-received
+	want := `{"title": "Consumer group billing is 8 messages behind (threshold 5)", "level": "CRITICAL", "resolved": false}`
+	if string(r.body) != want {
+		t.Errorf("body = %s, want %s", r.body, want)
+	}
+}
+
+func TestWebhookNotifierErrors(t *testing.T) {
+	if _, err := NewWebhookNotifier(WebhookOptions{Name: "hook", URL: "http://localhost", Template: "{{.Summary"}); err == nil {
+		t.Error("NewWebhookNotifier accepted an invalid template")
+	}
+
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+	}))
+	defer rejecting.Close()
+	n, err := NewWebhookNotifier(WebhookOptions{Name: "hook", URL: rejecting.URL})
+	if err != nil {
+		t.Fatalf("NewWebhookNotifier: %v", err)
+	}
+	err = n.Notify(context.Background(), testAlert(domain.AlertFiring))
+	if err == nil || !strings.Contains(err.Error(), "status 401: invalid token") {
+		t.Errorf("Notify error = %v, want the rejection", err)
+	}
+
+	// Templates referring to unknown fields fail when rendered
+	n, err = NewWebhookNotifier(WebhookOptions{Name: "hook", URL: rejecting.URL, Template: "{{.Unknown}}"})
+	if err != nil {
+		t.Fatalf("NewWebhookNotifier: %v", err)
+	}
+	if err := n.Notify(context.Background(), testAlert(domain.AlertFiring)); err == nil || !strings.Contains(err.Error(), "failed to render notification") {
+		t.Errorf("Notify error = %v, want a rendering error", err)
+	}
+}
+
+func TestSlackNotifier(t *testing.T) {
+	server, received := newReceiver(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		template string
+		alert    domain.Alert
+		want     string
+	}{
+		{
+			name:  "default firing",
+			alert: testAlert(domain.AlertFiring),
+			want:  ":rotating_light: *FIRING* [critical] lag on primary: Consumer group billing is 8 messages behind (threshold 5)",
+		},
+		{
+			name:  "default resolved",
+			alert: testAlert(domain.AlertResolved),
+			want:  ":white_check_mark: *RESOLVED* [critical] lag on primary: Consumer group billing is 8 messages behind (threshold 5)",
+		},
+		{
+			name:     "custom",
+			template: `{{.Subject}} "{{.Status}}" {{.Value}}/{{.Threshold}}`,
+			alert:    testAlert(domain.AlertFiring),
+			want:     `billing "firing" 8/5`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := NewSlackNotifier("slack", server.URL, tt.template)
+			if err != nil {
+				t.Fatalf("NewSlackNotifier: %v", err)
+			}
+			if err := n.Notify(ctx, tt.alert); err != nil {
+				t.Fatalf("Notify: %v", err)
+			}
+
+			r := <-received
+			var message map[string]string
+			if err := json.Unmarshal(r.body, &message); err != nil {
+				t.Fatalf("invalid body %s: %v", r.body, err)
+			}
+			if len(message) != 1 || message["text"] != tt.want {
+				t.Errorf("message = %q, want text %q", message, tt.want)
+			}
+		})
+	}
+}
+
+// mail is a message received by an SMTP stand-in
+type mail struct {
+	from string
+	to   []string
+	data string
+}
+
+// newSMTPServer starts an SMTP server accepting messages without authentication and
+// returns its port
+func newSMTPServer(t *testing.T) (int, chan mail) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan mail, 4)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, received)
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port, received
+}
+
+func serveSMTP(conn net.Conn, received chan mail) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	var m mail
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.TrimSpace(line)
+		switch verb := strings.ToUpper(strings.SplitN(command, " ", 2)[0]); verb {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL":
+			m.from = strings.Trim(strings.TrimPrefix(command, "MAIL FROM:"), "<>")
+			reply("250 OK")
+		case "RCPT":
+			m.to = append(m.to, strings.Trim(strings.TrimPrefix(command, "RCPT TO:"), "<>"))
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			m.data = data.String()
+			received <- m
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestEmailNotifier(t *testing.T) {
+	port, received := newSMTPServer(t)
+
+	n, err := NewEmailNotifier(EmailOptions{
+		Name: "oncall",
+		Host: "127.0.0.1",
+		Port: port,
+		From: "maestro@example.com",
+		To:   []string{"oncall@example.com", "kafka@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("NewEmailNotifier: %v", err)
+	}
+	if err := n.Notify(context.Background(), testAlert(domain.AlertResolved)); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	m := <-received
+	if m.from != "maestro@example.com" || len(m.to) != 2 {
+		t.Errorf("envelope from %q to %v", m.from, m.to)
+	}
+	header, body, ok := strings.Cut(m.data, "\r\n\r\n")
+	if !ok {
+		t.Fatalf("message without body: %q", m.data)
+	}
+	for _, want := range []string{
+		"From: maestro@example.com",
+		"To: oncall@example.com, kafka@example.com",
+		"Subject: [Maestro] RESOLVED: lag - billing",
+		"Content-Type: text/plain; charset=UTF-8",
+	} {
+		if !strings.Contains(header+"\r\n", want+"\r\n") {
+			t.Errorf("header %q lacks %q", header, want)
+		}
+	}
+	wantBody := strings.Join([]string{
+		"Consumer group billing is 8 messages behind (threshold 5)",
+		"",
+		"Status:    resolved",
+		"Rule:      lag (consumer-lag)",
+		"Severity:  critical",
+		"Cluster:   primary",
+		"Subject:   billing",
+		"Value:     8 (threshold 5)",
+		"Active at: 2026-05-01T12:00:00Z",
+		"Resolved:  2026-05-01T13:00:00Z",
+		"",
+	}, "\r\n")
+	if body != wantBody {
+		t.Errorf("body = %q, want %q", body, wantBody)
+	}
+
+	// A template replaces the body
+	n, err = NewEmailNotifier(EmailOptions{Name: "oncall", Host: "127.0.0.1", Port: port, From: "maestro@example.com", To: []string{"oncall@example.com"}, Template: "{{.Rule}} is {{.Status}}"})
+	if err != nil {
+		t.Fatalf("NewEmailNotifier: %v", err)
+	}
+	if err := n.Notify(context.Background(), testAlert(domain.AlertFiring)); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	m = <-received
+	if _, body, _ := strings.Cut(m.data, "\r\n\r\n"); body != "lag is firing\r\n" {
+		t.Errorf("body = %q, want the rendered template", body)
+	}
+}
+
+func TestTestNotifier(t *testing.T) {
+	e, _, received := newTestEngine(t)
+
+	if err := e.TestNotifier(context.Background(), "hook"); err != nil {
+		t.Fatalf("TestNotifier: %v", err)
+	}
+	var alert domain.Alert
+	if err := json.Unmarshal((<-received).body, &alert); err != nil {
+		t.Fatalf("invalid notification: %v", err)
+	}
+	if alert.Rule != "test" || alert.Status != domain.AlertFiring {
+		t.Errorf("test notification = %+v", alert)
+	}
+
+	if err := e.TestNotifier(context.Background(), "missing"); err == nil {
+		t.Error("TestNotifier accepted an unknown notifier")
+	}
+}
@@ -0,0 +1,248 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// Notifier types
+const (
+	NotifierWebhook = "webhook"
+	NotifierSlack   = "slack"
+	NotifierEmail   = "email"
+)
+
+// Notifier sends alert notifications
+type Notifier interface {
+	Notify(ctx context.Context, alert domain.Alert) error
+}
+
+// Default templates of the notifiers that send text
+const (
+	defaultSlackTemplate = `{{if eq .Status "resolved"}}:white_check_mark: *RESOLVED*{{else}}:rotating_light: *FIRING*{{end}} [{{.Severity}}] {{.Rule}} on {{.Cluster}}: {{.Summary}}`
+	defaultEmailTemplate = `{{.Summary}}
+
+Status:    {{.Status}}
+Rule:      {{.Rule}} ({{.Type}})
+Severity:  {{.Severity}}
+Cluster:   {{.Cluster}}
+Subject:   {{.Subject}}
+Value:     {{.Value}} (threshold {{.Threshold}})
+Active at: {{.ActiveAt.Format "2006-01-02T15:04:05Z07:00"}}
+{{- if .ResolvedAt}}
+Resolved:  {{.ResolvedAt.Format "2006-01-02T15:04:05Z07:00"}}{{end}}
+`
+)
+
+// templateFuncs are available to notification templates in addition to the built-in functions
+var templateFuncs = template.FuncMap{
+	// json encodes a value as JSON, to embed alert fields in JSON payloads
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// parseTemplate parses a notification template, falling back to a default one
+func parseTemplate(name, text, fallback string) (*template.Template, error) {
+	if text == "" {
+		text = fallback
+	}
+	tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template of notifier '%s': %w", name, err)
+	}
+	return tmpl, nil
+}
+
+func render(tmpl *template.Template, alert domain.Alert) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, alert); err != nil {
+		return nil, fmt.Errorf("failed to render notification: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// httpClient sends the requests of webhook and Slack notifiers
+var httpClient = &http.Client{Timeout: notifyTimeout}
+
+// post sends a notification request, failing on responses other than 2xx
+func post(ctx context.Context, url string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "maestro")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("notification rejected with status %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// WebhookOptions configures a generic webhook notifier
+type WebhookOptions struct {
+	Name    string
+	URL     string
+	Headers map[string]string
+	// Template renders the request body; the alert is sent as JSON when empty
+	Template string
+}
+
+// webhookNotifier POSTs alerts to an HTTP endpoint
+type webhookNotifier struct {
+	url     string
+	headers map[string]string
+	tmpl    *template.Template
+}
+
+// NewWebhookNotifier creates a notifier POSTing every alert to a URL
+func NewWebhookNotifier(opts WebhookOptions) (Notifier, error) {
+	n := &webhookNotifier{url: opts.URL, headers: opts.Headers}
+	if opts.Template != "" {
+		tmpl, err := parseTemplate(opts.Name, opts.Template, "")
+		if err != nil {
+			return nil, err
+		}
+		n.tmpl = tmpl
+	}
+	return n, nil
+}
+
+// Notify implements Notifier
+func (n *webhookNotifier) Notify(ctx context.Context, alert domain.Alert) error {
+	var body []byte
+	var err error
+	if n.tmpl != nil {
+		body, err = render(n.tmpl, alert)
+	} else {
+		body, err = json.Marshal(alert)
+	}
+	if err != nil {
+		return err
+	}
+	return post(ctx, n.url, n.headers, body)
+}
+
+// slackNotifier posts alerts as messages to Slack-compatible incoming webhooks
+type slackNotifier struct {
+	url  string
+	tmpl *template.Template
+}
+
+// NewSlackNotifier creates a notifier posting a message to a Slack incoming webhook URL.
+// The template renders the text of the message.
+func NewSlackNotifier(name, url, text string) (Notifier, error) {
+	tmpl, err := parseTemplate(name, text, defaultSlackTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return &slackNotifier{url: url, tmpl: tmpl}, nil
+}
+
+// Notify implements Notifier
+func (n *slackNotifier) Notify(ctx context.Context, alert domain.Alert) error {
+	text, err := render(n.tmpl, alert)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]string{"text": string(text)})
+	if err != nil {
+		return err
+	}
+	return post(ctx, n.url, nil, body)
+}
+
+// EmailOptions configures an email notifier
+type EmailOptions struct {
+	Name     string
+	Host     string
+	Port     int // Default 587
+	Username string
+	Password string
+	From     string
+	To       []string
+	// Template renders the body of the message
+	Template string
+}
+
+// emailNotifier sends alerts by email over SMTP
+type emailNotifier struct {
+	opts EmailOptions
+	tmpl *template.Template
+}
+
+// NewEmailNotifier creates a notifier sending an email for every alert. STARTTLS is used
+// when the server supports it; credentials are only sent over TLS.
+func NewEmailNotifier(opts EmailOptions) (Notifier, error) {
+	if opts.Port == 0 {
+		opts.Port = 587
+	}
+	tmpl, err := parseTemplate(opts.Name, opts.Template, defaultEmailTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return &emailNotifier{opts: opts, tmpl: tmpl}, nil
+}
+
+// Notify implements Notifier
+func (n *emailNotifier) Notify(ctx context.Context, alert domain.Alert) error {
+	body, err := render(n.tmpl, alert)
+	if err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("[Maestro] %s: %s", strings.ToUpper(alert.Status), alert.Rule)
+	if alert.Subject != "" {
+		subject += " - " + alert.Subject
+	}
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.opts.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.opts.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(string(body), "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if n.opts.Username != "" {
+		auth = smtp.PlainAuth("", n.opts.Username, n.opts.Password, n.opts.Host)
+	}
+	addr := net.JoinHostPort(n.opts.Host, strconv.Itoa(n.opts.Port))
+
+	// net/smtp takes no context: give up waiting for it once the context is done
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, n.opts.From, n.opts.To, msg.Bytes())
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,229 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"sync"
+
+	"github.com/valeriouberti/maestro/internal/kafka_client"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// groupConcurrency bounds the consumer groups whose lag is read at the same time
+const groupConcurrency = 8
+
+// finding is a subject a rule's condition holds for
+type finding struct {
+	subject string
+	value   int64
+	summary string
+}
+
+// snapshot reads the state of a cluster once per evaluation, however many rules use it
+type snapshot struct {
+	ctx    context.Context
+	client kafka_client.Client
+	topics func() ([]domain.TopicInfo, error)
+	groups func() ([]domain.ConsumerGroupInfo, error)
+	lags   map[string]*domain.ConsumerGroupLag
+}
+
+func newSnapshot(ctx context.Context, client kafka_client.Client) *snapshot {
+	return &snapshot{
+		ctx:    ctx,
+		client: client,
+		topics: sync.OnceValues(func() ([]domain.TopicInfo, error) { return client.ListTopics(ctx) }),
+		groups: sync.OnceValues(func() ([]domain.ConsumerGroupInfo, error) { return client.ListConsumerGroups(ctx) }),
+		lags:   make(map[string]*domain.ConsumerGroupLag),
+	}
+}
+
+func (s *snapshot) topicNames() (map[string]bool, error) {
+	topics, err := s.topics()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(topics))
+	for _, topic := range topics {
+		names[topic.Name] = true
+	}
+	return names, nil
+}
+
+// lag returns the lag of consumer groups, reading those not read yet concurrently.
+// Groups deleted in the meantime are left out.
+func (s *snapshot) lag(groupIDs []string) (map[string]*domain.ConsumerGroupLag, error) {
+	var missing []string
+	for _, groupID := range groupIDs {
+		if _, ok := s.lags[groupID]; !ok {
+			missing = append(missing, groupID)
+		}
+	}
+
+	lags := make([]*domain.ConsumerGroupLag, len(missing))
+	errs := make([]error, len(missing))
+	sem := make(chan struct{}, groupConcurrency)
+	var wg sync.WaitGroup
+	for i, groupID := range missing {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			lag, err := s.client.GetConsumerGroupLag(s.ctx, groupID)
+			if err != nil && !errors.Is(err, kafka_client.ErrNotFound) {
+				errs[i] = fmt.Errorf("consumer group '%s': %w", groupID, err)
+			}
+			lags[i] = lag
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	for i, groupID := range missing {
+		s.lags[groupID] = lags[i]
+	}
+
+	result := make(map[string]*domain.ConsumerGroupLag, len(groupIDs))
+	for _, groupID := range groupIDs {
+		if lag := s.lags[groupID]; lag != nil {
+			result[groupID] = lag
+		}
+	}
+	return result, nil
+}
+
+// matches reports whether a name matches a glob pattern; an empty pattern matches every name
+func matches(pattern, name string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, _ := path.Match(pattern, name)
+	return ok
+}
+
+// check returns the subjects the condition of a rule holds for
+func check(s *snapshot, rule Rule) ([]finding, error) {
+	switch rule.Type {
+	case domain.AlertConsumerLag:
+		return checkConsumerLag(s, rule)
+	case domain.AlertGroupEmpty:
+		return checkGroupEmpty(s, rule)
+	case domain.AlertUnderReplicated:
+		return checkUnderReplicated(s, rule)
+	default:
+		return nil, fmt.Errorf("unknown alert rule type %q", rule.Type)
+	}
+}
+
+func checkConsumerLag(s *snapshot, rule Rule) ([]finding, error) {
+	groups, err := s.groups()
+	if err != nil {
+		return nil, err
+	}
+	var groupIDs []string
+	for _, group := range groups {
+		if matches(rule.Groups, group.GroupID) {
+			groupIDs = append(groupIDs, group.GroupID)
+		}
+	}
+	lags, err := s.lag(groupIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []finding
+	for _, groupID := range groupIDs {
+		lag, ok := lags[groupID]
+		if !ok {
+			continue
+		}
+		var total int64
+		for _, partition := range lag.Partitions {
+			if matches(rule.Topics, partition.Topic) {
+				total += partition.Lag
+			}
+		}
+		if total > rule.Threshold {
+			findings = append(findings, finding{
+				subject: groupID,
+				value:   total,
+				summary: fmt.Sprintf("Consumer group %s is %d messages behind (threshold %d)", groupID, total, rule.Threshold),
+			})
+		}
+	}
+	return findings, nil
+}
+
+func checkGroupEmpty(s *snapshot, rule Rule) ([]finding, error) {
+	groups, err := s.groups()
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []finding
+	for _, group := range groups {
+		// Simple groups only commit offsets and never have members
+		if group.State != "Empty" || group.Simple || !matches(rule.Groups, group.GroupID) {
+			continue
+		}
+		findings = append(findings, finding{
+			subject: group.GroupID,
+			summary: fmt.Sprintf("Consumer group %s has no members", group.GroupID),
+		})
+	}
+	return findings, nil
+}
+
+func checkUnderReplicated(s *snapshot, rule Rule) ([]finding, error) {
+	topics, err := s.topics()
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []finding
+	for _, topic := range topics {
+		if !matches(rule.Topics, topic.Name) {
+			continue
+		}
+		var count int64
+		for _, partition := range topic.Partitions {
+			if len(partition.ISR) < len(partition.Replicas) {
+				count++
+			}
+		}
+		if count > rule.Threshold {
+			findings = append(findings, finding{
+				subject: topic.Name,
+				value:   count,
+				summary: fmt.Sprintf("Topic %s has %d under-replicated partitions", topic.Name, count),
+			})
+		}
+	}
+	return findings, nil
+}
+
+// topicEvents returns the topics created or deleted, depending on the rule, between two
+// evaluations
+func topicEvents(rule Rule, previous, current map[string]bool) []finding {
+	before, after, verb := previous, current, "created"
+	if rule.Type == domain.AlertTopicDeleted {
+		before, after, verb = current, previous, "deleted"
+	}
+
+	var findings []finding
+	for name := range after {
+		if !before[name] && matches(rule.Topics, name) {
+			findings = append(findings, finding{subject: name, summary: fmt.Sprintf("Topic %s was %s", name, verb)})
+		}
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		return findings[i].subject < findings[j].subject
+	})
+	return findings
+}
@@ -0,0 +1,124 @@
+// Package audit records an audit trail of the mutating operations performed through the API.
+//
+// Records are handed to a Logger, which writes them to one or more sinks in the
+// background. Sinks that can be read back, such as the file sink, also serve searches.
+package audit
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// Outcomes of audited operations
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeDenied  = "denied"
+)
+
+// queueSize is the number of records buffered before Log blocks
+const queueSize = 1024
+
+// ErrNotSearchable is returned by Search when no sink can be searched
+var ErrNotSearchable = errors.New("no searchable audit sink is configured")
+
+// Sink stores audit records
+type Sink interface {
+	Write(record domain.AuditRecord) error
+	Close() error
+}
+
+// Searcher is implemented by sinks whose records can be read back
+type Searcher interface {
+	// Search returns the records matching the query, most recent first
+	Search(query domain.AuditQuery) ([]domain.AuditRecord, error)
+}
+
+// Logger writes audit records to its sinks
+type Logger struct {
+	sinks   []Sink
+	records chan domain.AuditRecord
+	done    chan struct{}
+
+	closeOnce sync.Once
+}
+
+// NewLogger creates a Logger writing to the given sinks and starts its writer
+func NewLogger(sinks ...Sink) *Logger {
+	l := &Logger{
+		sinks:   sinks,
+		records: make(chan domain.AuditRecord, queueSize),
+		done:    make(chan struct{}),
+	}
+	go l.run()
+	return l
+}
+
+// Log queues a record for writing. It blocks rather than dropping records when
+// the sinks fall behind.
+func (l *Logger) Log(record domain.AuditRecord) {
+	l.records <- record
+}
+
+// Search returns the records matching the query from the first searchable sink
+func (l *Logger) Search(query domain.AuditQuery) ([]domain.AuditRecord, error) {
+	for _, sink := range l.sinks {
+		if searcher, ok := sink.(Searcher); ok {
+			return searcher.Search(query)
+		}
+	}
+	return nil, ErrNotSearchable
+}
+
+// Close writes the queued records and closes the sinks
+func (l *Logger) Close() {
+	l.closeOnce.Do(func() {
+		close(l.records)
+		<-l.done
+
+		for _, sink := range l.sinks {
+			if err := sink.Close(); err != nil {
+				slog.Error("Failed to close audit sink", "error", err)
+			}
+		}
+	})
+}
+
+func (l *Logger) run() {
+	defer close(l.done)
+
+	for record := range l.records {
+		for _, sink := range l.sinks {
+			if err := sink.Write(record); err != nil {
+				slog.Error("Failed to write audit record", "record_id", record.ID, "error", err)
+			}
+		}
+	}
+}
+
+// Matches reports whether a record is selected by a query
+func Matches(query domain.AuditQuery, record domain.AuditRecord) bool {
+	if query.User != "" && record.User != query.User {
+		return false
+	}
+	if query.Action != "" && !strings.Contains(record.Action, query.Action) {
+		return false
+	}
+	if query.Resource != "" && record.Resource != query.Resource {
+		return false
+	}
+	if query.Outcome != "" && record.Outcome != query.Outcome {
+		return false
+	}
+	if query.Since != nil && record.Time.Before(*query.Since) {
+		return false
+	}
+	if query.Until != nil && !record.Time.Before(*query.Until) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,185 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/valeriouberti/maestro/internal/auth"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// maxCapturedBody is the largest request or response body stored in a record
+const maxCapturedBody = 16 * 1024
+
+// Context keys for annotations set by handlers
+const (
+	changesKey  = "maestro.audit.changes"
+	resourceKey = "maestro.audit.resource"
+)
+
+// resourceParams are the route parameters naming the resource of an operation
+var resourceParams = []string{"topicName", "groupId", "jobId", "templateName"}
+
+// Middleware returns a gin middleware that records every mutating request (anything
+// but GET, HEAD and OPTIONS), including the ones rejected by authentication or access
+// control. It must be registered before the authentication middleware.
+func Middleware(l *Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		request := &capture{}
+		c.Request.Body = &teeBody{ReadCloser: c.Request.Body, capture: request}
+		response := &responseCapture{ResponseWriter: c.Writer}
+		c.Writer = response
+
+		c.Next()
+
+		record := domain.AuditRecord{
+			ID:          uuid.NewString(),
+			Time:        start.UTC(),
+			ClientIP:    c.ClientIP(),
+			Action:      c.Request.Method + " " + c.FullPath(),
+			Resource:    c.GetString(resourceKey),
+			Path:        c.Request.URL.RequestURI(),
+			RequestSize: request.size,
+			Status:      c.Writer.Status(),
+			Outcome:     outcome(c.Writer.Status()),
+			DurationMs:  time.Since(start).Milliseconds(),
+		}
+		if c.FullPath() == "" {
+			record.Action = c.Request.Method + " " + c.Request.URL.Path
+		}
+		if principal := auth.PrincipalFrom(c); principal != nil {
+			record.User = principal.Name
+			record.AuthMethod = principal.Method
+		}
+		if record.Resource == "" {
+			for _, param := range resourceParams {
+				if value := c.Param(param); value != "" {
+					record.Resource = value
+					break
+				}
+			}
+		}
+		if isJSON(c.ContentType()) {
+			record.Request = request.json()
+		}
+		record.Response = response.body.json()
+		if changes, ok := c.Get(changesKey); ok {
+			record.Changes = changes.([]domain.ConfigChange)
+		}
+
+		l.Log(record)
+	}
+}
+
+// SetResource names the resource of an operation whose route does not contain it,
+// e.g. the topic of a topic creation
+func SetResource(c *gin.Context, name string) {
+	c.Set(resourceKey, name)
+}
+
+// SetChanges attaches configuration changes to the audit record of a request
+func SetChanges(c *gin.Context, changes []domain.ConfigChange) {
+	c.Set(changesKey, changes)
+}
+
+// DiffConfig returns the entries that differ between two configurations, sorted by key
+func DiffConfig(before, after map[string]string) []domain.ConfigChange {
+	var changes []domain.ConfigChange
+	for key, oldValue := range before {
+		newValue, ok := after[key]
+		if !ok {
+			changes = append(changes, domain.ConfigChange{Key: key, Before: &oldValue})
+		} else if newValue != oldValue {
+			changes = append(changes, domain.ConfigChange{Key: key, Before: &oldValue, After: &newValue})
+		}
+	}
+	for key, newValue := range after {
+		if _, ok := before[key]; !ok {
+			changes = append(changes, domain.ConfigChange{Key: key, After: &newValue})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
+
+func outcome(status int) string {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return OutcomeDenied
+	case status >= 400:
+		return OutcomeFailure
+	default:
+		return OutcomeSuccess
+	}
+}
+
+func isJSON(contentType string) bool {
+	return contentType == "application/json" || strings.HasSuffix(contentType, "+json")
+}
+
+// capture keeps the first maxCapturedBody bytes of a body and counts its size
+type capture struct {
+	buf  bytes.Buffer
+	size int64
+}
+
+func (c *capture) write(p []byte) {
+	c.size += int64(len(p))
+	if room := maxCapturedBody - c.buf.Len(); room > 0 {
+		c.buf.Write(p[:min(room, len(p))])
+	}
+}
+
+// json returns the captured body if it is complete, valid JSON
+func (c *capture) json() json.RawMessage {
+	if c.size == 0 || c.size > maxCapturedBody || !json.Valid(c.buf.Bytes()) {
+		return nil
+	}
+	return json.RawMessage(c.buf.Bytes())
+}
+
+// teeBody captures a request body as the handler reads it
+type teeBody struct {
+	io.ReadCloser
+	capture *capture
+}
+
+func (t *teeBody) Read(p []byte) (int, error) {
+	n, err := t.ReadCloser.Read(p)
+	t.capture.write(p[:n])
+	return n, err
+}
+
+// responseCapture captures the response body as the handler writes it
+type responseCapture struct {
+	gin.ResponseWriter
+	body capture
+}
+
+func (w *responseCapture) Write(p []byte) (int, error) {
+	w.body.write(p)
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *responseCapture) WriteString(s string) (int, error) {
+	w.body.write([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,214 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/valeriouberti/maestro/internal/storage"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// Sink types
+const (
+	SinkFile   = "file"
+	SinkStdout = "stdout"
+	SinkKafka  = "kafka"
+	SinkStore  = "store"
+)
+
+// FileSink appends records as JSON lines to a file and can search them
+type FileSink struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink opens, or creates, the audit file
+func NewFileSink(path string) (*FileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create audit directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %w", err)
+	}
+
+	return &FileSink{path: path, file: file}, nil
+}
+
+// Write implements Sink
+func (s *FileSink) Write(record domain.AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close implements Sink
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// Search implements Searcher by scanning the whole file
+func (s *FileSink) Search(query domain.AuditQuery) ([]domain.AuditRecord, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %w", err)
+	}
+	defer file.Close()
+
+	var matches []domain.AuditRecord
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var record domain.AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			// Skip a partially written last line
+			continue
+		}
+		if !Matches(query, record) {
+			continue
+		}
+
+		matches = append(matches, record)
+		// Keep only the most recent records when the file is large
+		if query.Limit > 0 && len(matches) > 2*query.Limit {
+			matches = slices.Delete(matches, 0, len(matches)-query.Limit)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit file: %w", err)
+	}
+
+	if query.Limit > 0 && len(matches) > query.Limit {
+		matches = matches[len(matches)-query.Limit:]
+	}
+	slices.Reverse(matches)
+	return matches, nil
+}
+
+// WriterSink writes records as JSON lines to a writer such as os.Stdout
+type WriterSink struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewWriterSink creates a WriterSink
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{encoder: json.NewEncoder(w)}
+}
+
+// Write implements Sink
+func (s *WriterSink) Write(record domain.AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.encoder.Encode(record)
+}
+
+// Close implements Sink
+func (s *WriterSink) Close() error {
+	return nil
+}
+
+// Publisher publishes a single message to a Kafka topic. It is implemented by kafka_client.Client.
+type Publisher interface {
+	PublishMessage(ctx context.Context, topicName string, partition int32, key string, value string, headers map[string]string) error
+}
+
+// KafkaSink publishes records as JSON messages keyed by user to a Kafka topic
+type KafkaSink struct {
+	publisher Publisher
+	topic     string
+}
+
+// NewKafkaSink creates a KafkaSink
+func NewKafkaSink(publisher Publisher, topic string) *KafkaSink {
+	return &KafkaSink{publisher: publisher, topic: topic}
+}
+
+// Write implements Sink
+func (s *KafkaSink) Write(record domain.AuditRecord) error {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	headers := map[string]string{"maestro-audit-action": record.Action}
+	return s.publisher.PublishMessage(ctx, s.topic, -1, record.User, string(value), headers)
+}
+
+// Close implements Sink
+func (s *KafkaSink) Close() error {
+	return nil
+}
+
+// StoreSink keeps records in the audit bucket of a storage.Store and can search them
+type StoreSink struct {
+	store storage.Store
+}
+
+// NewStoreSink creates a StoreSink
+func NewStoreSink(store storage.Store) *StoreSink {
+	return &StoreSink{store: store}
+}
+
+// Write implements Sink. Keys start with the zero-padded record time so that
+// records are kept in chronological order.
+func (s *StoreSink) Write(record domain.AuditRecord) error {
+	key := fmt.Sprintf("%020d-%s", record.Time.UnixNano(), record.ID)
+	return storage.PutJSON(s.store, storage.BucketAudit, key, record)
+}
+
+// Close implements Sink. The store is owned by the caller.
+func (s *StoreSink) Close() error {
+	return nil
+}
+
+// Search implements Searcher
+func (s *StoreSink) Search(query domain.AuditQuery) ([]domain.AuditRecord, error) {
+	var matches []domain.AuditRecord
+	err := s.store.ForEach(storage.BucketAudit, func(key string, value []byte) error {
+		var record domain.AuditRecord
+		if err := json.Unmarshal(value, &record); err != nil {
+			return fmt.Errorf("failed to decode audit record %s: %w", key, err)
+		}
+		if !Matches(query, record) {
+			return nil
+		}
+
+		matches = append(matches, record)
+		if query.Limit > 0 && len(matches) > 2*query.Limit {
+			matches = slices.Delete(matches, 0, len(matches)-query.Limit)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if query.Limit > 0 && len(matches) > query.Limit {
+		matches = matches[len(matches)-query.Limit:]
+	}
+	slices.Reverse(matches)
+	return matches, nil
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// APIKeyHeader is the request header carrying an API key. Keys can also be sent
+// as "Authorization: ApiKey <key>".
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuthenticator authenticates requests carrying a static API key.
+//
+// Keys are read from a file with one "name:sha256hex[:group1,group2]" entry per
+// line, where sha256hex is the hex encoded SHA-256 hash of the key. Empty lines
+// and lines starting with # are ignored. A hash can be generated with:
+//
+//	printf '%s' "$KEY" | sha256sum
+type APIKeyAuthenticator struct {
+	keys []apiKey
+}
+
+type apiKey struct {
+	name   string
+	hash   []byte
+	groups []string
+}
+
+// NewAPIKeyAuthenticator creates an APIKeyAuthenticator from an API keys file
+func NewAPIKeyAuthenticator(path string) (*APIKeyAuthenticator, error) {
+	if path == "" {
+		return nil, fmt.Errorf("API key authentication requires an API keys file")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open API keys file: %w", err)
+	}
+	defer file.Close()
+
+	var keys []apiKey
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) < 2 || parts[0] == "" {
+			return nil, fmt.Errorf("API keys file line %d: expected name:sha256hex[:groups]", lineNumber)
+		}
+		hash, err := hex.DecodeString(parts[1])
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("API keys file line %d: key hash must be a hex encoded SHA-256 digest", lineNumber)
+		}
+
+		key := apiKey{name: parts[0], hash: hash}
+		if len(parts) == 3 {
+			for _, group := range strings.Split(parts[2], ",") {
+				if group = strings.TrimSpace(group); group != "" {
+					key.groups = append(key.groups, group)
+				}
+			}
+		}
+		keys = append(keys, key)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read API keys file: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("API keys file %s contains no keys", path)
+	}
+
+	return &APIKeyAuthenticator{keys: keys}, nil
+}
+
+// Authenticate implements Authenticator
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	key := r.Header.Get(APIKeyHeader)
+	if key == "" {
+		scheme, value, found := strings.Cut(r.Header.Get("Authorization"), " ")
+		if found && strings.EqualFold(scheme, "ApiKey") {
+			key = strings.TrimSpace(value)
+		}
+	}
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+
+	hash := sha256.Sum256([]byte(key))
+	var match *apiKey
+	// Compare against every key so the response time does not reveal which one matched
+	for i := range a.keys {
+		if subtle.ConstantTimeCompare(hash[:], a.keys[i].hash) == 1 {
+			match = &a.keys[i]
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("%w: unknown API key", ErrInvalidCredentials)
+	}
+
+	return &Principal{
+		Subject: match.name,
+		Name:    match.name,
+		Groups:  match.groups,
+		Method:  MethodAPIKey,
+	}, nil
+}
+
+// Challenge implements Authenticator
+func (a *APIKeyAuthenticator) Challenge() string {
+	return `ApiKey realm="maestro"`
+}
@@ -0,0 +1,168 @@
+// Package auth authenticates HTTP API requests.
+//
+// Three methods are supported and can be combined: OIDC/JWT bearer tokens, static
+// API keys stored as SHA-256 hashes, and HTTP basic auth backed by an htpasswd file.
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/valeriouberti/maestro/internal/logging"
+	"github.com/valeriouberti/maestro/internal/problem"
+)
+
+// Authentication methods
+const (
+	MethodOIDC   = "oidc"
+	MethodAPIKey = "apikey"
+	MethodBasic  = "basic"
+)
+
+// principalKey is the gin context key under which the authenticated principal is stored
+const principalKey = "maestro.principal"
+
+var (
+	// ErrNoCredentials is returned by an Authenticator when the request carries no
+	// credentials it is responsible for
+	ErrNoCredentials = errors.New("no credentials provided")
+	// ErrInvalidCredentials is returned when credentials are present but not valid
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Principal is an authenticated caller of the API
+type Principal struct {
+	Subject string   `json:"subject"`
+	Name    string   `json:"name"`
+	Groups  []string `json:"groups,omitempty"`
+	Method  string   `json:"method"`
+}
+
+// Authenticator verifies the credentials carried by a request
+type Authenticator interface {
+	// Authenticate returns the principal of the request, ErrNoCredentials if the
+	// request carries no credentials for this authenticator, or another error if
+	// the credentials are invalid
+	Authenticate(r *http.Request) (*Principal, error)
+	// Challenge returns the WWW-Authenticate challenge of the authenticator
+	Challenge() string
+}
+
+// Options configures the authenticators created by New
+type Options struct {
+	// Methods lists the enabled authentication methods. Authentication is disabled when empty.
+	Methods []string
+
+	OIDC OIDCOptions
+
+	// APIKeysFile is the path of the API keys file
+	APIKeysFile string
+
+	// HtpasswdFile is the path of the htpasswd file for basic auth
+	HtpasswdFile string
+}
+
+// New creates an Authenticator combining the enabled methods, or nil when
+// authentication is disabled
+func New(opts Options) (Authenticator, error) {
+	if len(opts.Methods) == 0 {
+		return nil, nil
+	}
+
+	chain := make(Chain, 0, len(opts.Methods))
+	for _, method := range opts.Methods {
+		switch strings.TrimSpace(method) {
+		case MethodOIDC:
+			a, err := NewOIDCAuthenticator(opts.OIDC)
+			if err != nil {
+				return nil, err
+			}
+			chain = append(chain, a)
+		case MethodAPIKey:
+			a, err := NewAPIKeyAuthenticator(opts.APIKeysFile)
+			if err != nil {
+				return nil, err
+			}
+			chain = append(chain, a)
+		case MethodBasic:
+			a, err := NewBasicAuthenticator(opts.HtpasswdFile)
+			if err != nil {
+				return nil, err
+			}
+			chain = append(chain, a)
+		default:
+			return nil, fmt.Errorf("unknown authentication method %q (supported: oidc, apikey, basic)", method)
+		}
+	}
+
+	return chain, nil
+}
+
+// Chain tries several authenticators in order and uses the first one for which
+// the request carries credentials
+type Chain []Authenticator
+
+// Authenticate implements Authenticator
+func (c Chain) Authenticate(r *http.Request) (*Principal, error) {
+	for _, a := range c {
+		principal, err := a.Authenticate(r)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return principal, err
+	}
+	return nil, ErrNoCredentials
+}
+
+// Challenge implements Authenticator
+func (c Chain) Challenge() string {
+	challenges := make([]string, 0, len(c))
+	for _, a := range c {
+		challenges = append(challenges, a.Challenge())
+	}
+	return strings.Join(challenges, ", ")
+}
+
+// Middleware returns a gin middleware that rejects unauthenticated requests with
+// 401 Unauthorized and stores the principal of authenticated ones in the context.
+// A nil authenticator lets every request through.
+func Middleware(a Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if a == nil {
+			c.Next()
+			return
+		}
+
+		principal, err := a.Authenticate(c.Request)
+		if err != nil {
+			title := "Authentication required"
+			if !errors.Is(err, ErrNoCredentials) {
+				title = "Authentication failed"
+			}
+
+			c.Header("WWW-Authenticate", a.Challenge())
+			problem.Abort(c, problem.New(http.StatusUnauthorized, problem.CodeUnauthenticated, title, err.Error()))
+			return
+		}
+
+		c.Set(principalKey, principal)
+		c.Request = c.Request.WithContext(logging.With(c.Request.Context(), slog.String("user", principal.Name)))
+		c.Next()
+	}
+}
+
+// PrincipalFrom returns the authenticated principal of a request, or nil when
+// authentication is disabled
+func PrincipalFrom(c *gin.Context) *Principal {
+	value, ok := c.Get(principalKey)
+	if !ok {
+		return nil
+	}
+	principal, _ := value.(*Principal)
+	return principal
+}
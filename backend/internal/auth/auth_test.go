@@ -0,0 +1,257 @@
+package auth
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/valeriouberti/maestro/internal/problem"
+)
+
+// writeFile writes a credentials file and returns its path
+func writeFile(t *testing.T, name string, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
+
+// sha256Hex returns the hash of an API key as written in the API keys file
+func sha256Hex(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// newTestAPIKeys creates an authenticator of the keys "ci-key" and "ops-key"
+func newTestAPIKeys(t *testing.T) *APIKeyAuthenticator {
+	t.Helper()
+	a, err := NewAPIKeyAuthenticator(writeFile(t, "api-keys",
+		"# CI pipelines",
+		"ci:"+sha256Hex("ci-key")+":deployers, readers",
+		"",
+		"ops:"+sha256Hex("ops-key"),
+	))
+	if err != nil {
+		t.Fatalf("NewAPIKeyAuthenticator: %v", err)
+	}
+	return a
+}
+
+func TestAPIKeyAuthenticate(t *testing.T) {
+	a := newTestAPIKeys(t)
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   string
+		groups []string
+	}{
+		{"header", APIKeyHeader, "ci-key", "ci", []string{"deployers", "readers"}},
+		{"authorization", "Authorization", "ApiKey ops-key", "ops", nil},
+		{"authorization scheme case", "Authorization", "apikey ops-key", "ops", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/topics", nil)
+			r.Header.Set(tt.header, tt.value)
+			principal, err := a.Authenticate(r)
+			if err != nil {
+				t.Fatalf("Authenticate: %v", err)
+			}
+			if principal.Name != tt.want || principal.Method != MethodAPIKey || !slices.Equal(principal.Groups, tt.groups) {
+				t.Errorf("principal = %+v, want %s in %v", principal, tt.want, tt.groups)
+			}
+		})
+	}
+}
+
+func TestAPIKeyRejectsWrongKeys(t *testing.T) {
+	a := newTestAPIKeys(t)
+
+	for _, key := range []string{"wrong-key", "ci-key ", sha256Hex("ci-key")} {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/topics", nil)
+		r.Header.Set(APIKeyHeader, key)
+		if _, err := a.Authenticate(r); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("key %q: error = %v, want %v", key, err, ErrInvalidCredentials)
+		}
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/topics", nil)
+	r.Header.Set("Authorization", "Bearer ci-key")
+	if _, err := a.Authenticate(r); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("bearer token: error = %v, want %v", err, ErrNoCredentials)
+	}
+}
+
+func TestAPIKeysFileErrors(t *testing.T) {
+	tests := map[string][]string{
+		"no name":       {":" + sha256Hex("key")},
+		"no hash":       {"ci"},
+		"not hex":       {"ci:not-a-hash"},
+		"not sha256":    {"ci:" + hex.EncodeToString([]byte("short"))},
+		"no keys":       {"# nobody"},
+		"plain api key": {"ci:ci-key"},
+	}
+	for name, lines := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewAPIKeyAuthenticator(writeFile(t, "api-keys", lines...)); err == nil {
+				t.Error("NewAPIKeyAuthenticator accepted the file")
+			}
+		})
+	}
+	if _, err := NewAPIKeyAuthenticator(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("NewAPIKeyAuthenticator accepted a missing file")
+	}
+}
+
+// newTestHtpasswd creates an authenticator of alice, with a bcrypt hash, and bob, with
+// a SHA-1 hash
+func newTestHtpasswd(t *testing.T) *BasicAuthenticator {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte("alice-password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword: %v", err)
+	}
+	sum := sha1.Sum([]byte("bob-password"))
+	a, err := NewBasicAuthenticator(writeFile(t, "htpasswd",
+		"alice:"+string(hash),
+		"bob:{SHA}"+base64.StdEncoding.EncodeToString(sum[:]),
+	))
+	if err != nil {
+		t.Fatalf("NewBasicAuthenticator: %v", err)
+	}
+	return a
+}
+
+// basicAuth returns a request authenticated with HTTP basic auth
+func basicAuth(user, password string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/topics", nil)
+	r.SetBasicAuth(user, password)
+	return r
+}
+
+func TestBasicAuthenticate(t *testing.T) {
+	a := newTestHtpasswd(t)
+
+	for user, password := range map[string]string{"alice": "alice-password", "bob": "bob-password"} {
+		principal, err := a.Authenticate(basicAuth(user, password))
+		if err != nil {
+			t.Fatalf("Authenticate(%s): %v", user, err)
+		}
+		if principal.Name != user || principal.Method != MethodBasic {
+			t.Errorf("principal = %+v, want %s", principal, user)
+		}
+	}
+
+	tests := []struct{ user, password string }{
+		{"alice", "wrong"},
+		{"alice", ""},
+		{"alice", "bob-password"},
+		{"bob", "alice-password"},
+		{"carol", "alice-password"},
+	}
+	for _, tt := range tests {
+		if _, err := a.Authenticate(basicAuth(tt.user, tt.password)); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("%s:%s: error = %v, want %v", tt.user, tt.password, err, ErrInvalidCredentials)
+		}
+	}
+
+	if _, err := a.Authenticate(httptest.NewRequest(http.MethodGet, "/api/v1/topics", nil)); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("no credentials: error = %v, want %v", err, ErrNoCredentials)
+	}
+}
+
+func TestHtpasswdFileErrors(t *testing.T) {
+	tests := map[string][]string{
+		"crypt":   {"alice:rl4JvNYAmy5SE"},
+		"md5":     {"alice:$apr1$r31.....$HqJZimcKQFAMYayBlzkrA/"},
+		"plain":   {"alice:alice-password"},
+		"no hash": {"alice"},
+		"no user": {":{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g="},
+		"empty":   {"# nobody"},
+	}
+	for name, lines := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewBasicAuthenticator(writeFile(t, "htpasswd", lines...)); err == nil {
+				t.Error("NewBasicAuthenticator accepted the file")
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	chain := Chain{newTestAPIKeys(t), newTestHtpasswd(t)}
+
+	r := gin.New()
+	r.Use(problem.Middleware())
+	r.GET("/whoami", Middleware(chain), func(c *gin.Context) {
+		c.String(http.StatusOK, PrincipalFrom(c).Name)
+	})
+
+	tests := []struct {
+		name    string
+		request *http.Request
+		status  int
+		body    string
+	}{
+		{"api key", func() *http.Request {
+			req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+			req.Header.Set(APIKeyHeader, "ci-key")
+			return req
+		}(), http.StatusOK, "ci"},
+		{"basic", func() *http.Request {
+			req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+			req.SetBasicAuth("alice", "alice-password")
+			return req
+		}(), http.StatusOK, "alice"},
+		{"wrong password", func() *http.Request {
+			req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+			req.SetBasicAuth("alice", "wrong")
+			return req
+		}(), http.StatusUnauthorized, "Authentication failed"},
+		{"no credentials", httptest.NewRequest(http.MethodGet, "/whoami", nil), http.StatusUnauthorized, "Authentication required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, tt.request)
+			if w.Code != tt.status || !strings.Contains(w.Body.String(), tt.body) {
+				t.Fatalf("response %d %s, want %d with %q", w.Code, w.Body, tt.status, tt.body)
+			}
+			if tt.status == http.StatusUnauthorized {
+				want := `ApiKey realm="maestro", Basic realm="maestro", charset="UTF-8"`
+				if challenge := w.Header().Get("WWW-Authenticate"); challenge != want {
+					t.Errorf("WWW-Authenticate = %q, want %q", challenge, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	if a, err := New(Options{}); a != nil || err != nil {
+		t.Errorf("New without methods = %v, %v, want authentication disabled", a, err)
+	}
+	if _, err := New(Options{Methods: []string{"kerberos"}}); err == nil {
+		t.Error("New accepted an unknown method")
+	}
+	if _, err := New(Options{Methods: []string{MethodAPIKey}}); err == nil {
+		t.Error("New accepted the apikey method without a keys file")
+	}
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BasicAuthenticator authenticates requests with HTTP basic auth against an htpasswd file.
+//
+// Only bcrypt ("$2y$", as generated by "htpasswd -B") and SHA-1 ("{SHA}") entries are
+// supported; crypt and MD5 entries are rejected when the file is loaded.
+type BasicAuthenticator struct {
+	users map[string]string
+}
+
+// NewBasicAuthenticator creates a BasicAuthenticator from an htpasswd file
+func NewBasicAuthenticator(path string) (*BasicAuthenticator, error) {
+	if path == "" {
+		return nil, fmt.Errorf("basic authentication requires an htpasswd file")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open htpasswd file: %w", err)
+	}
+	defer file.Close()
+
+	users := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		user, hash, found := strings.Cut(line, ":")
+		if !found || user == "" {
+			return nil, fmt.Errorf("htpasswd file line %d: expected user:hash", lineNumber)
+		}
+		if !strings.HasPrefix(hash, "$2") && !strings.HasPrefix(hash, "{SHA}") {
+			return nil, fmt.Errorf("htpasswd file line %d: unsupported hash for user %q (use bcrypt or SHA)", lineNumber, user)
+		}
+		users[user] = hash
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read htpasswd file: %w", err)
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("htpasswd file %s contains no users", path)
+	}
+
+	return &BasicAuthenticator{users: users}, nil
+}
+
+// Authenticate implements Authenticator
+func (a *BasicAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	user, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+
+	hash, exists := a.users[user]
+	if !exists || !verifyPassword(hash, password) {
+		return nil, fmt.Errorf("%w: wrong username or password", ErrInvalidCredentials)
+	}
+
+	return &Principal{
+		Subject: user,
+		Name:    user,
+		Method:  MethodBasic,
+	}, nil
+}
+
+// Challenge implements Authenticator
+func (a *BasicAuthenticator) Challenge() string {
+	return `Basic realm="maestro", charset="UTF-8"`
+}
+
+// verifyPassword checks a password against an htpasswd hash
+func verifyPassword(hash, password string) bool {
+	if encoded, ok := strings.CutPrefix(hash, "{SHA}"); ok {
+		sum := sha1.Sum([]byte(password))
+		expected := base64.StdEncoding.EncodeToString(sum[:])
+		return subtle.ConstantTimeCompare([]byte(expected), []byte(encoded)) == 1
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
@@ -0,0 +1,346 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// jwksCacheTTL is how long a fetched key set is used before it is refreshed
+	jwksCacheTTL = time.Hour
+	// jwksMinRefreshInterval limits refreshes triggered by tokens with unknown key IDs
+	jwksMinRefreshInterval = 30 * time.Second
+)
+
+// OIDCOptions configures validation of OIDC/JWT bearer tokens. Exactly one of
+// JWKSURL and PublicKeyFile must be set.
+type OIDCOptions struct {
+	Issuer        string // Expected "iss" claim; not checked when empty
+	Audience      string // Expected "aud" claim; not checked when empty
+	JWKSURL       string // URL of the JSON Web Key Set of the identity provider
+	PublicKeyFile string // PEM file with the public key used to sign tokens
+	UsernameClaim string // Claim holding the display name (default: preferred_username)
+	GroupsClaim   string // Claim holding the group memberships (default: groups)
+}
+
+// OIDCAuthenticator validates JWT bearer tokens issued by an OIDC identity provider
+type OIDCAuthenticator struct {
+	opts   OIDCOptions
+	parser *jwt.Parser
+	keys   keySource
+}
+
+// keySource resolves the verification key of a token
+type keySource interface {
+	key(kid string) (crypto.PublicKey, error)
+}
+
+// NewOIDCAuthenticator creates an OIDCAuthenticator
+func NewOIDCAuthenticator(opts OIDCOptions) (*OIDCAuthenticator, error) {
+	if (opts.JWKSURL == "") == (opts.PublicKeyFile == "") {
+		return nil, fmt.Errorf("OIDC authentication requires exactly one of a JWKS URL or a public key file")
+	}
+	if opts.UsernameClaim == "" {
+		opts.UsernameClaim = "preferred_username"
+	}
+	if opts.GroupsClaim == "" {
+		opts.GroupsClaim = "groups"
+	}
+
+	var keys keySource
+	if opts.PublicKeyFile != "" {
+		key, err := loadPublicKeyFile(opts.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = staticKey{publicKey: key}
+	} else {
+		keys = &jwksCache{url: opts.JWKSURL, client: &http.Client{Timeout: 10 * time.Second}}
+	}
+
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(opts.Issuer))
+	}
+	if opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.Audience))
+	}
+
+	return &OIDCAuthenticator{
+		opts:   opts,
+		parser: jwt.NewParser(parserOpts...),
+		keys:   keys,
+	}, nil
+}
+
+// Authenticate implements Authenticator
+func (a *OIDCAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return nil, ErrNoCredentials
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := a.parser.ParseWithClaims(strings.TrimSpace(token), claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return a.keys.key(kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	subject, _ := claims.GetSubject()
+	name, _ := claims[a.opts.UsernameClaim].(string)
+	if name == "" {
+		name = subject
+	}
+
+	return &Principal{
+		Subject: subject,
+		Name:    name,
+		Groups:  stringList(claims[a.opts.GroupsClaim]),
+		Method:  MethodOIDC,
+	}, nil
+}
+
+// Challenge implements Authenticator
+func (a *OIDCAuthenticator) Challenge() string {
+	return `Bearer realm="maestro"`
+}
+
+// stringList converts a claim holding a string or a list of strings into a slice
+func stringList(claim interface{}) []string {
+	switch v := claim.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	default:
+		return nil
+	}
+}
+
+// staticKey verifies every token with the same key
+type staticKey struct {
+	publicKey crypto.PublicKey
+}
+
+func (s staticKey) key(string) (crypto.PublicKey, error) {
+	return s.publicKey, nil
+}
+
+// loadPublicKeyFile reads a PEM encoded RSA, ECDSA or Ed25519 public key or certificate
+func loadPublicKeyFile(path string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key file: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("public key file %s does not contain PEM data", path)
+	}
+
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		return cert.PublicKey, nil
+	case "RSA PUBLIC KEY":
+		key, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse RSA public key: %w", err)
+		}
+		return key, nil
+	default:
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key: %w", err)
+		}
+		return key, nil
+	}
+}
+
+// jwksCache fetches and caches the keys of a JSON Web Key Set
+type jwksCache struct {
+	url    string
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+func (j *jwksCache) key(kid string) (crypto.PublicKey, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.keys == nil || time.Since(j.fetchedAt) > jwksCacheTTL {
+		if err := j.refresh(); err != nil {
+			return nil, err
+		}
+	}
+
+	if key, ok := j.lookup(kid); ok {
+		return key, nil
+	}
+
+	// The identity provider may have rotated its keys
+	if time.Since(j.fetchedAt) > jwksMinRefreshInterval {
+		if err := j.refresh(); err != nil {
+			return nil, err
+		}
+		if key, ok := j.lookup(kid); ok {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no signing key found for key ID %q", kid)
+}
+
+// lookup finds a key by ID; tokens without a key ID are accepted when the set has a single key
+func (j *jwksCache) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, true
+		}
+	}
+	key, ok := j.keys[kid]
+	return key, ok
+}
+
+func (j *jwksCache) refresh() error {
+	resp, err := j.client.Get(j.url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch JWKS: unexpected status %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			// Skip key types we cannot use rather than rejecting the whole set
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	j.keys = keys
+	j.fetchedAt = time.Now()
+	return nil
+}
+
+// jsonWebKey is a public key of a JSON Web Key Set (RFC 7517)
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBase64URLInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBase64URLInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBase64URLInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBase64URLInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		// ed25519.Verify panics on keys of another size
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 public key size %d", len(x))
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBase64URLInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,324 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testIssuer   = "https://login.example.com/realms/kafka"
+	testAudience = "maestro"
+)
+
+// jwksServer is an identity provider serving the public keys of its signing keys
+type jwksServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	keys    map[string]*rsa.PrivateKey
+	fetches int
+}
+
+// newJWKSServer starts a JWKS endpoint serving the keys, by key ID
+func newJWKSServer(t *testing.T, keys map[string]*rsa.PrivateKey) *jwksServer {
+	t.Helper()
+	s := &jwksServer{keys: keys}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.fetches++
+
+		var set struct {
+			Keys []jsonWebKey `json:"keys"`
+		}
+		for kid, key := range s.keys {
+			set.Keys = append(set.Keys, jsonWebKey{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		// Keys for other uses are ignored
+		set.Keys = append(set.Keys, jsonWebKey{Kty: "RSA", Kid: "encryption", Use: "enc", N: "AQAB", E: "AQAB"})
+		json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// rotate adds a signing key
+func (s *jwksServer) rotate(kid string, key *rsa.PrivateKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[kid] = key
+}
+
+func (s *jwksServer) fetchCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fetches
+}
+
+// generateKey returns a new RSA key
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	return key
+}
+
+// validClaims returns the claims of a token accepted by the test authenticator
+func validClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":                testIssuer,
+		"aud":                testAudience,
+		"sub":                "0b6a1d0e",
+		"preferred_username": "alice",
+		"groups":             []string{"platform", "oncall"},
+		"iat":                now.Unix(),
+		"nbf":                now.Unix(),
+		"exp":                now.Add(time.Hour).Unix(),
+	}
+}
+
+// sign returns a token with the claims signed by a key
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	return signed
+}
+
+// bearer returns a request carrying a bearer token
+func bearer(token string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/topics", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	return r
+}
+
+// newTestOIDC creates an authenticator of the tokens of the test issuer and audience
+func newTestOIDC(t *testing.T, jwks *jwksServer) *OIDCAuthenticator {
+	t.Helper()
+	a, err := NewOIDCAuthenticator(OIDCOptions{Issuer: testIssuer, Audience: testAudience, JWKSURL: jwks.URL})
+	if err != nil {
+		t.Fatalf("NewOIDCAuthenticator: %v", err)
+	}
+	return a
+}
+
+func TestOIDCAuthenticate(t *testing.T) {
+	key := generateKey(t)
+	a := newTestOIDC(t, newJWKSServer(t, map[string]*rsa.PrivateKey{"k1": key}))
+
+	principal, err := a.Authenticate(bearer(sign(t, jwt.SigningMethodRS256, "k1", key, validClaims())))
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	want := Principal{Subject: "0b6a1d0e", Name: "alice", Groups: []string{"platform", "oncall"}, Method: MethodOIDC}
+	if principal.Subject != want.Subject || principal.Name != want.Name || !slices.Equal(principal.Groups, want.Groups) || principal.Method != want.Method {
+		t.Errorf("principal = %+v, want %+v", principal, want)
+	}
+
+	// The subject names callers without a username
+	claims := validClaims()
+	delete(claims, "preferred_username")
+	claims["groups"] = "platform"
+	principal, err = a.Authenticate(bearer(sign(t, jwt.SigningMethodRS256, "k1", key, claims)))
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if principal.Name != "0b6a1d0e" || !slices.Equal(principal.Groups, []string{"platform"}) {
+		t.Errorf("principal = %+v, want the subject and a single group", principal)
+	}
+
+	// Tokens without a key ID are verified with the only key of the set
+	if _, err := a.Authenticate(bearer(sign(t, jwt.SigningMethodRS256, "", key, validClaims()))); err != nil {
+		t.Errorf("Authenticate without a key ID: %v", err)
+	}
+}
+
+func TestOIDCRejectsInvalidTokens(t *testing.T) {
+	key := generateKey(t)
+	other := generateKey(t)
+	a := newTestOIDC(t, newJWKSServer(t, map[string]*rsa.PrivateKey{"k1": key}))
+
+	with := func(changes jwt.MapClaims, remove ...string) jwt.MapClaims {
+		claims := validClaims()
+		for name, value := range changes {
+			claims[name] = value
+		}
+		for _, name := range remove {
+			delete(claims, name)
+		}
+		return claims
+	}
+	now := time.Now()
+
+	// The public key of the RSA key, which an attacker knows, used as an HMAC secret
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"signed by another key", sign(t, jwt.SigningMethodRS256, "k1", other, validClaims())},
+		{"tampered claims", func() string {
+			// The signature of a valid token on other claims
+			token := sign(t, jwt.SigningMethodRS256, "k1", key, validClaims())
+			forged := sign(t, jwt.SigningMethodRS256, "k1", other, with(jwt.MapClaims{"preferred_username": "admin"}))
+			return forged[:strings.LastIndex(forged, ".")] + token[strings.LastIndex(token, "."):]
+		}()},
+		{"expired", sign(t, jwt.SigningMethodRS256, "k1", key, with(jwt.MapClaims{"exp": now.Add(-time.Minute).Unix()}))},
+		{"without expiration", sign(t, jwt.SigningMethodRS256, "k1", key, with(nil, "exp"))},
+		{"not yet valid", sign(t, jwt.SigningMethodRS256, "k1", key, with(jwt.MapClaims{"nbf": now.Add(time.Minute).Unix()}))},
+		{"wrong issuer", sign(t, jwt.SigningMethodRS256, "k1", key, with(jwt.MapClaims{"iss": "https://evil.example.com"}))},
+		{"without issuer", sign(t, jwt.SigningMethodRS256, "k1", key, with(nil, "iss"))},
+		{"wrong audience", sign(t, jwt.SigningMethodRS256, "k1", key, with(jwt.MapClaims{"aud": []string{"kafka-ui"}}))},
+		{"without audience", sign(t, jwt.SigningMethodRS256, "k1", key, with(nil, "aud"))},
+		{"alg none", sign(t, jwt.SigningMethodNone, "k1", jwt.UnsafeAllowNoneSignatureType, validClaims())},
+		{"HS256 with the public key", sign(t, jwt.SigningMethodHS256, "k1", publicDER, validClaims())},
+		{"unknown key ID", sign(t, jwt.SigningMethodRS256, "k2", key, validClaims())},
+		{"malformed", "not.a.token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := a.Authenticate(bearer(tt.token))
+			if err == nil {
+				t.Fatalf("Authenticate accepted the token as %+v", principal)
+			}
+			if !errors.Is(err, ErrInvalidCredentials) {
+				t.Errorf("error = %v, want %v", err, ErrInvalidCredentials)
+			}
+		})
+	}
+}
+
+func TestOIDCWithoutBearerToken(t *testing.T) {
+	a := newTestOIDC(t, newJWKSServer(t, map[string]*rsa.PrivateKey{"k1": generateKey(t)}))
+
+	for _, header := range []string{"", "Basic YWxpY2U6c2VjcmV0", "Bearer"} {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/topics", nil)
+		if header != "" {
+			r.Header.Set("Authorization", header)
+		}
+		if _, err := a.Authenticate(r); !errors.Is(err, ErrNoCredentials) {
+			t.Errorf("Authorization %q: error = %v, want %v", header, err, ErrNoCredentials)
+		}
+	}
+}
+
+func TestOIDCKeyRotation(t *testing.T) {
+	key := generateKey(t)
+	jwks := newJWKSServer(t, map[string]*rsa.PrivateKey{"k1": key})
+	a := newTestOIDC(t, jwks)
+
+	if _, err := a.Authenticate(bearer(sign(t, jwt.SigningMethodRS256, "k1", key, validClaims()))); err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if _, err := a.Authenticate(bearer(sign(t, jwt.SigningMethodRS256, "k1", key, validClaims()))); err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if fetches := jwks.fetchCount(); fetches != 1 {
+		t.Fatalf("fetched the key set %d times, want it cached", fetches)
+	}
+
+	rotated := generateKey(t)
+	jwks.rotate("k2", rotated)
+	token := sign(t, jwt.SigningMethodRS256, "k2", rotated, validClaims())
+
+	// Unknown key IDs refresh the key set at most every jwksMinRefreshInterval
+	if _, err := a.Authenticate(bearer(token)); err == nil {
+		t.Fatal("Authenticate accepted a token with a key ID unknown since the last refresh")
+	}
+	if fetches := jwks.fetchCount(); fetches != 1 {
+		t.Fatalf("fetched the key set %d times within the minimum refresh interval", fetches)
+	}
+
+	cache := a.keys.(*jwksCache)
+	cache.mu.Lock()
+	cache.fetchedAt = time.Now().Add(-2 * jwksMinRefreshInterval)
+	cache.mu.Unlock()
+
+	if _, err := a.Authenticate(bearer(token)); err != nil {
+		t.Fatalf("Authenticate with the rotated key: %v", err)
+	}
+	if fetches := jwks.fetchCount(); fetches != 2 {
+		t.Errorf("fetched the key set %d times, want one refresh", fetches)
+	}
+}
+
+func TestOIDCUnavailableKeySet(t *testing.T) {
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer jwks.Close()
+	a, err := NewOIDCAuthenticator(OIDCOptions{JWKSURL: jwks.URL})
+	if err != nil {
+		t.Fatalf("NewOIDCAuthenticator: %v", err)
+	}
+
+	key := generateKey(t)
+	if _, err := a.Authenticate(bearer(sign(t, jwt.SigningMethodRS256, "k1", key, validClaims()))); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("error = %v, want %v", err, ErrInvalidCredentials)
+	}
+}
+
+func TestNewOIDCAuthenticatorRequiresOneKeySource(t *testing.T) {
+	for _, opts := range []OIDCOptions{{}, {JWKSURL: "https://login.example.com/certs", PublicKeyFile: "/etc/maestro/jwt.pem"}} {
+		if _, err := NewOIDCAuthenticator(opts); err == nil {
+			t.Errorf("NewOIDCAuthenticator(%+v) succeeded", opts)
+		}
+	}
+}
+
+func TestJSONWebKeyEd25519Size(t *testing.T) {
+	public, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	for _, size := range []int{0, ed25519.PublicKeySize - 1, ed25519.PublicKeySize + 1, 64} {
+		jwk := jsonWebKey{Kty: "OKP", Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(make([]byte, size))}
+		if _, err := jwk.publicKey(); err == nil {
+			t.Errorf("accepted an Ed25519 key of %d bytes", size)
+		}
+	}
+
+	jwk := jsonWebKey{Kty: "OKP", Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(public)}
+	key, err := jwk.publicKey()
+	if err != nil {
+		t.Fatalf("publicKey: %v", err)
+	}
+	if !public.Equal(key) {
+		t.Errorf("publicKey = %x, want %x", key, public)
+	}
+}
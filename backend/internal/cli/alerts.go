@@ -0,0 +1,45 @@
+package cli
+
+import "time"
+
+func runAlertsList(e *env, args []string) error {
+	fs := e.flags("alerts list", "")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.ListAlerts(e.ctx)
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"RULE", "STATUS", "SEVERITY", "CLUSTER", "SUBJECT", "SINCE", "SUMMARY"}}
+	for _, alert := range resp.Alerts {
+		t.add(alert.Rule, alert.Status, alert.Severity, alert.Cluster, alert.Subject, alert.ActiveAt.Local().Format(time.DateTime), alert.Summary)
+	}
+	return e.print(resp.Alerts, t)
+}
+
+func runAlertsTest(e *env, args []string) error {
+	fs := e.flags("alerts test", "NOTIFIER")
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.TestNotifier(e.ctx, rest[0])
+	if err != nil {
+		return err
+	}
+	t := table{}
+	t.add(resp.Message + ": " + resp.Notifier)
+	return e.print(resp, t)
+}
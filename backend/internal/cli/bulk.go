@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"flag"
+	"math"
+
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// bulkFlags defines the flags selecting the topics of a bulk operation and confirming it
+type bulkFlags struct {
+	pattern *string
+	confirm *string
+}
+
+func newBulkFlags(fs *flag.FlagSet) *bulkFlags {
+	return &bulkFlags{
+		pattern: fs.String("pattern", "", "Regular expression the whole topic names must match, instead of TOPIC arguments"),
+		confirm: fs.String("confirm", "", "Confirmation printed by the preview; without it the operation is only previewed"),
+	}
+}
+
+// spec returns the selector and options of a bulk operation on the topics given as arguments
+func (f *bulkFlags) spec(topics []string) (domain.TopicSelector, domain.BulkOptions, error) {
+	if (len(topics) == 0) == (*f.pattern == "") {
+		return domain.TopicSelector{}, domain.BulkOptions{}, usagef("select topics with either TOPIC arguments or -pattern")
+	}
+	selector := domain.TopicSelector{Topics: topics, Pattern: *f.pattern}
+	return selector, domain.BulkOptions{DryRun: *f.confirm == "", Confirmation: *f.confirm}, nil
+}
+
+// printBulk prints the outcome of a bulk operation for every topic
+func (e *env) printBulk(value any, message, confirmation string, dryRun bool, topics []domain.BulkTopicResult) error {
+	t := table{header: []string{"TOPIC", "APPLIED", "REASON"}}
+	for _, topic := range topics {
+		reason := topic.Skipped
+		if topic.Error != "" {
+			reason = topic.Error
+		}
+		t.add(topic.Topic, topic.Applied, reason)
+	}
+	note := table{}
+	note.add(message)
+	if dryRun {
+		note.add("Nothing was changed; pass -confirm " + confirmation + " to apply the operation")
+	}
+	return e.print(value, t, note)
+}
+
+func runTopicsBulkDelete(e *env, args []string) error {
+	fs := e.flags("topics bulk-delete", "[TOPIC...]")
+	bulk := newBulkFlags(fs)
+	rest, err := parseRange(fs, args, 0, math.MaxInt)
+	if err != nil {
+		return err
+	}
+	selector, opts, err := bulk.spec(rest)
+	if err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.BulkDeleteTopics(e.ctx, domain.BulkTopicDeletionSpec{TopicSelector: selector, BulkOptions: opts})
+	if err != nil {
+		return err
+	}
+	return e.printBulk(resp, resp.Message, resp.Confirmation, resp.DryRun, resp.Topics)
+}
+
+func runTopicsBulkAlter(e *env, args []string) error {
+	fs := e.flags("topics bulk-alter", "[TOPIC...]")
+	bulk := newBulkFlags(fs)
+	var set keyValues
+	var unset stringList
+	fs.Var(&set, "config", "Configuration override to set as KEY=VALUE (repeatable)")
+	fs.Var(&unset, "delete-config", "Configuration override to remove (repeatable)")
+	rest, err := parseRange(fs, args, 0, math.MaxInt)
+	if err != nil {
+		return err
+	}
+	if len(set) == 0 && len(unset) == 0 {
+		return usagef("nothing to change; pass -config or -delete-config")
+	}
+	selector, opts, err := bulk.spec(rest)
+	if err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.BulkUpdateTopicsConfig(e.ctx, domain.BulkTopicConfigSpec{
+		TopicSelector: selector,
+		BulkOptions:   opts,
+		Set:           set,
+		Delete:        unset,
+	})
+	if err != nil {
+		return err
+	}
+	return e.printBulk(resp, resp.Message, resp.Confirmation, resp.DryRun, resp.Topics)
+}
+
+func runTopicsAddPartitions(e *env, args []string) error {
+	fs := e.flags("topics add-partitions", "[TOPIC...]")
+	bulk := newBulkFlags(fs)
+	partitions := fs.Int("partitions", 0, "New number of partitions (required)")
+	rest, err := parseRange(fs, args, 0, math.MaxInt)
+	if err != nil {
+		return err
+	}
+	if *partitions <= 0 {
+		return usagef("-partitions is required")
+	}
+	selector, opts, err := bulk.spec(rest)
+	if err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.BulkCreatePartitions(e.ctx, domain.BulkPartitionsSpec{
+		TopicSelector: selector,
+		BulkOptions:   opts,
+		Partitions:    int32(*partitions),
+	})
+	if err != nil {
+		return err
+	}
+	return e.printBulk(resp, resp.Message, resp.Confirmation, resp.DryRun, resp.Topics)
+}
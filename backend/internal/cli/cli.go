@@ -0,0 +1,332 @@
+// Package cli implements the maestro command-line client. It manages topics, messages
+// and consumer groups through the REST API of a Maestro server, using the generated
+// client of package client, and selects the server with named contexts.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/valeriouberti/maestro/internal/problem"
+	"github.com/valeriouberti/maestro/pkg/client"
+)
+
+// Exit codes of the maestro command, stable for scripting
+const (
+	ExitOK          = 0
+	ExitError       = 1 // The command failed for another reason
+	ExitUsage       = 2 // The command line is invalid
+	ExitNotFound    = 3 // The topic, partition, group or job does not exist
+	ExitDenied      = 4 // Authentication failed or the action is not permitted
+	ExitConflict    = 5 // The resource already exists or is in a state that prevents the action
+	ExitUnavailable = 6 // The server or the Kafka cluster cannot be reached, or timed out
+)
+
+// defaultServer is the server used when neither a flag, the environment nor a context names one
+const defaultServer = "http://localhost:8080"
+
+// Environment variables overriding the selected context
+const (
+	envContextsFile = "MAESTRO_CONTEXTS_FILE"
+	envContext      = "MAESTRO_CONTEXT"
+	envServer       = "MAESTRO_SERVER"
+	envCluster      = "MAESTRO_CLUSTER"
+	envAPIKey       = "MAESTRO_API_KEY"
+	envToken        = "MAESTRO_TOKEN"
+)
+
+// command is a command or a group of subcommands
+type command struct {
+	name    string
+	usage   string // Arguments, e.g. "NAME"
+	summary string
+	run     func(e *env, args []string) error
+	sub     []*command
+}
+
+// usageError is an invalid command line
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func usagef(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// globalOptions are the flags accepted by every command
+type globalOptions struct {
+	configPath string
+	context    string
+	server     string
+	cluster    string
+	apiKey     string
+	token      string
+	output     string
+	timeout    time.Duration
+}
+
+// env is what a command runs with
+type env struct {
+	ctx    context.Context
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	opts   globalOptions
+}
+
+func commands() *command {
+	return &command{
+		name: "maestro",
+		sub: []*command{
+			{name: "clusters", summary: "List the Kafka clusters of the server and the brokers of the selected one", run: runClusters},
+			{name: "topics", summary: "Manage topics", sub: []*command{
+				{name: "list", summary: "List topics", run: runTopicsList},
+				{name: "describe", usage: "NAME", summary: "Show the partitions and configuration of a topic", run: runTopicsDescribe},
+				{name: "create", usage: "[NAME]", summary: "Create a topic, optionally from a template", run: runTopicsCreate},
+				{name: "delete", usage: "NAME", summary: "Delete a topic", run: runTopicsDelete},
+				{name: "alter", usage: "NAME", summary: "Change the configuration of a topic", run: runTopicsAlter},
+				{name: "consumers", usage: "NAME", summary: "List the consumer groups of a topic with their lag", run: runTopicsConsumers},
+				{name: "bulk-delete", usage: "[TOPIC...]", summary: "Delete the topics given or matching -pattern, after a preview", run: runTopicsBulkDelete},
+				{name: "bulk-alter", usage: "[TOPIC...]", summary: "Change the configuration of the topics given or matching -pattern, after a preview", run: runTopicsBulkAlter},
+				{name: "add-partitions", usage: "[TOPIC...]", summary: "Increase the partitions of the topics given or matching -pattern, after a preview", run: runTopicsAddPartitions},
+			}},
+			{name: "templates", summary: "Manage topic templates", sub: []*command{
+				{name: "list", summary: "List topic templates", run: runTemplatesList},
+				{name: "describe", usage: "NAME", summary: "Show the defaults of a topic template", run: runTemplatesDescribe},
+				{name: "create", usage: "NAME", summary: "Create a topic template", run: runTemplatesCreate},
+				{name: "update", usage: "NAME", summary: "Replace a topic template", run: runTemplatesUpdate},
+				{name: "delete", usage: "NAME", summary: "Delete a topic template", run: runTemplatesDelete},
+			}},
+			{name: "messages", summary: "Read and publish messages", sub: []*command{
+				{name: "tail", usage: "TOPIC", summary: "Show the latest messages of a topic, and follow new ones", run: runMessagesTail},
+				{name: "produce", usage: "TOPIC", summary: "Publish a message, or one message per line of standard input", run: runMessagesProduce},
+				{name: "search", usage: "TOPIC", summary: "Find the messages of a topic matching filters", run: runMessagesSearch},
+			}},
+			{name: "groups", summary: "Inspect and manage consumer groups", sub: []*command{
+				{name: "list", summary: "List consumer groups", run: runGroupsList},
+				{name: "describe", usage: "GROUP", summary: "Show the members and topics of a consumer group", run: runGroupsDescribe},
+				{name: "lag", usage: "GROUP", summary: "Show the lag of a consumer group per partition", run: runGroupsLag},
+				{name: "lag-history", usage: "GROUP", summary: "Show the recorded lag of a consumer group and whether it is catching up", run: runGroupsLagHistory},
+				{name: "reset", usage: "GROUP", summary: "Reset the committed offsets of a consumer group", run: runGroupsReset},
+				{name: "delete", usage: "GROUP", summary: "Delete a consumer group without members", run: runGroupsDelete},
+				{name: "prune", usage: "PATTERN", summary: "Delete the consumer groups without members matching a glob pattern", run: runGroupsPrune},
+				{name: "delete-offsets", usage: "GROUP", summary: "Delete the committed offsets of a consumer group on a topic", run: runGroupsDeleteOffsets},
+				{name: "remove-members", usage: "GROUP", summary: "Remove static members from a consumer group", run: runGroupsRemoveMembers},
+			}},
+			{name: "alerts", summary: "Inspect alerts and notifiers", sub: []*command{
+				{name: "list", summary: "List the pending and firing alerts", run: runAlertsList},
+				{name: "test", usage: "NOTIFIER", summary: "Send a test notification to a notifier", run: runAlertsTest},
+			}},
+			{name: "context", summary: "Manage the named contexts selecting a Maestro server", sub: []*command{
+				{name: "list", summary: "List contexts", run: runContextList},
+				{name: "current", summary: "Show the current context", run: runContextCurrent},
+				{name: "use", usage: "NAME", summary: "Make a context the current one", run: runContextUse},
+				{name: "set", usage: "NAME", summary: "Create or update a context", run: runContextSet},
+				{name: "delete", usage: "NAME", summary: "Delete a context", run: runContextDelete},
+			}},
+		},
+	}
+}
+
+// Run runs the command given by args, without the program name, and returns the exit code
+func Run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	e := &env{ctx: ctx, stdin: stdin, stdout: stdout, stderr: stderr}
+
+	root := commands()
+	cmd, path, rest := root.find(args)
+	if cmd.run == nil {
+		if len(rest) > 0 && rest[0] != "help" && rest[0] != "-h" && rest[0] != "--help" {
+			fmt.Fprintf(stderr, "maestro: unknown command %q\n\n", strings.Join(append(path, rest[0]), " "))
+			cmd.printUsage(stderr, path)
+			return ExitUsage
+		}
+		cmd.printUsage(stdout, path)
+		return ExitOK
+	}
+
+	err := cmd.run(e, rest)
+	if err == nil {
+		return ExitOK
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitOK
+	}
+	if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+		// Interrupted, e.g. while following messages
+		return ExitOK
+	}
+
+	fmt.Fprintf(stderr, "maestro: %v\n", err)
+	var usage *usageError
+	if errors.As(err, &usage) {
+		fmt.Fprintf(stderr, "Run 'maestro %s -h' for usage.\n", strings.Join(path, " "))
+	}
+	return ExitCode(err)
+}
+
+// find returns the command named by the leading arguments, its path and the remaining arguments
+func (c *command) find(args []string) (*command, []string, []string) {
+	var path []string
+	for len(args) > 0 && c.run == nil {
+		var next *command
+		for _, sub := range c.sub {
+			if sub.name == args[0] {
+				next = sub
+			}
+		}
+		if next == nil {
+			break
+		}
+		c = next
+		path = append(path, next.name)
+		args = args[1:]
+	}
+	return c, path, args
+}
+
+// printUsage lists the subcommands of a command group
+func (c *command) printUsage(w io.Writer, path []string) {
+	name := strings.Join(append([]string{"maestro"}, path...), " ")
+	fmt.Fprintf(w, "Usage: %s <command> [flags]\n\nCommands:\n", name)
+	for _, sub := range c.sub {
+		fmt.Fprintf(w, "  %-16s %s\n", strings.TrimSpace(sub.name+" "+sub.usage), sub.summary)
+	}
+	if len(path) == 0 {
+		fmt.Fprintf(w, "  %-16s %s\n", "serve", "Run the Maestro server (the default without arguments)")
+	}
+	fmt.Fprintf(w, "\nRun '%s <command> -h' for the flags of a command.\n", name)
+}
+
+// flags creates the flag set of a command with the global flags registered
+func (e *env) flags(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(e.stderr, "Usage: maestro %s [flags] %s\n\nFlags:\n", name, usage)
+		fs.PrintDefaults()
+	}
+
+	fs.StringVar(&e.opts.configPath, "contexts-file", os.Getenv(envContextsFile), "Path of the contexts file (default: "+defaultConfigPath()+")")
+	fs.StringVar(&e.opts.context, "context", os.Getenv(envContext), "Context to use instead of the current one")
+	fs.StringVar(&e.opts.server, "server", os.Getenv(envServer), "URL of the Maestro server, overriding the context")
+	fs.StringVar(&e.opts.cluster, "cluster", os.Getenv(envCluster), "Kafka cluster of the server to use, overriding the context (default: the server's default cluster)")
+	fs.StringVar(&e.opts.apiKey, "api-key", os.Getenv(envAPIKey), "API key, overriding the context")
+	fs.StringVar(&e.opts.token, "token", os.Getenv(envToken), "Bearer token, overriding the context")
+	fs.StringVar(&e.opts.output, "output", "", "Output format: table, json or yaml (default: the context's, or table)")
+	fs.StringVar(&e.opts.output, "o", "", "Shorthand for -output")
+	fs.DurationVar(&e.opts.timeout, "timeout", 30*time.Second, "Timeout of each API request")
+	return fs
+}
+
+// parse parses flags and positional arguments in any order and checks the number of
+// positional arguments
+func parse(fs *flag.FlagSet, args []string, positional int) ([]string, error) {
+	return parseRange(fs, args, positional, positional)
+}
+
+// parseRange is like parse for commands taking between least and most positional arguments
+func parseRange(fs *flag.FlagSet, args []string, least, most int) ([]string, error) {
+	var rest []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, &usageError{msg: err.Error()}
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		rest = append(rest, args[0])
+		args = args[1:]
+	}
+
+	switch {
+	case least == most && len(rest) != least:
+		return nil, usagef("expected %d argument(s), got %d", least, len(rest))
+	case len(rest) < least || len(rest) > most:
+		return nil, usagef("expected %d to %d argument(s), got %d", least, most, len(rest))
+	}
+	return rest, nil
+}
+
+// client creates the API client of the selected context
+func (e *env) client() (*client.Client, error) {
+	conn, err := e.connection()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []client.Option{client.WithHTTPClient(&http.Client{Timeout: e.opts.timeout})}
+	switch {
+	case conn.APIKey != "":
+		opts = append(opts, client.WithAPIKey(conn.APIKey))
+	case conn.Token != "":
+		opts = append(opts, client.WithBearerToken(conn.Token))
+	case conn.Username != "":
+		opts = append(opts, client.WithBasicAuth(conn.Username, conn.Password))
+	}
+	if conn.Cluster != "" {
+		opts = append(opts, client.WithCluster(conn.Cluster))
+	}
+	return client.New(conn.Server, opts...), nil
+}
+
+// ExitCode returns the exit code reporting an error
+func ExitCode(err error) int {
+	var usage *usageError
+	if errors.As(err, &usage) {
+		return ExitUsage
+	}
+
+	var p *client.Problem
+	if errors.As(err, &p) {
+		switch p.Code {
+		case problem.CodeNotFound:
+			return ExitNotFound
+		case problem.CodeUnauthenticated, problem.CodeForbidden, problem.CodeUnauthorized:
+			return ExitDenied
+		case problem.CodeAlreadyExists, problem.CodeConflict:
+			return ExitConflict
+		case problem.CodeTimeout, problem.CodeBrokerUnavailable:
+			return ExitUnavailable
+		}
+
+		// Responses without problem details, e.g. from a proxy
+		switch p.Status {
+		case http.StatusNotFound:
+			return ExitNotFound
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return ExitDenied
+		case http.StatusConflict:
+			return ExitConflict
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return ExitUnavailable
+		}
+		return ExitError
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+		return ExitUnavailable
+	}
+	return ExitError
+}
@@ -0,0 +1,269 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Context is a named Maestro server and Kafka cluster with the credentials to use with them
+type Context struct {
+	Server   string `yaml:"server"`
+	Cluster  string `yaml:"cluster,omitempty"` // Empty uses the default cluster of the server
+	APIKey   string `yaml:"api-key,omitempty"`
+	Token    string `yaml:"token,omitempty"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	Output   string `yaml:"output,omitempty"` // Default output format
+}
+
+// Contexts is the contents of the contexts file
+type Contexts struct {
+	Current  string              `yaml:"current-context,omitempty"`
+	Contexts map[string]*Context `yaml:"contexts,omitempty"`
+}
+
+// defaultConfigPath returns the path of the contexts file when neither -contexts-file nor
+// MAESTRO_CONTEXTS_FILE is set
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "maestro.yaml"
+	}
+	return filepath.Join(dir, "maestro", "contexts.yaml")
+}
+
+func (e *env) configPath() string {
+	if e.opts.configPath != "" {
+		return e.opts.configPath
+	}
+	return defaultConfigPath()
+}
+
+// loadContexts reads the contexts file; a missing file holds no contexts
+func (e *env) loadContexts() (*Contexts, error) {
+	contexts := &Contexts{}
+	data, err := os.ReadFile(e.configPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return contexts, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read contexts: %w", err)
+	}
+	if err := yaml.Unmarshal(data, contexts); err != nil {
+		return nil, fmt.Errorf("failed to parse contexts file %s: %w", e.configPath(), err)
+	}
+	return contexts, nil
+}
+
+// saveContexts writes the contexts file, readable only by the user as it holds credentials
+func (e *env) saveContexts(contexts *Contexts) error {
+	data, err := yaml.Marshal(contexts)
+	if err != nil {
+		return fmt.Errorf("failed to encode contexts: %w", err)
+	}
+
+	path := e.configPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create contexts directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write contexts: %w", err)
+	}
+	return nil
+}
+
+// connection resolves the server and credentials to use: flags and environment
+// variables override the selected context, which is the current one unless -context
+// names another
+func (e *env) connection() (Context, error) {
+	contexts, err := e.loadContexts()
+	if err != nil {
+		return Context{}, err
+	}
+
+	var conn Context
+	name := e.opts.context
+	if name == "" {
+		name = contexts.Current
+	}
+	if name != "" {
+		selected, ok := contexts.Contexts[name]
+		if !ok {
+			return Context{}, usagef("context %q does not exist", name)
+		}
+		conn = *selected
+	}
+
+	if e.opts.server != "" {
+		conn.Server = e.opts.server
+	}
+	if e.opts.cluster != "" {
+		conn.Cluster = e.opts.cluster
+	}
+	if conn.Server == "" {
+		conn.Server = defaultServer
+	}
+	if e.opts.apiKey != "" || e.opts.token != "" {
+		// Credentials given explicitly replace those of the context
+		conn.APIKey, conn.Token, conn.Username, conn.Password = e.opts.apiKey, e.opts.token, "", ""
+	}
+	return conn, nil
+}
+
+func runContextList(e *env, args []string) error {
+	fs := e.flags("context list", "")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	contexts, err := e.loadContexts()
+	if err != nil {
+		return err
+	}
+
+	type entry struct {
+		Name    string `json:"name"`
+		Server  string `json:"server"`
+		Cluster string `json:"cluster,omitempty"`
+		Current bool   `json:"current"`
+	}
+	entries := []entry{}
+	t := table{header: []string{"CURRENT", "NAME", "SERVER", "CLUSTER"}}
+	for _, name := range slices.Sorted(maps.Keys(contexts.Contexts)) {
+		ctx := contexts.Contexts[name]
+		current := name == contexts.Current
+		entries = append(entries, entry{Name: name, Server: ctx.Server, Cluster: ctx.Cluster, Current: current})
+		marker := ""
+		if current {
+			marker = "*"
+		}
+		cluster := ctx.Cluster
+		if cluster == "" {
+			cluster = "(default)"
+		}
+		t.add(marker, name, ctx.Server, cluster)
+	}
+	return e.print(entries, t)
+}
+
+func runContextCurrent(e *env, args []string) error {
+	fs := e.flags("context current", "")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	contexts, err := e.loadContexts()
+	if err != nil {
+		return err
+	}
+	if contexts.Current == "" {
+		return errors.New("no current context")
+	}
+	fmt.Fprintln(e.stdout, contexts.Current)
+	return nil
+}
+
+func runContextUse(e *env, args []string) error {
+	fs := e.flags("context use", "NAME")
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	contexts, err := e.loadContexts()
+	if err != nil {
+		return err
+	}
+	if _, ok := contexts.Contexts[rest[0]]; !ok {
+		return usagef("context %q does not exist", rest[0])
+	}
+
+	contexts.Current = rest[0]
+	if err := e.saveContexts(contexts); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "Switched to context %q\n", rest[0])
+	return nil
+}
+
+func runContextSet(e *env, args []string) error {
+	fs := e.flags("context set", "NAME")
+	var update Context
+	fs.StringVar(&update.Username, "username", "", "User name for basic authentication")
+	fs.StringVar(&update.Password, "password", "", "Password for basic authentication")
+	fs.StringVar(&update.Output, "default-output", "", "Default output format of the context")
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	// The global -server, -cluster, -api-key and -token flags set the corresponding fields
+	update.Server, update.Cluster, update.APIKey, update.Token = e.opts.server, e.opts.cluster, e.opts.apiKey, e.opts.token
+
+	contexts, err := e.loadContexts()
+	if err != nil {
+		return err
+	}
+	if contexts.Contexts == nil {
+		contexts.Contexts = make(map[string]*Context)
+	}
+	ctx, exists := contexts.Contexts[rest[0]]
+	if !exists {
+		ctx = &Context{Server: defaultServer}
+		contexts.Contexts[rest[0]] = ctx
+	}
+	for _, field := range []struct{ target, value *string }{
+		{&ctx.Server, &update.Server},
+		{&ctx.Cluster, &update.Cluster},
+		{&ctx.APIKey, &update.APIKey},
+		{&ctx.Token, &update.Token},
+		{&ctx.Username, &update.Username},
+		{&ctx.Password, &update.Password},
+		{&ctx.Output, &update.Output},
+	} {
+		if *field.value != "" {
+			*field.target = *field.value
+		}
+	}
+	if contexts.Current == "" {
+		contexts.Current = rest[0]
+	}
+
+	if err := e.saveContexts(contexts); err != nil {
+		return err
+	}
+	verb := "Updated"
+	if !exists {
+		verb = "Created"
+	}
+	fmt.Fprintf(e.stdout, "%s context %q\n", verb, rest[0])
+	return nil
+}
+
+func runContextDelete(e *env, args []string) error {
+	fs := e.flags("context delete", "NAME")
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	contexts, err := e.loadContexts()
+	if err != nil {
+		return err
+	}
+	if _, ok := contexts.Contexts[rest[0]]; !ok {
+		return usagef("context %q does not exist", rest[0])
+	}
+
+	delete(contexts.Contexts, rest[0])
+	if contexts.Current == rest[0] {
+		contexts.Current = ""
+	}
+	if err := e.saveContexts(contexts); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "Deleted context %q\n", rest[0])
+	return nil
+}
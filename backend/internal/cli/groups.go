@@ -0,0 +1,358 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/valeriouberti/maestro/pkg/client"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+func runGroupsList(e *env, args []string) error {
+	fs := e.flags("groups list", "")
+	state := fs.String("state", "", "Comma-separated states the groups must be in, e.g. Stable,Empty")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.ListConsumerGroups(e.ctx, &client.ListConsumerGroupsParams{State: *state})
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"GROUP", "STATE", "TYPE", "ASSIGNOR", "MEMBERS", "COORDINATOR"}}
+	for _, group := range resp.Groups {
+		coordinator := "-"
+		if group.Coordinator != nil {
+			coordinator = fmt.Sprint(group.Coordinator.ID)
+		}
+		t.add(group.GroupID, group.State, groupType(group.Type, group.Simple), group.PartitionAssignor, group.MemberCount, coordinator)
+	}
+	return e.print(resp.Groups, t)
+}
+
+func runGroupsDescribe(e *env, args []string) error {
+	fs := e.flags("groups describe", "GROUP")
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.GetConsumerGroup(e.ctx, rest[0])
+	if err != nil {
+		return err
+	}
+	group := resp.Group
+
+	summary := table{}
+	summary.add("Group:", group.GroupID)
+	summary.add("State:", group.State)
+	summary.add("Type:", groupType(group.Type, group.Simple))
+	summary.add("Assignor:", group.PartitionAssignor)
+	summary.add("Members:", group.MemberCount)
+	summary.add("Coordinator:", fmt.Sprintf("%d (%s:%d)", group.Coordinator.ID, group.Coordinator.Host, group.Coordinator.Port))
+	summary.add("Topics:", strings.Join(group.Topics, ","))
+
+	members := table{title: "Members", header: []string{"CONSUMER", "INSTANCE", "CLIENT", "HOST", "ASSIGNMENTS"}}
+	for _, member := range group.Members {
+		assignments := make([]string, len(member.Assignments))
+		for i, assignment := range member.Assignments {
+			assignments[i] = fmt.Sprintf("%s/%d", assignment.Topic, assignment.Partition)
+		}
+		members.add(member.ConsumerID, member.GroupInstanceID, member.ClientID, member.Host, strings.Join(assignments, ","))
+	}
+
+	tables := []table{summary}
+	if len(members.rows) > 0 {
+		tables = append(tables, members)
+	}
+	return e.print(group, tables...)
+}
+
+// groupType describes the type of a consumer group, noting simple groups
+func groupType(name string, simple bool) string {
+	if simple {
+		return strings.TrimSpace(name + " (simple)")
+	}
+	return name
+}
+
+func runGroupsLag(e *env, args []string) error {
+	fs := e.flags("groups lag", "GROUP")
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.GetConsumerGroupLag(e.ctx, rest[0])
+	if err != nil {
+		return err
+	}
+	lag := resp.Lag
+
+	t := table{header: []string{"TOPIC", "PARTITION", "COMMITTED", "LOG-END", "LAG"}}
+	for _, partition := range lag.Partitions {
+		t.add(partition.Topic, partition.Partition, partition.CommittedOffset, partition.LogEndOffset, partition.Lag)
+	}
+	total := table{}
+	total.add("Total lag:", lag.TotalLag)
+	return e.print(lag, t, total)
+}
+
+func runGroupsLagHistory(e *env, args []string) error {
+	fs := e.flags("groups lag-history", "GROUP")
+	since := fs.String("since", "", "Start of the history, as RFC 3339 or a duration ago such as 6h (default: 24h)")
+	until := fs.String("until", "", "End of the history, as RFC 3339 or a duration ago (default: now)")
+	topic := fs.String("topic", "", "Only the lag on this topic")
+	window := fs.String("window", "", "Period at the end of the history the rates are computed over (default: 15m)")
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	params := &client.GetConsumerGroupLagHistoryParams{Topic: *topic, Window: *window}
+	for value, target := range map[string]*string{*since: &params.Since, *until: &params.Until} {
+		t, err := parseTime(value)
+		if err != nil {
+			return err
+		}
+		if t != nil {
+			*target = t.Format(time.RFC3339)
+		}
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.GetConsumerGroupLagHistory(e.ctx, rest[0], params)
+	if err != nil {
+		return err
+	}
+	history := resp.History
+
+	t := table{header: []string{"TIME", "LAG"}}
+	for _, sample := range history.Samples {
+		t.add(sample.Time.Local().Format(time.DateTime), sample.TotalLag)
+	}
+	summary := table{}
+	if trend := history.Trend; trend != nil {
+		summary.add("Status:", trend.Status)
+		summary.add("Consumption:", fmt.Sprintf("%.2f msg/s", trend.ConsumptionRate))
+		summary.add("Production:", fmt.Sprintf("%.2f msg/s", trend.ProductionRate))
+		if trend.CatchUpAt != nil {
+			summary.add("Caught up in:", fmt.Sprintf("%s (at %s)", time.Duration(*trend.ETASeconds*float64(time.Second)).Round(time.Second), trend.CatchUpAt.Local().Format(time.DateTime)))
+		}
+	} else {
+		summary.add("Not enough samples to compute a trend")
+	}
+	return e.print(history, t, summary)
+}
+
+func runGroupsReset(e *env, args []string) error {
+	fs := e.flags("groups reset", "GROUP")
+	topic := fs.String("topic", "", "Topic whose offsets to reset (required)")
+	partitionList := fs.String("partition", "", "Comma-separated partitions to reset (default: all)")
+	toEarliest := fs.Bool("to-earliest", false, "Reset to the earliest available offset")
+	toLatest := fs.Bool("to-latest", false, "Reset to the end of the partitions, skipping all messages")
+	toOffset := fs.Int64("to-offset", -1, "Reset to this offset")
+	toDatetime := fs.String("to-datetime", "", "Reset to the first offset at or after this time, as RFC 3339 or a duration ago such as 2h")
+	execute := fs.Bool("execute", false, "Commit the new offsets; without it the reset is only previewed")
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if *topic == "" {
+		return usagef("-topic is required")
+	}
+
+	spec := domain.OffsetResetSpec{Topic: *topic, DryRun: !*execute}
+	if spec.Partitions, err = parsePartitions(*partitionList); err != nil {
+		return err
+	}
+	targets := 0
+	if *toEarliest {
+		spec.To = domain.ResetToEarliest
+		targets++
+	}
+	if *toLatest {
+		spec.To = domain.ResetToLatest
+		targets++
+	}
+	if *toOffset >= 0 {
+		spec.To, spec.Offset = domain.ResetToOffset, toOffset
+		targets++
+	}
+	if *toDatetime != "" {
+		if spec.Timestamp, err = parseTime(*toDatetime); err != nil {
+			return err
+		}
+		spec.To = domain.ResetToTimestamp
+		targets++
+	}
+	if targets != 1 {
+		return usagef("pass exactly one of -to-earliest, -to-latest, -to-offset and -to-datetime")
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	resp, err := c.ResetConsumerGroupOffsets(e.ctx, rest[0], spec)
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"TOPIC", "PARTITION", "PREVIOUS", "NEW"}}
+	for _, offset := range resp.Offsets {
+		previous := "-"
+		if offset.PreviousOffset >= 0 {
+			previous = fmt.Sprint(offset.PreviousOffset)
+		}
+		t.add(offset.Topic, offset.Partition, previous, offset.NewOffset)
+	}
+	note := table{}
+	if resp.DryRun {
+		note.add("Dry run: no offsets were committed; pass -execute to apply the reset")
+	} else {
+		note.add(resp.Message)
+	}
+	return e.print(resp, t, note)
+}
+
+func runGroupsDelete(e *env, args []string) error {
+	fs := e.flags("groups delete", "GROUP")
+	yes := fs.Bool("yes", false, "Confirm the deletion")
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if !*yes {
+		return usagef("deleting group %q loses its committed offsets; pass -yes to confirm", rest[0])
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.DeleteConsumerGroup(e.ctx, rest[0])
+	if err != nil {
+		return err
+	}
+	t := table{}
+	t.add(resp.Message + ": " + resp.GroupID)
+	return e.print(resp, t)
+}
+
+func runGroupsPrune(e *env, args []string) error {
+	fs := e.flags("groups prune", "PATTERN")
+	confirm := fs.String("confirm", "", "Confirmation printed by the preview; without it the deletion is only previewed")
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	spec := domain.GroupDeletionSpec{Pattern: rest[0]}
+	spec.DryRun, spec.Confirmation = *confirm == "", *confirm
+	resp, err := c.DeleteConsumerGroups(e.ctx, spec)
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"GROUP", "DELETED", "REASON"}}
+	for _, group := range resp.Groups {
+		reason := group.Skipped
+		if group.Error != "" {
+			reason = group.Error
+		}
+		t.add(group.GroupID, group.Deleted, reason)
+	}
+	note := table{}
+	note.add(resp.Message)
+	if resp.DryRun {
+		note.add("No groups were deleted; pass -confirm " + resp.Confirmation + " to delete them")
+	}
+	return e.print(resp, t, note)
+}
+
+func runGroupsDeleteOffsets(e *env, args []string) error {
+	fs := e.flags("groups delete-offsets", "GROUP")
+	topic := fs.String("topic", "", "Topic whose offsets to delete (required)")
+	partitionList := fs.String("partition", "", "Comma-separated partitions whose offsets to delete (default: all)")
+	yes := fs.Bool("yes", false, "Confirm the deletion")
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if *topic == "" {
+		return usagef("-topic is required")
+	}
+	spec := domain.OffsetDeletionSpec{Topic: *topic}
+	if spec.Partitions, err = parsePartitions(*partitionList); err != nil {
+		return err
+	}
+	if !*yes {
+		return usagef("group %q loses its position on topic %q; pass -yes to confirm", rest[0], *topic)
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.DeleteConsumerGroupOffsets(e.ctx, rest[0], spec)
+	if err != nil {
+		return err
+	}
+	t := table{header: []string{"TOPIC", "PARTITION", "OFFSET"}}
+	for _, offset := range resp.Offsets {
+		t.add(offset.Topic, offset.Partition, offset.Offset)
+	}
+	note := table{}
+	note.add(resp.Message)
+	return e.print(resp, t, note)
+}
+
+func runGroupsRemoveMembers(e *env, args []string) error {
+	fs := e.flags("groups remove-members", "GROUP")
+	var instances stringList
+	fs.Var(&instances, "instance", "group.instance.id of a static member to remove (repeatable)")
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if len(instances) == 0 {
+		return usagef("pass at least one -instance")
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.RemoveConsumerGroupMembers(e.ctx, rest[0], client.MemberRemovalRequest{GroupInstanceIDs: instances})
+	if err != nil {
+		return err
+	}
+	t := table{header: []string{"INSTANCE", "MEMBER", "REMOVED", "ERROR"}}
+	for _, member := range resp.Members {
+		t.add(member.GroupInstanceID, member.MemberID, member.Removed, member.Error)
+	}
+	note := table{}
+	note.add(resp.Message)
+	return e.print(resp, t, note)
+}
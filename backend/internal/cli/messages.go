@@ -0,0 +1,458 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/valeriouberti/maestro/internal/export"
+	"github.com/valeriouberti/maestro/internal/jobs"
+	"github.com/valeriouberti/maestro/pkg/client"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+const (
+	// tailBatchSize is the maximum number of messages read from a partition per poll
+	tailBatchSize = 500
+	// searchPollInterval is the interval between checks of a running search job
+	searchPollInterval = 500 * time.Millisecond
+	// maxLineBytes caps the length of a message read from standard input
+	maxLineBytes = 1 << 20
+)
+
+var messageHeader = []string{"PARTITION", "OFFSET", "TIMESTAMP", "KEY", "VALUE"}
+
+// messageStream prints messages as they arrive: as table rows, one JSON object per
+// line or one YAML document per message
+type messageStream struct {
+	e      *env
+	format string
+	widths []int // Column widths of the table, growing with the rows printed
+}
+
+func (s *messageStream) write(messages []domain.TopicMessage) error {
+	switch s.format {
+	case outputJSON:
+		enc := json.NewEncoder(s.e.stdout)
+		for _, message := range messages {
+			if err := enc.Encode(message); err != nil {
+				return err
+			}
+		}
+		return nil
+	case outputYAML:
+		for _, message := range messages {
+			data, err := toYAML(message)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(s.e.stdout, "---\n%s", data)
+		}
+		return nil
+	}
+
+	if len(messages) == 0 {
+		return nil
+	}
+	var rows [][]string
+	if s.widths == nil {
+		rows = append(rows, messageHeader)
+		s.widths = make([]int, len(messageHeader))
+	}
+	for _, message := range messages {
+		rows = append(rows, messageRow(message))
+	}
+	// Unlike a tabwriter, keep the columns aligned with the rows of earlier polls
+	for _, row := range rows {
+		for i, cell := range row {
+			s.widths[i] = max(s.widths[i], len(cell))
+		}
+	}
+	for _, row := range rows {
+		var line strings.Builder
+		for i, cell := range row[:len(row)-1] {
+			fmt.Fprintf(&line, "%-*s   ", s.widths[i], cell)
+		}
+		line.WriteString(row[len(row)-1])
+		if _, err := fmt.Fprintln(s.e.stdout, line.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func messageRow(message domain.TopicMessage) []string {
+	return []string{
+		fmt.Sprint(message.Partition),
+		fmt.Sprint(message.Offset),
+		message.Timestamp.Format(time.RFC3339Nano),
+		message.Key,
+		message.Value,
+	}
+}
+
+// sortByTime orders messages of several partitions by timestamp, keeping the offset order within a partition
+func sortByTime(messages []domain.TopicMessage) {
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].Timestamp.Before(messages[j].Timestamp)
+	})
+}
+
+// topicPartitions returns the partitions given by a flag, or all partitions of the topic
+func (e *env) topicPartitions(c *client.Client, topicName, value string) ([]int32, error) {
+	partitions, err := parsePartitions(value)
+	if err != nil || len(partitions) > 0 {
+		return partitions, err
+	}
+
+	resp, err := c.GetTopic(e.ctx, topicName)
+	if err != nil {
+		return nil, err
+	}
+	for partition := int32(0); partition < resp.Topic.NumPartitions; partition++ {
+		partitions = append(partitions, partition)
+	}
+	return partitions, nil
+}
+
+func runMessagesTail(e *env, args []string) error {
+	fs := e.flags("messages tail", "TOPIC")
+	partitionList := fs.String("partition", "", "Comma-separated partitions to read (default: all)")
+	lines := fs.Int("n", 10, "Number of latest messages to show")
+	follow := fs.Bool("f", false, "Keep showing new messages until interrupted")
+	interval := fs.Duration("interval", time.Second, "Interval between polls for new messages with -f")
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if *lines < 1 {
+		return usagef("-n must be at least 1")
+	}
+	format, err := e.outputFormat()
+	if err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	topicName := rest[0]
+	partitions, err := e.topicPartitions(c, topicName, *partitionList)
+	if err != nil {
+		return err
+	}
+
+	// next holds the offset following the last message seen of every partition
+	next := make(map[int32]int64, len(partitions))
+	poll := func(limit int) ([]domain.TopicMessage, error) {
+		var messages []domain.TopicMessage
+		for _, partition := range partitions {
+			params := &client.GetTopicMessagesParams{Partition: partition, Offset: "latest", Limit: int64(limit)}
+			if offset, ok := next[partition]; ok {
+				params.Offset = fmt.Sprint(offset)
+			}
+			resp, err := c.GetTopicMessages(e.ctx, topicName, params)
+			if err != nil {
+				return nil, err
+			}
+			if n := len(resp.Messages); n > 0 {
+				next[partition] = resp.Messages[n-1].Offset + 1
+			}
+			messages = append(messages, resp.Messages...)
+		}
+		sortByTime(messages)
+		return messages, nil
+	}
+
+	stream := &messageStream{e: e, format: format}
+	messages, err := poll(*lines)
+	if err != nil {
+		return err
+	}
+	if len(messages) > *lines {
+		messages = messages[len(messages)-*lines:]
+	}
+	if err := stream.write(messages); err != nil {
+		return err
+	}
+
+	if !*follow {
+		return nil
+	}
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		messages, err := poll(tailBatchSize)
+		if err != nil {
+			if e.ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if err := stream.write(messages); err != nil {
+			return err
+		}
+	}
+}
+
+func runMessagesProduce(e *env, args []string) error {
+	fs := e.flags("messages produce", "TOPIC")
+	key := fs.String("key", "", "Key of the messages")
+	value := fs.String("value", "", "Value of a single message; without it, every line of the input is a message")
+	file := fs.String("file", "", "Read the messages from this file instead of standard input")
+	partition := fs.Int("partition", -1, "Partition to publish to (default: chosen by the partitioner)")
+	var headers keyValues
+	fs.Var(&headers, "header", "Header as KEY=VALUE (repeatable)")
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	valueSet := false
+	fs.Visit(func(f *flag.Flag) {
+		valueSet = valueSet || f.Name == "value"
+	})
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	topicName := rest[0]
+
+	if valueSet {
+		resp, err := c.PublishMessage(e.ctx, topicName, client.MessagePublishRequest{
+			Key:       *key,
+			Value:     *value,
+			Headers:   headers,
+			Partition: int32(*partition),
+		})
+		if err != nil {
+			return err
+		}
+		t := table{}
+		t.add(fmt.Sprintf("%s to %s", resp.Message, resp.Topic))
+		return e.print(resp, t)
+	}
+
+	input := e.stdin
+	if *file != "" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		input = f
+	}
+	records, err := readRecords(input, *key, headers, *partition)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return usagef("no messages to publish; pass -value or write one message per line to standard input")
+	}
+
+	resp, err := c.PublishBatch(e.ctx, topicName, &client.PublishBatchParams{Results: "failed"}, client.BatchPublishRequest{Records: records})
+	if err != nil {
+		return err
+	}
+
+	summary := table{}
+	summary.add(resp.Message + " to " + resp.Topic)
+	failures := table{title: "Failures", header: []string{"LINE", "ERROR"}}
+	for _, result := range resp.Results {
+		failures.add(result.Index+1, result.Error)
+	}
+	tables := []table{summary}
+	if len(failures.rows) > 0 {
+		tables = append(tables, failures)
+	}
+	if err := e.print(resp, tables...); err != nil {
+		return err
+	}
+	if resp.Summary.Failed > 0 {
+		return fmt.Errorf("%d of %d messages were not published", resp.Summary.Failed, resp.Summary.Total)
+	}
+	return nil
+}
+
+// readRecords reads one record per line, skipping empty lines
+func readRecords(r io.Reader, key string, headers map[string]string, partition int) ([]domain.ProduceRecord, error) {
+	var target *int32
+	if partition >= 0 {
+		p := int32(partition)
+		target = &p
+	}
+
+	var records []domain.ProduceRecord
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineBytes)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		records = append(records, domain.ProduceRecord{
+			Key:       key,
+			Value:     line,
+			Headers:   headers,
+			Partition: target,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read messages: %w", err)
+	}
+	return records, nil
+}
+
+func runMessagesSearch(e *env, args []string) error {
+	fs := e.flags("messages search", "TOPIC")
+	var where stringList
+	fs.Var(&where, "where", "Filter as 'FIELD OP [VALUE]', e.g. 'value contains timeout' or 'header:trace-id exists' (repeatable, all must match)")
+	partitionList := fs.String("partition", "", "Comma-separated partitions to search (default: all)")
+	since := fs.String("since", "", "Only messages at or after this time, as RFC 3339 or a duration ago such as 2h")
+	until := fs.String("until", "", "Only messages before this time, as RFC 3339 or a duration ago")
+	startOffset := fs.Int64("start-offset", -1, "First offset to search in every partition")
+	endOffset := fs.Int64("end-offset", -1, "Offset to stop at in every partition, exclusive")
+	limit := fs.Int("limit", 0, "Maximum number of messages to show (default: all)")
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	spec := domain.ExportSpec{Topic: rest[0], Format: export.FormatNDJSON}
+	for _, expr := range where {
+		filter, err := parseFilter(expr)
+		if err != nil {
+			return err
+		}
+		spec.Filters = append(spec.Filters, filter)
+	}
+	if spec.Range.Partitions, err = parsePartitions(*partitionList); err != nil {
+		return err
+	}
+	if spec.Range.StartTime, err = parseTime(*since); err != nil {
+		return err
+	}
+	if spec.Range.EndTime, err = parseTime(*until); err != nil {
+		return err
+	}
+	if *startOffset >= 0 {
+		spec.Range.StartOffset = startOffset
+	}
+	if *endOffset >= 0 {
+		spec.Range.EndOffset = endOffset
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	// Check the topic first: a failed job only reports a message, not why it failed
+	if _, err := c.GetTopic(e.ctx, spec.Topic); err != nil {
+		return err
+	}
+	messages, err := e.search(c, spec, *limit)
+	if err != nil {
+		return err
+	}
+
+	t := table{header: messageHeader}
+	for _, message := range messages {
+		t.rows = append(t.rows, messageRow(message))
+	}
+	return e.print(messages, t)
+}
+
+// search runs an export job with the filters of spec and returns up to limit of the
+// exported messages. Interrupting the search cancels the job.
+func (e *env) search(c *client.Client, spec domain.ExportSpec, limit int) ([]domain.TopicMessage, error) {
+	params, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	submitted, err := c.SubmitJob(e.ctx, client.JobSubmitRequest{Type: export.JobType, Params: params})
+	if err != nil {
+		return nil, err
+	}
+	job := submitted.Job
+
+	for job.Status == jobs.StatusPending || job.Status == jobs.StatusRunning {
+		select {
+		case <-e.ctx.Done():
+			ctx, cancel := context.WithTimeout(context.Background(), e.opts.timeout)
+			defer cancel()
+			if _, err := c.CancelJob(ctx, job.ID); err != nil {
+				fmt.Fprintf(e.stderr, "maestro: failed to cancel search job %s: %v\n", job.ID, err)
+			}
+			return nil, e.ctx.Err()
+		case <-time.After(searchPollInterval):
+		}
+
+		resp, err := c.GetJob(e.ctx, job.ID)
+		if err != nil {
+			return nil, err
+		}
+		job = resp.Job
+	}
+
+	if job.Status != jobs.StatusCompleted {
+		return nil, fmt.Errorf("search job %s %s: %s", job.ID, job.Status, job.Error)
+	}
+
+	result, err := c.GetJobResult(e.ctx, job.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	messages := []domain.TopicMessage{}
+	dec := json.NewDecoder(result)
+	for limit <= 0 || len(messages) < limit {
+		var message domain.TopicMessage
+		if err := dec.Decode(&message); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read search results: %w", err)
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// parseFilter parses a filter given as "FIELD OP [VALUE]"
+func parseFilter(expr string) (domain.MessageFilter, error) {
+	field, rest, _ := strings.Cut(strings.TrimSpace(expr), " ")
+	op, value, _ := strings.Cut(strings.TrimSpace(rest), " ")
+	if field == "" || op == "" {
+		return domain.MessageFilter{}, usagef("invalid filter %q, expected 'FIELD OP [VALUE]'", expr)
+	}
+	return domain.MessageFilter{Field: field, Op: op, Value: strings.TrimSpace(value)}, nil
+}
+
+// parseTime parses a time given as RFC 3339 or as a duration before now; empty is no time
+func parseTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		t := time.Now().Add(-d)
+		return &t, nil
+	}
+	return nil, usagef("invalid time %q, expected RFC 3339 or a duration such as 2h", value)
+}
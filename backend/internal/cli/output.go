@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// table is a section of table output
+type table struct {
+	title  string // Printed above the table when not empty
+	header []string
+	rows   [][]string
+}
+
+func (t *table) add(cells ...any) {
+	row := make([]string, len(cells))
+	for i, cell := range cells {
+		row[i] = fmt.Sprint(cell)
+	}
+	t.rows = append(t.rows, row)
+}
+
+// outputFormat returns the selected output format: the -output flag, then the
+// default of the context, then table
+func (e *env) outputFormat() (string, error) {
+	format := e.opts.output
+	if format == "" {
+		if conn, err := e.connection(); err == nil {
+			format = conn.Output
+		}
+	}
+	switch format {
+	case "":
+		return outputTable, nil
+	case outputTable, outputJSON, outputYAML:
+		return format, nil
+	}
+	return "", usagef("unsupported output format %q, expected table, json or yaml", format)
+}
+
+// print writes value as JSON or YAML, or the tables in table format
+func (e *env) print(value any, tables ...table) error {
+	format, err := e.outputFormat()
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case outputJSON:
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(value)
+	case outputYAML:
+		data, err := toYAML(value)
+		if err != nil {
+			return err
+		}
+		_, err = e.stdout.Write(data)
+		return err
+	}
+
+	for i, t := range tables {
+		if i > 0 {
+			fmt.Fprintln(e.stdout)
+		}
+		if t.title != "" {
+			fmt.Fprintf(e.stdout, "%s:\n", t.title)
+		}
+		w := tabwriter.NewWriter(e.stdout, 0, 0, 3, ' ', 0)
+		if len(t.header) > 0 {
+			fmt.Fprintln(w, strings.Join(t.header, "\t"))
+		}
+		for _, row := range t.rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toYAML encodes value as YAML with the field names and order of its JSON encoding
+func toYAML(value any) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is YAML: decoding it into a node keeps the order of the fields
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	blockStyle(&node)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), enc.Close()
+}
+
+// blockStyle turns the flow style of decoded JSON into the YAML block style
+func blockStyle(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode {
+		if node.Tag == "!!str" {
+			// Keep quotes only where needed, e.g. for strings that look like numbers
+			node.Style = 0
+		}
+		return
+	}
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"flag"
+	"maps"
+	"slices"
+
+	"github.com/valeriouberti/maestro/pkg/client"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+func runTemplatesList(e *env, args []string) error {
+	fs := e.flags("templates list", "")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.ListTopicTemplates(e.ctx)
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"NAME", "TOPIC NAME", "PARTITIONS", "REPLICATION", "SOURCE", "DESCRIPTION"}}
+	for _, template := range resp.Templates {
+		t.add(template.Name, template.TopicName, template.NumPartitions, template.ReplicationFactor, template.Source, template.Description)
+	}
+	return e.print(resp.Templates, t)
+}
+
+func runTemplatesDescribe(e *env, args []string) error {
+	fs := e.flags("templates describe", "NAME")
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.GetTopicTemplate(e.ctx, rest[0])
+	if err != nil {
+		return err
+	}
+	return e.printTemplate(resp.Template)
+}
+
+// templateOptions are the flags of the fields of a topic template
+type templateOptions struct {
+	description       *string
+	topicName         *string
+	partitions        *int
+	replicationFactor *int
+	config            keyValues
+}
+
+func templateFlags(fs *flag.FlagSet) *templateOptions {
+	o := &templateOptions{
+		description:       fs.String("description", "", "Description of the template"),
+		topicName:         fs.String("topic-name", "", "Name of the topics created from the template, with {placeholders}, e.g. {team}.{entity}.changelog"),
+		partitions:        fs.Int("partitions", 1, "Number of partitions"),
+		replicationFactor: fs.Int("replication-factor", 1, "Replication factor"),
+	}
+	fs.Var(&o.config, "config", "Configuration entry as KEY=VALUE (repeatable)")
+	return o
+}
+
+func (o *templateOptions) request(name string) client.TopicTemplateRequest {
+	return client.TopicTemplateRequest{
+		Name:              name,
+		Description:       *o.description,
+		TopicName:         *o.topicName,
+		NumPartitions:     int32(*o.partitions),
+		ReplicationFactor: int32(*o.replicationFactor),
+		Config:            o.config,
+	}
+}
+
+func runTemplatesCreate(e *env, args []string) error {
+	fs := e.flags("templates create", "NAME")
+	opts := templateFlags(fs)
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.CreateTopicTemplate(e.ctx, opts.request(rest[0]))
+	if err != nil {
+		return err
+	}
+	return e.printTemplate(resp.Template)
+}
+
+func runTemplatesUpdate(e *env, args []string) error {
+	fs := e.flags("templates update", "NAME")
+	opts := templateFlags(fs)
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.UpdateTopicTemplate(e.ctx, rest[0], opts.request(rest[0]))
+	if err != nil {
+		return err
+	}
+	return e.printTemplate(resp.Template)
+}
+
+func runTemplatesDelete(e *env, args []string) error {
+	fs := e.flags("templates delete", "NAME")
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.DeleteTopicTemplate(e.ctx, rest[0])
+	if err != nil {
+		return err
+	}
+	t := table{}
+	t.add(resp.Message + ": " + resp.Template)
+	return e.print(resp, t)
+}
+
+func (e *env) printTemplate(template domain.TopicTemplate) error {
+	summary := table{}
+	summary.add("Name:", template.Name)
+	if template.Description != "" {
+		summary.add("Description:", template.Description)
+	}
+	if template.TopicName != "" {
+		summary.add("Topic name:", template.TopicName)
+	}
+	summary.add("Partitions:", template.NumPartitions)
+	summary.add("Replication:", template.ReplicationFactor)
+	summary.add("Source:", template.Source)
+
+	config := table{title: "Configuration", header: []string{"KEY", "VALUE"}}
+	for _, key := range slices.Sorted(maps.Keys(template.Config)) {
+		config.add(key, template.Config[key])
+	}
+
+	tables := []table{summary}
+	if len(config.rows) > 0 {
+		tables = append(tables, config)
+	}
+	return e.print(template, tables...)
+}
@@ -0,0 +1,334 @@
+package cli
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/valeriouberti/maestro/pkg/client"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// keyValues is a repeatable KEY=VALUE flag
+type keyValues map[string]string
+
+func (kv *keyValues) String() string {
+	pairs := make([]string, 0, len(*kv))
+	for _, key := range slices.Sorted(maps.Keys(*kv)) {
+		pairs = append(pairs, key+"="+(*kv)[key])
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (kv *keyValues) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected KEY=VALUE, got %q", value)
+	}
+	if *kv == nil {
+		*kv = make(keyValues)
+	}
+	(*kv)[key] = val
+	return nil
+}
+
+// stringList is a repeatable string flag
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// parsePartitions parses a comma-separated list of partitions
+func parsePartitions(value string) ([]int32, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var partitions []int32
+	for _, part := range strings.Split(value, ",") {
+		partition, err := strconv.ParseInt(strings.TrimSpace(part), 10, 32)
+		if err != nil || partition < 0 {
+			return nil, usagef("invalid partition %q", part)
+		}
+		partitions = append(partitions, int32(partition))
+	}
+	return partitions, nil
+}
+
+func runClusters(e *env, args []string) error {
+	fs := e.flags("clusters", "")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	configured, err := c.ListConfiguredClusters(e.ctx)
+	if err != nil {
+		return err
+	}
+	resp, err := c.GetClusters(e.ctx)
+	if err != nil {
+		return err
+	}
+
+	conn, err := e.connection()
+	if err != nil {
+		return err
+	}
+	clusters := table{title: "Clusters", header: []string{"SELECTED", "NAME", "DEFAULT", "BROKERS"}}
+	for _, cluster := range configured.Clusters {
+		marker := ""
+		if cluster.Name == conn.Cluster || (conn.Cluster == "" && cluster.Default) {
+			marker = "*"
+		}
+		brokers := strings.Join(cluster.Brokers, ",")
+		if cluster.Demo {
+			brokers = "(demo)"
+		}
+		clusters.add(marker, cluster.Name, cluster.Default, brokers)
+	}
+	brokers := table{title: "Brokers", header: []string{"ID", "HOST", "PORT"}}
+	for _, broker := range resp.Brokers {
+		brokers.add(broker.ID, broker.Host, broker.Port)
+	}
+
+	value := struct {
+		Clusters []domain.ClusterInfo `json:"clusters"`
+		Brokers  []domain.BrokerInfo  `json:"brokers"`
+	}{configured.Clusters, resp.Brokers}
+	return e.print(value, clusters, brokers)
+}
+
+func runTopicsList(e *env, args []string) error {
+	fs := e.flags("topics list", "")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.ListTopics(e.ctx)
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"NAME", "PARTITIONS", "REPLICATION", "FLAGS"}}
+	for _, topic := range resp.Topics {
+		t.add(topic.Name, topic.NumPartitions, topic.ReplicationFactor, topicFlags(topic))
+	}
+	return e.print(resp.Topics, t)
+}
+
+func runTopicsDescribe(e *env, args []string) error {
+	fs := e.flags("topics describe", "NAME")
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.GetTopic(e.ctx, rest[0])
+	if err != nil {
+		return err
+	}
+	return e.printTopic(resp.Topic)
+}
+
+// printTopic prints a topic with its partitions and configuration overrides
+func (e *env) printTopic(topic domain.TopicInfo) error {
+	summary := table{}
+	summary.add("Name:", topic.Name)
+	summary.add("Partitions:", topic.NumPartitions)
+	summary.add("Replication:", topic.ReplicationFactor)
+	if flags := topicFlags(topic); flags != "" {
+		summary.add("Flags:", flags)
+	}
+
+	partitions := table{title: "Partitions", header: []string{"PARTITION", "LEADER", "REPLICAS", "ISR"}}
+	for _, partition := range topic.Partitions {
+		partitions.add(partition.ID, partition.Leader, joinInts(partition.Replicas), joinInts(partition.ISR))
+	}
+
+	config := table{title: "Configuration", header: []string{"KEY", "VALUE"}}
+	for _, key := range slices.Sorted(maps.Keys(topic.Config)) {
+		config.add(key, topic.Config[key])
+	}
+
+	tables := []table{summary}
+	if len(partitions.rows) > 0 {
+		tables = append(tables, partitions)
+	}
+	if len(config.rows) > 0 {
+		tables = append(tables, config)
+	}
+	return e.print(topic, tables...)
+}
+
+// topicFlags tells whether a topic is internal or protected
+func topicFlags(topic domain.TopicInfo) string {
+	var flags []string
+	if topic.IsInternal {
+		flags = append(flags, "internal")
+	}
+	if topic.IsProtected {
+		flags = append(flags, "protected")
+	}
+	return strings.Join(flags, ",")
+}
+
+func joinInts(values []int32) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = strconv.Itoa(int(value))
+	}
+	return strings.Join(parts, ",")
+}
+
+func runTopicsCreate(e *env, args []string) error {
+	fs := e.flags("topics create", "[NAME]")
+	partitions := fs.Int("partitions", 0, "Number of partitions (default 1, or the template's)")
+	replicationFactor := fs.Int("replication-factor", 0, "Replication factor (default 1, or the template's)")
+	var config, params keyValues
+	fs.Var(&config, "config", "Configuration override as KEY=VALUE (repeatable)")
+	template := fs.String("template", "", "Topic template providing the defaults")
+	fs.Var(&params, "param", "Parameter of the template's topic name as KEY=VALUE, used without NAME (repeatable)")
+	rest, err := parseRange(fs, args, 0, 1)
+	if err != nil {
+		return err
+	}
+
+	request := client.TopicCreationRequest{
+		NumPartitions:     int32(*partitions),
+		ReplicationFactor: int32(*replicationFactor),
+		Config:            config,
+		Template:          *template,
+		Parameters:        params,
+	}
+	if len(rest) > 0 {
+		request.Name = rest[0]
+	}
+	if *template == "" {
+		if request.Name == "" {
+			return usagef("NAME is required without -template")
+		}
+		request.NumPartitions = max(request.NumPartitions, 1)
+		request.ReplicationFactor = max(request.ReplicationFactor, 1)
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	resp, err := c.CreateTopic(e.ctx, request)
+	if err != nil {
+		return err
+	}
+	return e.printTopic(resp.Topic)
+}
+
+func runTopicsDelete(e *env, args []string) error {
+	fs := e.flags("topics delete", "NAME")
+	yes := fs.Bool("yes", false, "Confirm the deletion")
+	confirm := fs.String("confirm", "", "Name of the topic, confirming the deletion of a protected topic")
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if !*yes {
+		return usagef("deleting topic %q loses its messages; pass -yes to confirm", rest[0])
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.DeleteTopic(e.ctx, rest[0], &client.DeleteTopicParams{Confirm: *confirm})
+	if err != nil {
+		return err
+	}
+	t := table{}
+	t.add(resp.Message + ": " + resp.Topic)
+	return e.print(resp, t)
+}
+
+func runTopicsAlter(e *env, args []string) error {
+	fs := e.flags("topics alter", "NAME")
+	var set keyValues
+	var unset stringList
+	fs.Var(&set, "config", "Configuration override to set as KEY=VALUE (repeatable)")
+	fs.Var(&unset, "delete-config", "Configuration override to remove (repeatable)")
+	confirm := fs.String("confirm", "", "Name of the topic, confirming risky changes to a protected topic")
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if len(set) == 0 && len(unset) == 0 {
+		return usagef("nothing to change; pass -config or -delete-config")
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	// The API replaces all overrides, so apply the changes to the current ones
+	current, err := c.GetTopic(e.ctx, rest[0])
+	if err != nil {
+		return err
+	}
+	config := maps.Clone(current.Topic.Config)
+	if config == nil {
+		config = make(map[string]string)
+	}
+	maps.Copy(config, set)
+	for _, key := range unset {
+		delete(config, key)
+	}
+
+	resp, err := c.UpdateTopicConfig(e.ctx, rest[0], &client.UpdateTopicConfigParams{Confirm: *confirm}, client.TopicConfigUpdateRequest{Config: config})
+	if err != nil {
+		return err
+	}
+	return e.printTopic(resp.Topic)
+}
+
+func runTopicsConsumers(e *env, args []string) error {
+	fs := e.flags("topics consumers", "NAME")
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.GetTopicConsumers(e.ctx, rest[0])
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"GROUP", "STATE", "MEMBERS", "ASSIGNED", "LAG"}}
+	for _, consumer := range resp.Consumers {
+		assigned := make([]string, len(consumer.AssignedPartitions))
+		for i, partition := range consumer.AssignedPartitions {
+			assigned[i] = fmt.Sprint(partition)
+		}
+		t.add(consumer.GroupID, consumer.State, consumer.MemberCount, strings.Join(assigned, ","), consumer.Lag)
+	}
+	return e.print(resp.Consumers, t)
+}
@@ -0,0 +1,227 @@
+package clusters
+
+import (
+	"context"
+	"time"
+
+	"github.com/valeriouberti/maestro/internal/kafka_client"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// The methods of kafka_client.Client forward the operation to the cluster selected by ctx
+
+// GetBrokers implements kafka_client.Client
+func (r *Registry) GetBrokers(ctx context.Context) ([]domain.BrokerInfo, error) {
+	client, err := r.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetBrokers(ctx)
+}
+
+// ListTopics implements kafka_client.Client
+func (r *Registry) ListTopics(ctx context.Context) ([]domain.TopicInfo, error) {
+	client, err := r.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListTopics(ctx)
+}
+
+// GetTopicDetails implements kafka_client.Client
+func (r *Registry) GetTopicDetails(ctx context.Context, topicName string) (*domain.TopicInfo, error) {
+	client, err := r.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetTopicDetails(ctx, topicName)
+}
+
+// CreateTopic implements kafka_client.Client
+func (r *Registry) CreateTopic(ctx context.Context, topic domain.TopicInfo) error {
+	client, err := r.Client(ctx)
+	if err != nil {
+		return err
+	}
+	return client.CreateTopic(ctx, topic)
+}
+
+// DeleteTopic implements kafka_client.Client
+func (r *Registry) DeleteTopic(ctx context.Context, topicName string) error {
+	client, err := r.Client(ctx)
+	if err != nil {
+		return err
+	}
+	return client.DeleteTopic(ctx, topicName)
+}
+
+// UpdateTopicConfig implements kafka_client.Client
+func (r *Registry) UpdateTopicConfig(ctx context.Context, topicName string, config map[string]string) error {
+	client, err := r.Client(ctx)
+	if err != nil {
+		return err
+	}
+	return client.UpdateTopicConfig(ctx, topicName, config)
+}
+
+// DeleteTopics implements kafka_client.Client
+func (r *Registry) DeleteTopics(ctx context.Context, topicNames []string) ([]domain.BulkTopicResult, error) {
+	client, err := r.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.DeleteTopics(ctx, topicNames)
+}
+
+// AlterTopicsConfig implements kafka_client.Client
+func (r *Registry) AlterTopicsConfig(ctx context.Context, topicNames []string, set map[string]string, deleted []string) ([]domain.BulkTopicResult, error) {
+	client, err := r.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.AlterTopicsConfig(ctx, topicNames, set, deleted)
+}
+
+// CreatePartitions implements kafka_client.Client
+func (r *Registry) CreatePartitions(ctx context.Context, topicNames []string, partitions int32) ([]domain.BulkTopicResult, error) {
+	client, err := r.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.CreatePartitions(ctx, topicNames, partitions)
+}
+
+// ListConsumerGroups implements kafka_client.Client
+func (r *Registry) ListConsumerGroups(ctx context.Context, states ...string) ([]domain.ConsumerGroupInfo, error) {
+	client, err := r.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListConsumerGroups(ctx, states...)
+}
+
+// GetConsumerGroupDetails implements kafka_client.Client
+func (r *Registry) GetConsumerGroupDetails(ctx context.Context, groupID string) (*domain.ConsumerGroupDetails, error) {
+	client, err := r.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetConsumerGroupDetails(ctx, groupID)
+}
+
+// GetConsumerGroupLag implements kafka_client.Client
+func (r *Registry) GetConsumerGroupLag(ctx context.Context, groupID string) (*domain.ConsumerGroupLag, error) {
+	client, err := r.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetConsumerGroupLag(ctx, groupID)
+}
+
+// ResetConsumerGroupOffsets implements kafka_client.Client
+func (r *Registry) ResetConsumerGroupOffsets(ctx context.Context, groupID string, spec domain.OffsetResetSpec) ([]domain.OffsetReset, error) {
+	client, err := r.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.ResetConsumerGroupOffsets(ctx, groupID, spec)
+}
+
+// DeleteConsumerGroup implements kafka_client.Client
+func (r *Registry) DeleteConsumerGroup(ctx context.Context, groupID string) error {
+	client, err := r.Client(ctx)
+	if err != nil {
+		return err
+	}
+	return client.DeleteConsumerGroup(ctx, groupID)
+}
+
+// DeleteConsumerGroups implements kafka_client.Client
+func (r *Registry) DeleteConsumerGroups(ctx context.Context, groupIDs []string) ([]domain.GroupDeletion, error) {
+	client, err := r.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.DeleteConsumerGroups(ctx, groupIDs)
+}
+
+// DeleteConsumerGroupOffsets implements kafka_client.Client
+func (r *Registry) DeleteConsumerGroupOffsets(ctx context.Context, groupID string, spec domain.OffsetDeletionSpec) ([]domain.CommittedOffset, error) {
+	client, err := r.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.DeleteConsumerGroupOffsets(ctx, groupID, spec)
+}
+
+// RemoveConsumerGroupMembers implements kafka_client.Client
+func (r *Registry) RemoveConsumerGroupMembers(ctx context.Context, groupID string, instanceIDs []string) ([]domain.MemberRemoval, error) {
+	client, err := r.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.RemoveConsumerGroupMembers(ctx, groupID, instanceIDs)
+}
+
+// GetActiveProducers implements kafka_client.Client
+func (r *Registry) GetActiveProducers(ctx context.Context, topicNames []string) ([]domain.ActiveProducer, error) {
+	client, err := r.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetActiveProducers(ctx, topicNames)
+}
+
+// GetTopicMessages implements kafka_client.Client
+func (r *Registry) GetTopicMessages(ctx context.Context, topicName string, partition int32, offset int64, limit int) ([]domain.TopicMessage, error) {
+	client, err := r.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetTopicMessages(ctx, topicName, partition, offset, limit)
+}
+
+// StreamTopicMessages implements kafka_client.Client
+func (r *Registry) StreamTopicMessages(ctx context.Context, topicName string, rng domain.MessageRange, fn func(domain.TopicMessage) error) error {
+	client, err := r.Client(ctx)
+	if err != nil {
+		return err
+	}
+	return client.StreamTopicMessages(ctx, topicName, rng, fn)
+}
+
+// PublishMessage implements kafka_client.Client
+func (r *Registry) PublishMessage(ctx context.Context, topicName string, partition int32, key string, value string, headers map[string]string) error {
+	client, err := r.Client(ctx)
+	if err != nil {
+		return err
+	}
+	return client.PublishMessage(ctx, topicName, partition, key, value, headers)
+}
+
+// PublishMessages implements kafka_client.Client
+func (r *Registry) PublishMessages(ctx context.Context, topicName string, records []domain.ProduceRecord, ratePerSecond int) ([]domain.ProduceResult, error) {
+	client, err := r.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.PublishMessages(ctx, topicName, records, ratePerSecond)
+}
+
+// NewTopicProducer implements kafka_client.Client
+func (r *Registry) NewTopicProducer(ctx context.Context, topicName string, onDelivery func(err error)) (kafka_client.Producer, error) {
+	client, err := r.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.NewTopicProducer(ctx, topicName, onDelivery)
+}
+
+// OperationTimeout implements kafka_client.Client. It returns the timeout of the default cluster.
+func (r *Registry) OperationTimeout() time.Duration {
+	client, err := r.Client(context.Background())
+	if err != nil {
+		return 0
+	}
+	return client.OperationTimeout()
+}
@@ -0,0 +1,288 @@
+// Package clusters manages the Kafka clusters defined in the configuration. Its
+// Registry is a kafka_client.Client that forwards every operation to the cluster
+// selected for the request, so that handlers and jobs work with any cluster, and the
+// cluster definitions can be reloaded while the server runs.
+package clusters
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/valeriouberti/maestro/internal/config"
+	"github.com/valeriouberti/maestro/internal/kafka_client"
+	"github.com/valeriouberti/maestro/internal/kafka_client/fake"
+	"github.com/valeriouberti/maestro/internal/logging"
+	"github.com/valeriouberti/maestro/internal/problem"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// Header selects the cluster of a request; the "cluster" query parameter may be used instead
+const Header = "X-Maestro-Cluster"
+
+// closeDelay is how long the client of a removed or changed cluster stays open for the
+// operations still using it
+const closeDelay = time.Minute
+
+type contextKey struct{}
+
+// WithName returns a context selecting the named cluster; an empty name selects the default cluster
+func WithName(ctx context.Context, name string) context.Context {
+	if name == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKey{}, name)
+}
+
+// NameFrom returns the cluster selected by a context, or an empty string for the default cluster
+func NameFrom(ctx context.Context) string {
+	name, _ := ctx.Value(contextKey{}).(string)
+	return name
+}
+
+// cluster is a configured cluster and its client
+type cluster struct {
+	def    config.ClusterConfig
+	client kafka_client.Client
+}
+
+// Changes lists the clusters affected by a reload
+type Changes struct {
+	Added   []string
+	Updated []string
+	Removed []string
+}
+
+// Registry holds a client for every configured cluster
+type Registry struct {
+	mu          sync.RWMutex
+	clusters    map[string]*cluster
+	names       []string // In configuration order
+	defaultName string
+}
+
+var _ kafka_client.Client = (*Registry)(nil)
+
+// NewRegistry creates the clients of the configured clusters
+func NewRegistry(cfg *config.Config) (*Registry, error) {
+	r := &Registry{clusters: make(map[string]*cluster)}
+	if _, err := r.Load(cfg); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Load replaces the cluster definitions with those of cfg. Clients of unchanged
+// clusters are kept; those of removed or changed clusters are closed after a delay.
+// On error the current definitions stay in place.
+func (r *Registry) Load(cfg *config.Config) (Changes, error) {
+	r.mu.RLock()
+	current := r.clusters
+	r.mu.RUnlock()
+
+	var changes Changes
+	clusters := make(map[string]*cluster, len(cfg.Clusters))
+	names := make([]string, 0, len(cfg.Clusters))
+	var created []kafka_client.Client
+	for _, def := range cfg.Clusters {
+		names = append(names, def.Name)
+		if existing, ok := current[def.Name]; ok && sameConnection(existing.def, def) {
+			clusters[def.Name] = &cluster{def: def, client: existing.client}
+			continue
+		}
+
+		client, err := newClient(def)
+		if err != nil {
+			for _, c := range created {
+				c.Close()
+			}
+			return Changes{}, fmt.Errorf("failed to create the client of cluster %q: %w", def.Name, err)
+		}
+		created = append(created, client)
+		clusters[def.Name] = &cluster{def: def, client: client}
+		if _, ok := current[def.Name]; ok {
+			changes.Updated = append(changes.Updated, def.Name)
+		} else {
+			changes.Added = append(changes.Added, def.Name)
+		}
+	}
+
+	var stale []kafka_client.Client
+	for name, old := range current {
+		if c, ok := clusters[name]; !ok {
+			changes.Removed = append(changes.Removed, name)
+			stale = append(stale, old.client)
+		} else if c.client != old.client {
+			stale = append(stale, old.client)
+		}
+	}
+	slices.Sort(changes.Removed)
+
+	r.mu.Lock()
+	r.clusters = clusters
+	r.names = names
+	r.defaultName = cfg.DefaultCluster().Name
+	r.mu.Unlock()
+
+	if len(stale) > 0 {
+		time.AfterFunc(closeDelay, func() {
+			for _, client := range stale {
+				client.Close()
+			}
+		})
+	}
+	return changes, nil
+}
+
+// sameConnection reports whether two definitions of a cluster can share a client
+func sameConnection(a, b config.ClusterConfig) bool {
+	a.Default, b.Default = false, false
+	return reflect.DeepEqual(a, b)
+}
+
+// newClient creates the client of a cluster, traced
+func newClient(def config.ClusterConfig) (kafka_client.Client, error) {
+	if def.Demo {
+		return kafka_client.NewTracedClient(fake.NewDemo(), def.Name), nil
+	}
+	client, err := kafka_client.NewKafkaClientWithOptions(kafka_client.Options{
+		Brokers:    def.Brokers,
+		Timeout:    def.Timeout.Duration,
+		Properties: properties(def),
+		Logger:     slog.Default().With("cluster", def.Name),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return kafka_client.NewTracedClient(client, def.Name), nil
+}
+
+// properties returns the librdkafka properties of a cluster: its security settings
+// and the properties it sets explicitly, which take precedence
+func properties(def config.ClusterConfig) map[string]string {
+	props := make(map[string]string)
+	security := def.Security
+	set := func(key, value string) {
+		if value != "" {
+			props[key] = value
+		}
+	}
+	set("security.protocol", security.Protocol)
+	set("sasl.mechanism", security.SASLMechanism)
+	set("sasl.username", security.Username.Value())
+	set("sasl.password", security.Password.Value())
+	set("ssl.ca.location", security.CAFile)
+	set("ssl.certificate.location", security.CertFile)
+	set("ssl.key.location", security.KeyFile)
+	if security.InsecureSkipVerify {
+		props["enable.ssl.certificate.verification"] = strconv.FormatBool(false)
+	}
+	for key, value := range def.Properties.Values() {
+		props[key] = value
+	}
+	return props
+}
+
+// List returns the configured clusters in configuration order
+func (r *Registry) List() []domain.ClusterInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]domain.ClusterInfo, 0, len(r.names))
+	for _, name := range r.names {
+		def := r.clusters[name].def
+		list = append(list, domain.ClusterInfo{
+			Name:              def.Name,
+			Default:           def.Name == r.defaultName,
+			Demo:              def.Demo,
+			Brokers:           def.Brokers,
+			SchemaRegistryURL: def.SchemaRegistry.URL,
+		})
+	}
+	return list
+}
+
+// Has reports whether a cluster is configured
+func (r *Registry) Has(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.clusters[name]
+	return ok
+}
+
+// Selected returns the name of the cluster selected by ctx, the default cluster when
+// ctx selects none
+func (r *Registry) Selected(ctx context.Context) string {
+	if name := NameFrom(ctx); name != "" {
+		return name
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.defaultName
+}
+
+// Client returns the client of the cluster selected by ctx
+func (r *Registry) Client(ctx context.Context) (kafka_client.Client, error) {
+	name := NameFrom(ctx)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if name == "" {
+		name = r.defaultName
+	}
+	c, ok := r.clusters[name]
+	if !ok {
+		return nil, kafka_client.ClusterNotFoundError(name)
+	}
+	return c.client, nil
+}
+
+// Close closes the clients of all clusters
+func (r *Registry) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range r.clusters {
+		c.client.Close()
+	}
+	r.clusters = map[string]*cluster{}
+	r.names = nil
+}
+
+// Middleware selects the cluster named by the X-Maestro-Cluster header or the
+// "cluster" query parameter for the rest of the request. Requests naming an unknown
+// cluster are rejected with 404 Not Found.
+func Middleware(r *Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.GetHeader(Header)
+		if name == "" {
+			name = c.Query("cluster")
+		}
+		if name == "" {
+			c.Next()
+			return
+		}
+
+		if !r.Has(name) {
+			problem.Abort(c, problem.FromError(kafka_client.ClusterNotFoundError(name), "Unknown cluster"))
+			return
+		}
+		ctx := logging.With(c.Request.Context(), slog.String("cluster", name))
+		c.Request = c.Request.WithContext(WithName(ctx, name))
+		c.Next()
+	}
+}
+
+// LogChanges logs the clusters affected by a reload
+func LogChanges(changes Changes) {
+	if len(changes.Added)+len(changes.Updated)+len(changes.Removed) == 0 {
+		slog.Info("Cluster definitions unchanged")
+		return
+	}
+	slog.Info("Cluster definitions reloaded", "added", changes.Added, "updated", changes.Updated, "removed", changes.Removed)
+}
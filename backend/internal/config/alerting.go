@@ -0,0 +1,167 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"slices"
+)
+
+// AlertingConfig configures the alert rules evaluated against the clusters and the
+// notifiers they notify. Alerting is disabled without rules.
+type AlertingConfig struct {
+	Interval  Duration         `yaml:"interval" toml:"interval" json:"interval"` // Time between two evaluations of the rules
+	Rules     []AlertRule      `yaml:"rules" toml:"rules" json:"rules"`
+	Notifiers []NotifierConfig `yaml:"notifiers" toml:"notifiers" json:"notifiers"`
+}
+
+// AlertRule is a condition on the topics or consumer groups of clusters
+type AlertRule struct {
+	Name     string   `yaml:"name" toml:"name" json:"name"`
+	Type     string   `yaml:"type" toml:"type" json:"type"`             // consumer-lag, under-replicated, topic-created, topic-deleted or group-empty
+	Clusters []string `yaml:"clusters" toml:"clusters" json:"clusters"` // Clusters the rule applies to; all when empty
+	Groups   string   `yaml:"groups" toml:"groups" json:"groups"`       // Glob pattern of the consumer groups of consumer-lag and group-empty rules
+	Topics   string   `yaml:"topics" toml:"topics" json:"topics"`       // Glob pattern of the topics; for consumer-lag rules, the topics whose lag counts
+
+	// Threshold is the value the lag or the number of under-replicated partitions must exceed
+	Threshold int64 `yaml:"threshold" toml:"threshold" json:"threshold"`
+	// For is how long the condition must hold before the alert fires
+	For Duration `yaml:"for" toml:"for" json:"for"`
+	// RepeatInterval is how often a firing alert is notified again; zero notifies it once
+	RepeatInterval Duration `yaml:"repeatInterval" toml:"repeatInterval" json:"repeatInterval"`
+
+	Severity string   `yaml:"severity" toml:"severity" json:"severity"` // Free-form, e.g. warning (default) or critical
+	Notify   []string `yaml:"notify" toml:"notify" json:"notify"`       // Names of the notifiers; all when empty
+}
+
+// NotifierConfig defines where alert notifications are sent
+type NotifierConfig struct {
+	Name string `yaml:"name" toml:"name" json:"name"`
+	Type string `yaml:"type" toml:"type" json:"type"` // webhook, slack or email
+
+	// URL receives the notifications of webhook and slack notifiers. It is a Secret as
+	// Slack incoming webhook URLs embed their credentials.
+	URL Secret `yaml:"url" toml:"url" json:"url"`
+	// Headers are additional headers of webhook requests. They are Secrets as they
+	// usually carry credentials, such as Authorization.
+	Headers map[string]*Secret `yaml:"headers" toml:"headers" json:"headers"`
+
+	// Template is a Go template of the webhook request body, the Slack message text or
+	// the email body, executed with the alert
+	Template string `yaml:"template" toml:"template" json:"template"`
+
+	SMTP SMTPConfig `yaml:"smtp" toml:"smtp" json:"smtp"`
+}
+
+// SMTPConfig configures the delivery of email notifications
+type SMTPConfig struct {
+	Host     string   `yaml:"host" toml:"host" json:"host"`
+	Port     int      `yaml:"port" toml:"port" json:"port"` // Default 587
+	Username Secret   `yaml:"username" toml:"username" json:"username"`
+	Password Secret   `yaml:"password" toml:"password" json:"password"`
+	From     string   `yaml:"from" toml:"from" json:"from"`
+	To       []string `yaml:"to" toml:"to" json:"to"`
+}
+
+// alertRuleTypes are the supported types of alert rules
+var alertRuleTypes = []string{"consumer-lag", "under-replicated", "topic-created", "topic-deleted", "group-empty"}
+
+// validateAlerting checks the alert rules and the notifiers
+func (c *Config) validateAlerting() []error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	alerting := c.Alerting
+	if len(alerting.Rules) > 0 && alerting.Interval.Duration <= 0 {
+		fail("alerting.interval (ALERTING_INTERVAL) must be positive")
+	}
+
+	notifiers := make(map[string]bool, len(alerting.Notifiers))
+	for i, notifier := range alerting.Notifiers {
+		field := fmt.Sprintf("alerting.notifiers[%d]", i)
+		switch {
+		case notifier.Name == "":
+			fail("%s.name must be specified", field)
+		case notifiers[notifier.Name]:
+			fail("notifier %q is defined more than once", notifier.Name)
+		}
+		notifiers[notifier.Name] = true
+		if notifier.Name != "" {
+			field = fmt.Sprintf("notifier %q", notifier.Name)
+		}
+
+		switch notifier.Type {
+		case "webhook", "slack":
+			// References are checked once resolved
+			if !notifier.URL.IsReference() {
+				if u, err := url.Parse(notifier.URL.Value()); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+					fail("%s: url must be an http or https URL", field)
+				}
+			}
+		case "email":
+			if notifier.SMTP.Host == "" {
+				fail("%s: smtp.host must be specified", field)
+			}
+			if notifier.SMTP.Port < 0 || notifier.SMTP.Port > 65535 {
+				fail("%s: smtp.port must be a port number", field)
+			}
+			if notifier.SMTP.From == "" || len(notifier.SMTP.To) == 0 {
+				fail("%s: smtp.from and smtp.to must be specified", field)
+			}
+		default:
+			fail("%s: unknown type %q (supported: webhook, slack, email)", field, notifier.Type)
+		}
+	}
+
+	clusters := make(map[string]bool, len(c.Clusters))
+	for _, cluster := range c.Clusters {
+		clusters[cluster.Name] = true
+	}
+	rules := make(map[string]bool, len(alerting.Rules))
+	for i, rule := range alerting.Rules {
+		field := fmt.Sprintf("alerting.rules[%d]", i)
+		switch {
+		case rule.Name == "":
+			fail("%s.name must be specified", field)
+		case rules[rule.Name]:
+			fail("alert rule %q is defined more than once", rule.Name)
+		}
+		rules[rule.Name] = true
+		if rule.Name != "" {
+			field = fmt.Sprintf("alert rule %q", rule.Name)
+		}
+
+		if !slices.Contains(alertRuleTypes, rule.Type) {
+			fail("%s: unknown type %q (supported: consumer-lag, under-replicated, topic-created, topic-deleted, group-empty)", field, rule.Type)
+		}
+		for _, cluster := range rule.Clusters {
+			if !clusters[cluster] {
+				fail("%s: unknown cluster %q", field, cluster)
+			}
+		}
+		if _, err := path.Match(rule.Groups, ""); err != nil {
+			fail("%s: invalid groups pattern %q", field, rule.Groups)
+		}
+		if _, err := path.Match(rule.Topics, ""); err != nil {
+			fail("%s: invalid topics pattern %q", field, rule.Topics)
+		}
+		if rule.Threshold < 0 {
+			fail("%s: threshold must not be negative", field)
+		}
+		if rule.For.Duration < 0 || rule.RepeatInterval.Duration < 0 {
+			fail("%s: for and repeatInterval must not be negative", field)
+		}
+		for _, name := range rule.Notify {
+			if !notifiers[name] {
+				fail("%s: unknown notifier %q", field, name)
+			}
+		}
+	}
+	if len(alerting.Rules) > 0 && len(alerting.Notifiers) == 0 {
+		fail("alerting.notifiers must define at least one notifier for the alert rules")
+	}
+
+	return errs
+}
@@ -1,94 +1,490 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"regexp"
+	"slices"
 	"strconv"
-	"strings"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
-// Config holds application configuration
+// Config holds application configuration. It is read from the file named by
+// CONFIG_FILE, if any, and from environment variables, which override the file.
+// Credentials are Secrets, which are redacted when the configuration is encoded.
 type Config struct {
-	KafkaBrokers    []string
-	ServerPort      string
-	ReadTimeout     time.Duration
-	WriteTimeout    time.Duration
-	KafkaTimeout    time.Duration
-	LogLevel        string
-	EnableTLS       bool
-	CertFile        string
-	KeyFile         string
-	EnvironmentName string
-}
-
-// LoadConfig loads configuration from environment variables
+	Server          ServerConfig          `yaml:"server" toml:"server" json:"server"`
+	Auth            AuthConfig            `yaml:"auth" toml:"auth" json:"auth"`
+	Clusters        []ClusterConfig       `yaml:"clusters" toml:"clusters" json:"clusters"`
+	Features        FeaturesConfig        `yaml:"features" toml:"features" json:"features"`
+	Storage         StorageConfig         `yaml:"storage" toml:"storage" json:"storage"`
+	Jobs            JobsConfig            `yaml:"jobs" toml:"jobs" json:"jobs"`
+	LagHistory      LagHistoryConfig      `yaml:"lagHistory" toml:"lagHistory" json:"lagHistory"`
+	Alerting        AlertingConfig        `yaml:"alerting" toml:"alerting" json:"alerting"`
+	TopicPolicies   []TopicPolicyConfig   `yaml:"topicPolicies" toml:"topicPolicies" json:"topicPolicies"`
+	TopicTemplates  []TopicTemplateConfig `yaml:"topicTemplates" toml:"topicTemplates" json:"topicTemplates"`
+	ProtectedTopics ProtectedTopicsConfig `yaml:"protectedTopics" toml:"protectedTopics" json:"protectedTopics"`
+	Audit           AuditConfig           `yaml:"audit" toml:"audit" json:"audit"`
+	Secrets         SecretsConfig         `yaml:"secrets" toml:"secrets" json:"secrets"`
+	Tracing         TracingConfig         `yaml:"tracing" toml:"tracing" json:"tracing"`
+
+	// Path is the configuration file the configuration was read from, if any
+	Path string `yaml:"-" toml:"-" json:"path,omitempty"`
+}
+
+// ServerConfig configures the HTTP server
+type ServerConfig struct {
+	Port         string   `yaml:"port" toml:"port" json:"port"`
+	ReadTimeout  Duration `yaml:"readTimeout" toml:"readTimeout" json:"readTimeout"`
+	WriteTimeout Duration `yaml:"writeTimeout" toml:"writeTimeout" json:"writeTimeout"`
+	LogLevel     string   `yaml:"logLevel" toml:"logLevel" json:"logLevel"`
+	LogFormat    string   `yaml:"logFormat" toml:"logFormat" json:"logFormat"` // text or json
+	Environment  string   `yaml:"environment" toml:"environment" json:"environment"`
+	TLS          TLSFiles `yaml:"tls" toml:"tls" json:"tls"`
+
+	// CORSAllowedOrigins lists the origins allowed to call the API; "*" allows any origin
+	CORSAllowedOrigins []string `yaml:"corsAllowedOrigins" toml:"corsAllowedOrigins" json:"corsAllowedOrigins"`
+
+	// ConfigWatchInterval is how often the configuration file is checked for changes; zero disables the check
+	ConfigWatchInterval Duration `yaml:"configWatchInterval" toml:"configWatchInterval" json:"configWatchInterval"`
+}
+
+// TLSFiles enables TLS with a certificate and its key
+type TLSFiles struct {
+	Enabled  bool   `yaml:"enabled" toml:"enabled" json:"enabled"`
+	CertFile string `yaml:"certFile" toml:"certFile" json:"certFile"`
+	KeyFile  string `yaml:"keyFile" toml:"keyFile" json:"keyFile"`
+}
+
+// AuthConfig configures authentication and access control
+type AuthConfig struct {
+	Methods      []string   `yaml:"methods" toml:"methods" json:"methods"` // Enabled authentication methods (oidc, apikey, basic); empty disables authentication
+	OIDC         OIDCConfig `yaml:"oidc" toml:"oidc" json:"oidc"`
+	APIKeysFile  string     `yaml:"apiKeysFile" toml:"apiKeysFile" json:"apiKeysFile"`
+	HtpasswdFile string     `yaml:"htpasswdFile" toml:"htpasswdFile" json:"htpasswdFile"`
+
+	// RBACPolicyFile is the role-based access control policy; access control is disabled when empty
+	RBACPolicyFile string `yaml:"rbacPolicyFile" toml:"rbacPolicyFile" json:"rbacPolicyFile"`
+}
+
+// OIDCConfig configures the validation of OIDC bearer tokens
+type OIDCConfig struct {
+	Issuer        string `yaml:"issuer" toml:"issuer" json:"issuer"`
+	Audience      string `yaml:"audience" toml:"audience" json:"audience"`
+	JWKSURL       string `yaml:"jwksUrl" toml:"jwksUrl" json:"jwksUrl"`
+	PublicKeyFile string `yaml:"publicKeyFile" toml:"publicKeyFile" json:"publicKeyFile"`
+	UsernameClaim string `yaml:"usernameClaim" toml:"usernameClaim" json:"usernameClaim"`
+	GroupsClaim   string `yaml:"groupsClaim" toml:"groupsClaim" json:"groupsClaim"`
+}
+
+// ClusterConfig defines a Kafka cluster managed by Maestro
+type ClusterConfig struct {
+	Name    string   `yaml:"name" toml:"name" json:"name"`
+	Default bool     `yaml:"default" toml:"default" json:"default"` // Used when a request names no cluster; the first cluster when none is marked
+	Demo    bool     `yaml:"demo" toml:"demo" json:"demo"`          // Serve an in-memory cluster with sample data instead of connecting to Kafka
+	Brokers []string `yaml:"brokers" toml:"brokers" json:"brokers"`
+	Timeout Duration `yaml:"timeout" toml:"timeout" json:"timeout"` // Timeout of Kafka operations
+
+	Security       SecurityConfig       `yaml:"security" toml:"security" json:"security"`
+	SchemaRegistry SchemaRegistryConfig `yaml:"schemaRegistry" toml:"schemaRegistry" json:"schemaRegistry"`
+
+	// Properties are additional librdkafka client properties, e.g. "socket.timeout.ms".
+	// Their values may be secret references.
+	Properties Properties `yaml:"properties" toml:"properties" json:"properties"`
+}
+
+// SecurityConfig configures how Maestro connects and authenticates to the brokers
+type SecurityConfig struct {
+	Protocol           string `yaml:"protocol" toml:"protocol" json:"protocol"`                // PLAINTEXT (default), SSL, SASL_PLAINTEXT or SASL_SSL
+	SASLMechanism      string `yaml:"saslMechanism" toml:"saslMechanism" json:"saslMechanism"` // PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512
+	Username           Secret `yaml:"username" toml:"username" json:"username"`
+	Password           Secret `yaml:"password" toml:"password" json:"password"`
+	CAFile             string `yaml:"caFile" toml:"caFile" json:"caFile"`
+	CertFile           string `yaml:"certFile" toml:"certFile" json:"certFile"` // Client certificate for mutual TLS
+	KeyFile            string `yaml:"keyFile" toml:"keyFile" json:"keyFile"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify" toml:"insecureSkipVerify" json:"insecureSkipVerify"`
+}
+
+// SchemaRegistryConfig locates the schema registry of a cluster
+type SchemaRegistryConfig struct {
+	URL      string `yaml:"url" toml:"url" json:"url"`
+	Username Secret `yaml:"username" toml:"username" json:"username"`
+	Password Secret `yaml:"password" toml:"password" json:"password"`
+}
+
+// FeaturesConfig turns features of the API on or off
+type FeaturesConfig struct {
+	ReadOnly          bool `yaml:"readOnly" toml:"readOnly" json:"readOnly"`                            // Reject every change to the clusters
+	TopicDeletion     bool `yaml:"topicDeletion" toml:"topicDeletion" json:"topicDeletion"`             // Allow deleting topics
+	MessagePublishing bool `yaml:"messagePublishing" toml:"messagePublishing" json:"messagePublishing"` // Allow publishing and replaying messages
+	OffsetReset       bool `yaml:"offsetReset" toml:"offsetReset" json:"offsetReset"`                   // Allow resetting consumer group offsets
+	GroupDeletion     bool `yaml:"groupDeletion" toml:"groupDeletion" json:"groupDeletion"`             // Allow deleting consumer groups, their offsets and static members
+}
+
+// StorageConfig configures the storage of Maestro's own state
+type StorageConfig struct {
+	Driver string `yaml:"driver" toml:"driver" json:"driver"` // bolt or memory
+	Path   string `yaml:"path" toml:"path" json:"path"`       // Database file of the bolt driver
+}
+
+// JobsConfig configures background jobs
+type JobsConfig struct {
+	Dir           string   `yaml:"dir" toml:"dir" json:"dir"`                               // Directory holding job result files
+	MaxConcurrent int      `yaml:"maxConcurrent" toml:"maxConcurrent" json:"maxConcurrent"` // Maximum number of jobs running at the same time
+	Retention     Duration `yaml:"retention" toml:"retention" json:"retention"`             // How long finished jobs are kept
+}
+
+// LagHistoryConfig configures the periodic sampling of consumer group lag
+type LagHistoryConfig struct {
+	Enabled   bool     `yaml:"enabled" toml:"enabled" json:"enabled"`
+	Interval  Duration `yaml:"interval" toml:"interval" json:"interval"`    // Time between two samples
+	Retention Duration `yaml:"retention" toml:"retention" json:"retention"` // How long samples are kept
+}
+
+// AuditConfig configures the audit trail
+type AuditConfig struct {
+	Sinks      []string `yaml:"sinks" toml:"sinks" json:"sinks"`                // Sinks receiving audit records (store, file, stdout, kafka); "none" disables auditing
+	File       string   `yaml:"file" toml:"file" json:"file"`                   // JSON lines file of the file sink
+	KafkaTopic string   `yaml:"kafkaTopic" toml:"kafkaTopic" json:"kafkaTopic"` // Topic of the kafka sink
+}
+
+// TracingConfig configures the export of OpenTelemetry traces over OTLP/HTTP
+type TracingConfig struct {
+	Enabled     bool    `yaml:"enabled" toml:"enabled" json:"enabled"`
+	Endpoint    string  `yaml:"endpoint" toml:"endpoint" json:"endpoint"`          // OTLP/HTTP traces URL; the OTEL_EXPORTER_OTLP_* variables apply when empty
+	ServiceName string  `yaml:"serviceName" toml:"serviceName" json:"serviceName"` // Overridden by OTEL_SERVICE_NAME
+	SampleRatio float64 `yaml:"sampleRatio" toml:"sampleRatio" json:"sampleRatio"` // Fraction of new traces recorded, from 0 to 1
+}
+
+// Duration is a time.Duration written as a string such as "30s" in configuration files
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = duration
+	return nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, reporting the line of invalid durations
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	if err := d.UnmarshalText([]byte(node.Value)); err != nil {
+		return fmt.Errorf("line %d: invalid duration %q, expected e.g. 30s or 5m", node.Line, node.Value)
+	}
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// defaultClusterName names the cluster defined by KAFKA_BROKERS or DEMO_MODE without a configuration file
+const defaultClusterName = "default"
+
+// clusterNamePattern restricts cluster names to what can be passed in a header or a query parameter
+var clusterNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// defaults returns the configuration used for settings that are neither in the file nor in the environment
+func defaults() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:                "8080",
+			ReadTimeout:         Duration{120 * time.Second},
+			WriteTimeout:        Duration{120 * time.Second},
+			LogLevel:            "info",
+			LogFormat:           "text",
+			Environment:         "development",
+			CORSAllowedOrigins:  []string{"*"},
+			ConfigWatchInterval: Duration{10 * time.Second},
+		},
+		Auth: AuthConfig{
+			OIDC: OIDCConfig{
+				UsernameClaim: "preferred_username",
+				GroupsClaim:   "groups",
+			},
+		},
+		Features: FeaturesConfig{
+			TopicDeletion:     true,
+			MessagePublishing: true,
+			OffsetReset:       true,
+			GroupDeletion:     true,
+		},
+		Storage: StorageConfig{
+			Driver: "bolt",
+			Path:   "data/maestro.db",
+		},
+		Jobs: JobsConfig{
+			Dir:           "data/jobs",
+			MaxConcurrent: 2,
+			Retention:     Duration{7 * 24 * time.Hour},
+		},
+		LagHistory: LagHistoryConfig{
+			Enabled:   true,
+			Interval:  Duration{time.Minute},
+			Retention: Duration{7 * 24 * time.Hour},
+		},
+		Alerting: AlertingConfig{
+			Interval: Duration{time.Minute},
+		},
+		Audit: AuditConfig{
+			Sinks: []string{"store"},
+			File:  "data/audit/audit.log",
+		},
+		Secrets: SecretsConfig{
+			RefreshInterval: Duration{5 * time.Minute},
+		},
+		Tracing: TracingConfig{
+			ServiceName: "maestro",
+			SampleRatio: 1,
+		},
+	}
+}
+
+// defaultClusterTimeout is the timeout of Kafka operations of clusters that set none
+const defaultClusterTimeout = 60 * time.Second
+
+// secretsTimeout limits the time spent resolving the secret references of the configuration
+const secretsTimeout = 30 * time.Second
+
+// LoadConfig loads the configuration file named by CONFIG_FILE, if set, applies the
+// environment variables, validates the result and resolves its secret references
 func LoadConfig() (*Config, error) {
-	config := &Config{
-		ServerPort:      getEnvWithDefault("PORT", "8080"),
-		ReadTimeout:     getEnvDurationWithDefault("READ_TIMEOUT", 120*time.Second),  // Increase to 2 minutes
-		WriteTimeout:    getEnvDurationWithDefault("WRITE_TIMEOUT", 120*time.Second), // Increase to 2 minutes
-		KafkaTimeout:    getEnvDurationWithDefault("KAFKA_TIMEOUT", 60*time.Second),  // Increase to 1 minute
-		LogLevel:        getEnvWithDefault("LOG_LEVEL", "info"),
-		EnableTLS:       getEnvBoolWithDefault("ENABLE_TLS", false),
-		CertFile:        getEnvWithDefault("CERT_FILE", ""),
-		KeyFile:         getEnvWithDefault("KEY_FILE", ""),
-		EnvironmentName: getEnvWithDefault("ENVIRONMENT", "development"),
+	config := defaults()
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := config.readFile(path); err != nil {
+			return nil, err
+		}
+		config.Path = path
 	}
 
-	kafkaBrokersStr := os.Getenv("KAFKA_BROKERS")
-	if kafkaBrokersStr == "" {
-		return nil, fmt.Errorf("KAFKA_BROKERS environment variable is required")
+	if err := config.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	for i := range config.Clusters {
+		if config.Clusters[i].Timeout.Duration == 0 {
+			config.Clusters[i].Timeout.Duration = defaultClusterTimeout
+		}
 	}
-	config.KafkaBrokers = strings.Split(kafkaBrokersStr, ",")
 
 	if err := config.validate(); err != nil {
 		return nil, err
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), secretsTimeout)
+	defer cancel()
+	if err := config.resolveSecrets(ctx); err != nil {
+		return nil, err
+	}
+
 	return config, nil
 }
 
-// validate checks configuration for errors
+// DefaultCluster returns the cluster used when a request names none
+func (c *Config) DefaultCluster() *ClusterConfig {
+	for i := range c.Clusters {
+		if c.Clusters[i].Default {
+			return &c.Clusters[i]
+		}
+	}
+	if len(c.Clusters) == 0 {
+		return nil
+	}
+	return &c.Clusters[0]
+}
+
+// validate checks configuration for errors and reports all of them
 func (c *Config) validate() error {
-	if len(c.KafkaBrokers) == 0 {
-		return fmt.Errorf("at least one Kafka broker must be specified")
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
 	}
 
-	if c.EnableTLS {
-		if c.CertFile == "" {
-			return fmt.Errorf("CERT_FILE must be specified when TLS is enabled")
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port <= 0 || port > 65535 {
+		fail("server.port (PORT) must be a port number, got %q", c.Server.Port)
+	}
+	if c.Server.ReadTimeout.Duration <= 0 {
+		fail("server.readTimeout (READ_TIMEOUT) must be positive")
+	}
+	if c.Server.WriteTimeout.Duration <= 0 {
+		fail("server.writeTimeout (WRITE_TIMEOUT) must be positive")
+	}
+	if c.Server.ConfigWatchInterval.Duration < 0 {
+		fail("server.configWatchInterval (CONFIG_WATCH_INTERVAL) must not be negative")
+	}
+	if !slices.Contains([]string{"debug", "info", "warn", "error"}, c.Server.LogLevel) {
+		fail("unknown log level %q in server.logLevel (LOG_LEVEL) (supported: debug, info, warn, error)", c.Server.LogLevel)
+	}
+	if !slices.Contains([]string{"text", "json"}, c.Server.LogFormat) {
+		fail("unknown log format %q in server.logFormat (LOG_FORMAT) (supported: text, json)", c.Server.LogFormat)
+	}
+	if len(c.Server.CORSAllowedOrigins) == 0 {
+		fail("server.corsAllowedOrigins (CORS_ALLOWED_ORIGINS) must list at least one origin")
+	}
+	if c.Server.TLS.Enabled {
+		if c.Server.TLS.CertFile == "" {
+			fail("server.tls.certFile (CERT_FILE) must be specified when TLS is enabled")
 		}
-		if c.KeyFile == "" {
-			return fmt.Errorf("KEY_FILE must be specified when TLS is enabled")
+		if c.Server.TLS.KeyFile == "" {
+			fail("server.tls.keyFile (KEY_FILE) must be specified when TLS is enabled")
 		}
 	}
 
-	return nil
-}
+	errs = append(errs, c.validateClusters()...)
+	errs = append(errs, c.validateSecrets()...)
+	errs = append(errs, c.validateAlerting()...)
+	errs = append(errs, c.validatePolicies()...)
+	errs = append(errs, c.validateTemplates()...)
+	errs = append(errs, c.validateProtectedTopics()...)
 
-// Helper functions for environment variable parsing
-func getEnvWithDefault(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+	switch c.Storage.Driver {
+	case "bolt":
+		if c.Storage.Path == "" {
+			fail("storage.path (STORAGE_PATH) must be specified for the bolt storage driver")
+		}
+	case "memory":
+	default:
+		fail("unknown storage driver %q in storage.driver (STORAGE_DRIVER) (supported: bolt, memory)", c.Storage.Driver)
+	}
+
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		fail("tracing.sampleRatio (TRACING_SAMPLE_RATIO) must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+	}
+	if c.Tracing.ServiceName == "" {
+		fail("tracing.serviceName (TRACING_SERVICE_NAME) must not be empty")
 	}
-	return defaultValue
-}
 
-func getEnvDurationWithDefault(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
-		if duration, err := time.ParseDuration(value); err == nil {
-			return duration
+	if c.Jobs.MaxConcurrent <= 0 {
+		fail("jobs.maxConcurrent (JOBS_MAX_CONCURRENT) must be greater than 0")
+	}
+	if c.Jobs.Retention.Duration <= 0 {
+		fail("jobs.retention (JOBS_RETENTION) must be positive")
+	}
+	if c.LagHistory.Enabled {
+		if c.LagHistory.Interval.Duration <= 0 {
+			fail("lagHistory.interval (LAG_HISTORY_INTERVAL) must be positive")
+		}
+		if c.LagHistory.Retention.Duration <= 0 {
+			fail("lagHistory.retention (LAG_HISTORY_RETENTION) must be positive")
+		}
+	}
+
+	for _, method := range c.Auth.Methods {
+		switch method {
+		case "oidc":
+			if (c.Auth.OIDC.JWKSURL == "") == (c.Auth.OIDC.PublicKeyFile == "") {
+				fail("exactly one of auth.oidc.jwksUrl (OIDC_JWKS_URL) or auth.oidc.publicKeyFile (OIDC_PUBLIC_KEY_FILE) must be specified when oidc authentication is enabled")
+			}
+		case "apikey":
+			if c.Auth.APIKeysFile == "" {
+				fail("auth.apiKeysFile (API_KEYS_FILE) must be specified when apikey authentication is enabled")
+			}
+		case "basic":
+			if c.Auth.HtpasswdFile == "" {
+				fail("auth.htpasswdFile (HTPASSWD_FILE) must be specified when basic authentication is enabled")
+			}
+		default:
+			fail("unknown authentication method %q in auth.methods (AUTH_METHODS) (supported: oidc, apikey, basic)", method)
+		}
+	}
+
+	if c.Auth.RBACPolicyFile != "" && len(c.Auth.Methods) == 0 {
+		fail("auth.rbacPolicyFile (RBAC_POLICY_FILE) requires authentication to be enabled with auth.methods (AUTH_METHODS)")
+	}
+
+	for _, sink := range c.Audit.Sinks {
+		switch sink {
+		case "none", "stdout", "store":
+		case "file":
+			if c.Audit.File == "" {
+				fail("audit.file (AUDIT_FILE) must be specified when the file audit sink is enabled")
+			}
+		case "kafka":
+			if c.Audit.KafkaTopic == "" {
+				fail("audit.kafkaTopic (AUDIT_KAFKA_TOPIC) must be specified when the kafka audit sink is enabled")
+			}
+		default:
+			fail("unknown audit sink %q in audit.sinks (AUDIT_SINKS) (supported: store, file, stdout, kafka, none)", sink)
 		}
 	}
-	return defaultValue
+
+	return errors.Join(errs...)
 }
 
-func getEnvBoolWithDefault(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
-		if boolValue, err := strconv.ParseBool(value); err == nil {
-			return boolValue
+// validateClusters checks the cluster definitions
+func (c *Config) validateClusters() []error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if len(c.Clusters) == 0 {
+		fail("no Kafka cluster is configured: set KAFKA_BROKERS or DEMO_MODE, or define clusters in the configuration file")
+	}
+
+	names := make(map[string]bool, len(c.Clusters))
+	defaults := 0
+	for i, cluster := range c.Clusters {
+		field := fmt.Sprintf("clusters[%d]", i)
+		switch {
+		case cluster.Name == "":
+			fail("%s.name must be specified", field)
+		case !clusterNamePattern.MatchString(cluster.Name):
+			fail("%s.name %q may only contain letters, digits, '.', '_' and '-'", field, cluster.Name)
+		case names[cluster.Name]:
+			fail("cluster %q is defined more than once", cluster.Name)
+		}
+		names[cluster.Name] = true
+		if cluster.Name != "" {
+			field = fmt.Sprintf("cluster %q", cluster.Name)
+		}
+
+		if cluster.Default {
+			defaults++
+		}
+		if len(cluster.Brokers) == 0 && !cluster.Demo {
+			fail("%s must list at least one broker", field)
+		}
+		if cluster.Timeout.Duration <= 0 {
+			fail("%s: timeout must be positive", field)
 		}
+
+		security := cluster.Security
+		switch security.Protocol {
+		case "", "PLAINTEXT", "SSL", "SASL_PLAINTEXT", "SASL_SSL":
+		default:
+			fail("%s: unknown security protocol %q (supported: PLAINTEXT, SSL, SASL_PLAINTEXT, SASL_SSL)", field, security.Protocol)
+		}
+		if security.Protocol == "SASL_PLAINTEXT" || security.Protocol == "SASL_SSL" {
+			switch security.SASLMechanism {
+			case "PLAIN", "SCRAM-SHA-256", "SCRAM-SHA-512":
+			default:
+				fail("%s: unknown SASL mechanism %q (supported: PLAIN, SCRAM-SHA-256, SCRAM-SHA-512)", field, security.SASLMechanism)
+			}
+			if !security.Username.IsSet() || !security.Password.IsSet() {
+				fail("%s: SASL authentication requires a username and a password", field)
+			}
+		} else if security.SASLMechanism != "" {
+			fail("%s: saslMechanism requires the SASL_PLAINTEXT or SASL_SSL security protocol", field)
+		}
+		if (security.CertFile == "") != (security.KeyFile == "") {
+			fail("%s: certFile and keyFile must be specified together", field)
+		}
+	}
+	if defaults > 1 {
+		fail("only one cluster may be the default, got %d", defaults)
 	}
-	return defaultValue
+
+	return errs
 }
@@ -0,0 +1,261 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigFile(t *testing.T) {
+	files := map[string]string{
+		"maestro.yaml": `
+server:
+  port: "9090"
+  readTimeout: 30s
+  logLevel: warn
+auth:
+  methods: [apikey]
+  apiKeysFile: /etc/maestro/api-keys
+clusters:
+  - name: staging
+    demo: true
+  - name: production
+    default: true
+    brokers: [kafka-1:9092, kafka-2:9092]
+    timeout: 10s
+features:
+  readOnly: true
+storage:
+  driver: memory
+`,
+		"maestro.toml": `
+[server]
+port = "9090"
+readTimeout = "30s"
+logLevel = "warn"
+
+[auth]
+methods = ["apikey"]
+apiKeysFile = "/etc/maestro/api-keys"
+
+[[clusters]]
+name = "staging"
+demo = true
+
+[[clusters]]
+name = "production"
+default = true
+brokers = ["kafka-1:9092", "kafka-2:9092"]
+timeout = "10s"
+
+[features]
+readOnly = true
+
+[storage]
+driver = "memory"
+`,
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := loadFile(t, name, content)
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+
+			if cfg.Server.Port != "9090" || cfg.Server.ReadTimeout.Duration != 30*time.Second || cfg.Server.LogLevel != "warn" {
+				t.Errorf("server = %+v, want the file settings", cfg.Server)
+			}
+			if cfg.Server.WriteTimeout.Duration != 120*time.Second || cfg.Server.LogFormat != "text" {
+				t.Errorf("server = %+v, want the defaults of unset settings", cfg.Server)
+			}
+			if !slices.Equal(cfg.Auth.Methods, []string{"apikey"}) || cfg.Auth.APIKeysFile != "/etc/maestro/api-keys" {
+				t.Errorf("auth = %+v, want the file settings", cfg.Auth)
+			}
+			if !cfg.Features.ReadOnly || !cfg.Features.TopicDeletion || cfg.Storage.Driver != "memory" {
+				t.Errorf("features %+v and storage %+v, want the file settings over the defaults", cfg.Features, cfg.Storage)
+			}
+
+			if len(cfg.Clusters) != 2 {
+				t.Fatalf("clusters = %+v, want staging and production", cfg.Clusters)
+			}
+			if staging := cfg.Clusters[0]; staging.Name != "staging" || !staging.Demo || staging.Timeout.Duration != defaultClusterTimeout {
+				t.Errorf("staging = %+v, want a demo cluster with the default timeout", staging)
+			}
+			production := cfg.DefaultCluster()
+			if production.Name != "production" || !slices.Equal(production.Brokers, []string{"kafka-1:9092", "kafka-2:9092"}) || production.Timeout.Duration != 10*time.Second {
+				t.Errorf("default cluster = %+v, want production", production)
+			}
+			if cfg.Path == "" {
+				t.Error("the configuration does not record its file")
+			}
+		})
+	}
+}
+
+func TestEnvOverridesFile(t *testing.T) {
+	t.Setenv("PORT", "9191")
+	t.Setenv("READ_TIMEOUT", "45s")
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("AUTH_METHODS", "apikey, basic")
+	t.Setenv("HTPASSWD_FILE", "/etc/maestro/htpasswd")
+	t.Setenv("ENABLE_TOPIC_DELETION", "false")
+	t.Setenv("STORAGE_DRIVER", "memory")
+	t.Setenv("KAFKA_BROKERS", "kafka-3:9092")
+
+	cfg, err := loadFile(t, "maestro.yaml", `
+server:
+  port: "9090"
+  readTimeout: 30s
+  writeTimeout: 60s
+  logLevel: warn
+auth:
+  methods: [apikey]
+  apiKeysFile: /etc/maestro/api-keys
+clusters:
+  - name: staging
+    demo: true
+  - name: production
+    default: true
+    brokers: [kafka-1:9092]
+storage:
+  driver: bolt
+`)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	if cfg.Server.Port != "9191" || cfg.Server.ReadTimeout.Duration != 45*time.Second || cfg.Server.LogLevel != "debug" {
+		t.Errorf("server = %+v, want the environment over the file", cfg.Server)
+	}
+	if cfg.Server.WriteTimeout.Duration != 60*time.Second {
+		t.Errorf("writeTimeout = %s, want the file setting", cfg.Server.WriteTimeout)
+	}
+	if !slices.Equal(cfg.Auth.Methods, []string{"apikey", "basic"}) || cfg.Auth.APIKeysFile != "/etc/maestro/api-keys" || cfg.Auth.HtpasswdFile != "/etc/maestro/htpasswd" {
+		t.Errorf("auth = %+v, want the environment methods and the files of both", cfg.Auth)
+	}
+	if cfg.Features.TopicDeletion || cfg.Storage.Driver != "memory" {
+		t.Errorf("features %+v and storage %+v, want the environment settings", cfg.Features, cfg.Storage)
+	}
+	// KAFKA_BROKERS applies to the default cluster only
+	if brokers := cfg.Clusters[1].Brokers; !slices.Equal(brokers, []string{"kafka-3:9092"}) {
+		t.Errorf("production brokers = %v, want the environment brokers", brokers)
+	}
+	if staging := cfg.Clusters[0]; !staging.Demo || len(staging.Brokers) > 0 {
+		t.Errorf("staging = %+v, want it unchanged", staging)
+	}
+}
+
+func TestEnvWithoutFile(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("KAFKA_BROKERS", "kafka-1:9092,kafka-2:9092")
+	t.Setenv("KAFKA_TIMEOUT", "15s")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if len(cfg.Clusters) != 1 {
+		t.Fatalf("clusters = %+v, want the cluster of the environment", cfg.Clusters)
+	}
+	cluster := cfg.Clusters[0]
+	if cluster.Name != defaultClusterName || cluster.Demo || !slices.Equal(cluster.Brokers, []string{"kafka-1:9092", "kafka-2:9092"}) || cluster.Timeout.Duration != 15*time.Second {
+		t.Errorf("cluster = %+v, want the brokers of the environment", cluster)
+	}
+	if cfg.Path != "" {
+		t.Errorf("path = %q, want none", cfg.Path)
+	}
+}
+
+func TestValidationErrors(t *testing.T) {
+	const cluster = "clusters:\n  - name: primary\n    demo: true\n"
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		env     map[string]string
+		want    []string
+	}{
+		{
+			name: "yaml duration", file: "maestro.yaml",
+			content: cluster + "server:\n  readTimeout: soon\n",
+			want:    []string{`line 5: invalid duration "soon", expected e.g. 30s or 5m`},
+		},
+		{
+			name: "toml duration", file: "maestro.toml",
+			content: "[server]\nwriteTimeout = \"soon\"\n",
+			want:    []string{"line 2, column", `"soon"`},
+		},
+		{
+			name: "environment duration", file: "maestro.yaml",
+			content: cluster,
+			env:     map[string]string{"READ_TIMEOUT": "soon"},
+			want:    []string{`invalid value "soon" for READ_TIMEOUT`},
+		},
+		{
+			name: "negative duration", file: "maestro.yaml",
+			content: cluster + "jobs:\n  retention: -1h\n",
+			want:    []string{"jobs.retention (JOBS_RETENTION) must be positive"},
+		},
+		{
+			name: "unknown auth method", file: "maestro.yaml",
+			content: cluster + "auth:\n  methods: [kerberos]\n",
+			want:    []string{`unknown authentication method "kerberos" in auth.methods (AUTH_METHODS) (supported: oidc, apikey, basic)`},
+		},
+		{
+			name: "environment auth method", file: "maestro.yaml",
+			content: cluster,
+			env:     map[string]string{"AUTH_METHODS": "basic,ldap"},
+			want: []string{
+				"auth.htpasswdFile (HTPASSWD_FILE) must be specified when basic authentication is enabled",
+				`unknown authentication method "ldap"`,
+			},
+		},
+		{
+			name: "duplicate cluster names", file: "maestro.toml",
+			content: "[[clusters]]\nname = \"primary\"\ndemo = true\n\n[[clusters]]\nname = \"primary\"\nbrokers = [\"kafka:9092\"]\n",
+			want:    []string{`cluster "primary" is defined more than once`},
+		},
+		{
+			name: "unknown yaml setting", file: "maestro.yaml",
+			content: cluster + "server:\n  prot: 9090\n",
+			want:    []string{"field prot not found"},
+		},
+		{
+			name: "unknown toml setting", file: "maestro.toml",
+			content: "[server]\nprot = 9090\n",
+			want:    []string{"unknown settings", "2| prot = 9090"},
+		},
+		{
+			name: "unsupported extension", file: "maestro.json",
+			content: "{}",
+			want:    []string{`unsupported configuration file extension ".json"`},
+		},
+		{
+			name: "every error", file: "maestro.yaml",
+			content: cluster + "  - name: primary\n    demo: true\nauth:\n  methods: [kerberos]\nstorage:\n  driver: sqlite\n",
+			want: []string{
+				`cluster "primary" is defined more than once`,
+				`unknown authentication method "kerberos"`,
+				`unknown storage driver "sqlite"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			_, err := loadFile(t, tt.file, tt.content)
+			if err == nil {
+				t.Fatal("LoadConfig accepted the configuration")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q lacks %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// applyEnv overrides the configuration with the environment variables that are set.
+// The KAFKA_* variables and DEMO_MODE apply to the default cluster, which they define
+// when the configuration file has no clusters.
+func (c *Config) applyEnv() error {
+	env := &envReader{}
+
+	env.string("PORT", &c.Server.Port)
+	env.duration("READ_TIMEOUT", &c.Server.ReadTimeout)
+	env.duration("WRITE_TIMEOUT", &c.Server.WriteTimeout)
+	env.string("LOG_LEVEL", &c.Server.LogLevel)
+	env.string("LOG_FORMAT", &c.Server.LogFormat)
+	env.string("ENVIRONMENT", &c.Server.Environment)
+	env.bool("ENABLE_TLS", &c.Server.TLS.Enabled)
+	env.string("CERT_FILE", &c.Server.TLS.CertFile)
+	env.string("KEY_FILE", &c.Server.TLS.KeyFile)
+	env.list("CORS_ALLOWED_ORIGINS", &c.Server.CORSAllowedOrigins)
+	env.duration("CONFIG_WATCH_INTERVAL", &c.Server.ConfigWatchInterval)
+
+	env.list("AUTH_METHODS", &c.Auth.Methods)
+	env.string("OIDC_ISSUER", &c.Auth.OIDC.Issuer)
+	env.string("OIDC_AUDIENCE", &c.Auth.OIDC.Audience)
+	env.string("OIDC_JWKS_URL", &c.Auth.OIDC.JWKSURL)
+	env.string("OIDC_PUBLIC_KEY_FILE", &c.Auth.OIDC.PublicKeyFile)
+	env.string("OIDC_USERNAME_CLAIM", &c.Auth.OIDC.UsernameClaim)
+	env.string("OIDC_GROUPS_CLAIM", &c.Auth.OIDC.GroupsClaim)
+	env.string("API_KEYS_FILE", &c.Auth.APIKeysFile)
+	env.string("HTPASSWD_FILE", &c.Auth.HtpasswdFile)
+	env.string("RBAC_POLICY_FILE", &c.Auth.RBACPolicyFile)
+
+	env.bool("READ_ONLY", &c.Features.ReadOnly)
+	env.bool("ENABLE_TOPIC_DELETION", &c.Features.TopicDeletion)
+	env.bool("ENABLE_MESSAGE_PUBLISHING", &c.Features.MessagePublishing)
+	env.bool("ENABLE_OFFSET_RESET", &c.Features.OffsetReset)
+	env.bool("ENABLE_GROUP_DELETION", &c.Features.GroupDeletion)
+
+	env.string("STORAGE_DRIVER", &c.Storage.Driver)
+	env.string("STORAGE_PATH", &c.Storage.Path)
+
+	env.string("JOBS_DIR", &c.Jobs.Dir)
+	env.int("JOBS_MAX_CONCURRENT", &c.Jobs.MaxConcurrent)
+	env.duration("JOBS_RETENTION", &c.Jobs.Retention)
+
+	env.bool("LAG_HISTORY_ENABLED", &c.LagHistory.Enabled)
+	env.duration("LAG_HISTORY_INTERVAL", &c.LagHistory.Interval)
+	env.duration("LAG_HISTORY_RETENTION", &c.LagHistory.Retention)
+	env.duration("ALERTING_INTERVAL", &c.Alerting.Interval)
+
+	env.list("AUDIT_SINKS", &c.Audit.Sinks)
+	env.string("AUDIT_FILE", &c.Audit.File)
+	env.string("AUDIT_KAFKA_TOPIC", &c.Audit.KafkaTopic)
+
+	env.duration("SECRETS_REFRESH_INTERVAL", &c.Secrets.RefreshInterval)
+	env.string("VAULT_ADDR", &c.Secrets.Vault.Address)
+	env.secret("VAULT_TOKEN", &c.Secrets.Vault.Token)
+	env.string("VAULT_NAMESPACE", &c.Secrets.Vault.Namespace)
+
+	env.bool("TRACING_ENABLED", &c.Tracing.Enabled)
+	env.string("TRACING_ENDPOINT", &c.Tracing.Endpoint)
+	env.string("TRACING_SERVICE_NAME", &c.Tracing.ServiceName)
+	env.float("TRACING_SAMPLE_RATIO", &c.Tracing.SampleRatio)
+
+	if env.isSet("KAFKA_BROKERS", "KAFKA_TIMEOUT", "DEMO_MODE") {
+		if len(c.Clusters) == 0 {
+			c.Clusters = []ClusterConfig{{Name: defaultClusterName}}
+		}
+		cluster := c.DefaultCluster()
+		if env.isSet("KAFKA_BROKERS") {
+			// Brokers replace the in-memory cluster unless DEMO_MODE is set as well
+			cluster.Demo = false
+		}
+		env.list("KAFKA_BROKERS", &cluster.Brokers)
+		env.duration("KAFKA_TIMEOUT", &cluster.Timeout)
+		env.bool("DEMO_MODE", &cluster.Demo)
+	}
+
+	return errors.Join(env.errs...)
+}
+
+// envReader reads typed environment variables, collecting the values that cannot be parsed
+type envReader struct {
+	errs []error
+}
+
+func (r *envReader) isSet(keys ...string) bool {
+	for _, key := range keys {
+		if os.Getenv(key) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup returns the value of a variable that is set to a non-empty value
+func (r *envReader) lookup(key string) (string, bool) {
+	value := os.Getenv(key)
+	return value, value != ""
+}
+
+func (r *envReader) invalid(key, value string, err error) {
+	r.errs = append(r.errs, fmt.Errorf("invalid value %q for %s: %w", value, key, err))
+}
+
+func (r *envReader) string(key string, dst *string) {
+	if value, ok := r.lookup(key); ok {
+		*dst = value
+	}
+}
+
+func (r *envReader) secret(key string, dst *Secret) {
+	if value, ok := r.lookup(key); ok {
+		_ = dst.UnmarshalText([]byte(value))
+	}
+}
+
+func (r *envReader) duration(key string, dst *Duration) {
+	value, ok := r.lookup(key)
+	if !ok {
+		return
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		r.invalid(key, value, err)
+		return
+	}
+	dst.Duration = duration
+}
+
+func (r *envReader) bool(key string, dst *bool) {
+	value, ok := r.lookup(key)
+	if !ok {
+		return
+	}
+	boolValue, err := strconv.ParseBool(value)
+	if err != nil {
+		r.invalid(key, value, errors.New("expected true or false"))
+		return
+	}
+	*dst = boolValue
+}
+
+func (r *envReader) int(key string, dst *int) {
+	value, ok := r.lookup(key)
+	if !ok {
+		return
+	}
+	intValue, err := strconv.Atoi(value)
+	if err != nil {
+		r.invalid(key, value, errors.New("expected an integer"))
+		return
+	}
+	*dst = intValue
+}
+
+func (r *envReader) float(key string, dst *float64) {
+	value, ok := r.lookup(key)
+	if !ok {
+		return
+	}
+	floatValue, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		r.invalid(key, value, errors.New("expected a number"))
+		return
+	}
+	*dst = floatValue
+}
+
+// list parses a comma-separated list, ignoring empty items
+func (r *envReader) list(key string, dst *[]string) {
+	value, ok := r.lookup(key)
+	if !ok {
+		return
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	*dst = list
+}
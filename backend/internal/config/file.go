@@ -0,0 +1,50 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// readFile decodes a YAML or TOML configuration file, chosen by its extension, over
+// the current configuration. Unknown settings are errors rather than being ignored.
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read configuration file: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("invalid configuration file %s: %w", path, err)
+		}
+	case ".toml":
+		dec := toml.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(c); err != nil {
+			var details *toml.DecodeError
+			var strict *toml.StrictMissingError
+			switch {
+			case errors.As(err, &details):
+				row, column := details.Position()
+				return fmt.Errorf("invalid configuration file %s: line %d, column %d: %w", path, row, column, err)
+			case errors.As(err, &strict):
+				return fmt.Errorf("invalid configuration file %s: unknown settings:\n%s", path, strict.String())
+			}
+			return fmt.Errorf("invalid configuration file %s: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("unsupported configuration file extension %q of %s (supported: .yaml, .yml, .toml)", ext, path)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// TopicPolicyConfig restricts the topics that may be created and their configuration.
+// Unset limits do not restrict.
+type TopicPolicyConfig struct {
+	Name     string   `yaml:"name" toml:"name" json:"name"`
+	Clusters []string `yaml:"clusters" toml:"clusters" json:"clusters"` // Clusters the policy applies to; all when empty
+	Groups   []string `yaml:"groups" toml:"groups" json:"groups"`       // Groups of the users, e.g. teams, the policy applies to; everyone when empty
+
+	NamePattern          string   `yaml:"namePattern" toml:"namePattern" json:"namePattern"` // Regular expression topic names must match
+	Prefixes             []string `yaml:"prefixes" toml:"prefixes" json:"prefixes"`          // Topic names must start with one of the prefixes of the policies that apply
+	MinPartitions        int32    `yaml:"minPartitions" toml:"minPartitions" json:"minPartitions"`
+	MaxPartitions        int32    `yaml:"maxPartitions" toml:"maxPartitions" json:"maxPartitions"`
+	MinReplicationFactor int      `yaml:"minReplicationFactor" toml:"minReplicationFactor" json:"minReplicationFactor"`
+
+	// RequiredConfigs are configuration entries every topic must set
+	RequiredConfigs []string `yaml:"requiredConfigs" toml:"requiredConfigs" json:"requiredConfigs"`
+	// BannedConfigs are values configuration entries must not have; "*" bans setting the entry
+	BannedConfigs map[string][]string `yaml:"bannedConfigs" toml:"bannedConfigs" json:"bannedConfigs"`
+}
+
+// validatePolicies checks the topic policies
+func (c *Config) validatePolicies() []error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	clusters := make(map[string]bool, len(c.Clusters))
+	for _, cluster := range c.Clusters {
+		clusters[cluster.Name] = true
+	}
+	names := make(map[string]bool, len(c.TopicPolicies))
+	for i, policy := range c.TopicPolicies {
+		field := fmt.Sprintf("topicPolicies[%d]", i)
+		switch {
+		case policy.Name == "":
+			fail("%s.name must be specified", field)
+		case names[policy.Name]:
+			fail("topic policy %q is defined more than once", policy.Name)
+		}
+		names[policy.Name] = true
+		if policy.Name != "" {
+			field = fmt.Sprintf("topic policy %q", policy.Name)
+		}
+
+		for _, cluster := range policy.Clusters {
+			if !clusters[cluster] {
+				fail("%s: unknown cluster %q", field, cluster)
+			}
+		}
+		if _, err := regexp.Compile(policy.NamePattern); err != nil {
+			fail("%s: invalid namePattern: %v", field, err)
+		}
+		if policy.MinPartitions < 0 || policy.MaxPartitions < 0 || policy.MinReplicationFactor < 0 {
+			fail("%s: minPartitions, maxPartitions and minReplicationFactor must not be negative", field)
+		}
+		if policy.MaxPartitions > 0 && policy.MinPartitions > policy.MaxPartitions {
+			fail("%s: minPartitions must not be greater than maxPartitions", field)
+		}
+	}
+	return errs
+}
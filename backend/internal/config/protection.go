@@ -0,0 +1,27 @@
+package config
+
+import (
+	"fmt"
+	"path"
+)
+
+// ProtectedTopicsConfig protects topics from deletion and risky configuration changes.
+// Topics internal to Kafka, such as __consumer_offsets, are always protected.
+type ProtectedTopicsConfig struct {
+	// Patterns are glob patterns of the names of protected topics, e.g. "_schemas" or "_confluent-*"
+	Patterns []string `yaml:"patterns" toml:"patterns" json:"patterns"`
+	// RiskyConfigs are the configuration entries whose change needs confirmation; "*" covers every entry.
+	// Defaults to cleanup.policy, retention and replication related entries.
+	RiskyConfigs []string `yaml:"riskyConfigs" toml:"riskyConfigs" json:"riskyConfigs"`
+}
+
+// validateProtectedTopics checks the patterns of protected topics
+func (c *Config) validateProtectedTopics() []error {
+	var errs []error
+	for i, pattern := range c.ProtectedTopics.Patterns {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			errs = append(errs, fmt.Errorf("protectedTopics.patterns[%d]: invalid pattern %q", i, pattern))
+		}
+	}
+	return errs
+}
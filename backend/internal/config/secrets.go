@@ -0,0 +1,242 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/valeriouberti/maestro/internal/secrets"
+)
+
+// redacted replaces secrets written in the configuration when it is encoded or printed
+const redacted = "[REDACTED]"
+
+// Secret is a sensitive setting such as a password. It is either the secret itself or
+// a reference to it, "file:/path", "env:NAME" or "vault:path#key", which is resolved
+// when the configuration is loaded. Encoding or printing a Secret shows the reference,
+// never the secret.
+type Secret struct {
+	ref   string // The setting as written
+	value string // The resolved secret
+}
+
+// Value returns the secret
+func (s Secret) Value() string {
+	return s.value
+}
+
+// IsSet reports whether the setting is specified
+func (s Secret) IsSet() bool {
+	return s.ref != ""
+}
+
+// IsReference reports whether the setting refers to a secret kept elsewhere
+func (s Secret) IsReference() bool {
+	return secrets.IsReference(s.ref)
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (s *Secret) UnmarshalText(text []byte) error {
+	s.ref = string(text)
+	s.value = ""
+	if !secrets.IsReference(s.ref) {
+		s.value = s.ref
+	}
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler, keeping references and redacting secrets
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// String returns the reference of the secret, or a placeholder when the secret is written in the configuration
+func (s Secret) String() string {
+	if s.ref == "" || s.IsReference() {
+		return s.ref
+	}
+	return redacted
+}
+
+// GoString implements fmt.GoStringer so that %#v does not print the secret either
+func (s Secret) GoString() string {
+	return fmt.Sprintf("config.Secret(%q)", s.String())
+}
+
+// Properties are librdkafka client properties. Every value may be a secret reference;
+// the values of sensitive properties, such as sasl.password, are redacted when encoded.
+type Properties map[string]*Secret
+
+// Values returns the properties with their secret references resolved
+func (p Properties) Values() map[string]string {
+	values := make(map[string]string, len(p))
+	for key, value := range p {
+		if value != nil {
+			values[key] = value.Value()
+		}
+	}
+	return values
+}
+
+// MarshalJSON implements json.Marshaler, redacting the values of sensitive properties
+// written in the configuration
+func (p Properties) MarshalJSON() ([]byte, error) {
+	encoded := make(map[string]string, len(p))
+	for key, value := range p {
+		switch {
+		case value == nil:
+			encoded[key] = ""
+		case sensitiveProperty(key):
+			encoded[key] = value.String()
+		default:
+			encoded[key] = value.ref
+		}
+	}
+	return json.Marshal(encoded)
+}
+
+// sensitivePropertyParts are the parts of the names of librdkafka properties holding
+// credentials or private key material
+var sensitivePropertyParts = []string{"password", "secret", "credential", "jaas", "oauthbearer.config", "ssl.key.", "keystore", "private"}
+
+// sensitiveProperty reports whether a librdkafka property holds credentials or key
+// material. Properties locating files, such as ssl.key.location, do not.
+func sensitiveProperty(key string) bool {
+	key = strings.ToLower(key)
+	if strings.HasSuffix(key, ".location") {
+		return false
+	}
+	for _, part := range sensitivePropertyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// SecretsConfig configures the resolution of secret references
+type SecretsConfig struct {
+	// RefreshInterval is how often secret references are resolved again, so that
+	// rotated credentials are picked up; zero disables the refresh
+	RefreshInterval Duration    `yaml:"refreshInterval" toml:"refreshInterval" json:"refreshInterval"`
+	Vault           VaultConfig `yaml:"vault" toml:"vault" json:"vault"`
+}
+
+// VaultConfig locates the Vault-compatible server resolving vault: references
+type VaultConfig struct {
+	Address   string `yaml:"address" toml:"address" json:"address"`
+	Token     Secret `yaml:"token" toml:"token" json:"token"` // May refer to a file or an environment variable
+	Namespace string `yaml:"namespace" toml:"namespace" json:"namespace"`
+}
+
+// secretSetting is a secret of the configuration and the setting holding it
+type secretSetting struct {
+	name   string
+	secret *Secret
+}
+
+// secretSettings returns the secrets of the configuration, except the Vault token
+func (c *Config) secretSettings() []secretSetting {
+	var settings []secretSetting
+	for i := range c.Clusters {
+		cluster := &c.Clusters[i]
+		field := fmt.Sprintf("cluster %q", cluster.Name)
+		settings = append(settings,
+			secretSetting{field + ": security.username", &cluster.Security.Username},
+			secretSetting{field + ": security.password", &cluster.Security.Password},
+			secretSetting{field + ": schemaRegistry.username", &cluster.SchemaRegistry.Username},
+			secretSetting{field + ": schemaRegistry.password", &cluster.SchemaRegistry.Password},
+		)
+		for _, key := range slices.Sorted(maps.Keys(cluster.Properties)) {
+			if property := cluster.Properties[key]; property != nil {
+				settings = append(settings, secretSetting{fmt.Sprintf("%s: properties.%s", field, key), property})
+			}
+		}
+	}
+	for i := range c.Alerting.Notifiers {
+		notifier := &c.Alerting.Notifiers[i]
+		field := fmt.Sprintf("notifier %q", notifier.Name)
+		settings = append(settings,
+			secretSetting{field + ": url", &notifier.URL},
+			secretSetting{field + ": smtp.username", &notifier.SMTP.Username},
+			secretSetting{field + ": smtp.password", &notifier.SMTP.Password},
+		)
+		for _, name := range slices.Sorted(maps.Keys(notifier.Headers)) {
+			if header := notifier.Headers[name]; header != nil {
+				settings = append(settings, secretSetting{fmt.Sprintf("%s: headers.%s", field, name), header})
+			}
+		}
+	}
+	return settings
+}
+
+// HasSecretReferences reports whether any secret of the configuration is a reference
+func (c *Config) HasSecretReferences() bool {
+	if c.Secrets.Vault.Token.IsReference() {
+		return true
+	}
+	for _, setting := range c.secretSettings() {
+		if setting.secret.IsReference() {
+			return true
+		}
+	}
+	return false
+}
+
+// validateSecrets checks that the references can be resolved with the configured resolvers
+func (c *Config) validateSecrets() []error {
+	var errs []error
+	if c.Secrets.RefreshInterval.Duration < 0 {
+		errs = append(errs, fmt.Errorf("secrets.refreshInterval (SECRETS_REFRESH_INTERVAL) must not be negative"))
+	}
+	if secrets.IsVaultReference(c.Secrets.Vault.Token.ref) {
+		errs = append(errs, fmt.Errorf("secrets.vault.token (VAULT_TOKEN) cannot be a vault: reference"))
+	}
+	if c.Secrets.Vault.Address == "" {
+		for _, setting := range c.secretSettings() {
+			if secrets.IsVaultReference(setting.secret.ref) {
+				errs = append(errs, fmt.Errorf("%s refers to Vault, but secrets.vault.address (VAULT_ADDR) is not specified", setting.name))
+			}
+		}
+	}
+	return errs
+}
+
+// resolveSecrets resolves the secret references of the configuration, the Vault token
+// first, and reports every reference that cannot be resolved
+func (c *Config) resolveSecrets(ctx context.Context) error {
+	token := &c.Secrets.Vault.Token
+	if err := token.resolve(ctx, secrets.NewResolver(secrets.VaultOptions{})); err != nil {
+		return fmt.Errorf("failed to resolve secrets.vault.token (VAULT_TOKEN): %w", err)
+	}
+
+	resolver := secrets.NewResolver(secrets.VaultOptions{
+		Address:   c.Secrets.Vault.Address,
+		Token:     token.Value(),
+		Namespace: c.Secrets.Vault.Namespace,
+	})
+	var errs []error
+	for _, setting := range c.secretSettings() {
+		if err := setting.secret.resolve(ctx, resolver); err != nil {
+			errs = append(errs, fmt.Errorf("failed to resolve %s: %w", setting.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// resolve sets the value of a secret that is a reference
+func (s *Secret) resolve(ctx context.Context, resolver *secrets.Resolver) error {
+	if !s.IsReference() {
+		return nil
+	}
+	value, err := resolver.Resolve(ctx, s.ref)
+	if err != nil {
+		return err
+	}
+	s.value = value
+	return nil
+}
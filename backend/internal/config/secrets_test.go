@@ -0,0 +1,175 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// loadFile loads the configuration from a file with the given name and content
+func loadFile(t *testing.T, name, content string) (*Config, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	return LoadConfig()
+}
+
+// encode returns the configuration as served by the API
+func encode(t *testing.T, cfg *Config) string {
+	t.Helper()
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	return string(data)
+}
+
+func TestNotifierHeadersAreSecrets(t *testing.T) {
+	t.Setenv("ONCALL_AUTHORIZATION", "Bearer env-token")
+
+	files := map[string]string{
+		"maestro.yaml": `
+clusters:
+  - name: primary
+    demo: true
+alerting:
+  notifiers:
+    - name: oncall
+      type: webhook
+      url: https://events.example.com/maestro
+      headers:
+        Authorization: env:ONCALL_AUTHORIZATION
+        X-Api-Key: written-token
+`,
+		"maestro.toml": `
+[[clusters]]
+name = "primary"
+demo = true
+
+[[alerting.notifiers]]
+name = "oncall"
+type = "webhook"
+url = "https://events.example.com/maestro"
+headers = { Authorization = "env:ONCALL_AUTHORIZATION", X-Api-Key = "written-token" }
+`,
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := loadFile(t, name, content)
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+
+			headers := cfg.Alerting.Notifiers[0].Headers
+			if got := headers["Authorization"].Value(); got != "Bearer env-token" {
+				t.Errorf("Authorization = %q, want the resolved reference", got)
+			}
+			if got := headers["X-Api-Key"].Value(); got != "written-token" {
+				t.Errorf("X-Api-Key = %q, want the written value", got)
+			}
+
+			encoded := encode(t, cfg)
+			if strings.Contains(encoded, "env-token") || strings.Contains(encoded, "written-token") {
+				t.Errorf("the encoded configuration reveals a header: %s", encoded)
+			}
+			if !strings.Contains(encoded, `"Authorization":"env:ONCALL_AUTHORIZATION"`) {
+				t.Errorf("the encoded configuration lacks the header reference: %s", encoded)
+			}
+		})
+	}
+}
+
+func TestUnresolvableNotifierHeader(t *testing.T) {
+	_, err := loadFile(t, "maestro.yaml", `
+clusters:
+  - name: primary
+    demo: true
+alerting:
+  notifiers:
+    - name: oncall
+      type: webhook
+      url: https://events.example.com/maestro
+      headers: {Authorization: "env:MAESTRO_TEST_UNSET"}
+`)
+	if err == nil || !strings.Contains(err.Error(), `notifier "oncall": headers.Authorization`) {
+		t.Fatalf("LoadConfig error = %v, want the unresolvable header", err)
+	}
+}
+
+func TestClusterPropertySecrets(t *testing.T) {
+	t.Setenv("KAFKA_KEY_PASSWORD", "env-key-password")
+
+	cfg, err := loadFile(t, "maestro.yaml", `
+clusters:
+  - name: primary
+    brokers: [localhost:9092]
+    properties:
+      socket.keepalive.enable: "true"
+      ssl.key.location: /etc/maestro/client.key
+      ssl.key.password: env:KAFKA_KEY_PASSWORD
+      sasl.password: written-password
+      sasl.oauthbearer.client.secret: written-client-secret
+      ssl.key.pem: written-private-key
+`)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	values := cfg.Clusters[0].Properties.Values()
+	want := map[string]string{
+		"socket.keepalive.enable":        "true",
+		"ssl.key.location":               "/etc/maestro/client.key",
+		"ssl.key.password":               "env-key-password",
+		"sasl.password":                  "written-password",
+		"sasl.oauthbearer.client.secret": "written-client-secret",
+		"ssl.key.pem":                    "written-private-key",
+	}
+	for key, value := range want {
+		if values[key] != value {
+			t.Errorf("property %s = %q, want %q", key, values[key], value)
+		}
+	}
+
+	encoded := encode(t, cfg)
+	for _, secret := range []string{"env-key-password", "written-password", "written-client-secret", "written-private-key"} {
+		if strings.Contains(encoded, secret) {
+			t.Errorf("the encoded configuration reveals %q: %s", secret, encoded)
+		}
+	}
+	for _, shown := range []string{
+		`"socket.keepalive.enable":"true"`,
+		`"ssl.key.location":"/etc/maestro/client.key"`,
+		`"ssl.key.password":"env:KAFKA_KEY_PASSWORD"`,
+		`"sasl.password":"[REDACTED]"`,
+	} {
+		if !strings.Contains(encoded, shown) {
+			t.Errorf("the encoded configuration lacks %s: %s", shown, encoded)
+		}
+	}
+}
+
+func TestSensitiveProperty(t *testing.T) {
+	tests := map[string]bool{
+		"sasl.password":                  true,
+		"ssl.key.password":               true,
+		"ssl.keystore.password":          true,
+		"ssl.key.pem":                    true,
+		"sasl.oauthbearer.client.secret": true,
+		"sasl.oauthbearer.config":        true,
+		"SASL.PASSWORD":                  true,
+		"ssl.key.location":               false,
+		"ssl.keystore.location":          false,
+		"sasl.username":                  false,
+		"socket.timeout.ms":              false,
+	}
+	for key, want := range tests {
+		if got := sensitiveProperty(key); got != want {
+			t.Errorf("sensitiveProperty(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package config
+
+import "fmt"
+
+// TopicTemplateConfig defines a read-only topic template: the defaults of the topics
+// created from it
+type TopicTemplateConfig struct {
+	Name        string `yaml:"name" toml:"name" json:"name"`
+	Description string `yaml:"description" toml:"description" json:"description"`
+	// TopicName names the topics created from the template, with {placeholders} replaced
+	// by the parameters of the request, e.g. "{team}.{entity}.changelog"
+	TopicName         string            `yaml:"topicName" toml:"topicName" json:"topicName"`
+	NumPartitions     int32             `yaml:"numPartitions" toml:"numPartitions" json:"numPartitions"`
+	ReplicationFactor int16             `yaml:"replicationFactor" toml:"replicationFactor" json:"replicationFactor"`
+	Config            map[string]string `yaml:"config" toml:"config" json:"config"`
+}
+
+// validateTemplates checks the topic templates. Their names and topic names are checked
+// when the templates are loaded.
+func (c *Config) validateTemplates() []error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	names := make(map[string]bool, len(c.TopicTemplates))
+	for i, template := range c.TopicTemplates {
+		field := fmt.Sprintf("topicTemplates[%d]", i)
+		switch {
+		case template.Name == "":
+			fail("%s.name must be specified", field)
+		case names[template.Name]:
+			fail("topic template %q is defined more than once", template.Name)
+		}
+		names[template.Name] = true
+		if template.Name != "" {
+			field = fmt.Sprintf("topic template %q", template.Name)
+		}
+
+		if template.NumPartitions < 1 || template.ReplicationFactor < 1 {
+			fail("%s: numPartitions and replicationFactor must be at least 1", field)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,321 @@
+// Package export encodes Kafka messages into downloadable file formats.
+//
+// Every format preserves the key, value, headers, partition, offset and timestamp
+// of each message. NDJSON and CSV files use the same field names as the batch
+// publish endpoint, so they can be re-imported as they are. As they are text, they
+// base64 encode the key, value and header values of messages that are not valid
+// UTF-8 and mark them with the base64 encoding.
+package export
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hamba/avro/v2/ocf"
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// Supported export formats
+const (
+	FormatNDJSON  = "ndjson"
+	FormatCSV     = "csv"
+	FormatAvro    = "avro"
+	FormatParquet = "parquet"
+)
+
+// Formats lists the supported export formats
+var Formats = []string{FormatNDJSON, FormatCSV, FormatAvro, FormatParquet}
+
+// Writer encodes messages into an export file. Close must be called to flush
+// buffered data and write any trailing metadata; it does not close the underlying writer.
+type Writer interface {
+	Write(message domain.TopicMessage) error
+	Close() error
+}
+
+// NewWriter creates a Writer for the given format that writes to w
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatNDJSON:
+		return newNDJSONWriter(w), nil
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatAvro:
+		return newAvroWriter(w)
+	case FormatParquet:
+		return newParquetWriter(w), nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %q (supported: %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// IsSupported reports whether format is a supported export format
+func IsSupported(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentType returns the MIME type of files in the given format
+func ContentType(format string) string {
+	switch format {
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatCSV:
+		return "text/csv"
+	case FormatAvro:
+		return "application/avro"
+	case FormatParquet:
+		return "application/vnd.apache.parquet"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// FileExtension returns the file extension, including the dot, for the given format
+func FileExtension(format string) string {
+	return "." + format
+}
+
+// ndjsonWriter writes one JSON object per line using the domain.TopicMessage layout
+type ndjsonWriter struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func newNDJSONWriter(w io.Writer) *ndjsonWriter {
+	buf := bufio.NewWriter(w)
+	return &ndjsonWriter{buf: buf, enc: json.NewEncoder(buf)}
+}
+
+// ndjsonRecord is a message of NDJSON exports
+type ndjsonRecord struct {
+	domain.TopicMessage
+	Encoding string `json:"encoding,omitempty"`
+}
+
+func (w *ndjsonWriter) Write(message domain.TopicMessage) error {
+	message, encoding := textEncoded(message)
+	return w.enc.Encode(ndjsonRecord{TopicMessage: message, Encoding: encoding})
+}
+
+// textEncoded returns a message that can be written as text, with its encoding: the
+// message itself when it is valid UTF-8, or else its key, value and header values
+// base64 encoded
+func textEncoded(message domain.TopicMessage) (domain.TopicMessage, string) {
+	binary := !utf8.ValidString(message.Key) || !utf8.ValidString(message.Value)
+	for _, value := range message.Headers {
+		binary = binary || !utf8.ValidString(value)
+	}
+	if !binary {
+		return message, ""
+	}
+
+	encode := base64.StdEncoding.EncodeToString
+	message.Key = encode([]byte(message.Key))
+	message.Value = encode([]byte(message.Value))
+	headers := make(map[string]string, len(message.Headers))
+	for name, value := range message.Headers {
+		headers[name] = encode([]byte(value))
+	}
+	if len(headers) > 0 {
+		message.Headers = headers
+	}
+	return message, domain.EncodingBase64
+}
+
+func (w *ndjsonWriter) Close() error {
+	return w.buf.Flush()
+}
+
+// csvColumns is the column layout of CSV exports
+var csvColumns = []string{"topic", "partition", "offset", "timestamp", "key", "value", "headers", "encoding"}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvColumns); err != nil {
+		return nil, err
+	}
+	return &csvWriter{w: cw}, nil
+}
+
+func (w *csvWriter) Write(message domain.TopicMessage) error {
+	message, encoding := textEncoded(message)
+	headers := ""
+	if len(message.Headers) > 0 {
+		encoded, err := json.Marshal(message.Headers)
+		if err != nil {
+			return err
+		}
+		headers = string(encoded)
+	}
+
+	return w.w.Write([]string{
+		message.Topic,
+		strconv.FormatInt(int64(message.Partition), 10),
+		strconv.FormatInt(message.Offset, 10),
+		message.Timestamp.UTC().Format(time.RFC3339Nano),
+		message.Key,
+		message.Value,
+		headers,
+		encoding,
+	})
+}
+
+func (w *csvWriter) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// avroSchema describes the records of Avro object container exports
+const avroSchema = `{
+	"type": "record",
+	"name": "KafkaMessage",
+	"namespace": "io.maestro.export",
+	"fields": [
+		{"name": "topic", "type": "string"},
+		{"name": "partition", "type": "int"},
+		{"name": "offset", "type": "long"},
+		{"name": "timestamp", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+		{"name": "key", "type": "bytes"},
+		{"name": "value", "type": "bytes"},
+		{"name": "headers", "type": {"type": "map", "values": "bytes"}}
+	]
+}`
+
+type avroRecord struct {
+	Topic     string            `avro:"topic"`
+	Partition int32             `avro:"partition"`
+	Offset    int64             `avro:"offset"`
+	Timestamp time.Time         `avro:"timestamp"`
+	Key       []byte            `avro:"key"`
+	Value     []byte            `avro:"value"`
+	Headers   map[string][]byte `avro:"headers"`
+}
+
+type avroWriter struct {
+	enc *ocf.Encoder
+}
+
+func newAvroWriter(w io.Writer) (*avroWriter, error) {
+	enc, err := ocf.NewEncoder(avroSchema, w, ocf.WithCodec(ocf.Deflate))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Avro encoder: %w", err)
+	}
+	return &avroWriter{enc: enc}, nil
+}
+
+func (w *avroWriter) Write(message domain.TopicMessage) error {
+	headers := make(map[string][]byte, len(message.Headers))
+	for k, v := range message.Headers {
+		headers[k] = []byte(v)
+	}
+
+	return w.enc.Encode(avroRecord{
+		Topic:     message.Topic,
+		Partition: message.Partition,
+		Offset:    message.Offset,
+		Timestamp: message.Timestamp,
+		Key:       []byte(message.Key),
+		Value:     []byte(message.Value),
+		Headers:   headers,
+	})
+}
+
+func (w *avroWriter) Close() error {
+	return w.enc.Close()
+}
+
+// parquetRowGroupSize is the number of rows buffered before a row group is written
+const parquetRowGroupSize = 10000
+
+type parquetHeader struct {
+	Key   string `parquet:"key"`
+	Value []byte `parquet:"value"`
+}
+
+type parquetRecord struct {
+	Topic     string          `parquet:"topic,dict"`
+	Partition int32           `parquet:"partition"`
+	Offset    int64           `parquet:"offset"`
+	Timestamp int64           `parquet:"timestamp,timestamp(millisecond)"`
+	Key       []byte          `parquet:"key"`
+	Value     []byte          `parquet:"value"`
+	Headers   []parquetHeader `parquet:"headers,list"`
+}
+
+type parquetWriter struct {
+	w    *parquet.GenericWriter[parquetRecord]
+	rows []parquetRecord
+}
+
+func newParquetWriter(w io.Writer) *parquetWriter {
+	return &parquetWriter{
+		w:    parquet.NewGenericWriter[parquetRecord](w, parquet.Compression(&parquet.Snappy)),
+		rows: make([]parquetRecord, 0, parquetRowGroupSize),
+	}
+}
+
+func (w *parquetWriter) Write(message domain.TopicMessage) error {
+	keys := make([]string, 0, len(message.Headers))
+	for k := range message.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	headers := make([]parquetHeader, 0, len(keys))
+	for _, k := range keys {
+		headers = append(headers, parquetHeader{Key: k, Value: []byte(message.Headers[k])})
+	}
+
+	w.rows = append(w.rows, parquetRecord{
+		Topic:     message.Topic,
+		Partition: message.Partition,
+		Offset:    message.Offset,
+		Timestamp: message.Timestamp.UnixMilli(),
+		Key:       []byte(message.Key),
+		Value:     []byte(message.Value),
+		Headers:   headers,
+	})
+
+	if len(w.rows) >= parquetRowGroupSize {
+		return w.flushRows()
+	}
+	return nil
+}
+
+func (w *parquetWriter) flushRows() error {
+	if len(w.rows) == 0 {
+		return nil
+	}
+	if _, err := w.w.Write(w.rows); err != nil {
+		return err
+	}
+	w.rows = w.rows[:0]
+	return w.w.Flush()
+}
+
+func (w *parquetWriter) Close() error {
+	if err := w.flushRows(); err != nil {
+		return err
+	}
+	return w.w.Close()
+}
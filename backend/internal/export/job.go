@@ -0,0 +1,123 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/valeriouberti/maestro/internal/filter"
+	"github.com/valeriouberti/maestro/internal/jobs"
+	"github.com/valeriouberti/maestro/internal/kafka_client"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// JobType is the job type under which exports run
+const JobType = "export"
+
+// Progress counters reported by export jobs
+const (
+	CounterRead     = "read"
+	CounterExported = "exported"
+)
+
+// JobResult summarizes a finished export job
+type JobResult struct {
+	Messages int64  `json:"messages"`
+	Format   string `json:"format"`
+	File     string `json:"file"`
+}
+
+// Runner runs export jobs, writing the exported file as the job result. With
+// filters, an export job also serves as a scan for matching messages.
+type Runner struct {
+	client kafka_client.Client
+}
+
+// NewRunner creates a Runner that reads messages through the given client
+func NewRunner(client kafka_client.Client) *Runner {
+	return &Runner{client: client}
+}
+
+// Validate checks that params hold a valid domain.ExportSpec
+func (r *Runner) Validate(params json.RawMessage) error {
+	_, _, err := parseSpec(params)
+	return err
+}
+
+// Run exports the messages selected by the spec in params to the job result file
+func (r *Runner) Run(ctx context.Context, h *jobs.Handle, params json.RawMessage) error {
+	spec, match, err := parseSpec(params)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s%s", spec.Topic, time.Now().UTC().Format("20060102T150405Z"), FileExtension(spec.Format))
+	file, err := h.CreateResultFile(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer, err := NewWriter(spec.Format, file)
+	if err != nil {
+		return err
+	}
+
+	var exported int64
+	err = r.client.StreamTopicMessages(ctx, spec.Topic, spec.Range, func(message domain.TopicMessage) error {
+		matched := match(message)
+		h.Progress(func(progress *domain.JobProgress) {
+			progress.Done++
+			if progress.Counters == nil {
+				progress.Counters = make(map[string]int64)
+			}
+			progress.Counters[CounterRead]++
+			if matched {
+				progress.Counters[CounterExported]++
+			}
+		})
+		if !matched {
+			return nil
+		}
+
+		exported++
+		return writer.Write(message)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to finalize export file: %w", err)
+	}
+
+	return h.SetResult(JobResult{
+		Messages: exported,
+		Format:   spec.Format,
+		File:     name,
+	})
+}
+
+// parseSpec decodes, validates and compiles the spec of an export job
+func parseSpec(params json.RawMessage) (domain.ExportSpec, filter.Matcher, error) {
+	var spec domain.ExportSpec
+	if err := json.Unmarshal(params, &spec); err != nil {
+		return spec, nil, fmt.Errorf("invalid export spec: %w", err)
+	}
+	if spec.Topic == "" {
+		return spec, nil, fmt.Errorf("topic is required")
+	}
+	if spec.Format == "" {
+		spec.Format = FormatNDJSON
+	}
+	if !IsSupported(spec.Format) {
+		return spec, nil, fmt.Errorf("unsupported export format %q", spec.Format)
+	}
+
+	match, err := filter.Compile(spec.Filters)
+	if err != nil {
+		return spec, nil, err
+	}
+	return spec, match, nil
+}
@@ -0,0 +1,159 @@
+// Package filter evaluates message filters against Kafka messages.
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// Matcher reports whether a message satisfies a compiled set of filters
+type Matcher func(message domain.TopicMessage) bool
+
+// Compile validates filters and compiles them into a single Matcher that
+// requires every filter to match. An empty set of filters matches every message.
+func Compile(filters []domain.MessageFilter) (Matcher, error) {
+	matchers := make([]Matcher, 0, len(filters))
+	for i, filter := range filters {
+		m, err := compileFilter(filter)
+		if err != nil {
+			return nil, fmt.Errorf("filter %d: %w", i, err)
+		}
+		matchers = append(matchers, m)
+	}
+
+	return func(message domain.TopicMessage) bool {
+		for _, m := range matchers {
+			if !m(message) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func compileFilter(filter domain.MessageFilter) (Matcher, error) {
+	switch {
+	case filter.Field == "key":
+		return compileStringFilter(filter, func(m domain.TopicMessage) (string, bool) { return m.Key, true })
+	case filter.Field == "value":
+		return compileStringFilter(filter, func(m domain.TopicMessage) (string, bool) { return m.Value, true })
+	case strings.HasPrefix(filter.Field, "header:"):
+		name := strings.TrimPrefix(filter.Field, "header:")
+		if name == "" {
+			return nil, fmt.Errorf("header filter requires a header name")
+		}
+		return compileStringFilter(filter, func(m domain.TopicMessage) (string, bool) {
+			value, ok := m.Headers[name]
+			return value, ok
+		})
+	case filter.Field == "partition":
+		return compileNumberFilter(filter, func(m domain.TopicMessage) int64 { return int64(m.Partition) }, parseInteger)
+	case filter.Field == "offset":
+		return compileNumberFilter(filter, func(m domain.TopicMessage) int64 { return m.Offset }, parseInteger)
+	case filter.Field == "timestamp":
+		return compileNumberFilter(filter, func(m domain.TopicMessage) int64 { return m.Timestamp.UnixMilli() }, parseTimestampMillis)
+	default:
+		return nil, fmt.Errorf("unknown field %q (supported: key, value, partition, offset, timestamp, header:<name>)", filter.Field)
+	}
+}
+
+func compileStringFilter(filter domain.MessageFilter, extract func(domain.TopicMessage) (string, bool)) (Matcher, error) {
+	expected := filter.Value
+
+	switch filter.Op {
+	case "exists":
+		return func(m domain.TopicMessage) bool {
+			_, ok := extract(m)
+			return ok
+		}, nil
+	case "eq":
+		return func(m domain.TopicMessage) bool {
+			value, ok := extract(m)
+			return ok && value == expected
+		}, nil
+	case "neq":
+		return func(m domain.TopicMessage) bool {
+			value, ok := extract(m)
+			return !ok || value != expected
+		}, nil
+	case "contains":
+		return func(m domain.TopicMessage) bool {
+			value, ok := extract(m)
+			return ok && strings.Contains(value, expected)
+		}, nil
+	case "prefix":
+		return func(m domain.TopicMessage) bool {
+			value, ok := extract(m)
+			return ok && strings.HasPrefix(value, expected)
+		}, nil
+	case "suffix":
+		return func(m domain.TopicMessage) bool {
+			value, ok := extract(m)
+			return ok && strings.HasSuffix(value, expected)
+		}, nil
+	case "regex":
+		re, err := regexp.Compile(expected)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %w", err)
+		}
+		return func(m domain.TopicMessage) bool {
+			value, ok := extract(m)
+			return ok && re.MatchString(value)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported operator %q for field %q", filter.Op, filter.Field)
+	}
+}
+
+func compileNumberFilter(filter domain.MessageFilter, extract func(domain.TopicMessage) int64, parse func(string) (int64, error)) (Matcher, error) {
+	expected, err := parse(filter.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	var compare func(value int64) bool
+	switch filter.Op {
+	case "eq":
+		compare = func(value int64) bool { return value == expected }
+	case "neq":
+		compare = func(value int64) bool { return value != expected }
+	case "gt":
+		compare = func(value int64) bool { return value > expected }
+	case "gte":
+		compare = func(value int64) bool { return value >= expected }
+	case "lt":
+		compare = func(value int64) bool { return value < expected }
+	case "lte":
+		compare = func(value int64) bool { return value <= expected }
+	default:
+		return nil, fmt.Errorf("unsupported operator %q for field %q", filter.Op, filter.Field)
+	}
+
+	return func(m domain.TopicMessage) bool {
+		return compare(extract(m))
+	}, nil
+}
+
+func parseInteger(value string) (int64, error) {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", value)
+	}
+	return n, nil
+}
+
+func parseTimestampMillis(value string) (int64, error) {
+	if millis, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return millis, nil
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp %q: use RFC 3339 or epoch milliseconds", value)
+	}
+	return timestamp.UnixMilli(), nil
+}
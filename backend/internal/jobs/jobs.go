@@ -0,0 +1,606 @@
+// Package jobs runs long-running operations such as exports and replays in the
+// background, outside of the HTTP request that started them.
+//
+// Jobs are identified by an ID and expose their progress while they run. A bounded
+// number of jobs runs at a time; the others wait in the pending state. Job state is
+// persisted in a storage.Store so that it survives restarts: jobs that were still
+// pending are started again, jobs that were running are marked as failed. Result
+// files are kept in a directory on disk.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/valeriouberti/maestro/internal/logging"
+	"github.com/valeriouberti/maestro/internal/storage"
+	"github.com/valeriouberti/maestro/internal/tracing"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// Job statuses
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// persistInterval is how often progress updates of running jobs are persisted
+const persistInterval = 5 * time.Second
+
+var (
+	// ErrJobNotFound is returned when no job exists with the requested ID
+	ErrJobNotFound = errors.New("job not found")
+	// ErrUnknownJobType is returned when submitting a job of an unregistered type
+	ErrUnknownJobType = errors.New("unknown job type")
+	// ErrInvalidParams is returned when the parameters of a submitted job are rejected by its runner
+	ErrInvalidParams = errors.New("invalid job parameters")
+	// ErrJobNotFinished is returned when requesting the result of a job that is still active
+	ErrJobNotFinished = errors.New("job has not finished")
+	// ErrNoResult is returned when a finished job did not produce a result
+	ErrNoResult = errors.New("job has no result")
+)
+
+// Runner implements a type of job
+type Runner interface {
+	// Validate checks the parameters of a job before it is accepted
+	Validate(params json.RawMessage) error
+	// Run performs the job, reporting progress and results through h. It must
+	// return promptly once ctx is cancelled.
+	Run(ctx context.Context, h *Handle, params json.RawMessage) error
+}
+
+// Options configures a Manager
+type Options struct {
+	// Store persists the job state. Jobs are only kept in memory when nil.
+	Store storage.Store
+	// Dir holds the result files. Jobs cannot produce result files when empty.
+	Dir string
+	// MaxConcurrent is the maximum number of jobs running at the same time
+	MaxConcurrent int
+	// Retention is how long finished jobs and their results are kept. Zero keeps them forever.
+	Retention time.Duration
+	// ClusterContext, when set, adds the Kafka cluster of a job to the context it runs with
+	ClusterContext func(ctx context.Context, cluster string) context.Context
+}
+
+// entry holds the mutable state of a job
+type entry struct {
+	mu     sync.Mutex
+	job    domain.Job
+	cancel context.CancelFunc
+}
+
+func (e *entry) snapshot() domain.Job {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	job := e.job
+	if job.Progress.Counters != nil {
+		counters := make(map[string]int64, len(job.Progress.Counters))
+		for k, v := range job.Progress.Counters {
+			counters[k] = v
+		}
+		job.Progress.Counters = counters
+	}
+	return job
+}
+
+// Manager schedules, runs and tracks background jobs
+type Manager struct {
+	opts    Options
+	runners map[string]Runner
+	slots   chan struct{}
+	ctx     context.Context
+	stop    context.CancelFunc
+	wg      sync.WaitGroup
+
+	mu      sync.RWMutex
+	entries map[string]*entry
+	dirty   bool
+
+	persistMu sync.Mutex
+}
+
+// NewManager creates a Manager and restores the jobs persisted in opts.Store.
+// Runners must be registered before Start is called.
+func NewManager(opts Options) (*Manager, error) {
+	if opts.MaxConcurrent <= 0 {
+		opts.MaxConcurrent = 1
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	m := &Manager{
+		opts:    opts,
+		runners: make(map[string]Runner),
+		slots:   make(chan struct{}, opts.MaxConcurrent),
+		ctx:     ctx,
+		stop:    stop,
+		entries: make(map[string]*entry),
+	}
+
+	if opts.Dir != "" {
+		if err := os.MkdirAll(m.resultsDir(), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create job directory: %w", err)
+		}
+	}
+	if opts.Store != nil {
+		if err := m.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// Register makes a job type available
+func (m *Manager) Register(jobType string, runner Runner) {
+	m.runners[jobType] = runner
+}
+
+// Start resumes the restored jobs that were still pending and starts
+// the background maintenance loop
+func (m *Manager) Start() {
+	m.mu.RLock()
+	pending := make([]*entry, 0)
+	for _, e := range m.entries {
+		if e.job.Status == StatusPending {
+			pending = append(pending, e)
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(pending, func(i, k int) bool {
+		return pending[i].job.CreatedAt.Before(pending[k].job.CreatedAt)
+	})
+	for _, e := range pending {
+		m.launch(e)
+	}
+
+	m.wg.Add(1)
+	go m.maintain()
+}
+
+// Submit validates and enqueues a new job of the given type to run against a Kafka
+// cluster; an empty cluster is the default one
+func (m *Manager) Submit(cluster, jobType string, params any) (domain.Job, error) {
+	runner, ok := m.runners[jobType]
+	if !ok {
+		return domain.Job{}, fmt.Errorf("%w: %q", ErrUnknownJobType, jobType)
+	}
+
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return domain.Job{}, fmt.Errorf("%w: %v", ErrInvalidParams, err)
+	}
+	if err := runner.Validate(raw); err != nil {
+		return domain.Job{}, fmt.Errorf("%w: %v", ErrInvalidParams, err)
+	}
+
+	e := &entry{
+		job: domain.Job{
+			ID:        uuid.New().String(),
+			Type:      jobType,
+			Cluster:   cluster,
+			Status:    StatusPending,
+			Params:    raw,
+			CreatedAt: time.Now(),
+		},
+	}
+
+	m.mu.Lock()
+	m.entries[e.job.ID] = e
+	m.mu.Unlock()
+	m.persist()
+
+	m.launch(e)
+
+	return e.snapshot(), nil
+}
+
+// Get returns the current state of a job
+func (m *Manager) Get(id string) (domain.Job, error) {
+	e, err := m.lookup(id)
+	if err != nil {
+		return domain.Job{}, err
+	}
+	return e.snapshot(), nil
+}
+
+// List returns the jobs matching the given type and status, most recent first.
+// Empty values match every job.
+func (m *Manager) List(jobType, status string) []domain.Job {
+	m.mu.RLock()
+	list := make([]domain.Job, 0, len(m.entries))
+	for _, e := range m.entries {
+		job := e.snapshot()
+		if (jobType == "" || job.Type == jobType) && (status == "" || job.Status == status) {
+			list = append(list, job)
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(list, func(i, k int) bool {
+		return list[i].CreatedAt.After(list[k].CreatedAt)
+	})
+	return list
+}
+
+// Cancel requests cancellation of a job. Cancelling a finished job has no effect.
+func (m *Manager) Cancel(id string) (domain.Job, error) {
+	e, err := m.lookup(id)
+	if err != nil {
+		return domain.Job{}, err
+	}
+
+	e.mu.Lock()
+	switch {
+	case e.job.Status == StatusPending:
+		// Never started: it will be skipped when its turn comes
+		now := time.Now()
+		e.job.Status = StatusCancelled
+		e.job.FinishedAt = &now
+	case e.cancel != nil:
+		e.cancel()
+	}
+	e.mu.Unlock()
+
+	m.persist()
+	return e.snapshot(), nil
+}
+
+// ResultPath returns the path of the result file of a finished job
+func (m *Manager) ResultPath(id string) (string, error) {
+	e, err := m.lookup(id)
+	if err != nil {
+		return "", err
+	}
+
+	job := e.snapshot()
+	if job.Status == StatusPending || job.Status == StatusRunning {
+		return "", ErrJobNotFinished
+	}
+	if job.ResultFile == "" {
+		return "", ErrNoResult
+	}
+	return m.resultPath(job.ID, job.ResultFile), nil
+}
+
+// Shutdown cancels running jobs, waits for them to stop and persists the final state.
+// Jobs still waiting for a slot stay pending and are started on the next start;
+// jobs interrupted by the shutdown are marked as failed.
+func (m *Manager) Shutdown() {
+	m.stop()
+	m.wg.Wait()
+	m.persist()
+}
+
+func (m *Manager) lookup(id string) (*entry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	e, ok := m.entries[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return e, nil
+}
+
+// launch runs a job in the background as soon as a slot is available
+func (m *Manager) launch(e *entry) {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		select {
+		case <-m.ctx.Done():
+			return
+		case m.slots <- struct{}{}:
+		}
+		defer func() { <-m.slots }()
+
+		m.run(e)
+	}()
+}
+
+func (m *Manager) run(e *entry) {
+	ctx, cancel := context.WithCancel(m.ctx)
+	defer cancel()
+
+	e.mu.Lock()
+	if e.job.Status != StatusPending {
+		// Cancelled while waiting for a slot
+		e.mu.Unlock()
+		return
+	}
+	started := time.Now()
+	e.job.Status = StatusRunning
+	e.job.StartedAt = &started
+	e.cancel = cancel
+	runner := m.runners[e.job.Type]
+	params := e.job.Params
+	if m.opts.ClusterContext != nil {
+		ctx = m.opts.ClusterContext(ctx, e.job.Cluster)
+	}
+	ctx = logging.With(ctx, slog.String("job_id", e.job.ID), slog.String("job_type", e.job.Type))
+	ctx, span := tracing.Tracer().Start(ctx, "job "+e.job.Type, trace.WithAttributes(
+		attribute.String("maestro.job.id", e.job.ID),
+		attribute.String("maestro.job.type", e.job.Type),
+		attribute.String("maestro.cluster", e.job.Cluster),
+	))
+	defer span.End()
+	e.mu.Unlock()
+	m.persist()
+
+	var err error
+	if runner == nil {
+		err = fmt.Errorf("%w: %q", ErrUnknownJobType, e.job.Type)
+	} else {
+		err = m.safeRun(ctx, runner, &Handle{m: m, e: e}, params)
+	}
+
+	finished := time.Now()
+	e.mu.Lock()
+	e.cancel = nil
+	switch {
+	case m.ctx.Err() != nil && errors.Is(err, context.Canceled):
+		// Partially applied work cannot be resumed safely, e.g. a replay
+		// started again would produce duplicates
+		e.job.Status = StatusFailed
+		e.job.Error = "interrupted by a server shutdown"
+		e.job.FinishedAt = &finished
+	case errors.Is(err, context.Canceled):
+		e.job.Status = StatusCancelled
+		e.job.FinishedAt = &finished
+	case err != nil:
+		e.job.Status = StatusFailed
+		e.job.Error = err.Error()
+		e.job.FinishedAt = &finished
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	default:
+		e.job.Status = StatusCompleted
+		e.job.FinishedAt = &finished
+	}
+	job := e.job
+	e.mu.Unlock()
+	m.persist()
+
+	span.SetAttributes(attribute.String("maestro.job.status", string(job.Status)))
+	slog.InfoContext(ctx, "Job finished", "status", job.Status, "duration", finished.Sub(started).Round(time.Millisecond))
+}
+
+// safeRun runs a job, turning a panic into a job failure instead of a crash
+func (m *Manager) safeRun(ctx context.Context, runner Runner, h *Handle, params json.RawMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return runner.Run(ctx, h, params)
+}
+
+// maintain periodically persists progress and removes expired jobs
+func (m *Manager) maintain() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(persistInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+			m.prune()
+			m.mu.RLock()
+			dirty := m.dirty
+			m.mu.RUnlock()
+			if dirty {
+				m.persist()
+			}
+		}
+	}
+}
+
+// prune removes finished jobs older than the retention period along with their results
+func (m *Manager) prune() {
+	if m.opts.Retention <= 0 {
+		return
+	}
+	cutoff := time.Now().Add(-m.opts.Retention)
+
+	m.mu.Lock()
+	removed := make([]domain.Job, 0)
+	for id, e := range m.entries {
+		job := e.snapshot()
+		if job.FinishedAt != nil && job.FinishedAt.Before(cutoff) {
+			delete(m.entries, id)
+			removed = append(removed, job)
+		}
+	}
+	m.mu.Unlock()
+
+	if len(removed) == 0 {
+		return
+	}
+	for _, job := range removed {
+		if m.opts.Dir != "" {
+			_ = os.RemoveAll(filepath.Join(m.resultsDir(), job.ID))
+		}
+	}
+
+	if m.opts.Store == nil {
+		return
+	}
+	m.persistMu.Lock()
+	defer m.persistMu.Unlock()
+	err := m.opts.Store.Update(func(tx storage.Tx) error {
+		for _, job := range removed {
+			if err := tx.Delete(storage.BucketJobs, job.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("Failed to delete expired jobs", "error", err)
+	}
+}
+
+func (m *Manager) markDirty() {
+	m.mu.Lock()
+	m.dirty = true
+	m.mu.Unlock()
+}
+
+func (m *Manager) resultsDir() string {
+	return filepath.Join(m.opts.Dir, "results")
+}
+
+func (m *Manager) resultPath(id, name string) string {
+	return filepath.Join(m.resultsDir(), id, name)
+}
+
+// persist writes the state of all jobs to the store in a single transaction
+func (m *Manager) persist() {
+	if m.opts.Store == nil {
+		return
+	}
+
+	m.persistMu.Lock()
+	defer m.persistMu.Unlock()
+
+	m.mu.Lock()
+	m.dirty = false
+	list := make([]domain.Job, 0, len(m.entries))
+	for _, e := range m.entries {
+		list = append(list, e.snapshot())
+	}
+	m.mu.Unlock()
+
+	err := m.opts.Store.Update(func(tx storage.Tx) error {
+		for _, job := range list {
+			if err := storage.PutJSON(tx, storage.BucketJobs, job.ID, job); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("Failed to write job state", "error", err)
+	}
+}
+
+// load restores persisted jobs. Jobs that were running when the process stopped
+// cannot be resumed and are marked as failed.
+func (m *Manager) load() error {
+	var list []domain.Job
+	err := m.opts.Store.ForEach(storage.BucketJobs, func(key string, value []byte) error {
+		var job domain.Job
+		if err := json.Unmarshal(value, &job); err != nil {
+			return fmt.Errorf("failed to decode job %s: %w", key, err)
+		}
+		list = append(list, job)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read job state: %w", err)
+	}
+
+	now := time.Now()
+	for _, job := range list {
+		if job.Status == StatusRunning {
+			job.Status = StatusFailed
+			job.Error = "interrupted by a server restart"
+			job.FinishedAt = &now
+			m.dirty = true
+		}
+		m.entries[job.ID] = &entry{job: job}
+	}
+
+	return nil
+}
+
+// Handle lets a running job report its progress and results
+type Handle struct {
+	m *Manager
+	e *entry
+}
+
+// ID returns the ID of the job
+func (h *Handle) ID() string {
+	return h.e.snapshot().ID
+}
+
+// Progress updates the progress of the job
+func (h *Handle) Progress(fn func(progress *domain.JobProgress)) {
+	h.e.mu.Lock()
+	fn(&h.e.job.Progress)
+	h.e.mu.Unlock()
+	h.m.markDirty()
+}
+
+// Count adds delta to a named progress counter
+func (h *Handle) Count(counter string, delta int64) {
+	h.Progress(func(progress *domain.JobProgress) {
+		if progress.Counters == nil {
+			progress.Counters = make(map[string]int64)
+		}
+		progress.Counters[counter] += delta
+	})
+}
+
+// SetResult stores a JSON-encodable result for the job
+func (h *Handle) SetResult(result any) error {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode job result: %w", err)
+	}
+
+	h.e.mu.Lock()
+	h.e.job.Result = raw
+	h.e.mu.Unlock()
+	h.m.markDirty()
+	return nil
+}
+
+// CreateResultFile creates the downloadable result file of the job. Only the base
+// name is kept; a job has at most one result file.
+func (h *Handle) CreateResultFile(name string) (*os.File, error) {
+	if h.m.opts.Dir == "" {
+		return nil, fmt.Errorf("job results require a job directory to be configured")
+	}
+
+	name = filepath.Base(name)
+	job := h.e.snapshot()
+	dir := filepath.Join(h.m.resultsDir(), job.ID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create result directory: %w", err)
+	}
+
+	file, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create result file: %w", err)
+	}
+
+	h.e.mu.Lock()
+	h.e.job.ResultFile = name
+	h.e.mu.Unlock()
+	h.m.markDirty()
+
+	return file, nil
+}
@@ -0,0 +1,223 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/valeriouberti/maestro/internal/storage"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+const testJobType = "test"
+
+type testParams struct {
+	Messages int  `json:"messages"`
+	Block    bool `json:"block"`
+}
+
+// testRunner counts to the requested number of messages, or blocks until
+// cancelled when asked to
+type testRunner struct{}
+
+func (testRunner) Validate(params json.RawMessage) error {
+	var p testParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return err
+	}
+	if p.Messages < 0 {
+		return fmt.Errorf("messages must not be negative")
+	}
+	return nil
+}
+
+func (testRunner) Run(ctx context.Context, h *Handle, params json.RawMessage) error {
+	var p testParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return err
+	}
+	if p.Block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	h.Count("messages", int64(p.Messages))
+	return h.SetResult(map[string]int{"messages": p.Messages})
+}
+
+// newStore returns a migrated in-memory store
+func newStore(t *testing.T) storage.Store {
+	t.Helper()
+	store := storage.NewMemory()
+	if err := storage.Migrate(store); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	return store
+}
+
+// startManager creates and starts a Manager running the test job type
+func startManager(t *testing.T, store storage.Store) *Manager {
+	t.Helper()
+	m, err := NewManager(Options{Store: store, Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	m.Register(testJobType, testRunner{})
+	m.Start()
+	return m
+}
+
+// waitFor polls a job until it reaches status
+func waitFor(t *testing.T, m *Manager, id, status string) domain.Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := m.Get(id)
+		if err != nil {
+			t.Fatalf("Get(%s): %v", id, err)
+		}
+		if job.Status == status {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s is %s, want %s", id, job.Status, status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSubmitValidation(t *testing.T) {
+	m := startManager(t, nil)
+	defer m.Shutdown()
+
+	if _, err := m.Submit("", "unknown", testParams{}); !errors.Is(err, ErrUnknownJobType) {
+		t.Errorf("Submit(unknown) error = %v, want %v", err, ErrUnknownJobType)
+	}
+	if _, err := m.Submit("", testJobType, testParams{Messages: -1}); !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Submit(invalid) error = %v, want %v", err, ErrInvalidParams)
+	}
+	if _, err := m.Get("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Get(missing) error = %v, want %v", err, ErrJobNotFound)
+	}
+}
+
+func TestJobsSurviveRestart(t *testing.T) {
+	store := newStore(t)
+
+	m := startManager(t, store)
+	job, err := m.Submit("primary", testJobType, testParams{Messages: 42})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	waitFor(t, m, job.ID, StatusCompleted)
+	m.Shutdown()
+
+	restarted := startManager(t, store)
+	defer restarted.Shutdown()
+
+	restored, err := restarted.Get(job.ID)
+	if err != nil {
+		t.Fatalf("Get after restart: %v", err)
+	}
+	if restored.Status != StatusCompleted {
+		t.Errorf("status = %s, want %s", restored.Status, StatusCompleted)
+	}
+	if restored.Cluster != "primary" {
+		t.Errorf("cluster = %q, want %q", restored.Cluster, "primary")
+	}
+	if got := restored.Progress.Counters["messages"]; got != 42 {
+		t.Errorf("messages counter = %d, want 42", got)
+	}
+	if string(restored.Result) != `{"messages":42}` {
+		t.Errorf("result = %s, want {\"messages\":42}", restored.Result)
+	}
+}
+
+func TestRunningJobFailsAfterRestart(t *testing.T) {
+	store := newStore(t)
+
+	m := startManager(t, store)
+	job, err := m.Submit("", testJobType, testParams{Block: true})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	running := waitFor(t, m, job.ID, StatusRunning)
+
+	// Simulate a crash: the running state is persisted but the manager never shuts down
+	err = store.Update(func(tx storage.Tx) error {
+		return storage.PutJSON(tx, storage.BucketJobs, running.ID, running)
+	})
+	if err != nil {
+		t.Fatalf("PutJSON: %v", err)
+	}
+	defer m.Shutdown()
+
+	restarted := startManager(t, store)
+	defer restarted.Shutdown()
+
+	restored, err := restarted.Get(job.ID)
+	if err != nil {
+		t.Fatalf("Get after restart: %v", err)
+	}
+	if restored.Status != StatusFailed {
+		t.Fatalf("status = %s, want %s", restored.Status, StatusFailed)
+	}
+	if restored.Error == "" || restored.FinishedAt == nil {
+		t.Errorf("interrupted job has no error or finish time: %+v", restored)
+	}
+}
+
+func TestPendingJobResumesAfterRestart(t *testing.T) {
+	store := newStore(t)
+
+	params, _ := json.Marshal(testParams{Messages: 7})
+	pending := domain.Job{
+		ID:        "pending-job",
+		Type:      testJobType,
+		Status:    StatusPending,
+		Params:    params,
+		CreatedAt: time.Now(),
+	}
+	err := store.Update(func(tx storage.Tx) error {
+		return storage.PutJSON(tx, storage.BucketJobs, pending.ID, pending)
+	})
+	if err != nil {
+		t.Fatalf("PutJSON: %v", err)
+	}
+
+	m := startManager(t, store)
+	job := waitFor(t, m, pending.ID, StatusCompleted)
+	m.Shutdown()
+
+	if got := job.Progress.Counters["messages"]; got != 7 {
+		t.Errorf("messages counter = %d, want 7", got)
+	}
+
+	persisted, err := storage.GetJSON[domain.Job](store, storage.BucketJobs, pending.ID)
+	if err != nil {
+		t.Fatalf("GetJSON: %v", err)
+	}
+	if persisted.Status != StatusCompleted {
+		t.Errorf("persisted status = %s, want %s", persisted.Status, StatusCompleted)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	store := newStore(t)
+
+	m := startManager(t, store)
+	defer m.Shutdown()
+
+	job, err := m.Submit("", testJobType, testParams{Block: true})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	waitFor(t, m, job.ID, StatusRunning)
+
+	if _, err := m.Cancel(job.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	waitFor(t, m, job.ID, StatusCancelled)
+}
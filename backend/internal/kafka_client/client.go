@@ -3,43 +3,156 @@ package kafka_client
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 	"github.com/google/uuid"
+	"github.com/twmb/franz-go/pkg/kgo"
 	"github.com/valeriouberti/maestro/pkg/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // KafkaClient manages interactions with Kafka cluster
 type KafkaClient struct {
 	AdminClient *kafka.AdminClient
 	Brokers     []string
-	Timeout     time.Duration // Default timeout for operations
+	Timeout     time.Duration     // Default timeout for operations
+	Properties  map[string]string // librdkafka properties of every client created, e.g. security settings
+	Logger      *slog.Logger      // Receives the logs of the client and of librdkafka
+
+	// adminProducer is the librdkafka instance behind AdminClient
+	adminProducer *kafka.Producer
+	// logs receives the logs of every librdkafka instance created by the client until done is closed
+	logs chan kafka.LogEvent
+	done chan struct{}
+
+	// coordinator sends the group coordinator requests librdkafka does not support; see coordinatorClient
+	coordinatorOnce sync.Once
+	coordinator     *kgo.Client
+	coordinatorErr  error
+}
+
+// logsBuffer is the number of librdkafka log events buffered before librdkafka blocks
+const logsBuffer = 1000
+
+// Options configure a KafkaClient
+type Options struct {
+	Brokers []string
+	Timeout time.Duration // Default: 10s
+
+	// Properties are librdkafka properties applied to the admin client and to every
+	// consumer and producer created, e.g. "security.protocol" or "sasl.username".
+	// Settings Maestro relies on, such as the group ids of its readers, take precedence.
+	Properties map[string]string
+
+	// Logger receives the logs of the client, including those of librdkafka (default: slog.Default())
+	Logger *slog.Logger
 }
 
 // NewKafkaClient creates a new Kafka client with the provided broker addresses
 func NewKafkaClient(brokers []string) (*KafkaClient, error) {
-	if len(brokers) == 0 {
-		return nil, fmt.Errorf("no Kafka brokers provided")
+	return NewKafkaClientWithOptions(Options{Brokers: brokers})
+}
+
+// NewKafkaClientWithOptions creates a new Kafka client for the brokers of opts
+func NewKafkaClientWithOptions(opts Options) (*KafkaClient, error) {
+	if len(opts.Brokers) == 0 {
+		return nil, InvalidArgumentError("no Kafka brokers provided")
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
 	}
 
-	configMap := &kafka.ConfigMap{
-		"bootstrap.servers": strings.Join(brokers, ","),
-		"client.id":         "maestro-client",
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
 	}
 
-	adminClient, err := kafka.NewAdminClient(configMap)
+	kc := &KafkaClient{
+		Brokers:    opts.Brokers,
+		Timeout:    opts.Timeout,
+		Properties: opts.Properties,
+		Logger:     opts.Logger,
+		logs:       make(chan kafka.LogEvent, logsBuffer),
+		done:       make(chan struct{}),
+	}
+	go kc.forwardLogs()
+
+	// The admin client is derived from a producer because only producers and consumers
+	// forward the logs of librdkafka
+	producer, err := kafka.NewProducer(kc.configMap(kafka.ConfigMap{
+		"client.id": "maestro-client",
+	}))
+	if err != nil {
+		close(kc.done)
+		return nil, wrapError(err, "failed to create Kafka admin client")
+	}
+	adminClient, err := kafka.NewAdminClientFromProducer(producer)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Kafka admin client: %w", err)
+		producer.Close()
+		close(kc.done)
+		return nil, wrapError(err, "failed to create Kafka admin client")
 	}
+	kc.adminProducer = producer
+	kc.AdminClient = adminClient
 
-	return &KafkaClient{
-		AdminClient: adminClient,
-		Brokers:     brokers,
-		Timeout:     10 * time.Second, // Default timeout
-	}, nil
+	return kc, nil
+}
+
+// forwardLogs logs the log events of librdkafka until the client is closed
+func (kc *KafkaClient) forwardLogs() {
+	for {
+		select {
+		case <-kc.done:
+			return
+		case event := <-kc.logs:
+			kc.Logger.Log(context.Background(), syslogLevel(event.Level), event.Message,
+				slog.String("component", "librdkafka"),
+				slog.String("instance", event.Name),
+				slog.String("tag", event.Tag),
+			)
+		}
+	}
+}
+
+// syslogLevel maps the syslog level of a librdkafka log event to a slog level
+func syslogLevel(level int) slog.Level {
+	switch {
+	case level <= 3: // Emergency, alert, critical and error
+		return slog.LevelError
+	case level == 4: // Warning
+		return slog.LevelWarn
+	case level <= 6: // Notice and informational
+		return slog.LevelInfo
+	default:
+		return slog.LevelDebug
+	}
+}
+
+// configMap returns the configuration of a client with the brokers, the properties of
+// the KafkaClient and the given settings. The logs of the client are forwarded to the
+// logger of the KafkaClient.
+func (kc *KafkaClient) configMap(settings kafka.ConfigMap) *kafka.ConfigMap {
+	config := kafka.ConfigMap{}
+	for key, value := range kc.Properties {
+		config[key] = value
+	}
+	config["bootstrap.servers"] = strings.Join(kc.Brokers, ",")
+	config["go.logs.channel.enable"] = true
+	config["go.logs.channel"] = kc.logs
+	for key, value := range settings {
+		config[key] = value
+	}
+	return &config
+}
+
+// OperationTimeout returns the default timeout for operations
+func (kc *KafkaClient) OperationTimeout() time.Duration {
+	return kc.Timeout
 }
 
 // Close releases resources used by the Kafka client
@@ -47,6 +160,30 @@ func (kc *KafkaClient) Close() {
 	if kc.AdminClient != nil {
 		kc.AdminClient.Close()
 	}
+	if kc.adminProducer != nil {
+		kc.adminProducer.Close()
+	}
+	// Prevent the creation of a coordinator client after closing
+	kc.coordinatorOnce.Do(func() {})
+	if kc.coordinator != nil {
+		kc.coordinator.Close()
+	}
+	if kc.done != nil {
+		close(kc.done)
+	}
+}
+
+// getMetadata retrieves the metadata of a topic, or of all topics when topicName is nil
+func (kc *KafkaClient) getMetadata(ctx context.Context, topicName *string, allTopics bool) (*kafka.Metadata, error) {
+	var span trace.Span
+	if topicName != nil {
+		span = kc.startCall(ctx, "GetMetadata", topicAttribute(*topicName))
+	} else {
+		span = kc.startCall(ctx, "GetMetadata")
+	}
+	metadata, err := kc.AdminClient.GetMetadata(topicName, allTopics, int(kc.Timeout.Milliseconds()))
+	endSpan(span, err)
+	return metadata, err
 }
 
 // GetBrokers retrieves information about all brokers in the Kafka cluster
@@ -54,9 +191,9 @@ func (kc *KafkaClient) GetBrokers(ctx context.Context) ([]domain.BrokerInfo, err
 	ctx, cancel := context.WithTimeout(ctx, kc.Timeout)
 	defer cancel()
 
-	metadata, err := kc.AdminClient.GetMetadata(nil, true, int(kc.Timeout.Milliseconds()))
+	metadata, err := kc.getMetadata(ctx, nil, true)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get broker metadata: %w", err)
+		return nil, wrapError(err, "failed to get broker metadata")
 	}
 
 	brokerList := make([]domain.BrokerInfo, 0, len(metadata.Brokers))
@@ -76,9 +213,9 @@ func (kc *KafkaClient) ListTopics(ctx context.Context) ([]domain.TopicInfo, erro
 	ctx, cancel := context.WithTimeout(ctx, kc.Timeout)
 	defer cancel()
 
-	metadata, err := kc.AdminClient.GetMetadata(nil, true, int(kc.Timeout.Milliseconds()))
+	metadata, err := kc.getMetadata(ctx, nil, true)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get topic metadata: %w", err)
+		return nil, wrapError(err, "failed to get topic metadata")
 	}
 
 	topics := make([]domain.TopicInfo, 0, len(metadata.Topics))
@@ -90,6 +227,11 @@ func (kc *KafkaClient) ListTopics(ctx context.Context) ([]domain.TopicInfo, erro
 
 	sort.Strings(topicNames)
 
+	internal, err := kc.internalTopics(ctx, topicNames)
+	if err != nil {
+		return nil, err
+	}
+
 	for _, topicName := range topicNames {
 		topicMetadata := metadata.Topics[topicName]
 		partitions := make([]domain.PartitionInfo, 0, len(topicMetadata.Partitions))
@@ -115,25 +257,49 @@ func (kc *KafkaClient) ListTopics(ctx context.Context) ([]domain.TopicInfo, erro
 			NumPartitions:     int32(len(topicMetadata.Partitions)),
 			ReplicationFactor: replicationFactor,
 			Partitions:        partitions,
+			IsInternal:        internal[topicName],
 		})
 	}
 
 	return topics, nil
 }
 
+// internalTopics returns which of the given topics are internal to Kafka, such as
+// __consumer_offsets. Metadata responses don't tell, so the topics are described.
+func (kc *KafkaClient) internalTopics(ctx context.Context, topicNames []string) (map[string]bool, error) {
+	internal := make(map[string]bool)
+	if len(topicNames) == 0 {
+		return internal, nil
+	}
+
+	span := kc.startCall(ctx, "DescribeTopics")
+	result, err := kc.AdminClient.DescribeTopics(ctx, kafka.NewTopicCollectionOfTopicNames(topicNames))
+	endSpan(span, err)
+	if err != nil {
+		return nil, wrapError(err, "failed to describe topics")
+	}
+
+	for _, description := range result.TopicDescriptions {
+		if description.IsInternal {
+			internal[description.Name] = true
+		}
+	}
+	return internal, nil
+}
+
 // GetTopicDetails retrieves detailed information about a specific topic
 func (kc *KafkaClient) GetTopicDetails(ctx context.Context, topicName string) (*domain.TopicInfo, error) {
 	ctx, cancel := context.WithTimeout(ctx, kc.Timeout)
 	defer cancel()
 
-	metadata, err := kc.AdminClient.GetMetadata(&topicName, false, int(kc.Timeout.Milliseconds()))
+	metadata, err := kc.getMetadata(ctx, &topicName, false)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get topic details: %w", err)
+		return nil, wrapError(err, "failed to get topic details")
 	}
 
 	topicMetadata, exists := metadata.Topics[topicName]
 	if !exists {
-		return nil, fmt.Errorf("topic '%s' not found", topicName)
+		return nil, TopicNotFoundError(topicName)
 	}
 
 	configResources := []kafka.ConfigResource{
@@ -143,9 +309,11 @@ func (kc *KafkaClient) GetTopicDetails(ctx context.Context, topicName string) (*
 		},
 	}
 
+	span := kc.startCall(ctx, "DescribeConfigs", topicAttribute(topicName))
 	configResult, err := kc.AdminClient.DescribeConfigs(ctx, configResources)
+	endSpan(span, err)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get topic configuration: %w", err)
+		return nil, wrapError(err, "failed to get topic configuration")
 	}
 
 	config := make(map[string]string)
@@ -172,12 +340,18 @@ func (kc *KafkaClient) GetTopicDetails(ctx context.Context, topicName string) (*
 		replicationFactor = len(topicMetadata.Partitions[0].Replicas)
 	}
 
+	internal, err := kc.internalTopics(ctx, []string{topicName})
+	if err != nil {
+		return nil, err
+	}
+
 	return &domain.TopicInfo{
 		Name:              topicName,
 		NumPartitions:     int32(len(topicMetadata.Partitions)),
 		ReplicationFactor: replicationFactor,
 		Config:            config,
 		Partitions:        partitions,
+		IsInternal:        internal[topicName],
 	}, nil
 }
 
@@ -187,13 +361,13 @@ func (kc *KafkaClient) CreateTopic(ctx context.Context, topic domain.TopicInfo)
 	defer cancel()
 
 	if topic.Name == "" {
-		return fmt.Errorf("topic name cannot be empty")
+		return InvalidArgumentError("topic name cannot be empty")
 	}
 	if topic.NumPartitions <= 0 {
-		return fmt.Errorf("number of partitions must be greater than 0")
+		return InvalidArgumentError("number of partitions must be greater than 0")
 	}
 	if topic.ReplicationFactor <= 0 {
-		return fmt.Errorf("replication factor must be greater than 0")
+		return InvalidArgumentError("replication factor must be greater than 0")
 	}
 
 	topicSpec := kafka.TopicSpecification{
@@ -203,20 +377,21 @@ func (kc *KafkaClient) CreateTopic(ctx context.Context, topic domain.TopicInfo)
 		Config:            topic.Config,
 	}
 
+	span := kc.startCall(ctx, "CreateTopics", topicAttribute(topic.Name))
 	topicResults, err := kc.AdminClient.CreateTopics(
 		ctx,
 		[]kafka.TopicSpecification{topicSpec},
 		kafka.SetAdminOperationTimeout(kc.Timeout),
 	)
+	endSpan(span, err)
 
 	if err != nil {
-		return fmt.Errorf("failed to create topic: %w", err)
+		return wrapError(err, "failed to create topic")
 	}
 
 	if len(topicResults) > 0 {
 		if topicResults[0].Error.Code() != kafka.ErrNoError {
-			return fmt.Errorf("failed to create topic '%s': %s",
-				topic.Name, topicResults[0].Error.String())
+			return wrapError(topicResults[0].Error, fmt.Sprintf("failed to create topic '%s'", topic.Name))
 		}
 	}
 
@@ -229,32 +404,33 @@ func (kc *KafkaClient) DeleteTopic(ctx context.Context, topicName string) error
 	defer cancel()
 
 	if topicName == "" {
-		return fmt.Errorf("topic name cannot be empty")
+		return InvalidArgumentError("topic name cannot be empty")
 	}
 
-	metadata, err := kc.AdminClient.GetMetadata(&topicName, false, int(kc.Timeout.Milliseconds()))
+	metadata, err := kc.getMetadata(ctx, &topicName, false)
 	if err != nil {
-		return fmt.Errorf("failed to check if topic exists: %w", err)
+		return wrapError(err, "failed to check if topic exists")
 	}
 
 	if _, exists := metadata.Topics[topicName]; !exists {
-		return fmt.Errorf("topic '%s' not found", topicName)
+		return TopicNotFoundError(topicName)
 	}
 
+	span := kc.startCall(ctx, "DeleteTopics", topicAttribute(topicName))
 	topicResults, err := kc.AdminClient.DeleteTopics(
 		ctx,
 		[]string{topicName},
 		kafka.SetAdminOperationTimeout(kc.Timeout),
 	)
+	endSpan(span, err)
 
 	if err != nil {
-		return fmt.Errorf("failed to delete topic: %w", err)
+		return wrapError(err, "failed to delete topic")
 	}
 
 	if len(topicResults) > 0 {
 		if topicResults[0].Error.Code() != kafka.ErrNoError {
-			return fmt.Errorf("failed to delete topic '%s': %s",
-				topicName, topicResults[0].Error.String())
+			return wrapError(topicResults[0].Error, fmt.Sprintf("failed to delete topic '%s'", topicName))
 		}
 	}
 
@@ -267,19 +443,19 @@ func (kc *KafkaClient) UpdateTopicConfig(ctx context.Context, topicName string,
 	defer cancel()
 
 	if topicName == "" {
-		return fmt.Errorf("topic name cannot be empty")
+		return InvalidArgumentError("topic name cannot be empty")
 	}
 	if len(config) == 0 {
-		return fmt.Errorf("no configuration provided")
+		return InvalidArgumentError("no configuration provided")
 	}
 
-	metadata, err := kc.AdminClient.GetMetadata(&topicName, false, int(kc.Timeout.Milliseconds()))
+	metadata, err := kc.getMetadata(ctx, &topicName, false)
 	if err != nil {
-		return fmt.Errorf("failed to check if topic exists: %w", err)
+		return wrapError(err, "failed to check if topic exists")
 	}
 
 	if _, exists := metadata.Topics[topicName]; !exists {
-		return fmt.Errorf("topic '%s' not found", topicName)
+		return TopicNotFoundError(topicName)
 	}
 
 	configEntries := make([]kafka.ConfigEntry, 0, len(config))
@@ -296,40 +472,206 @@ func (kc *KafkaClient) UpdateTopicConfig(ctx context.Context, topicName string,
 		Config: configEntries,
 	}
 
+	span := kc.startCall(ctx, "AlterConfigs", topicAttribute(topicName))
 	result, err := kc.AdminClient.AlterConfigs(
 		ctx,
 		[]kafka.ConfigResource{configResource},
 	)
+	endSpan(span, err)
 
 	if err != nil {
-		return fmt.Errorf("failed to update topic configuration: %w", err)
+		return wrapError(err, "failed to update topic configuration")
 	}
 
 	if len(result) > 0 {
 		if result[0].Error.Code() != kafka.ErrNoError {
-			return fmt.Errorf("failed to update topic configuration: %s", result[0].Error.String())
+			return wrapError(result[0].Error, "failed to update topic configuration")
 		}
 	}
 
 	return nil
 }
 
-// ListConsumerGroups retrieves a list of all consumer groups in the Kafka cluster
-func (kc *KafkaClient) ListConsumerGroups(ctx context.Context) ([]domain.ConsumerGroupInfo, error) {
+// DeleteTopics deletes topics with a single DeleteTopics request and reports the
+// outcome for every topic
+func (kc *KafkaClient) DeleteTopics(ctx context.Context, topicNames []string) ([]domain.BulkTopicResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, kc.Timeout)
+	defer cancel()
+
+	if len(topicNames) == 0 {
+		return nil, InvalidArgumentError("no topics provided")
+	}
+
+	span := kc.startCall(ctx, "DeleteTopics", topicsAttribute(topicNames))
+	topicResults, err := kc.AdminClient.DeleteTopics(ctx, topicNames, kafka.SetAdminOperationTimeout(kc.Timeout))
+	endSpan(span, err)
+	if err != nil {
+		return nil, wrapError(err, "failed to delete topics")
+	}
+	return bulkResults(topicNames, topicResults, "failed to delete topic"), nil
+}
+
+// AlterTopicsConfig sets and removes configuration overrides of topics with a single
+// IncrementalAlterConfigs request, leaving their other overrides in place
+func (kc *KafkaClient) AlterTopicsConfig(ctx context.Context, topicNames []string, set map[string]string, deleted []string) ([]domain.BulkTopicResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, kc.Timeout)
+	defer cancel()
+
+	if len(topicNames) == 0 {
+		return nil, InvalidArgumentError("no topics provided")
+	}
+	if len(set) == 0 && len(deleted) == 0 {
+		return nil, InvalidArgumentError("no configuration provided")
+	}
+
+	entries := make([]kafka.ConfigEntry, 0, len(set)+len(deleted))
+	for key, value := range set {
+		entries = append(entries, kafka.ConfigEntry{Name: key, Value: value, IncrementalOperation: kafka.AlterConfigOpTypeSet})
+	}
+	for _, key := range deleted {
+		entries = append(entries, kafka.ConfigEntry{Name: key, IncrementalOperation: kafka.AlterConfigOpTypeDelete})
+	}
+	resources := make([]kafka.ConfigResource, 0, len(topicNames))
+	for _, name := range topicNames {
+		resources = append(resources, kafka.ConfigResource{Type: kafka.ResourceTopic, Name: name, Config: entries})
+	}
+
+	span := kc.startCall(ctx, "IncrementalAlterConfigs", topicsAttribute(topicNames))
+	configResults, err := kc.AdminClient.IncrementalAlterConfigs(ctx, resources)
+	endSpan(span, err)
+	if err != nil {
+		return nil, wrapError(err, "failed to update topic configurations")
+	}
+
+	topicResults := make([]kafka.TopicResult, 0, len(configResults))
+	for _, result := range configResults {
+		topicResults = append(topicResults, kafka.TopicResult{Topic: result.Name, Error: result.Error})
+	}
+	return bulkResults(topicNames, topicResults, "failed to update the configuration of topic"), nil
+}
+
+// CreatePartitions increases the number of partitions of topics with a single
+// CreatePartitions request
+func (kc *KafkaClient) CreatePartitions(ctx context.Context, topicNames []string, partitions int32) ([]domain.BulkTopicResult, error) {
 	ctx, cancel := context.WithTimeout(ctx, kc.Timeout)
 	defer cancel()
 
-	groupList, err := kc.AdminClient.ListConsumerGroups(ctx)
+	if len(topicNames) == 0 {
+		return nil, InvalidArgumentError("no topics provided")
+	}
+	if partitions <= 0 {
+		return nil, InvalidArgumentError("number of partitions must be greater than 0")
+	}
+
+	specs := make([]kafka.PartitionsSpecification, 0, len(topicNames))
+	for _, name := range topicNames {
+		specs = append(specs, kafka.PartitionsSpecification{Topic: name, IncreaseTo: int(partitions)})
+	}
+
+	span := kc.startCall(ctx, "CreatePartitions", topicsAttribute(topicNames))
+	topicResults, err := kc.AdminClient.CreatePartitions(ctx, specs, kafka.SetAdminOperationTimeout(kc.Timeout))
+	endSpan(span, err)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list consumer groups: %w", err)
+		return nil, wrapError(err, "failed to create partitions")
 	}
+	return bulkResults(topicNames, topicResults, "failed to create partitions of topic"), nil
+}
 
-	groups := make([]domain.ConsumerGroupInfo, 0)
+// bulkResults reports the outcome of a bulk operation for every topic from the results
+// of its request. message prefixes the errors, followed by the topic name.
+func bulkResults(topicNames []string, topicResults []kafka.TopicResult, message string) []domain.BulkTopicResult {
+	errs := make(map[string]kafka.Error, len(topicResults))
+	for _, result := range topicResults {
+		errs[result.Topic] = result.Error
+	}
+
+	results := make([]domain.BulkTopicResult, 0, len(topicNames))
+	for _, name := range topicNames {
+		result := domain.BulkTopicResult{Topic: name}
+		err, found := errs[name]
+		switch {
+		case !found:
+			result.Error = "the cluster did not report on this topic"
+		case err.Code() != kafka.ErrNoError:
+			result.Error = wrapError(err, fmt.Sprintf("%s '%s'", message, name)).Error()
+		default:
+			result.Applied = true
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// ListConsumerGroups retrieves the consumer groups of the Kafka cluster with their
+// state, type, assignor, member count and coordinator. With states, only the groups
+// in one of them are listed.
+func (kc *KafkaClient) ListConsumerGroups(ctx context.Context, states ...string) ([]domain.ConsumerGroupInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, kc.Timeout)
+	defer cancel()
+
+	states, err := NormalizeGroupStates(states)
+	if err != nil {
+		return nil, err
+	}
+	var options []kafka.ListConsumerGroupsAdminOption
+	if len(states) > 0 {
+		matched := make([]kafka.ConsumerGroupState, 0, len(states))
+		for _, state := range states {
+			matched = append(matched, groupStates[state])
+		}
+		options = append(options, kafka.SetAdminMatchConsumerGroupStates(matched))
+	}
+
+	span := kc.startCall(ctx, "ListConsumerGroups")
+	groupList, err := kc.AdminClient.ListConsumerGroups(ctx, options...)
+	endSpan(span, err)
+	if err != nil {
+		return nil, wrapError(err, "failed to list consumer groups")
+	}
+
+	groups := make([]domain.ConsumerGroupInfo, 0, len(groupList.Valid))
+	groupIDs := make([]string, 0, len(groupList.Valid))
 	for _, group := range groupList.Valid {
 		groups = append(groups, domain.ConsumerGroupInfo{
 			GroupID: group.GroupID,
-			State:   "", // Basic listing doesn't include state
+			State:   groupStateName(group.State),
+			Type:    groupTypeName(group.Type),
+			Simple:  group.IsSimpleConsumerGroup,
 		})
+		groupIDs = append(groupIDs, group.GroupID)
+	}
+
+	// The listing has no members nor assignors: describe every group in one request
+	if len(groupIDs) > 0 {
+		span := kc.startCall(ctx, "DescribeConsumerGroups")
+		result, err := kc.AdminClient.DescribeConsumerGroups(ctx, groupIDs)
+		endSpan(span, err)
+		if err != nil {
+			return nil, wrapError(err, "failed to describe consumer groups")
+		}
+
+		descriptions := make(map[string]kafka.ConsumerGroupDescription, len(result.ConsumerGroupDescriptions))
+		for _, description := range result.ConsumerGroupDescriptions {
+			if description.Error.Code() == kafka.ErrNoError {
+				descriptions[description.GroupID] = description
+			}
+		}
+		for i := range groups {
+			description, ok := descriptions[groups[i].GroupID]
+			if !ok {
+				continue
+			}
+			groups[i].State = groupStateName(description.State)
+			groups[i].PartitionAssignor = description.PartitionAssignor
+			groups[i].MemberCount = len(description.Members)
+			if description.Coordinator.ID >= 0 {
+				groups[i].Coordinator = &domain.BrokerInfo{
+					ID:   int32(description.Coordinator.ID),
+					Host: description.Coordinator.Host,
+					Port: description.Coordinator.Port,
+				}
+			}
+		}
 	}
 
 	sort.Slice(groups, func(i, j int) bool {
@@ -339,34 +681,37 @@ func (kc *KafkaClient) ListConsumerGroups(ctx context.Context) ([]domain.Consume
 	return groups, nil
 }
 
-// GetConsumerGroupDetails retrieves detailed information about a specific consumer group
+// GetConsumerGroupDetails retrieves detailed information about a specific consumer group.
+// Its topics are those assigned to its members or with committed offsets.
 func (kc *KafkaClient) GetConsumerGroupDetails(ctx context.Context, groupID string) (*domain.ConsumerGroupDetails, error) {
 	ctx, cancel := context.WithTimeout(ctx, kc.Timeout)
 	defer cancel()
 
 	if groupID == "" {
-		return nil, fmt.Errorf("consumer group ID cannot be empty")
+		return nil, InvalidArgumentError("consumer group ID cannot be empty")
 	}
 
+	span := kc.startCall(ctx, "DescribeConsumerGroups", groupAttribute(groupID))
 	groups, err := kc.AdminClient.DescribeConsumerGroups(
 		ctx,
 		[]string{groupID},
 		// Using context timeout instead of operation-specific timeout
 	)
+	endSpan(span, err)
 	if err != nil {
-		return nil, fmt.Errorf("failed to describe consumer group: %w", err)
+		return nil, wrapError(err, "failed to describe consumer group")
 	}
 
 	if len(groups.ConsumerGroupDescriptions) == 0 {
-		return nil, fmt.Errorf("consumer group '%s' not found", groupID)
+		return nil, GroupNotFoundError(groupID)
 	}
 
 	group := groups.ConsumerGroupDescriptions[0]
 	if group.Error.Code() != kafka.ErrNoError {
 		if group.Error.Code() == kafka.ErrGroupIDNotFound {
-			return nil, fmt.Errorf("consumer group '%s' not found", groupID)
+			return nil, GroupNotFoundError(groupID)
 		}
-		return nil, fmt.Errorf("failed to get consumer group details: %s", group.Error.String())
+		return nil, wrapError(group.Error, "failed to get consumer group details")
 	}
 
 	members := make([]domain.ConsumerGroupMemberInfo, 0, len(group.Members))
@@ -380,16 +725,27 @@ func (kc *KafkaClient) GetConsumerGroupDetails(ctx context.Context, groupID stri
 				Topic:     *assignment.Topic,
 				Partition: assignment.Partition,
 			})
+			subscribedTopics[*assignment.Topic] = true
 		}
 
 		members = append(members, domain.ConsumerGroupMemberInfo{
-			ClientID:    member.ClientID,
-			ConsumerID:  member.ConsumerID,
-			Host:        member.Host,
-			Assignments: assignments,
+			ClientID:        member.ClientID,
+			ConsumerID:      member.ConsumerID,
+			GroupInstanceID: member.GroupInstanceID,
+			Host:            member.Host,
+			Assignments:     assignments,
 		})
 	}
 
+	// Groups also relate to the topics they committed offsets for while not consuming them
+	committed, err := kc.committedOffsets(ctx, groupID, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, tp := range committed {
+		subscribedTopics[*tp.Topic] = true
+	}
+
 	topics := make([]string, 0, len(subscribedTopics))
 	for topic := range subscribedTopics {
 		topics = append(topics, topic)
@@ -402,18 +758,35 @@ func (kc *KafkaClient) GetConsumerGroupDetails(ctx context.Context, groupID stri
 		Port: group.Coordinator.Port,
 	}
 
+	// The description of a group has no type: take it from the listing, when the
+	// brokers report it
+	groupType := ""
+	span = kc.startCall(ctx, "ListConsumerGroups", groupAttribute(groupID))
+	listing, err := kc.AdminClient.ListConsumerGroups(ctx, kafka.SetAdminMatchConsumerGroupStates([]kafka.ConsumerGroupState{group.State}))
+	endSpan(span, err)
+	if err == nil {
+		for _, listed := range listing.Valid {
+			if listed.GroupID == groupID {
+				groupType = groupTypeName(listed.Type)
+			}
+		}
+	}
+
 	groupInfo := &domain.ConsumerGroupDetails{
-		GroupID:     groupID,
-		State:       string(rune(group.State)),
-		Coordinator: coordinator,
-		Members:     members,
-		Topics:      topics,
+		GroupID:           groupID,
+		State:             groupStateName(group.State),
+		Type:              groupType,
+		PartitionAssignor: group.PartitionAssignor,
+		Simple:            group.IsSimpleConsumerGroup,
+		MemberCount:       len(members),
+		Coordinator:       coordinator,
+		Members:           members,
+		Topics:            topics,
 	}
 
 	return groupInfo, nil
 }
 
-// GetTopicMessages retrieves messages from a specified topic and partition
 // GetTopicMessages retrieves messages from a specified topic and partition
 func (kc *KafkaClient) GetTopicMessages(ctx context.Context, topicName string, partition int32, offset int64, limit int) ([]domain.TopicMessage, error) {
 	// Create a context with extended timeout for this operation specifically
@@ -421,18 +794,18 @@ func (kc *KafkaClient) GetTopicMessages(ctx context.Context, topicName string, p
 	defer cancel()
 
 	if topicName == "" {
-		return nil, fmt.Errorf("topic name cannot be empty")
+		return nil, InvalidArgumentError("topic name cannot be empty")
 	}
 
 	// Validate topic exists
-	metadata, err := kc.AdminClient.GetMetadata(&topicName, false, int(kc.Timeout.Milliseconds()))
+	metadata, err := kc.getMetadata(ctx, &topicName, false)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check if topic exists: %w", err)
+		return nil, wrapError(err, "failed to check if topic exists")
 	}
 
 	topicMetadata, exists := metadata.Topics[topicName]
 	if !exists {
-		return nil, fmt.Errorf("topic '%s' not found", topicName)
+		return nil, TopicNotFoundError(topicName)
 	}
 
 	// Validate partition exists
@@ -444,7 +817,7 @@ func (kc *KafkaClient) GetTopicMessages(ctx context.Context, topicName string, p
 		}
 	}
 	if !partitionExists && partition != -1 {
-		return nil, fmt.Errorf("partition %d does not exist for topic '%s'", partition, topicName)
+		return nil, PartitionNotFoundError(partition, topicName)
 	}
 
 	// For "latest" offset, first get the current high watermark to use as starting point
@@ -452,7 +825,7 @@ func (kc *KafkaClient) GetTopicMessages(ctx context.Context, topicName string, p
 		// We'll use the admin client to check topic offsets first
 		offsets, err := kc.getPartitionOffsets(ctx, topicName, partition)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get offset information: %w", err)
+			return nil, wrapError(err, "failed to get offset information")
 		}
 
 		// If partition is empty, return empty results immediately
@@ -479,28 +852,13 @@ func (kc *KafkaClient) GetTopicMessages(ctx context.Context, topicName string, p
 		offset = startOffset
 	}
 
-	// Create a consumer configuration with more robust settings
-	config := &kafka.ConfigMap{
-		"bootstrap.servers":         strings.Join(kc.Brokers, ","),
-		"group.id":                  "maestro-message-reader-" + uuid.New().String(),
-		"auto.offset.reset":         "earliest", // Use earliest as the default
-		"enable.auto.commit":        false,
-		"socket.keepalive.enable":   true,
-		"session.timeout.ms":        10000,   // 10 seconds
-		"max.poll.interval.ms":      30000,   // 30 seconds
-		"socket.timeout.ms":         10000,   // 10 seconds
-		"message.max.bytes":         1048576, // 1MB
-		"fetch.max.bytes":           5242880, // 5MB (must be >= message.max.bytes)
-		"receive.message.max.bytes": 5243392, // 5MB + 512 (must be >= fetch.max.bytes + 512)
-	}
-
-	consumer, err := kafka.NewConsumer(config)
+	consumer, err := kc.newMessageReader(false)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Kafka consumer: %w", err)
+		return nil, err
 	}
 	defer func() {
 		if err := consumer.Close(); err != nil {
-			fmt.Printf("Error closing Kafka consumer: %v", err)
+			kc.Logger.WarnContext(ctx, "Failed to close Kafka consumer", "error", err)
 		}
 	}()
 
@@ -513,9 +871,18 @@ func (kc *KafkaClient) GetTopicMessages(ctx context.Context, topicName string, p
 		},
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to assign partition: %w", err)
+		return nil, wrapError(err, "failed to assign partition")
 	}
 
+	span := kc.startCall(ctx, "Fetch", topicAttribute(topicName), partitionAttribute(partition), leaderAttribute(topicMetadata, partition))
+	messages, err := kc.pollMessages(ctx, consumer, topicName, partition, limit)
+	span.SetAttributes(attribute.Int("messaging.batch.message_count", len(messages)))
+	endSpan(span, err)
+	return messages, err
+}
+
+// pollMessages reads up to limit messages from the partition assigned to consumer
+func (kc *KafkaClient) pollMessages(ctx context.Context, consumer *kafka.Consumer, topicName string, partition int32, limit int) ([]domain.TopicMessage, error) {
 	messages := make([]domain.TopicMessage, 0, limit)
 	deadline := time.Now().Add(kc.Timeout)
 	messageCount := 0
@@ -542,33 +909,7 @@ func (kc *KafkaClient) GetTopicMessages(ctx context.Context, topicName string, p
 
 			switch e := ev.(type) {
 			case *kafka.Message:
-				var messageKey, messageValue string
-
-				// Safely handle message key and value
-				if e.Key != nil {
-					messageKey = string(e.Key)
-				}
-
-				if e.Value != nil {
-					messageValue = string(e.Value)
-				}
-
-				message := domain.TopicMessage{
-					Topic:     *e.TopicPartition.Topic,
-					Partition: e.TopicPartition.Partition,
-					Offset:    int64(e.TopicPartition.Offset),
-					Timestamp: e.Timestamp,
-					Key:       messageKey,
-					Value:     messageValue,
-					Headers:   make(map[string]string),
-				}
-
-				// Extract headers if any
-				for _, header := range e.Headers {
-					message.Headers[header.Key] = string(header.Value)
-				}
-
-				messages = append(messages, message)
+				messages = append(messages, toTopicMessage(e))
 				messageCount++
 
 				if messageCount >= limit {
@@ -581,11 +922,11 @@ func (kc *KafkaClient) GetTopicMessages(ctx context.Context, topicName string, p
 					kafkaErr == kafka.ErrTransport ||
 					kafkaErr == kafka.ErrBrokerNotAvailable {
 					// Log but continue
-					fmt.Printf("Recoverable Kafka error: %v\n", e)
+					kc.Logger.WarnContext(ctx, "Recoverable Kafka error", "topic", topicName, "partition", partition, "error", e)
 					continue
 				}
 
-				return messages, fmt.Errorf("consumer error: %v", e)
+				return messages, wrapError(e, "consumer error")
 			}
 		}
 	}
@@ -594,6 +935,63 @@ func (kc *KafkaClient) GetTopicMessages(ctx context.Context, topicName string, p
 	return messages, nil
 }
 
+// newMessageReader creates a consumer with a throwaway group ID for reading messages
+// through manual partition assignment. When partitionEOF is set, the consumer emits
+// a kafka.PartitionEOF event each time it reaches the end of a partition.
+func (kc *KafkaClient) newMessageReader(partitionEOF bool) (*kafka.Consumer, error) {
+	// Create a consumer configuration with more robust settings
+	config := kc.configMap(kafka.ConfigMap{
+		"group.id":                  "maestro-message-reader-" + uuid.New().String(),
+		"auto.offset.reset":         "earliest", // Use earliest as the default
+		"enable.auto.commit":        false,
+		"enable.partition.eof":      partitionEOF,
+		"socket.keepalive.enable":   true,
+		"session.timeout.ms":        10000,   // 10 seconds
+		"max.poll.interval.ms":      30000,   // 30 seconds
+		"socket.timeout.ms":         10000,   // 10 seconds
+		"message.max.bytes":         1048576, // 1MB
+		"fetch.max.bytes":           5242880, // 5MB (must be >= message.max.bytes)
+		"receive.message.max.bytes": 5243392, // 5MB + 512 (must be >= fetch.max.bytes + 512)
+	})
+
+	consumer, err := kafka.NewConsumer(config)
+	if err != nil {
+		return nil, wrapError(err, "failed to create Kafka consumer")
+	}
+	return consumer, nil
+}
+
+// toTopicMessage converts a consumed Kafka message into its domain representation
+func toTopicMessage(e *kafka.Message) domain.TopicMessage {
+	var messageKey, messageValue string
+
+	// Safely handle message key and value
+	if e.Key != nil {
+		messageKey = string(e.Key)
+	}
+
+	if e.Value != nil {
+		messageValue = string(e.Value)
+	}
+
+	message := domain.TopicMessage{
+		Topic:     *e.TopicPartition.Topic,
+		Partition: e.TopicPartition.Partition,
+		Offset:    int64(e.TopicPartition.Offset),
+		Timestamp: e.Timestamp,
+		Key:       messageKey,
+		Value:     messageValue,
+		Headers:   make(map[string]string),
+	}
+
+	// Extract headers if any
+	for _, header := range e.Headers {
+		message.Headers[header.Key] = string(header.Value)
+	}
+
+	return message
+}
+
 // Helper method to get partition offsets without creating a consumer
 type partitionOffsets struct {
 	low  int64
@@ -604,21 +1002,20 @@ func (kc *KafkaClient) getPartitionOffsets(ctx context.Context, topicName string
 	result := partitionOffsets{}
 
 	// Create a lightweight consumer just to get offsets
-	config := &kafka.ConfigMap{
-		"bootstrap.servers": strings.Join(kc.Brokers, ","),
-		"group.id":          "maestro-offset-checker",
-	}
+	config := kc.configMap(kafka.ConfigMap{
+		"group.id": "maestro-offset-checker",
+	})
 
 	c, err := kafka.NewConsumer(config)
 	if err != nil {
-		return result, fmt.Errorf("failed to create offset checker consumer: %w", err)
+		return result, wrapError(err, "failed to create offset checker consumer")
 	}
 	defer c.Close()
 
 	// Get low and high watermarks
 	low, high, err := c.GetWatermarkOffsets(topicName, partition)
 	if err != nil {
-		return result, fmt.Errorf("failed to get watermark offsets: %w", err)
+		return result, wrapError(err, "failed to get watermark offsets")
 	}
 
 	result.low = low
@@ -632,18 +1029,18 @@ func (kc *KafkaClient) PublishMessage(ctx context.Context, topicName string, par
 	defer cancel()
 
 	if topicName == "" {
-		return fmt.Errorf("topic name cannot be empty")
+		return InvalidArgumentError("topic name cannot be empty")
 	}
 
 	// Validate topic exists
-	metadata, err := kc.AdminClient.GetMetadata(&topicName, false, int(kc.Timeout.Milliseconds()))
+	metadata, err := kc.getMetadata(ctx, &topicName, false)
 	if err != nil {
-		return fmt.Errorf("failed to check if topic exists: %w", err)
+		return wrapError(err, "failed to check if topic exists")
 	}
 
 	topicMetadata, exists := metadata.Topics[topicName]
 	if !exists {
-		return fmt.Errorf("topic '%s' not found", topicName)
+		return TopicNotFoundError(topicName)
 	}
 
 	// Validate partition exists if specified
@@ -656,35 +1053,22 @@ func (kc *KafkaClient) PublishMessage(ctx context.Context, topicName string, par
 			}
 		}
 		if !partitionExists {
-			return fmt.Errorf("partition %d does not exist for topic '%s'", partition, topicName)
+			return PartitionNotFoundError(partition, topicName)
 		}
 	}
 
 	// Create producer
-	config := &kafka.ConfigMap{
-		"bootstrap.servers": strings.Join(kc.Brokers, ","),
-		"group.id":          "maestro-message-producer",
-		"acks":              "all", // Wait for all replicas
-	}
+	config := kc.configMap(kafka.ConfigMap{
+		"group.id": "maestro-message-producer",
+		"acks":     "all", // Wait for all replicas
+	})
 
 	producer, err := kafka.NewProducer(config)
 	if err != nil {
-		return fmt.Errorf("failed to create Kafka producer: %w", err)
+		return wrapError(err, "failed to create Kafka producer")
 	}
 	defer producer.Close()
 
-	// Create message headers if any
-	var kafkaHeaders []kafka.Header
-	if len(headers) > 0 {
-		kafkaHeaders = make([]kafka.Header, 0, len(headers))
-		for k, v := range headers {
-			kafkaHeaders = append(kafkaHeaders, kafka.Header{
-				Key:   k,
-				Value: []byte(v),
-			})
-		}
-	}
-
 	// Prepare the message
 	message := &kafka.Message{
 		TopicPartition: kafka.TopicPartition{
@@ -693,7 +1077,7 @@ func (kc *KafkaClient) PublishMessage(ctx context.Context, topicName string, par
 		},
 		Key:     []byte(key),
 		Value:   []byte(value),
-		Headers: kafkaHeaders,
+		Headers: toKafkaHeaders(headers),
 	}
 
 	// Set up delivery channel to receive delivery reports
@@ -701,9 +1085,17 @@ func (kc *KafkaClient) PublishMessage(ctx context.Context, topicName string, par
 	defer close(deliveryChan)
 
 	// Produce the message
-	err = producer.Produce(message, deliveryChan)
+	span := kc.startCall(ctx, "Produce", topicAttribute(topicName), partitionAttribute(partition), leaderAttribute(topicMetadata, partition))
+	err = produceMessage(ctx, producer, message, deliveryChan)
+	endSpan(span, err)
+	return err
+}
+
+// produceMessage produces a message and waits for its delivery report
+func produceMessage(ctx context.Context, producer *kafka.Producer, message *kafka.Message, deliveryChan chan kafka.Event) error {
+	err := producer.Produce(message, deliveryChan)
 	if err != nil {
-		return fmt.Errorf("failed to produce message: %w", err)
+		return wrapError(err, "failed to produce message")
 	}
 
 	// Wait for delivery report or context cancellation
@@ -713,9 +1105,174 @@ func (kc *KafkaClient) PublishMessage(ctx context.Context, topicName string, par
 	case e := <-deliveryChan:
 		m := e.(*kafka.Message)
 		if m.TopicPartition.Error != nil {
-			return fmt.Errorf("message delivery failed: %v", m.TopicPartition.Error)
+			return wrapError(m.TopicPartition.Error, "message delivery failed")
 		}
 		// Return the partition and offset where the message was stored
 		return nil
 	}
 }
+
+// PublishMessages publishes a batch of records to a specified Kafka topic and
+// reports the delivery outcome of every record. A failure of an individual
+// record does not abort the batch; only errors that prevent publishing
+// altogether are returned as an error.
+//
+// ratePerSecond caps the number of records handed to the producer per second;
+// zero or a negative value disables rate limiting.
+func (kc *KafkaClient) PublishMessages(ctx context.Context, topicName string, records []domain.ProduceRecord, ratePerSecond int) ([]domain.ProduceResult, error) {
+	if topicName == "" {
+		return nil, InvalidArgumentError("topic name cannot be empty")
+	}
+
+	// Validate topic exists
+	metadata, err := kc.getMetadata(ctx, &topicName, false)
+	if err != nil {
+		return nil, wrapError(err, "failed to check if topic exists")
+	}
+
+	topicMetadata, exists := metadata.Topics[topicName]
+	if !exists {
+		return nil, TopicNotFoundError(topicName)
+	}
+
+	validPartitions := make(map[int32]bool, len(topicMetadata.Partitions))
+	for _, partition := range topicMetadata.Partitions {
+		validPartitions[partition.ID] = true
+	}
+
+	config := kc.configMap(kafka.ConfigMap{
+		"acks":      "all", // Wait for all replicas
+		"linger.ms": 5,     // Allow small batches to form on the wire
+	})
+
+	producer, err := kafka.NewProducer(config)
+	if err != nil {
+		return nil, wrapError(err, "failed to create Kafka producer")
+	}
+	defer producer.Close()
+
+	results := make([]domain.ProduceResult, len(records))
+	for i := range results {
+		results[i] = domain.ProduceResult{Index: i, Partition: -1, Offset: -1}
+	}
+
+	var throttle <-chan time.Time
+	if ratePerSecond > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(ratePerSecond))
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+
+	// The delivery channel is sized for the whole batch so that delivery
+	// reports never block the producer while we are still producing.
+	deliveryChan := make(chan kafka.Event, len(records))
+	pending := 0
+
+produceLoop:
+	for i, record := range records {
+		partition := kafka.PartitionAny
+		if record.Partition != nil && *record.Partition >= 0 {
+			if !validPartitions[*record.Partition] {
+				results[i].Error = PartitionNotFoundError(*record.Partition, topicName).Error()
+				continue
+			}
+			partition = *record.Partition
+		}
+
+		if throttle != nil && i > 0 {
+			select {
+			case <-ctx.Done():
+				break produceLoop
+			case <-throttle:
+			}
+		}
+
+		message := &kafka.Message{
+			TopicPartition: kafka.TopicPartition{
+				Topic:     &topicName,
+				Partition: partition,
+			},
+			Value:   []byte(record.Value),
+			Headers: toKafkaHeaders(record.Headers),
+			Opaque:  i,
+		}
+		if record.Key != "" {
+			message.Key = []byte(record.Key)
+		}
+		if record.Timestamp != nil {
+			message.Timestamp = *record.Timestamp
+		}
+
+		for {
+			if ctx.Err() != nil {
+				break produceLoop
+			}
+			err = producer.Produce(message, deliveryChan)
+			if err == nil {
+				pending++
+				break
+			}
+			// Give the producer a chance to drain its queue before retrying
+			if kafkaErr, ok := err.(kafka.Error); ok && kafkaErr.Code() == kafka.ErrQueueFull {
+				producer.Flush(100)
+				continue
+			}
+			results[i].Error = fmt.Sprintf("failed to produce message: %v", err)
+			break
+		}
+	}
+
+	for pending > 0 {
+		select {
+		case <-ctx.Done():
+			markUndelivered(results, ctx.Err())
+			return results, nil
+		case e := <-deliveryChan:
+			m, ok := e.(*kafka.Message)
+			if !ok {
+				continue
+			}
+			pending--
+			index := m.Opaque.(int)
+			if m.TopicPartition.Error != nil {
+				results[index].Error = fmt.Sprintf("message delivery failed: %v", m.TopicPartition.Error)
+				continue
+			}
+			results[index].Partition = m.TopicPartition.Partition
+			results[index].Offset = int64(m.TopicPartition.Offset)
+		}
+	}
+
+	// Records skipped because the context ended while producing
+	markUndelivered(results, ctx.Err())
+
+	return results, nil
+}
+
+// toKafkaHeaders converts a header map into Kafka message headers
+func toKafkaHeaders(headers map[string]string) []kafka.Header {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	kafkaHeaders := make([]kafka.Header, 0, len(headers))
+	for k, v := range headers {
+		kafkaHeaders = append(kafkaHeaders, kafka.Header{
+			Key:   k,
+			Value: []byte(v),
+		})
+	}
+	return kafkaHeaders
+}
+
+// markUndelivered flags every record without a delivery report or error as failed
+func markUndelivered(results []domain.ProduceResult, cause error) {
+	if cause == nil {
+		return
+	}
+	for i := range results {
+		if results[i].Offset < 0 && results[i].Error == "" {
+			results[i].Error = fmt.Sprintf("message not delivered: %v", cause)
+		}
+	}
+}
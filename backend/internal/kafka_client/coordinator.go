@@ -0,0 +1,125 @@
+package kafka_client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+	"github.com/twmb/franz-go/pkg/sasl/scram"
+)
+
+// coordinatorClient returns the client sending the requests that librdkafka does not
+// support: OffsetDelete and the removal of static members with LeaveGroup, sent to
+// group coordinators, and DescribeProducers, sent to partition leaders. It is created
+// on first use from the brokers and security properties of the client.
+func (kc *KafkaClient) coordinatorClient() (*kgo.Client, error) {
+	kc.coordinatorOnce.Do(func() {
+		opts, err := coordinatorOptions(kc.Brokers, kc.Properties)
+		if err != nil {
+			kc.coordinatorErr = err
+			return
+		}
+		kc.coordinator, kc.coordinatorErr = kgo.NewClient(opts...)
+		if kc.coordinatorErr != nil {
+			kc.coordinatorErr = InvalidArgumentError("failed to create group coordinator client: %v", kc.coordinatorErr)
+		}
+	})
+	return kc.coordinator, kc.coordinatorErr
+}
+
+// coordinatorOptions translates the librdkafka properties Maestro sets for the security
+// of a cluster into options of a franz-go client
+func coordinatorOptions(brokers []string, props map[string]string) ([]kgo.Opt, error) {
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(brokers...),
+		kgo.ClientID("maestro-client"),
+	}
+
+	protocol := strings.ToUpper(props["security.protocol"])
+	switch protocol {
+	case "", "PLAINTEXT", "SASL_PLAINTEXT":
+	case "SSL", "SASL_SSL":
+		tlsConfig, err := coordinatorTLS(props)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, kgo.DialTLSConfig(tlsConfig))
+	default:
+		return nil, InvalidArgumentError("unsupported security.protocol %q", props["security.protocol"])
+	}
+
+	if strings.HasPrefix(protocol, "SASL_") {
+		mechanism, err := coordinatorSASL(props)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, kgo.SASL(mechanism))
+	}
+	return opts, nil
+}
+
+func coordinatorTLS(props map[string]string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if verify, err := strconv.ParseBool(props["enable.ssl.certificate.verification"]); err == nil && !verify {
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	if caFile := props["ssl.ca.location"]; caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, InvalidArgumentError("failed to read ssl.ca.location: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, InvalidArgumentError("no certificates found in ssl.ca.location %q", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	certFile, keyFile := props["ssl.certificate.location"], props["ssl.key.location"]
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, InvalidArgumentError("failed to load the client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+func coordinatorSASL(props map[string]string) (sasl.Mechanism, error) {
+	user, pass := props["sasl.username"], props["sasl.password"]
+	switch mechanism := strings.ToUpper(props["sasl.mechanism"]); mechanism {
+	case "", "PLAIN":
+		return plain.Auth{User: user, Pass: pass}.AsMechanism(), nil
+	case "SCRAM-SHA-256":
+		return scram.Auth{User: user, Pass: pass}.AsSha256Mechanism(), nil
+	case "SCRAM-SHA-512":
+		return scram.Auth{User: user, Pass: pass}.AsSha512Mechanism(), nil
+	default:
+		return nil, InvalidArgumentError("SASL mechanism %q is not supported for this operation", props["sasl.mechanism"])
+	}
+}
+
+// coordinatorError converts an error code returned by a group coordinator into a
+// kafka.Error, which wrapError classifies like the errors of librdkafka
+func coordinatorError(code int16) error {
+	err := kerr.ErrorForCode(code)
+	if err == nil {
+		return nil
+	}
+	var kerrErr *kerr.Error
+	if errors.As(err, &kerrErr) {
+		return kafka.NewError(kafka.ErrorCode(code), fmt.Sprintf("%s: %s", kerrErr.Message, kerrErr.Description), false)
+	}
+	return err
+}
@@ -0,0 +1,129 @@
+package kafka_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// Kinds of errors returned by Client implementations. Use errors.Is to check the kind of an error.
+var (
+	ErrNotFound          = errors.New("not found")
+	ErrAlreadyExists     = errors.New("already exists")
+	ErrConflict          = errors.New("conflict") // The resource is not in a state that allows the operation
+	ErrInvalidArgument   = errors.New("invalid argument")
+	ErrUnauthorized      = errors.New("unauthorized")
+	ErrTimeout           = errors.New("timeout")
+	ErrBrokerUnavailable = errors.New("broker unavailable")
+)
+
+// Error is an error of a Kafka operation. It matches its kind, one of the sentinel
+// errors above, with errors.Is and keeps the Kafka error code it originates from.
+type Error struct {
+	Kind error           // Sentinel error of the kind; nil when the error is not classified
+	Code kafka.ErrorCode // Kafka error code; kafka.ErrNoError when there is none
+	err  error
+}
+
+// NewError creates an Error with a message formatted like fmt.Errorf, including the
+// wrapping of an underlying error with %w
+func NewError(kind error, code kafka.ErrorCode, format string, args ...any) *Error {
+	return &Error{Kind: kind, Code: code, err: fmt.Errorf(format, args...)}
+}
+
+// Error implements error
+func (e *Error) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the kind and the underlying error
+func (e *Error) Unwrap() []error {
+	errs := []error{e.err}
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	return errs
+}
+
+// TopicNotFoundError is returned when a topic does not exist
+func TopicNotFoundError(topicName string) error {
+	return NewError(ErrNotFound, kafka.ErrUnknownTopicOrPart, "topic '%s' not found", topicName)
+}
+
+// PartitionNotFoundError is returned when a partition of a topic does not exist
+func PartitionNotFoundError(partition int32, topicName string) error {
+	return NewError(ErrNotFound, kafka.ErrUnknownPartition, "partition %d does not exist for topic '%s'", partition, topicName)
+}
+
+// GroupNotFoundError is returned when a consumer group does not exist
+func GroupNotFoundError(groupID string) error {
+	return NewError(ErrNotFound, kafka.ErrGroupIDNotFound, "consumer group '%s' not found", groupID)
+}
+
+// GroupNotEmptyError is returned when an operation requires a consumer group without active members
+func GroupNotEmptyError(groupID string) error {
+	return NewError(ErrConflict, kafka.ErrNonEmptyGroup, "consumer group '%s' has active members; stop its consumers first", groupID)
+}
+
+// GroupSubscribedError is returned when deleting the offsets of a consumer group on a topic it consumes
+func GroupSubscribedError(groupID, topicName string) error {
+	return NewError(ErrConflict, kafka.ErrGroupSubscribedToTopic, "consumer group '%s' is subscribed to topic '%s'; stop its consumers first", groupID, topicName)
+}
+
+// ClusterNotFoundError is returned when an operation names a cluster that is not configured
+func ClusterNotFoundError(name string) error {
+	return NewError(ErrNotFound, kafka.ErrNoError, "cluster '%s' is not configured", name)
+}
+
+// InvalidArgumentError is returned when an operation is called with an invalid argument
+func InvalidArgumentError(format string, args ...any) error {
+	return NewError(ErrInvalidArgument, kafka.ErrNoError, format, args...)
+}
+
+// wrapError classifies an error returned by the Kafka library by its error code and
+// prefixes its message with message
+func wrapError(err error, message string) error {
+	kind, code := classify(err)
+	return NewError(kind, code, "%s: %w", message, err)
+}
+
+// classify returns the kind and Kafka error code of an error returned by the Kafka library
+func classify(err error) (error, kafka.ErrorCode) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrTimeout, kafka.ErrTimedOut
+	}
+
+	var kafkaErr kafka.Error
+	if !errors.As(err, &kafkaErr) {
+		return nil, kafka.ErrNoError
+	}
+
+	code := kafkaErr.Code()
+	switch code {
+	case kafka.ErrUnknownTopicOrPart, kafka.ErrUnknownTopic, kafka.ErrUnknownPartition,
+		kafka.ErrUnknownTopicID, kafka.ErrGroupIDNotFound, kafka.ErrResourceNotFound:
+		return ErrNotFound, code
+	case kafka.ErrTopicAlreadyExists:
+		return ErrAlreadyExists, code
+	case kafka.ErrNonEmptyGroup, kafka.ErrRebalanceInProgress, kafka.ErrGroupSubscribedToTopic:
+		return ErrConflict, code
+	case kafka.ErrInvalidArg, kafka.ErrInvalidPartitions, kafka.ErrInvalidReplicationFactor,
+		kafka.ErrInvalidReplicaAssignment, kafka.ErrInvalidConfig, kafka.ErrInvalidRequest,
+		kafka.ErrTopicException, kafka.ErrPolicyViolation, kafka.ErrInvalidGroupID,
+		kafka.ErrInvalidMsgSize, kafka.ErrMsgSizeTooLarge, kafka.ErrInvalidTimestamp:
+		return ErrInvalidArgument, code
+	case kafka.ErrTopicAuthorizationFailed, kafka.ErrGroupAuthorizationFailed,
+		kafka.ErrClusterAuthorizationFailed, kafka.ErrTransactionalIDAuthorizationFailed,
+		kafka.ErrSaslAuthenticationFailed, kafka.ErrAuthentication:
+		return ErrUnauthorized, code
+	case kafka.ErrTimedOut, kafka.ErrRequestTimedOut, kafka.ErrTimedOutQueue, kafka.ErrMsgTimedOut:
+		return ErrTimeout, code
+	case kafka.ErrTransport, kafka.ErrAllBrokersDown, kafka.ErrBrokerNotAvailable,
+		kafka.ErrLeaderNotAvailable, kafka.ErrNotLeaderForPartition, kafka.ErrNetworkException,
+		kafka.ErrNotController, kafka.ErrNotEnoughReplicas, kafka.ErrCoordinatorNotAvailable:
+		return ErrBrokerUnavailable, code
+	}
+	return nil, code
+}
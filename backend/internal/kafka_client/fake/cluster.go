@@ -0,0 +1,1014 @@
+// Package fake provides an in-memory Kafka cluster implementing kafka_client.Client.
+// It keeps topics, partitions, records and consumer groups in memory and is meant
+// for handler tests and for running Maestro without a broker (demo mode).
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/valeriouberti/maestro/internal/kafka_client"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// Special offsets accepted by GetTopicMessages, matching the librdkafka logical offsets
+const (
+	OffsetEnd       int64 = -1
+	OffsetBeginning int64 = -2
+)
+
+// internalTopics are the topics Kafka itself uses, reported as internal
+var internalTopics = []string{"__consumer_offsets", "__transaction_state"}
+
+// maxLatestMessages caps the window read when the latest messages are requested
+const maxLatestMessages = 100
+
+// Cluster is an in-memory Kafka cluster. The zero value is not usable; create one with New.
+type Cluster struct {
+	mu      sync.RWMutex
+	brokers []domain.BrokerInfo
+	topics  map[string]*topic
+	groups  map[string]*group
+
+	// Now returns the timestamp given to records produced without one
+	Now func() time.Time
+}
+
+// topic holds the records of every partition, indexed by offset
+type topic struct {
+	replicationFactor int
+	config            map[string]string
+	partitions        [][]domain.TopicMessage
+	next              int32                   // Partition of the next unkeyed record
+	producers         []domain.ActiveProducer // Added with AddActiveProducer
+}
+
+// group holds a consumer group and its committed offsets
+type group struct {
+	state     string
+	groupType string
+	assignor  string
+	simple    bool
+	members   []domain.ConsumerGroupMemberInfo
+	offsets   map[string]map[int32]int64
+}
+
+var _ kafka_client.Client = (*Cluster)(nil)
+
+// New creates an empty cluster of three brokers
+func New() *Cluster {
+	return &Cluster{
+		brokers: []domain.BrokerInfo{
+			{ID: 1, Host: "localhost", Port: 9092},
+			{ID: 2, Host: "localhost", Port: 9093},
+			{ID: 3, Host: "localhost", Port: 9094},
+		},
+		topics: make(map[string]*topic),
+		groups: make(map[string]*group),
+		Now:    time.Now,
+	}
+}
+
+// OperationTimeout implements kafka_client.Client
+func (c *Cluster) OperationTimeout() time.Duration {
+	return 10 * time.Second
+}
+
+// Close implements kafka_client.Client
+func (c *Cluster) Close() {}
+
+// GetBrokers implements kafka_client.Client
+func (c *Cluster) GetBrokers(ctx context.Context) ([]domain.BrokerInfo, error) {
+	return slices.Clone(c.brokers), nil
+}
+
+// ListTopics implements kafka_client.Client
+func (c *Cluster) ListTopics(ctx context.Context) ([]domain.TopicInfo, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	topics := make([]domain.TopicInfo, 0, len(c.topics))
+	for _, name := range slices.Sorted(maps.Keys(c.topics)) {
+		info := c.topicInfo(name)
+		info.Config = nil
+		topics = append(topics, info)
+	}
+	return topics, nil
+}
+
+// GetTopicDetails implements kafka_client.Client
+func (c *Cluster) GetTopicDetails(ctx context.Context, topicName string) (*domain.TopicInfo, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if _, exists := c.topics[topicName]; !exists {
+		return nil, kafka_client.TopicNotFoundError(topicName)
+	}
+	info := c.topicInfo(topicName)
+	return &info, nil
+}
+
+// topicInfo describes a topic, spreading the replicas of its partitions over the brokers.
+// The caller must hold the lock.
+func (c *Cluster) topicInfo(name string) domain.TopicInfo {
+	t := c.topics[name]
+
+	partitions := make([]domain.PartitionInfo, 0, len(t.partitions))
+	for id := range t.partitions {
+		replicas := make([]int32, 0, t.replicationFactor)
+		for i := 0; i < t.replicationFactor; i++ {
+			replicas = append(replicas, c.brokers[(id+i)%len(c.brokers)].ID)
+		}
+		partitions = append(partitions, domain.PartitionInfo{
+			ID:       int32(id),
+			Leader:   replicas[0],
+			Replicas: replicas,
+			ISR:      slices.Clone(replicas),
+		})
+	}
+
+	return domain.TopicInfo{
+		Name:              name,
+		NumPartitions:     int32(len(t.partitions)),
+		ReplicationFactor: t.replicationFactor,
+		Config:            maps.Clone(t.config),
+		Partitions:        partitions,
+		IsInternal:        slices.Contains(internalTopics, name),
+	}
+}
+
+// CreateTopic implements kafka_client.Client
+func (c *Cluster) CreateTopic(ctx context.Context, info domain.TopicInfo) error {
+	if info.Name == "" {
+		return kafka_client.InvalidArgumentError("topic name cannot be empty")
+	}
+	if info.NumPartitions <= 0 {
+		return kafka_client.InvalidArgumentError("number of partitions must be greater than 0")
+	}
+	if info.ReplicationFactor <= 0 {
+		return kafka_client.InvalidArgumentError("replication factor must be greater than 0")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.topics[info.Name]; exists {
+		return kafka_client.NewError(kafka_client.ErrAlreadyExists, kafka.ErrTopicAlreadyExists,
+			"failed to create topic '%s': Topic '%s' already exists.", info.Name, info.Name)
+	}
+	if info.ReplicationFactor > len(c.brokers) {
+		return kafka_client.NewError(kafka_client.ErrInvalidArgument, kafka.ErrInvalidReplicationFactor,
+			"failed to create topic '%s': Replication factor: %d larger than available brokers: %d.",
+			info.Name, info.ReplicationFactor, len(c.brokers))
+	}
+
+	config := maps.Clone(info.Config)
+	if config == nil {
+		config = make(map[string]string)
+	}
+	c.topics[info.Name] = &topic{
+		replicationFactor: info.ReplicationFactor,
+		config:            config,
+		partitions:        make([][]domain.TopicMessage, info.NumPartitions),
+	}
+	return nil
+}
+
+// DeleteTopic implements kafka_client.Client. Committed offsets of consumer groups
+// on the topic are removed as well.
+func (c *Cluster) DeleteTopic(ctx context.Context, topicName string) error {
+	if topicName == "" {
+		return kafka_client.InvalidArgumentError("topic name cannot be empty")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.topics[topicName]; !exists {
+		return kafka_client.TopicNotFoundError(topicName)
+	}
+	delete(c.topics, topicName)
+	for _, g := range c.groups {
+		delete(g.offsets, topicName)
+	}
+	return nil
+}
+
+// UpdateTopicConfig implements kafka_client.Client
+func (c *Cluster) UpdateTopicConfig(ctx context.Context, topicName string, config map[string]string) error {
+	if topicName == "" {
+		return kafka_client.InvalidArgumentError("topic name cannot be empty")
+	}
+	if len(config) == 0 {
+		return kafka_client.InvalidArgumentError("no configuration provided")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, exists := c.topics[topicName]
+	if !exists {
+		return kafka_client.TopicNotFoundError(topicName)
+	}
+	t.config = maps.Clone(config)
+	return nil
+}
+
+// DeleteTopics implements kafka_client.Client
+func (c *Cluster) DeleteTopics(ctx context.Context, topicNames []string) ([]domain.BulkTopicResult, error) {
+	if len(topicNames) == 0 {
+		return nil, kafka_client.InvalidArgumentError("no topics provided")
+	}
+	return bulk(topicNames, func(name string) error { return c.DeleteTopic(ctx, name) }), nil
+}
+
+// AlterTopicsConfig implements kafka_client.Client
+func (c *Cluster) AlterTopicsConfig(ctx context.Context, topicNames []string, set map[string]string, deleted []string) ([]domain.BulkTopicResult, error) {
+	if len(topicNames) == 0 {
+		return nil, kafka_client.InvalidArgumentError("no topics provided")
+	}
+	if len(set) == 0 && len(deleted) == 0 {
+		return nil, kafka_client.InvalidArgumentError("no configuration provided")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return bulk(topicNames, func(name string) error {
+		t, exists := c.topics[name]
+		if !exists {
+			return kafka_client.TopicNotFoundError(name)
+		}
+		maps.Copy(t.config, set)
+		for _, key := range deleted {
+			delete(t.config, key)
+		}
+		return nil
+	}), nil
+}
+
+// CreatePartitions implements kafka_client.Client. Like Kafka, it refuses to decrease
+// the number of partitions.
+func (c *Cluster) CreatePartitions(ctx context.Context, topicNames []string, partitions int32) ([]domain.BulkTopicResult, error) {
+	if len(topicNames) == 0 {
+		return nil, kafka_client.InvalidArgumentError("no topics provided")
+	}
+	if partitions <= 0 {
+		return nil, kafka_client.InvalidArgumentError("number of partitions must be greater than 0")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return bulk(topicNames, func(name string) error {
+		t, exists := c.topics[name]
+		if !exists {
+			return kafka_client.TopicNotFoundError(name)
+		}
+		if current := int32(len(t.partitions)); partitions <= current {
+			return kafka_client.NewError(kafka_client.ErrInvalidArgument, kafka.ErrInvalidPartitions,
+				"failed to create partitions of topic '%s': Topic currently has %d partitions, which is higher than the requested %d.",
+				name, current, partitions)
+		}
+		for len(t.partitions) < int(partitions) {
+			t.partitions = append(t.partitions, nil)
+		}
+		return nil
+	}), nil
+}
+
+// bulk applies an operation to every topic and reports its outcome
+func bulk(topicNames []string, apply func(name string) error) []domain.BulkTopicResult {
+	results := make([]domain.BulkTopicResult, 0, len(topicNames))
+	for _, name := range topicNames {
+		result := domain.BulkTopicResult{Topic: name, Applied: true}
+		if err := apply(name); err != nil {
+			result.Applied = false
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// ListConsumerGroups implements kafka_client.Client
+func (c *Cluster) ListConsumerGroups(ctx context.Context, states ...string) ([]domain.ConsumerGroupInfo, error) {
+	states, err := kafka_client.NormalizeGroupStates(states)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	groups := make([]domain.ConsumerGroupInfo, 0, len(c.groups))
+	for _, id := range slices.Sorted(maps.Keys(c.groups)) {
+		g := c.groups[id]
+		if len(states) > 0 && !slices.Contains(states, g.state) {
+			continue
+		}
+		groups = append(groups, domain.ConsumerGroupInfo{
+			GroupID:           id,
+			State:             g.state,
+			Type:              g.groupType,
+			PartitionAssignor: g.assignor,
+			Simple:            g.simple,
+			MemberCount:       len(g.members),
+			Coordinator:       &c.brokers[0],
+		})
+	}
+	return groups, nil
+}
+
+// GetConsumerGroupDetails implements kafka_client.Client. The topics of a group are
+// those assigned to its members or with committed offsets.
+func (c *Cluster) GetConsumerGroupDetails(ctx context.Context, groupID string) (*domain.ConsumerGroupDetails, error) {
+	if groupID == "" {
+		return nil, kafka_client.InvalidArgumentError("consumer group ID cannot be empty")
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	g, exists := c.groups[groupID]
+	if !exists {
+		return nil, kafka_client.GroupNotFoundError(groupID)
+	}
+
+	topics := make(map[string]bool)
+	members := make([]domain.ConsumerGroupMemberInfo, 0, len(g.members))
+	for _, member := range g.members {
+		member.Assignments = slices.Clone(member.Assignments)
+		for _, assignment := range member.Assignments {
+			topics[assignment.Topic] = true
+		}
+		members = append(members, member)
+	}
+	for topicName := range g.offsets {
+		topics[topicName] = true
+	}
+
+	return &domain.ConsumerGroupDetails{
+		GroupID:           groupID,
+		State:             g.state,
+		Type:              g.groupType,
+		PartitionAssignor: g.assignor,
+		Simple:            g.simple,
+		MemberCount:       len(members),
+		Coordinator:       c.brokers[0],
+		Members:           members,
+		Topics:            slices.Sorted(maps.Keys(topics)),
+	}, nil
+}
+
+// AddConsumerGroup adds, or replaces, a classic consumer group with the given state
+// and members. Groups with members use the range assignor.
+func (c *Cluster) AddConsumerGroup(groupID, state string, members ...domain.ConsumerGroupMemberInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	g := &group{
+		state:     state,
+		groupType: "Classic",
+		members:   slices.Clone(members),
+		offsets:   make(map[string]map[int32]int64),
+	}
+	if len(members) > 0 {
+		g.assignor = "range"
+	}
+	c.groups[groupID] = g
+}
+
+// AddSimpleGroup adds, or replaces, an Empty consumer group whose consumers only
+// commit offsets, without joining the group
+func (c *Cluster) AddSimpleGroup(groupID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.groups[groupID] = &group{
+		state:     "Empty",
+		groupType: "Classic",
+		simple:    true,
+		offsets:   make(map[string]map[int32]int64),
+	}
+}
+
+// SetGroupProtocol sets the type of a consumer group, Classic or Consumer, and the
+// partition assignor of its members
+func (c *Cluster) SetGroupProtocol(groupID, groupType, assignor string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	g, exists := c.groups[groupID]
+	if !exists {
+		return kafka_client.GroupNotFoundError(groupID)
+	}
+	g.groupType, g.assignor = groupType, assignor
+	return nil
+}
+
+// CommitOffset sets the committed offset of a consumer group on a partition
+func (c *Cluster) CommitOffset(groupID, topicName string, partition int32, offset int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	g, exists := c.groups[groupID]
+	if !exists {
+		return kafka_client.GroupNotFoundError(groupID)
+	}
+	if _, err := c.partition(topicName, partition); err != nil {
+		return err
+	}
+
+	if g.offsets[topicName] == nil {
+		g.offsets[topicName] = make(map[int32]int64)
+	}
+	g.offsets[topicName][partition] = offset
+	return nil
+}
+
+// CommittedOffsets returns the committed offsets of a consumer group by topic and partition
+func (c *Cluster) CommittedOffsets(groupID string) (map[string]map[int32]int64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	g, exists := c.groups[groupID]
+	if !exists {
+		return nil, kafka_client.GroupNotFoundError(groupID)
+	}
+
+	offsets := make(map[string]map[int32]int64, len(g.offsets))
+	for topicName, partitions := range g.offsets {
+		offsets[topicName] = maps.Clone(partitions)
+	}
+	return offsets, nil
+}
+
+// GetConsumerGroupLag implements kafka_client.Client
+func (c *Cluster) GetConsumerGroupLag(ctx context.Context, groupID string) (*domain.ConsumerGroupLag, error) {
+	if groupID == "" {
+		return nil, kafka_client.InvalidArgumentError("consumer group ID cannot be empty")
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	g, exists := c.groups[groupID]
+	if !exists {
+		return nil, kafka_client.GroupNotFoundError(groupID)
+	}
+
+	lag := &domain.ConsumerGroupLag{GroupID: groupID, Partitions: make([]domain.PartitionLag, 0)}
+	for _, topicName := range slices.Sorted(maps.Keys(g.offsets)) {
+		for _, partition := range slices.Sorted(maps.Keys(g.offsets[topicName])) {
+			committed := g.offsets[topicName][partition]
+			high := int64(len(c.topics[topicName].partitions[partition]))
+			partitionLag := domain.PartitionLag{
+				Topic:           topicName,
+				Partition:       partition,
+				CommittedOffset: committed,
+				LogEndOffset:    high,
+				Lag:             max(high-committed, 0),
+			}
+			lag.TotalLag += partitionLag.Lag
+			lag.Partitions = append(lag.Partitions, partitionLag)
+		}
+	}
+	return lag, nil
+}
+
+// ResetConsumerGroupOffsets implements kafka_client.Client. Like Kafka, it refuses
+// groups with members and creates the group when it does not exist yet.
+func (c *Cluster) ResetConsumerGroupOffsets(ctx context.Context, groupID string, spec domain.OffsetResetSpec) ([]domain.OffsetReset, error) {
+	if groupID == "" {
+		return nil, kafka_client.InvalidArgumentError("consumer group ID cannot be empty")
+	}
+	if err := kafka_client.ValidateOffsetResetSpec(spec); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	g, exists := c.groups[groupID]
+	if exists && len(g.members) > 0 {
+		return nil, kafka_client.GroupNotEmptyError(groupID)
+	}
+
+	t, found := c.topics[spec.Topic]
+	if !found {
+		return nil, kafka_client.TopicNotFoundError(spec.Topic)
+	}
+	partitions := spec.Partitions
+	if len(partitions) == 0 {
+		for partition := range t.partitions {
+			partitions = append(partitions, int32(partition))
+		}
+	}
+
+	resets := make([]domain.OffsetReset, 0, len(partitions))
+	for _, partition := range partitions {
+		records, err := c.partition(spec.Topic, partition)
+		if err != nil {
+			return nil, err
+		}
+
+		var byTime int64
+		if spec.To == domain.ResetToTimestamp {
+			byTime = offsetForTime(records, *spec.Timestamp)
+		}
+		reset := domain.OffsetReset{
+			Topic:          spec.Topic,
+			Partition:      partition,
+			PreviousOffset: -1,
+			NewOffset:      kafka_client.ResetTarget(spec, 0, int64(len(records)), byTime),
+		}
+		if exists {
+			if committed, ok := g.offsets[spec.Topic][partition]; ok {
+				reset.PreviousOffset = committed
+			}
+		}
+		resets = append(resets, reset)
+	}
+
+	if spec.DryRun {
+		return resets, nil
+	}
+
+	if !exists {
+		g = &group{state: "Empty", offsets: make(map[string]map[int32]int64)}
+		c.groups[groupID] = g
+	}
+	if g.offsets[spec.Topic] == nil {
+		g.offsets[spec.Topic] = make(map[int32]int64)
+	}
+	for _, reset := range resets {
+		g.offsets[spec.Topic][reset.Partition] = reset.NewOffset
+	}
+	return resets, nil
+}
+
+// DeleteConsumerGroup implements kafka_client.Client. Like Kafka, it refuses groups with members.
+func (c *Cluster) DeleteConsumerGroup(ctx context.Context, groupID string) error {
+	if groupID == "" {
+		return kafka_client.InvalidArgumentError("consumer group ID cannot be empty")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	g, exists := c.groups[groupID]
+	if !exists {
+		return kafka_client.GroupNotFoundError(groupID)
+	}
+	if len(g.members) > 0 {
+		return kafka_client.GroupNotEmptyError(groupID)
+	}
+	delete(c.groups, groupID)
+	return nil
+}
+
+// DeleteConsumerGroups implements kafka_client.Client
+func (c *Cluster) DeleteConsumerGroups(ctx context.Context, groupIDs []string) ([]domain.GroupDeletion, error) {
+	if len(groupIDs) == 0 {
+		return nil, kafka_client.InvalidArgumentError("no consumer groups provided")
+	}
+
+	deletions := make([]domain.GroupDeletion, 0, len(groupIDs))
+	for _, groupID := range groupIDs {
+		deletion := domain.GroupDeletion{GroupID: groupID}
+		err := c.DeleteConsumerGroup(ctx, groupID)
+		switch {
+		case err == nil:
+			deletion.Deleted = true
+		case errors.Is(err, kafka_client.ErrConflict):
+			deletion.Skipped = kafka_client.GroupSkippedActive
+		case errors.Is(err, kafka_client.ErrNotFound):
+			deletion.Skipped = kafka_client.GroupSkippedMissing
+		default:
+			deletion.Error = err.Error()
+		}
+		deletions = append(deletions, deletion)
+	}
+	return deletions, nil
+}
+
+// DeleteConsumerGroupOffsets implements kafka_client.Client. Like Kafka, it refuses
+// topics assigned to members of the group.
+func (c *Cluster) DeleteConsumerGroupOffsets(ctx context.Context, groupID string, spec domain.OffsetDeletionSpec) ([]domain.CommittedOffset, error) {
+	if groupID == "" {
+		return nil, kafka_client.InvalidArgumentError("consumer group ID cannot be empty")
+	}
+	if spec.Topic == "" {
+		return nil, kafka_client.InvalidArgumentError("topic name cannot be empty")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	g, exists := c.groups[groupID]
+	if !exists {
+		return nil, kafka_client.GroupNotFoundError(groupID)
+	}
+
+	partitions := spec.Partitions
+	if len(partitions) == 0 {
+		partitions = slices.Sorted(maps.Keys(g.offsets[spec.Topic]))
+	}
+	deleted := make([]domain.CommittedOffset, 0, len(partitions))
+	for _, partition := range partitions {
+		if offset, ok := g.offsets[spec.Topic][partition]; ok {
+			deleted = append(deleted, domain.CommittedOffset{Topic: spec.Topic, Partition: partition, Offset: offset})
+		}
+	}
+	if len(deleted) == 0 {
+		return deleted, nil
+	}
+
+	for _, member := range g.members {
+		for _, assignment := range member.Assignments {
+			if assignment.Topic == spec.Topic {
+				return nil, kafka_client.GroupSubscribedError(groupID, spec.Topic)
+			}
+		}
+	}
+
+	for _, offset := range deleted {
+		delete(g.offsets[spec.Topic], offset.Partition)
+	}
+	if len(g.offsets[spec.Topic]) == 0 {
+		delete(g.offsets, spec.Topic)
+	}
+	return deleted, nil
+}
+
+// RemoveConsumerGroupMembers implements kafka_client.Client. A group left without
+// members becomes Empty.
+func (c *Cluster) RemoveConsumerGroupMembers(ctx context.Context, groupID string, instanceIDs []string) ([]domain.MemberRemoval, error) {
+	if groupID == "" {
+		return nil, kafka_client.InvalidArgumentError("consumer group ID cannot be empty")
+	}
+	if len(instanceIDs) == 0 {
+		return nil, kafka_client.InvalidArgumentError("no group instance IDs provided")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	g, exists := c.groups[groupID]
+	if !exists {
+		return nil, kafka_client.GroupNotFoundError(groupID)
+	}
+
+	removals := make([]domain.MemberRemoval, 0, len(instanceIDs))
+	for _, id := range instanceIDs {
+		removal := domain.MemberRemoval{GroupInstanceID: id, Error: "not a member of the group"}
+		index := slices.IndexFunc(g.members, func(member domain.ConsumerGroupMemberInfo) bool {
+			return id != "" && member.GroupInstanceID == id
+		})
+		if index >= 0 {
+			removal = domain.MemberRemoval{GroupInstanceID: id, MemberID: g.members[index].ConsumerID, Removed: true}
+			g.members = slices.Delete(g.members, index, index+1)
+		}
+		removals = append(removals, removal)
+	}
+	if len(g.members) == 0 {
+		g.state, g.assignor = "Empty", ""
+	}
+	return removals, nil
+}
+
+// partition returns the records of a partition. The caller must hold the lock.
+func (c *Cluster) partition(topicName string, partition int32) ([]domain.TopicMessage, error) {
+	t, exists := c.topics[topicName]
+	if !exists {
+		return nil, kafka_client.TopicNotFoundError(topicName)
+	}
+	if partition < 0 || int(partition) >= len(t.partitions) {
+		return nil, kafka_client.PartitionNotFoundError(partition, topicName)
+	}
+	return t.partitions[partition], nil
+}
+
+// GetActiveProducers implements kafka_client.Client. The cluster only knows the
+// producers added with AddActiveProducer.
+func (c *Cluster) GetActiveProducers(ctx context.Context, topicNames []string) ([]domain.ActiveProducer, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	producers := make([]domain.ActiveProducer, 0)
+	for _, topicName := range topicNames {
+		t, exists := c.topics[topicName]
+		if !exists {
+			return nil, kafka_client.TopicNotFoundError(topicName)
+		}
+		producers = append(producers, t.producers...)
+	}
+	return producers, nil
+}
+
+// AddActiveProducer records a producer that wrote to a partition
+func (c *Cluster) AddActiveProducer(producer domain.ActiveProducer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.partition(producer.Topic, producer.Partition); err != nil {
+		return err
+	}
+	t := c.topics[producer.Topic]
+	t.producers = append(t.producers, producer)
+	return nil
+}
+
+// GetTopicMessages implements kafka_client.Client. An offset of OffsetEnd reads the
+// latest min(limit, 100) messages and OffsetBeginning reads from the first message.
+func (c *Cluster) GetTopicMessages(ctx context.Context, topicName string, partition int32, offset int64, limit int) ([]domain.TopicMessage, error) {
+	if topicName == "" {
+		return nil, kafka_client.InvalidArgumentError("topic name cannot be empty")
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	records, err := c.partition(topicName, partition)
+	if err != nil {
+		return nil, err
+	}
+
+	high := int64(len(records))
+	if offset == OffsetEnd {
+		offset = high - int64(min(limit, maxLatestMessages))
+	}
+	// OffsetBeginning and windows larger than the partition start at the first message
+	offset = max(offset, 0)
+
+	end := min(offset+int64(limit), high)
+	if offset >= end {
+		return []domain.TopicMessage{}, nil
+	}
+	return cloneMessages(records[offset:end]), nil
+}
+
+// StreamTopicMessages implements kafka_client.Client. The selected partitions are read
+// one after the other, each in offset order. Like with a real cluster, the end of the
+// range is resolved when reading starts.
+func (c *Cluster) StreamTopicMessages(ctx context.Context, topicName string, rng domain.MessageRange, fn func(domain.TopicMessage) error) error {
+	if topicName == "" {
+		return kafka_client.InvalidArgumentError("topic name cannot be empty")
+	}
+
+	// Take a snapshot so that fn may use the cluster, e.g. to produce the messages elsewhere
+	c.mu.RLock()
+	t, exists := c.topics[topicName]
+	if !exists {
+		c.mu.RUnlock()
+		return kafka_client.TopicNotFoundError(topicName)
+	}
+	partitions := rng.Partitions
+	if len(partitions) == 0 {
+		for id := range t.partitions {
+			partitions = append(partitions, int32(id))
+		}
+	}
+	selected := make([][]domain.TopicMessage, 0, len(partitions))
+	for _, partition := range partitions {
+		records, err := c.partition(topicName, partition)
+		if err != nil {
+			c.mu.RUnlock()
+			return err
+		}
+		start, end := rangeBounds(records, rng)
+		if start < end {
+			selected = append(selected, cloneMessages(records[start:end]))
+		}
+	}
+	c.mu.RUnlock()
+
+	var count int64
+	for _, records := range selected {
+		for _, message := range records {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := fn(message); err != nil {
+				return err
+			}
+			count++
+			if rng.Limit > 0 && count >= rng.Limit {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// rangeBounds resolves the [start, end) offsets of the records of a partition within a MessageRange
+func rangeBounds(records []domain.TopicMessage, rng domain.MessageRange) (int64, int64) {
+	start, end := int64(0), int64(len(records))
+
+	if rng.StartOffset != nil {
+		start = max(start, *rng.StartOffset)
+	} else if rng.StartTime != nil {
+		start = offsetForTime(records, *rng.StartTime)
+	}
+
+	if rng.EndOffset != nil {
+		end = min(end, *rng.EndOffset)
+	} else if rng.EndTime != nil {
+		end = offsetForTime(records, *rng.EndTime)
+	}
+
+	return start, end
+}
+
+// offsetForTime returns the earliest offset whose timestamp is at or after t, or the
+// end of the partition when there is none
+func offsetForTime(records []domain.TopicMessage, t time.Time) int64 {
+	for _, record := range records {
+		if !record.Timestamp.Before(t) {
+			return record.Offset
+		}
+	}
+	return int64(len(records))
+}
+
+// PublishMessage implements kafka_client.Client
+func (c *Cluster) PublishMessage(ctx context.Context, topicName string, partition int32, key string, value string, headers map[string]string) error {
+	if topicName == "" {
+		return kafka_client.InvalidArgumentError("topic name cannot be empty")
+	}
+
+	record := domain.ProduceRecord{Key: key, Value: value, Headers: headers}
+	if partition >= 0 {
+		record.Partition = &partition
+	}
+	_, err := c.append(topicName, record)
+	return err
+}
+
+// PublishMessages implements kafka_client.Client
+func (c *Cluster) PublishMessages(ctx context.Context, topicName string, records []domain.ProduceRecord, ratePerSecond int) ([]domain.ProduceResult, error) {
+	if topicName == "" {
+		return nil, kafka_client.InvalidArgumentError("topic name cannot be empty")
+	}
+
+	c.mu.RLock()
+	_, exists := c.topics[topicName]
+	c.mu.RUnlock()
+	if !exists {
+		return nil, kafka_client.TopicNotFoundError(topicName)
+	}
+
+	var throttle <-chan time.Time
+	if ratePerSecond > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(ratePerSecond))
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+
+	results := make([]domain.ProduceResult, len(records))
+	for i, record := range records {
+		results[i] = domain.ProduceResult{Index: i, Partition: -1, Offset: -1}
+
+		if throttle != nil && i > 0 {
+			select {
+			case <-ctx.Done():
+			case <-throttle:
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			results[i].Error = fmt.Sprintf("message not delivered: %v", err)
+			continue
+		}
+
+		message, err := c.append(topicName, record)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].Partition = message.Partition
+		results[i].Offset = message.Offset
+	}
+	return results, nil
+}
+
+// append stores a record in its partition and returns it as stored. Records with a key
+// go to the partition given by a hash of the key; others are spread round-robin.
+func (c *Cluster) append(topicName string, record domain.ProduceRecord) (domain.TopicMessage, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, exists := c.topics[topicName]
+	if !exists {
+		return domain.TopicMessage{}, kafka_client.TopicNotFoundError(topicName)
+	}
+
+	numPartitions := int32(len(t.partitions))
+	var partition int32
+	switch {
+	case record.Partition != nil && *record.Partition >= 0:
+		partition = *record.Partition
+		if partition >= numPartitions {
+			return domain.TopicMessage{}, kafka_client.PartitionNotFoundError(partition, topicName)
+		}
+	case record.Key != "":
+		h := fnv.New32a()
+		h.Write([]byte(record.Key))
+		partition = int32(h.Sum32() % uint32(numPartitions))
+	default:
+		partition = t.next
+		t.next = (t.next + 1) % numPartitions
+	}
+
+	timestamp := c.Now()
+	if record.Timestamp != nil {
+		timestamp = *record.Timestamp
+	}
+
+	message := domain.TopicMessage{
+		Topic:     topicName,
+		Partition: partition,
+		Offset:    int64(len(t.partitions[partition])),
+		Timestamp: timestamp,
+		Key:       record.Key,
+		Value:     record.Value,
+		Headers:   maps.Clone(record.Headers),
+	}
+	if message.Headers == nil {
+		message.Headers = make(map[string]string)
+	}
+	t.partitions[partition] = append(t.partitions[partition], message)
+	return message, nil
+}
+
+// NewTopicProducer implements kafka_client.Client. Records are delivered synchronously.
+func (c *Cluster) NewTopicProducer(ctx context.Context, topicName string, onDelivery func(err error)) (kafka_client.Producer, error) {
+	if topicName == "" {
+		return nil, kafka_client.InvalidArgumentError("topic name cannot be empty")
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	t, exists := c.topics[topicName]
+	if !exists {
+		return nil, kafka_client.TopicNotFoundError(topicName)
+	}
+	return &producer{
+		cluster:       c,
+		topic:         topicName,
+		numPartitions: int32(len(t.partitions)),
+		onDelivery:    onDelivery,
+	}, nil
+}
+
+// producer is the kafka_client.Producer of a Cluster
+type producer struct {
+	cluster       *Cluster
+	topic         string
+	numPartitions int32
+	onDelivery    func(err error)
+}
+
+// NumPartitions implements kafka_client.Producer
+func (p *producer) NumPartitions() int32 {
+	return p.numPartitions
+}
+
+// Produce implements kafka_client.Producer
+func (p *producer) Produce(ctx context.Context, record domain.ProduceRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if record.Partition != nil && *record.Partition >= p.numPartitions {
+		return kafka_client.PartitionNotFoundError(*record.Partition, p.topic)
+	}
+
+	_, err := p.cluster.append(p.topic, record)
+	if p.onDelivery != nil {
+		p.onDelivery(err)
+	}
+	return nil
+}
+
+// Close implements kafka_client.Producer. Nothing is ever left undelivered.
+func (p *producer) Close(ctx context.Context) int {
+	return 0
+}
+
+// cloneMessages copies messages so that callers cannot modify the stored records
+func cloneMessages(messages []domain.TopicMessage) []domain.TopicMessage {
+	clones := make([]domain.TopicMessage, len(messages))
+	for i, message := range messages {
+		message.Headers = maps.Clone(message.Headers)
+		clones[i] = message
+	}
+	return clones
+}
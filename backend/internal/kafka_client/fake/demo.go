@@ -0,0 +1,156 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// demoTopics are the topics of the demo cluster
+var demoTopics = []domain.TopicInfo{
+	{Name: "orders", NumPartitions: 3, ReplicationFactor: 3, Config: map[string]string{"retention.ms": "604800000"}},
+	{Name: "payments", NumPartitions: 2, ReplicationFactor: 2, Config: map[string]string{"min.insync.replicas": "2"}},
+	{Name: "customers", NumPartitions: 1, ReplicationFactor: 3, Config: map[string]string{"cleanup.policy": "compact"}},
+	{Name: "audit-log", NumPartitions: 1, ReplicationFactor: 1},
+	{Name: "__consumer_offsets", NumPartitions: 3, ReplicationFactor: 3, Config: map[string]string{"cleanup.policy": "compact"}},
+}
+
+// NewDemo creates a cluster seeded with sample topics, messages and consumer groups,
+// timestamped over the last day
+func NewDemo() *Cluster {
+	c := New()
+	ctx := context.Background()
+
+	for _, info := range demoTopics {
+		if err := c.CreateTopic(ctx, info); err != nil {
+			panic(fmt.Sprintf("failed to seed demo topic: %v", err))
+		}
+	}
+
+	start := c.Now().Add(-24 * time.Hour)
+	statuses := []string{"created", "paid", "shipped", "delivered"}
+	methods := []string{"card", "paypal", "bank-transfer"}
+
+	for i := 0; i < 200; i++ {
+		timestamp := start.Add(time.Duration(i) * 7 * time.Minute)
+		customer := fmt.Sprintf("customer-%03d", i%25)
+		orderID := fmt.Sprintf("order-%05d", 10000+i)
+
+		c.seed("orders", domain.ProduceRecord{
+			Key:       orderID,
+			Value:     fmt.Sprintf(`{"orderId":%q,"customerId":%q,"status":%q,"amount":%d.%02d}`, orderID, customer, statuses[i%len(statuses)], 10+i%90, i%100),
+			Headers:   map[string]string{"content-type": "application/json", "source": "web-shop"},
+			Timestamp: &timestamp,
+		})
+
+		if i%2 == 0 {
+			c.seed("payments", domain.ProduceRecord{
+				Key:       orderID,
+				Value:     fmt.Sprintf(`{"orderId":%q,"method":%q,"approved":%t}`, orderID, methods[i%len(methods)], i%10 != 0),
+				Headers:   map[string]string{"content-type": "application/json"},
+				Timestamp: &timestamp,
+			})
+		}
+
+		if i < 25 {
+			c.seed("customers", domain.ProduceRecord{
+				Key:       customer,
+				Value:     fmt.Sprintf(`{"customerId":%q,"name":"Customer %d","country":%q}`, customer, i, []string{"IT", "DE", "FR", "US"}[i%4]),
+				Timestamp: &timestamp,
+			})
+		}
+
+		if i%5 == 0 {
+			c.seed("audit-log", domain.ProduceRecord{
+				Value:     fmt.Sprintf("user admin viewed %s", orderID),
+				Timestamp: &timestamp,
+			})
+		}
+	}
+
+	c.AddConsumerGroup("order-service", "Stable",
+		domain.ConsumerGroupMemberInfo{
+			ClientID:   "order-service-1",
+			ConsumerID: "order-service-1-4f1c2a",
+			Host:       "/10.0.0.11",
+			Assignments: []domain.TopicPartitionAssignment{
+				{Topic: "orders", Partition: 0},
+				{Topic: "orders", Partition: 1},
+			},
+		},
+		domain.ConsumerGroupMemberInfo{
+			ClientID:    "order-service-2",
+			ConsumerID:  "order-service-2-9b7d3e",
+			Host:        "/10.0.0.12",
+			Assignments: []domain.TopicPartitionAssignment{{Topic: "orders", Partition: 2}},
+		},
+	)
+	c.AddConsumerGroup("billing", "Stable",
+		domain.ConsumerGroupMemberInfo{
+			ClientID:        "billing-worker",
+			ConsumerID:      "billing-worker-1d8e5f",
+			GroupInstanceID: "billing-worker-0",
+			Host:            "/10.0.0.21",
+			Assignments: []domain.TopicPartitionAssignment{
+				{Topic: "payments", Partition: 0},
+				{Topic: "payments", Partition: 1},
+			},
+		},
+	)
+	if err := c.SetGroupProtocol("billing", "Consumer", "uniform"); err != nil {
+		panic(fmt.Sprintf("failed to seed demo group: %v", err))
+	}
+	c.AddConsumerGroup("analytics", "Empty")
+	// Stale groups left behind by the message readers of Maestro
+	c.AddSimpleGroup("maestro-message-reader-0b6f3c8e-5a1d-4e27-9c3b-7f2e1d4a6b90")
+	c.AddSimpleGroup("maestro-message-reader-9d2a7e41-c3f8-4b5e-a6d0-1e8b2f7c9a35")
+
+	// The web shop writes orders and payments; the audit log is written without idempotence
+	lastWrite := start.Add(199 * 7 * time.Minute)
+	producers := []domain.ActiveProducer{
+		{Topic: "orders", Partition: 0, ProducerID: 4001},
+		{Topic: "orders", Partition: 1, ProducerID: 4001},
+		{Topic: "orders", Partition: 2, ProducerID: 4001},
+		{Topic: "payments", Partition: 0, ProducerID: 4002},
+		{Topic: "payments", Partition: 1, ProducerID: 4002},
+	}
+	for _, producer := range producers {
+		producer.LastTimestamp = lastWrite
+		if err := c.AddActiveProducer(producer); err != nil {
+			panic(fmt.Sprintf("failed to seed demo producers: %v", err))
+		}
+	}
+
+	// Leave the consumers somewhat behind so that the groups show lag
+	commits := []struct {
+		group     string
+		topic     string
+		partition int32
+		behind    int64
+	}{
+		{"order-service", "orders", 0, 0},
+		{"order-service", "orders", 1, 3},
+		{"order-service", "orders", 2, 1},
+		{"billing", "payments", 0, 5},
+		{"billing", "payments", 1, 0},
+		{"analytics", "orders", 0, 40},
+		{"analytics", "customers", 0, 10},
+	}
+	for _, commit := range commits {
+		end := int64(len(c.topics[commit.topic].partitions[commit.partition]))
+		if err := c.CommitOffset(commit.group, commit.topic, commit.partition, max(end-commit.behind, 0)); err != nil {
+			panic(fmt.Sprintf("failed to seed demo offsets: %v", err))
+		}
+	}
+
+	return c
+}
+
+// seed appends a record to a demo topic
+func (c *Cluster) seed(topicName string, record domain.ProduceRecord) {
+	if _, err := c.append(topicName, record); err != nil {
+		panic(fmt.Sprintf("failed to seed demo message: %v", err))
+	}
+}
@@ -0,0 +1,302 @@
+package kafka_client
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// memberRemovalReason is reported to the group coordinator when removing static members
+const memberRemovalReason = "removed with Maestro"
+
+// Reasons a bulk deletion leaves a consumer group in place
+const (
+	GroupSkippedActive  = "the group has active members"
+	GroupSkippedMissing = "the group does not exist"
+)
+
+// GroupStates lists the states of a consumer group, as Kafka names them
+var GroupStates = []string{"PreparingRebalance", "CompletingRebalance", "Stable", "Dead", "Empty"}
+
+// groupStates maps the names in GroupStates to the states of librdkafka
+var groupStates = map[string]kafka.ConsumerGroupState{
+	"PreparingRebalance":  kafka.ConsumerGroupStatePreparingRebalance,
+	"CompletingRebalance": kafka.ConsumerGroupStateCompletingRebalance,
+	"Stable":              kafka.ConsumerGroupStateStable,
+	"Dead":                kafka.ConsumerGroupStateDead,
+	"Empty":               kafka.ConsumerGroupStateEmpty,
+}
+
+// NormalizeGroupStates checks the names of consumer group states, ignoring case, and
+// returns them as named in GroupStates
+func NormalizeGroupStates(states []string) ([]string, error) {
+	normalized := make([]string, 0, len(states))
+	for _, state := range states {
+		index := slices.IndexFunc(GroupStates, func(name string) bool {
+			return strings.EqualFold(name, state)
+		})
+		if index < 0 {
+			return nil, InvalidArgumentError("unknown consumer group state %q, expected one of %s", state, strings.Join(GroupStates, ", "))
+		}
+		normalized = append(normalized, GroupStates[index])
+	}
+	return normalized, nil
+}
+
+// groupStateName returns the name of a consumer group state, e.g. Stable
+func groupStateName(state kafka.ConsumerGroupState) string {
+	for name, s := range groupStates {
+		if s == state {
+			return name
+		}
+	}
+	return "Unknown"
+}
+
+// groupTypeName returns the name of a consumer group type, Classic or Consumer, or an
+// empty string when the brokers don't report it
+func groupTypeName(groupType kafka.ConsumerGroupType) string {
+	switch groupType {
+	case kafka.ConsumerGroupTypeClassic:
+		return "Classic"
+	case kafka.ConsumerGroupTypeConsumer:
+		return "Consumer"
+	default:
+		return ""
+	}
+}
+
+// DeleteConsumerGroup deletes a consumer group and its committed offsets. Kafka only
+// deletes groups without active members.
+func (kc *KafkaClient) DeleteConsumerGroup(ctx context.Context, groupID string) error {
+	ctx, cancel := context.WithTimeout(ctx, kc.Timeout)
+	defer cancel()
+
+	if groupID == "" {
+		return InvalidArgumentError("consumer group ID cannot be empty")
+	}
+
+	span := kc.startCall(ctx, "DeleteConsumerGroups", groupAttribute(groupID))
+	result, err := kc.AdminClient.DeleteConsumerGroups(ctx, []string{groupID})
+	endSpan(span, err)
+	if err != nil {
+		return wrapError(err, "failed to delete consumer group")
+	}
+
+	for _, group := range result.ConsumerGroupResults {
+		switch group.Error.Code() {
+		case kafka.ErrNoError:
+		case kafka.ErrGroupIDNotFound:
+			return GroupNotFoundError(groupID)
+		case kafka.ErrNonEmptyGroup:
+			return GroupNotEmptyError(groupID)
+		default:
+			return wrapError(group.Error, fmt.Sprintf("failed to delete consumer group '%s'", groupID))
+		}
+	}
+	return nil
+}
+
+// DeleteConsumerGroups deletes consumer groups and their committed offsets with a single
+// DeleteGroups request. Groups with active members are reported as skipped.
+func (kc *KafkaClient) DeleteConsumerGroups(ctx context.Context, groupIDs []string) ([]domain.GroupDeletion, error) {
+	ctx, cancel := context.WithTimeout(ctx, kc.Timeout)
+	defer cancel()
+
+	if len(groupIDs) == 0 {
+		return nil, InvalidArgumentError("no consumer groups provided")
+	}
+
+	span := kc.startCall(ctx, "DeleteConsumerGroups", groupsAttribute(groupIDs))
+	result, err := kc.AdminClient.DeleteConsumerGroups(ctx, groupIDs)
+	endSpan(span, err)
+	if err != nil {
+		return nil, wrapError(err, "failed to delete consumer groups")
+	}
+
+	errs := make(map[string]kafka.Error, len(result.ConsumerGroupResults))
+	for _, group := range result.ConsumerGroupResults {
+		errs[group.Group] = group.Error
+	}
+
+	deletions := make([]domain.GroupDeletion, 0, len(groupIDs))
+	for _, groupID := range groupIDs {
+		deletion := domain.GroupDeletion{GroupID: groupID}
+		err, found := errs[groupID]
+		switch {
+		case !found:
+			deletion.Error = "the cluster did not report on this group"
+		case err.Code() == kafka.ErrNoError:
+			deletion.Deleted = true
+		case err.Code() == kafka.ErrNonEmptyGroup:
+			deletion.Skipped = GroupSkippedActive
+		case err.Code() == kafka.ErrGroupIDNotFound:
+			deletion.Skipped = GroupSkippedMissing
+		default:
+			deletion.Error = wrapError(err, fmt.Sprintf("failed to delete consumer group '%s'", groupID)).Error()
+		}
+		deletions = append(deletions, deletion)
+	}
+	return deletions, nil
+}
+
+// DeleteConsumerGroupOffsets deletes the committed offsets of a consumer group on the
+// partitions of a topic, e.g. one it no longer consumes, so that it stops showing lag
+// on it. Kafka refuses the deletion while the group is subscribed to the topic. The
+// topic itself may no longer exist.
+func (kc *KafkaClient) DeleteConsumerGroupOffsets(ctx context.Context, groupID string, spec domain.OffsetDeletionSpec) ([]domain.CommittedOffset, error) {
+	ctx, cancel := context.WithTimeout(ctx, kc.Timeout)
+	defer cancel()
+
+	if groupID == "" {
+		return nil, InvalidArgumentError("consumer group ID cannot be empty")
+	}
+	if spec.Topic == "" {
+		return nil, InvalidArgumentError("topic name cannot be empty")
+	}
+
+	var selected []kafka.TopicPartition
+	for _, partition := range spec.Partitions {
+		selected = append(selected, kafka.TopicPartition{Topic: &spec.Topic, Partition: partition})
+	}
+	committed, err := kc.committedOffsets(ctx, groupID, selected)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := make([]domain.CommittedOffset, 0, len(committed))
+	for _, tp := range committed {
+		if *tp.Topic == spec.Topic {
+			deleted = append(deleted, domain.CommittedOffset{Topic: spec.Topic, Partition: tp.Partition, Offset: int64(tp.Offset)})
+		}
+	}
+	if len(deleted) == 0 {
+		// Listing the offsets of an unknown group succeeds, so check that it exists
+		if _, err := kc.GetConsumerGroupDetails(ctx, groupID); err != nil {
+			return nil, err
+		}
+		return deleted, nil
+	}
+
+	client, err := kc.coordinatorClient()
+	if err != nil {
+		return nil, err
+	}
+
+	req := kmsg.NewPtrOffsetDeleteRequest()
+	req.Group = groupID
+	topic := kmsg.NewOffsetDeleteRequestTopic()
+	topic.Topic = spec.Topic
+	for _, offset := range deleted {
+		partition := kmsg.NewOffsetDeleteRequestTopicPartition()
+		partition.Partition = offset.Partition
+		topic.Partitions = append(topic.Partitions, partition)
+	}
+	req.Topics = append(req.Topics, topic)
+
+	span := kc.startCall(ctx, "OffsetDelete", groupAttribute(groupID), topicAttribute(spec.Topic))
+	resp, err := req.RequestWith(ctx, client)
+	if err == nil {
+		err = coordinatorError(resp.ErrorCode)
+	}
+	endSpan(span, err)
+	if err != nil {
+		if resp != nil && resp.ErrorCode == kerr.GroupSubscribedToTopic.Code {
+			return nil, GroupSubscribedError(groupID, spec.Topic)
+		}
+		return nil, wrapError(err, "failed to delete consumer group offsets")
+	}
+
+	for _, t := range resp.Topics {
+		for _, p := range t.Partitions {
+			switch {
+			case p.ErrorCode == kerr.GroupSubscribedToTopic.Code:
+				return nil, GroupSubscribedError(groupID, spec.Topic)
+			case p.ErrorCode != 0:
+				return nil, wrapError(coordinatorError(p.ErrorCode), fmt.Sprintf("failed to delete offset of partition %d", p.Partition))
+			}
+		}
+	}
+	return deleted, nil
+}
+
+// RemoveConsumerGroupMembers removes static members from a consumer group, identified
+// by the group.instance.id of their consumers, without waiting for their session to
+// time out. The outcome is reported per member.
+func (kc *KafkaClient) RemoveConsumerGroupMembers(ctx context.Context, groupID string, instanceIDs []string) ([]domain.MemberRemoval, error) {
+	ctx, cancel := context.WithTimeout(ctx, kc.Timeout)
+	defer cancel()
+
+	if groupID == "" {
+		return nil, InvalidArgumentError("consumer group ID cannot be empty")
+	}
+	if len(instanceIDs) == 0 {
+		return nil, InvalidArgumentError("no group instance IDs provided")
+	}
+	for _, id := range instanceIDs {
+		if id == "" {
+			return nil, InvalidArgumentError("group instance IDs cannot be empty")
+		}
+	}
+
+	// The coordinator reports the members of an unknown group as unknown members
+	if _, err := kc.GetConsumerGroupDetails(ctx, groupID); err != nil {
+		return nil, err
+	}
+
+	client, err := kc.coordinatorClient()
+	if err != nil {
+		return nil, err
+	}
+
+	req := kmsg.NewPtrLeaveGroupRequest()
+	req.Group = groupID
+	for _, id := range instanceIDs {
+		member := kmsg.NewLeaveGroupRequestMember()
+		member.InstanceID = kmsg.StringPtr(id)
+		member.Reason = kmsg.StringPtr(memberRemovalReason)
+		req.Members = append(req.Members, member)
+	}
+
+	span := kc.startCall(ctx, "LeaveGroup", groupAttribute(groupID))
+	resp, err := req.RequestWith(ctx, client)
+	if err == nil {
+		err = coordinatorError(resp.ErrorCode)
+	}
+	endSpan(span, err)
+	if err != nil {
+		return nil, wrapError(err, "failed to remove consumer group members")
+	}
+
+	responses := make(map[string]kmsg.LeaveGroupResponseMember, len(resp.Members))
+	for _, member := range resp.Members {
+		if member.InstanceID != nil {
+			responses[*member.InstanceID] = member
+		}
+	}
+
+	removals := make([]domain.MemberRemoval, 0, len(instanceIDs))
+	for _, id := range instanceIDs {
+		removal := domain.MemberRemoval{GroupInstanceID: id}
+		member, found := responses[id]
+		switch {
+		case !found:
+			removal.Error = "the coordinator did not report on this member"
+		case member.ErrorCode == kerr.UnknownMemberID.Code:
+			removal.Error = "not a member of the group"
+		case member.ErrorCode != 0:
+			removal.Error = coordinatorError(member.ErrorCode).Error()
+		default:
+			removal.Removed = true
+			removal.MemberID = member.MemberID
+		}
+		removals = append(removals, removal)
+	}
+	return removals, nil
+}
@@ -0,0 +1,92 @@
+package kafka_client
+
+import (
+	"context"
+	"time"
+
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// Client is the set of Kafka operations used by the API handlers and background jobs.
+// It is implemented by KafkaClient, which talks to a real cluster, and by the
+// in-memory cluster of package fake, used for tests and demo mode.
+type Client interface {
+	// GetBrokers retrieves information about all brokers in the cluster
+	GetBrokers(ctx context.Context) ([]domain.BrokerInfo, error)
+
+	// ListTopics retrieves information about all topics in the cluster
+	ListTopics(ctx context.Context) ([]domain.TopicInfo, error)
+	// GetTopicDetails retrieves detailed information, including configuration overrides, about a topic
+	GetTopicDetails(ctx context.Context, topicName string) (*domain.TopicInfo, error)
+	// CreateTopic creates a new topic
+	CreateTopic(ctx context.Context, topic domain.TopicInfo) error
+	// DeleteTopic deletes a topic
+	DeleteTopic(ctx context.Context, topicName string) error
+	// UpdateTopicConfig replaces the configuration overrides of a topic
+	UpdateTopicConfig(ctx context.Context, topicName string, config map[string]string) error
+	// DeleteTopics deletes topics with a single request and reports the outcome for every topic
+	DeleteTopics(ctx context.Context, topicNames []string) ([]domain.BulkTopicResult, error)
+	// AlterTopicsConfig sets and removes configuration overrides of topics with a single
+	// request, leaving their other overrides in place, and reports the outcome for every topic
+	AlterTopicsConfig(ctx context.Context, topicNames []string, set map[string]string, deleted []string) ([]domain.BulkTopicResult, error)
+	// CreatePartitions increases the number of partitions of topics with a single request
+	// and reports the outcome for every topic
+	CreatePartitions(ctx context.Context, topicNames []string, partitions int32) ([]domain.BulkTopicResult, error)
+
+	// ListConsumerGroups retrieves the consumer groups of the cluster, or only those in
+	// one of the given states (see GroupStates)
+	ListConsumerGroups(ctx context.Context, states ...string) ([]domain.ConsumerGroupInfo, error)
+	// GetConsumerGroupDetails retrieves detailed information about a consumer group
+	GetConsumerGroupDetails(ctx context.Context, groupID string) (*domain.ConsumerGroupDetails, error)
+	// GetConsumerGroupLag retrieves the committed offsets of a consumer group and its lag behind the log end
+	GetConsumerGroupLag(ctx context.Context, groupID string) (*domain.ConsumerGroupLag, error)
+	// ResetConsumerGroupOffsets moves the committed offsets of a consumer group without active members
+	ResetConsumerGroupOffsets(ctx context.Context, groupID string, spec domain.OffsetResetSpec) ([]domain.OffsetReset, error)
+	// DeleteConsumerGroup deletes a consumer group without active members, including its committed offsets
+	DeleteConsumerGroup(ctx context.Context, groupID string) error
+	// DeleteConsumerGroups deletes consumer groups without active members with a single request
+	// and reports the outcome for every group
+	DeleteConsumerGroups(ctx context.Context, groupIDs []string) ([]domain.GroupDeletion, error)
+	// DeleteConsumerGroupOffsets deletes the committed offsets of a consumer group on a topic it is not
+	// subscribed to and returns the offsets deleted
+	DeleteConsumerGroupOffsets(ctx context.Context, groupID string, spec domain.OffsetDeletionSpec) ([]domain.CommittedOffset, error)
+	// RemoveConsumerGroupMembers removes static members, identified by their group.instance.id, from a consumer group
+	RemoveConsumerGroupMembers(ctx context.Context, groupID string, instanceIDs []string) ([]domain.MemberRemoval, error)
+
+	// GetActiveProducers retrieves the producers the partition leaders of the given topics keep state for
+	GetActiveProducers(ctx context.Context, topicNames []string) ([]domain.ActiveProducer, error)
+
+	// GetTopicMessages retrieves up to limit messages of a partition starting at offset;
+	// an offset of -1 reads the latest messages
+	GetTopicMessages(ctx context.Context, topicName string, partition int32, offset int64, limit int) ([]domain.TopicMessage, error)
+	// StreamTopicMessages hands the messages of a topic within a range to fn
+	StreamTopicMessages(ctx context.Context, topicName string, rng domain.MessageRange, fn func(domain.TopicMessage) error) error
+
+	// PublishMessage publishes a single message; a partition of -1 lets the producer pick one
+	PublishMessage(ctx context.Context, topicName string, partition int32, key string, value string, headers map[string]string) error
+	// PublishMessages publishes a batch of records and reports the outcome of every record
+	PublishMessages(ctx context.Context, topicName string, records []domain.ProduceRecord, ratePerSecond int) ([]domain.ProduceResult, error)
+	// NewTopicProducer creates a producer of records for a single topic
+	NewTopicProducer(ctx context.Context, topicName string, onDelivery func(err error)) (Producer, error)
+
+	// OperationTimeout returns the default timeout for operations
+	OperationTimeout() time.Duration
+	// Close releases the resources of the client
+	Close()
+}
+
+// Producer produces records to a single topic with asynchronous delivery
+type Producer interface {
+	// NumPartitions returns the number of partitions of the target topic
+	NumPartitions() int32
+	// Produce enqueues a record for delivery; a nil partition lets the producer pick one
+	Produce(ctx context.Context, record domain.ProduceRecord) error
+	// Close waits for outstanding deliveries until ctx is done and returns the
+	// number of records that were still undelivered
+	Close(ctx context.Context) int
+}
+
+var (
+	_ Client   = (*KafkaClient)(nil)
+	_ Producer = (*TopicProducer)(nil)
+)
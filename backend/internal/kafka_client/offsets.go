@@ -0,0 +1,244 @@
+package kafka_client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// GetConsumerGroupLag retrieves the committed offsets of a consumer group and how far
+// they are behind the end of their partitions
+func (kc *KafkaClient) GetConsumerGroupLag(ctx context.Context, groupID string) (*domain.ConsumerGroupLag, error) {
+	ctx, cancel := context.WithTimeout(ctx, kc.Timeout)
+	defer cancel()
+
+	if groupID == "" {
+		return nil, InvalidArgumentError("consumer group ID cannot be empty")
+	}
+
+	committed, err := kc.committedOffsets(ctx, groupID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(committed) == 0 {
+		// Listing the offsets of an unknown group succeeds, so check that it exists
+		if _, err := kc.GetConsumerGroupDetails(ctx, groupID); err != nil {
+			return nil, err
+		}
+	}
+
+	consumer, err := kc.newMessageReader(false)
+	if err != nil {
+		return nil, err
+	}
+	defer consumer.Close()
+
+	lag := &domain.ConsumerGroupLag{GroupID: groupID, Partitions: make([]domain.PartitionLag, 0, len(committed))}
+	timeoutMs := int(kc.Timeout.Milliseconds())
+	for _, tp := range committed {
+		_, high, err := consumer.QueryWatermarkOffsets(*tp.Topic, tp.Partition, timeoutMs)
+		if err != nil {
+			return nil, wrapError(err, fmt.Sprintf("failed to get watermark offsets for partition %d of topic '%s'", tp.Partition, *tp.Topic))
+		}
+
+		partitionLag := domain.PartitionLag{
+			Topic:           *tp.Topic,
+			Partition:       tp.Partition,
+			CommittedOffset: int64(tp.Offset),
+			LogEndOffset:    high,
+			Lag:             max(high-int64(tp.Offset), 0),
+		}
+		lag.TotalLag += partitionLag.Lag
+		lag.Partitions = append(lag.Partitions, partitionLag)
+	}
+
+	sort.Slice(lag.Partitions, func(i, j int) bool {
+		a, b := lag.Partitions[i], lag.Partitions[j]
+		if a.Topic != b.Topic {
+			return a.Topic < b.Topic
+		}
+		return a.Partition < b.Partition
+	})
+
+	return lag, nil
+}
+
+// ResetConsumerGroupOffsets moves the committed offsets of a consumer group on the
+// partitions of a topic. Kafka only accepts new offsets for a group without active
+// members, so the consumers of the group must be stopped first. With spec.DryRun the
+// new offsets are computed but not committed.
+func (kc *KafkaClient) ResetConsumerGroupOffsets(ctx context.Context, groupID string, spec domain.OffsetResetSpec) ([]domain.OffsetReset, error) {
+	ctx, cancel := context.WithTimeout(ctx, kc.Timeout*2)
+	defer cancel()
+
+	if groupID == "" {
+		return nil, InvalidArgumentError("consumer group ID cannot be empty")
+	}
+	if err := ValidateOffsetResetSpec(spec); err != nil {
+		return nil, err
+	}
+
+	span := kc.startCall(ctx, "DescribeConsumerGroups", groupAttribute(groupID))
+	groups, err := kc.AdminClient.DescribeConsumerGroups(ctx, []string{groupID})
+	endSpan(span, err)
+	if err != nil {
+		return nil, wrapError(err, "failed to describe consumer group")
+	}
+	if len(groups.ConsumerGroupDescriptions) > 0 && len(groups.ConsumerGroupDescriptions[0].Members) > 0 {
+		return nil, GroupNotEmptyError(groupID)
+	}
+
+	metadata, err := kc.getMetadata(ctx, &spec.Topic, false)
+	if err != nil {
+		return nil, wrapError(err, "failed to check if topic exists")
+	}
+	topicMetadata, exists := metadata.Topics[spec.Topic]
+	if !exists {
+		return nil, TopicNotFoundError(spec.Topic)
+	}
+	partitions, err := selectPartitions(spec.Topic, topicMetadata, spec.Partitions)
+	if err != nil {
+		return nil, err
+	}
+
+	query := make([]kafka.TopicPartition, 0, len(partitions))
+	for _, partition := range partitions {
+		query = append(query, kafka.TopicPartition{Topic: &spec.Topic, Partition: partition})
+	}
+	committed, err := kc.committedOffsets(ctx, groupID, query)
+	if err != nil {
+		return nil, err
+	}
+	previous := make(map[int32]int64, len(committed))
+	for _, tp := range committed {
+		previous[tp.Partition] = int64(tp.Offset)
+	}
+
+	consumer, err := kc.newMessageReader(false)
+	if err != nil {
+		return nil, err
+	}
+	defer consumer.Close()
+
+	var byTime map[int32]int64
+	if spec.To == domain.ResetToTimestamp {
+		byTime, err = kc.offsetsForTime(consumer, spec.Topic, partitions, spec.Timestamp.UnixMilli())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resets := make([]domain.OffsetReset, 0, len(partitions))
+	commit := make([]kafka.TopicPartition, 0, len(partitions))
+	for _, partition := range partitions {
+		low, high, err := consumer.QueryWatermarkOffsets(spec.Topic, partition, int(kc.Timeout.Milliseconds()))
+		if err != nil {
+			return nil, wrapError(err, fmt.Sprintf("failed to get watermark offsets for partition %d", partition))
+		}
+
+		offset, found := byTime[partition]
+		if spec.To == domain.ResetToTimestamp && (!found || offset < 0) {
+			// No message at or after the timestamp
+			offset = high
+		}
+		reset := domain.OffsetReset{
+			Topic:          spec.Topic,
+			Partition:      partition,
+			PreviousOffset: -1,
+			NewOffset:      ResetTarget(spec, low, high, offset),
+		}
+		if committedOffset, ok := previous[partition]; ok {
+			reset.PreviousOffset = committedOffset
+		}
+		resets = append(resets, reset)
+		commit = append(commit, kafka.TopicPartition{Topic: &spec.Topic, Partition: partition, Offset: kafka.Offset(reset.NewOffset)})
+	}
+
+	if spec.DryRun {
+		return resets, nil
+	}
+
+	span = kc.startCall(ctx, "AlterConsumerGroupOffsets", groupAttribute(groupID), topicAttribute(spec.Topic))
+	result, err := kc.AdminClient.AlterConsumerGroupOffsets(ctx, []kafka.ConsumerGroupTopicPartitions{{Group: groupID, Partitions: commit}})
+	endSpan(span, err)
+	if err != nil {
+		return nil, wrapError(err, "failed to reset consumer group offsets")
+	}
+	for _, group := range result.ConsumerGroupsTopicPartitions {
+		for _, tp := range group.Partitions {
+			if tp.Error != nil {
+				return nil, wrapError(tp.Error, fmt.Sprintf("failed to reset offset of partition %d", tp.Partition))
+			}
+		}
+	}
+
+	return resets, nil
+}
+
+// committedOffsets lists the committed offsets of a consumer group on the given
+// partitions, or on all partitions it has committed offsets for when partitions is nil.
+// Partitions without a committed offset are left out.
+func (kc *KafkaClient) committedOffsets(ctx context.Context, groupID string, partitions []kafka.TopicPartition) ([]kafka.TopicPartition, error) {
+	span := kc.startCall(ctx, "ListConsumerGroupOffsets", groupAttribute(groupID))
+	result, err := kc.AdminClient.ListConsumerGroupOffsets(ctx, []kafka.ConsumerGroupTopicPartitions{{Group: groupID, Partitions: partitions}})
+	endSpan(span, err)
+	if err != nil {
+		return nil, wrapError(err, "failed to list consumer group offsets")
+	}
+
+	var committed []kafka.TopicPartition
+	for _, group := range result.ConsumerGroupsTopicPartitions {
+		for _, tp := range group.Partitions {
+			if tp.Error != nil {
+				return nil, wrapError(tp.Error, fmt.Sprintf("failed to get committed offset of partition %d", tp.Partition))
+			}
+			if tp.Offset >= 0 {
+				committed = append(committed, tp)
+			}
+		}
+	}
+	return committed, nil
+}
+
+// ValidateOffsetResetSpec checks that an offset reset names a topic and a valid target
+func ValidateOffsetResetSpec(spec domain.OffsetResetSpec) error {
+	if spec.Topic == "" {
+		return InvalidArgumentError("topic name cannot be empty")
+	}
+
+	switch spec.To {
+	case domain.ResetToEarliest, domain.ResetToLatest:
+	case domain.ResetToOffset:
+		if spec.Offset == nil || *spec.Offset < 0 {
+			return InvalidArgumentError("a reset to an offset requires a non-negative offset")
+		}
+	case domain.ResetToTimestamp:
+		if spec.Timestamp == nil {
+			return InvalidArgumentError("a reset to a timestamp requires a timestamp")
+		}
+	default:
+		return InvalidArgumentError("unsupported reset target '%s', expected earliest, latest, offset or timestamp", spec.To)
+	}
+	return nil
+}
+
+// ResetTarget returns the offset a partition with the given watermarks is reset to.
+// byTime is the offset looked up for a timestamp target; other targets ignore it.
+// Offsets outside the watermarks are clamped to them.
+func ResetTarget(spec domain.OffsetResetSpec, low, high, byTime int64) int64 {
+	var offset int64
+	switch spec.To {
+	case domain.ResetToEarliest:
+		offset = low
+	case domain.ResetToLatest:
+		offset = high
+	case domain.ResetToOffset:
+		offset = *spec.Offset
+	case domain.ResetToTimestamp:
+		offset = byTime
+	}
+	return min(max(offset, low), high)
+}
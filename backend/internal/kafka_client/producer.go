@@ -0,0 +1,135 @@
+package kafka_client
+
+import (
+	"context"
+	"sync"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// TopicProducer produces records to a single topic with asynchronous delivery.
+// It is meant for long-running copy operations where records are produced one
+// at a time and delivery reports are only needed in aggregate.
+type TopicProducer struct {
+	producer      *kafka.Producer
+	topic         string
+	numPartitions int32
+	onDelivery    func(err error)
+	wg            sync.WaitGroup
+}
+
+// NewTopicProducer creates a TopicProducer for an existing topic. onDelivery, when
+// not nil, is called once for every record with its delivery error, if any.
+func (kc *KafkaClient) NewTopicProducer(ctx context.Context, topicName string, onDelivery func(err error)) (Producer, error) {
+	if topicName == "" {
+		return nil, InvalidArgumentError("topic name cannot be empty")
+	}
+
+	metadata, err := kc.getMetadata(ctx, &topicName, false)
+	if err != nil {
+		return nil, wrapError(err, "failed to check if topic exists")
+	}
+
+	topicMetadata, exists := metadata.Topics[topicName]
+	if !exists {
+		return nil, TopicNotFoundError(topicName)
+	}
+
+	config := kc.configMap(kafka.ConfigMap{
+		"acks":      "all", // Wait for all replicas
+		"linger.ms": 5,
+	})
+
+	producer, err := kafka.NewProducer(config)
+	if err != nil {
+		return nil, wrapError(err, "failed to create Kafka producer")
+	}
+
+	tp := &TopicProducer{
+		producer:      producer,
+		topic:         topicName,
+		numPartitions: int32(len(topicMetadata.Partitions)),
+		onDelivery:    onDelivery,
+	}
+
+	tp.wg.Add(1)
+	go tp.handleEvents()
+
+	return tp, nil
+}
+
+// NumPartitions returns the number of partitions of the target topic
+func (tp *TopicProducer) NumPartitions() int32 {
+	return tp.numPartitions
+}
+
+// Produce enqueues a record for delivery. A nil partition lets the producer pick one.
+// It blocks while the local producer queue is full.
+func (tp *TopicProducer) Produce(ctx context.Context, record domain.ProduceRecord) error {
+	partition := kafka.PartitionAny
+	if record.Partition != nil && *record.Partition >= 0 {
+		if *record.Partition >= tp.numPartitions {
+			return PartitionNotFoundError(*record.Partition, tp.topic)
+		}
+		partition = *record.Partition
+	}
+
+	message := &kafka.Message{
+		TopicPartition: kafka.TopicPartition{
+			Topic:     &tp.topic,
+			Partition: partition,
+		},
+		Value:   []byte(record.Value),
+		Headers: toKafkaHeaders(record.Headers),
+	}
+	if record.Key != "" {
+		message.Key = []byte(record.Key)
+	}
+	if record.Timestamp != nil {
+		message.Timestamp = *record.Timestamp
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := tp.producer.Produce(message, nil)
+		if err == nil {
+			return nil
+		}
+		// Give the producer a chance to drain its queue before retrying
+		if kafkaErr, ok := err.(kafka.Error); ok && kafkaErr.Code() == kafka.ErrQueueFull {
+			tp.producer.Flush(100)
+			continue
+		}
+		return wrapError(err, "failed to produce message")
+	}
+}
+
+// Close waits for outstanding deliveries until ctx is done and releases the producer.
+// It returns the number of records that were still undelivered.
+func (tp *TopicProducer) Close(ctx context.Context) int {
+	for tp.producer.Len() > 0 && ctx.Err() == nil {
+		tp.producer.Flush(100)
+	}
+	undelivered := tp.producer.Len()
+
+	tp.producer.Close()
+	tp.wg.Wait()
+
+	return undelivered
+}
+
+// handleEvents consumes delivery reports until the producer is closed
+func (tp *TopicProducer) handleEvents() {
+	defer tp.wg.Done()
+
+	for ev := range tp.producer.Events() {
+		m, ok := ev.(*kafka.Message)
+		if !ok || tp.onDelivery == nil {
+			continue
+		}
+		tp.onDelivery(m.TopicPartition.Error)
+	}
+}
@@ -0,0 +1,86 @@
+package kafka_client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// GetActiveProducers retrieves the idempotent and transactional producers that the
+// partition leaders of the given topics keep state for, i.e. the producers that wrote
+// to them within transactional.id.expiration.ms. Producers that are neither idempotent
+// nor transactional are not tracked by Kafka.
+func (kc *KafkaClient) GetActiveProducers(ctx context.Context, topicNames []string) ([]domain.ActiveProducer, error) {
+	ctx, cancel := context.WithTimeout(ctx, kc.Timeout)
+	defer cancel()
+
+	producers := make([]domain.ActiveProducer, 0)
+	if len(topicNames) == 0 {
+		return producers, nil
+	}
+
+	metadata, err := kc.getMetadata(ctx, nil, true)
+	if err != nil {
+		return nil, wrapError(err, "failed to get topic metadata")
+	}
+
+	req := kmsg.NewPtrDescribeProducersRequest()
+	for _, topicName := range topicNames {
+		topicMetadata, exists := metadata.Topics[topicName]
+		if !exists {
+			return nil, TopicNotFoundError(topicName)
+		}
+		topic := kmsg.NewDescribeProducersRequestTopic()
+		topic.Topic = topicName
+		for _, partition := range topicMetadata.Partitions {
+			topic.Partitions = append(topic.Partitions, partition.ID)
+		}
+		req.Topics = append(req.Topics, topic)
+	}
+
+	client, err := kc.coordinatorClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// The request is split between the leaders of the partitions
+	span := kc.startCall(ctx, "DescribeProducers")
+	resp, err := req.RequestWith(ctx, client)
+	endSpan(span, err)
+	if err != nil {
+		return nil, wrapError(err, "failed to describe producers")
+	}
+
+	for _, topic := range resp.Topics {
+		for _, partition := range topic.Partitions {
+			if partition.ErrorCode != 0 {
+				return nil, wrapError(coordinatorError(partition.ErrorCode), fmt.Sprintf("failed to describe the producers of partition %d of topic '%s'", partition.Partition, topic.Topic))
+			}
+			for _, producer := range partition.ActiveProducers {
+				producers = append(producers, domain.ActiveProducer{
+					Topic:         topic.Topic,
+					Partition:     partition.Partition,
+					ProducerID:    producer.ProducerID,
+					ProducerEpoch: producer.ProducerEpoch,
+					LastTimestamp: time.UnixMilli(producer.LastTimestamp).UTC(),
+				})
+			}
+		}
+	}
+
+	sort.Slice(producers, func(i, j int) bool {
+		a, b := producers[i], producers[j]
+		if a.Topic != b.Topic {
+			return a.Topic < b.Topic
+		}
+		if a.Partition != b.Partition {
+			return a.Partition < b.Partition
+		}
+		return a.ProducerID < b.ProducerID
+	})
+	return producers, nil
+}
@@ -0,0 +1,258 @@
+package kafka_client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// rangeBounds holds the resolved [start, end) offsets of a partition within a MessageRange
+type rangeBounds struct {
+	start int64
+	end   int64
+	done  bool
+}
+
+// StreamTopicMessages reads the messages of a topic that fall within the given range
+// and hands them to fn in the order they are consumed. Partitions are read together,
+// so messages of different partitions are interleaved while each partition stays in
+// offset order.
+//
+// The end of the range is resolved once, when reading starts: messages produced
+// afterwards are not included. Reading stops when every selected partition reaches
+// the end of its range, when the range limit is reached, or when fn returns an error,
+// which is then returned to the caller.
+func (kc *KafkaClient) StreamTopicMessages(ctx context.Context, topicName string, rng domain.MessageRange, fn func(domain.TopicMessage) error) error {
+	if topicName == "" {
+		return InvalidArgumentError("topic name cannot be empty")
+	}
+
+	metadata, err := kc.getMetadata(ctx, &topicName, false)
+	if err != nil {
+		return wrapError(err, "failed to check if topic exists")
+	}
+
+	topicMetadata, exists := metadata.Topics[topicName]
+	if !exists {
+		return TopicNotFoundError(topicName)
+	}
+
+	partitions, err := selectPartitions(topicName, topicMetadata, rng.Partitions)
+	if err != nil {
+		return err
+	}
+
+	consumer, err := kc.newMessageReader(true)
+	if err != nil {
+		return err
+	}
+	defer consumer.Close()
+
+	bounds, err := kc.resolveRangeBounds(consumer, topicName, partitions, rng)
+	if err != nil {
+		return err
+	}
+
+	assignments := make([]kafka.TopicPartition, 0, len(partitions))
+	for _, partition := range partitions {
+		b := bounds[partition]
+		if b.start >= b.end {
+			b.done = true
+			continue
+		}
+		assignments = append(assignments, kafka.TopicPartition{
+			Topic:     &topicName,
+			Partition: partition,
+			Offset:    kafka.Offset(b.start),
+		})
+	}
+	if len(assignments) == 0 {
+		return nil
+	}
+
+	if err := consumer.Assign(assignments); err != nil {
+		return wrapError(err, "failed to assign partitions")
+	}
+
+	remaining := len(assignments)
+	finish := func(partition int32) {
+		b := bounds[partition]
+		if b == nil || b.done {
+			return
+		}
+		b.done = true
+		remaining--
+		// Stop fetching data we are not going to use
+		_ = consumer.Pause([]kafka.TopicPartition{{Topic: &topicName, Partition: partition}})
+	}
+
+	var count int64
+	idlePolls := 0
+	maxIdlePolls := int(kc.Timeout.Milliseconds() / 200)
+
+	for remaining > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		ev := consumer.Poll(200)
+		if ev == nil {
+			idlePolls++
+			if idlePolls > maxIdlePolls {
+				return NewError(ErrTimeout, kafka.ErrTimedOut, "timed out waiting for messages from topic '%s'", topicName)
+			}
+			continue
+		}
+		idlePolls = 0
+
+		switch e := ev.(type) {
+		case *kafka.Message:
+			partition := e.TopicPartition.Partition
+			b := bounds[partition]
+			if b == nil || b.done {
+				continue
+			}
+
+			offset := int64(e.TopicPartition.Offset)
+			if offset >= b.end {
+				finish(partition)
+				continue
+			}
+
+			if err := fn(toTopicMessage(e)); err != nil {
+				return err
+			}
+
+			count++
+			if rng.Limit > 0 && count >= rng.Limit {
+				return nil
+			}
+
+			if offset >= b.end-1 {
+				finish(partition)
+			}
+		case kafka.PartitionEOF:
+			// Gaps left by transaction markers or compaction mean the last
+			// offset of the range may never be delivered
+			finish(e.Partition)
+		case kafka.Error:
+			kafkaErr := e.Code()
+			if kafkaErr == kafka.ErrTimedOut ||
+				kafkaErr == kafka.ErrTransport ||
+				kafkaErr == kafka.ErrBrokerNotAvailable {
+				continue
+			}
+
+			return wrapError(e, "consumer error")
+		}
+	}
+
+	return nil
+}
+
+// selectPartitions returns the requested partitions after checking that they exist,
+// or all partitions of the topic in ascending order when none are requested
+func selectPartitions(topicName string, topicMetadata kafka.TopicMetadata, requested []int32) ([]int32, error) {
+	existing := make(map[int32]bool, len(topicMetadata.Partitions))
+	all := make([]int32, 0, len(topicMetadata.Partitions))
+	for _, partition := range topicMetadata.Partitions {
+		existing[partition.ID] = true
+		all = append(all, partition.ID)
+	}
+
+	if len(requested) == 0 {
+		sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+		return all, nil
+	}
+
+	for _, partition := range requested {
+		if !existing[partition] {
+			return nil, PartitionNotFoundError(partition, topicName)
+		}
+	}
+	return requested, nil
+}
+
+// resolveRangeBounds translates the offsets and timestamps of a MessageRange into
+// concrete [start, end) offsets for every partition, clamped to the partition watermarks
+func (kc *KafkaClient) resolveRangeBounds(consumer *kafka.Consumer, topicName string, partitions []int32, rng domain.MessageRange) (map[int32]*rangeBounds, error) {
+	timeoutMs := int(kc.Timeout.Milliseconds())
+	bounds := make(map[int32]*rangeBounds, len(partitions))
+
+	for _, partition := range partitions {
+		low, high, err := consumer.QueryWatermarkOffsets(topicName, partition, timeoutMs)
+		if err != nil {
+			return nil, wrapError(err, fmt.Sprintf("failed to get watermark offsets for partition %d", partition))
+		}
+
+		b := &rangeBounds{start: low, end: high}
+		if rng.StartOffset != nil && *rng.StartOffset > low {
+			b.start = *rng.StartOffset
+		}
+		if rng.EndOffset != nil && *rng.EndOffset < high {
+			b.end = *rng.EndOffset
+		}
+		bounds[partition] = b
+	}
+
+	if rng.StartOffset == nil && rng.StartTime != nil {
+		offsets, err := kc.offsetsForTime(consumer, topicName, partitions, rng.StartTime.UnixMilli())
+		if err != nil {
+			return nil, err
+		}
+		for partition, offset := range offsets {
+			b := bounds[partition]
+			if offset < 0 {
+				// No message at or after the start time
+				b.start = b.end
+			} else if offset > b.start {
+				b.start = offset
+			}
+		}
+	}
+
+	if rng.EndOffset == nil && rng.EndTime != nil {
+		offsets, err := kc.offsetsForTime(consumer, topicName, partitions, rng.EndTime.UnixMilli())
+		if err != nil {
+			return nil, err
+		}
+		for partition, offset := range offsets {
+			b := bounds[partition]
+			if offset >= 0 && offset < b.end {
+				b.end = offset
+			}
+		}
+	}
+
+	return bounds, nil
+}
+
+// offsetsForTime looks up, for every partition, the earliest offset whose timestamp is
+// at or after timestampMs. Partitions without such a message map to -1.
+func (kc *KafkaClient) offsetsForTime(consumer *kafka.Consumer, topicName string, partitions []int32, timestampMs int64) (map[int32]int64, error) {
+	query := make([]kafka.TopicPartition, 0, len(partitions))
+	for _, partition := range partitions {
+		query = append(query, kafka.TopicPartition{
+			Topic:     &topicName,
+			Partition: partition,
+			Offset:    kafka.Offset(timestampMs),
+		})
+	}
+
+	result, err := consumer.OffsetsForTimes(query, int(kc.Timeout.Milliseconds()))
+	if err != nil {
+		return nil, wrapError(err, "failed to look up offsets by timestamp")
+	}
+
+	offsets := make(map[int32]int64, len(result))
+	for _, tp := range result {
+		if tp.Error != nil {
+			return nil, wrapError(tp.Error, fmt.Sprintf("failed to look up offset by timestamp for partition %d", tp.Partition))
+		}
+		offsets[tp.Partition] = int64(tp.Offset)
+	}
+	return offsets, nil
+}
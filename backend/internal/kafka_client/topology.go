@@ -0,0 +1,197 @@
+package kafka_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// groupConcurrency bounds the consumer groups described at the same time when relating
+// groups to topics, as every group takes its own requests
+const groupConcurrency = 8
+
+// groupView is a consumer group with its members and lag, from which its topics are known
+type groupView struct {
+	details *domain.ConsumerGroupDetails
+	lag     *domain.ConsumerGroupLag
+}
+
+// consumer returns the consumption of a topic by the group, if it consumes the topic
+func (g groupView) consumer(topicName string) (domain.TopicConsumer, bool) {
+	if !slices.Contains(g.details.Topics, topicName) {
+		return domain.TopicConsumer{}, false
+	}
+
+	consumer := domain.TopicConsumer{GroupID: g.details.GroupID, State: g.details.State}
+	for _, member := range g.details.Members {
+		assigned := false
+		for _, assignment := range member.Assignments {
+			if assignment.Topic == topicName {
+				consumer.AssignedPartitions = append(consumer.AssignedPartitions, assignment.Partition)
+				assigned = true
+			}
+		}
+		if assigned {
+			consumer.MemberCount++
+		}
+	}
+	slices.Sort(consumer.AssignedPartitions)
+
+	for _, partition := range g.lag.Partitions {
+		if partition.Topic == topicName {
+			consumer.Partitions = append(consumer.Partitions, partition)
+			consumer.Lag += partition.Lag
+		}
+	}
+	return consumer, true
+}
+
+// describeGroups retrieves the details and lag of consumer groups concurrently. Groups
+// deleted in the meantime are left out.
+func describeGroups(ctx context.Context, k Client, groups []domain.ConsumerGroupInfo) ([]groupView, error) {
+	views := make([]groupView, len(groups))
+	errs := make([]error, len(groups))
+	sem := make(chan struct{}, groupConcurrency)
+	var wg sync.WaitGroup
+	for i, group := range groups {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			details, err := k.GetConsumerGroupDetails(ctx, group.GroupID)
+			if err == nil {
+				views[i].details = details
+				views[i].lag, err = k.GetConsumerGroupLag(ctx, group.GroupID)
+			}
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				errs[i] = fmt.Errorf("consumer group '%s': %w", group.GroupID, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(views, func(view groupView) bool {
+		return view.details == nil || view.lag == nil
+	}), nil
+}
+
+// TopicConsumers lists the consumer groups, among the given ones, with committed offsets
+// or assigned partitions on a topic, with their lag on it
+func TopicConsumers(ctx context.Context, k Client, topicName string, groups []domain.ConsumerGroupInfo) ([]domain.TopicConsumer, error) {
+	views, err := describeGroups(ctx, k, groups)
+	if err != nil {
+		return nil, err
+	}
+
+	consumers := make([]domain.TopicConsumer, 0)
+	for _, view := range views {
+		if consumer, ok := view.consumer(topicName); ok {
+			consumers = append(consumers, consumer)
+		}
+	}
+	sort.Slice(consumers, func(i, j int) bool {
+		return consumers[i].GroupID < consumers[j].GroupID
+	})
+	return consumers, nil
+}
+
+// BuildTopology relates the given topics to the given consumer groups and to the
+// producers that recently wrote to them. Groups consuming none of the topics are
+// left out.
+func BuildTopology(ctx context.Context, k Client, topicNames []string, groups []domain.ConsumerGroupInfo) (*domain.Topology, error) {
+	views, err := describeGroups(ctx, k, groups)
+	if err != nil {
+		return nil, err
+	}
+	producers, err := k.GetActiveProducers(ctx, topicNames)
+	if err != nil {
+		return nil, err
+	}
+
+	topology := &domain.Topology{Nodes: make([]domain.TopologyNode, 0), Edges: make([]domain.TopologyEdge, 0)}
+	sort.Strings(topicNames)
+	for _, topicName := range topicNames {
+		topology.Nodes = append(topology.Nodes, domain.TopologyNode{ID: nodeID(domain.NodeTopic, topicName), Kind: domain.NodeTopic, Label: topicName})
+	}
+
+	// Producers write to partitions: merge them into one edge per producer and topic
+	type producerTopic struct {
+		producerID int64
+		topic      string
+	}
+	produced := make(map[producerTopic][]int32)
+	for _, producer := range producers {
+		key := producerTopic{producer.ProducerID, producer.Topic}
+		produced[key] = append(produced[key], producer.Partition)
+	}
+	keys := slices.Collect(maps.Keys(produced))
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].producerID != keys[j].producerID {
+			return keys[i].producerID < keys[j].producerID
+		}
+		return keys[i].topic < keys[j].topic
+	})
+	for i, key := range keys {
+		producerID := strconv.FormatInt(key.producerID, 10)
+		if i == 0 || keys[i-1].producerID != key.producerID {
+			topology.Nodes = append(topology.Nodes, domain.TopologyNode{ID: nodeID(domain.NodeProducer, producerID), Kind: domain.NodeProducer, Label: "producer " + producerID})
+		}
+		partitions := produced[key]
+		slices.Sort(partitions)
+		topology.Edges = append(topology.Edges, domain.TopologyEdge{
+			Source:     nodeID(domain.NodeProducer, producerID),
+			Target:     nodeID(domain.NodeTopic, key.topic),
+			Kind:       domain.EdgeProduces,
+			Partitions: slices.Compact(partitions),
+		})
+	}
+
+	sort.Slice(views, func(i, j int) bool {
+		return views[i].details.GroupID < views[j].details.GroupID
+	})
+	for _, view := range views {
+		groupNode := nodeID(domain.NodeGroup, view.details.GroupID)
+		consumes := false
+		for _, topicName := range topicNames {
+			consumer, ok := view.consumer(topicName)
+			if !ok {
+				continue
+			}
+			consumes = true
+			partitions := consumer.AssignedPartitions
+			if len(partitions) == 0 {
+				for _, partition := range consumer.Partitions {
+					partitions = append(partitions, partition.Partition)
+				}
+			}
+			topology.Edges = append(topology.Edges, domain.TopologyEdge{
+				Source:     nodeID(domain.NodeTopic, topicName),
+				Target:     groupNode,
+				Kind:       domain.EdgeConsumes,
+				Partitions: partitions,
+				Lag:        &consumer.Lag,
+			})
+		}
+		if consumes {
+			topology.Nodes = append(topology.Nodes, domain.TopologyNode{ID: groupNode, Kind: domain.NodeGroup, Label: view.details.GroupID, State: view.details.State})
+		}
+	}
+	return topology, nil
+}
+
+// nodeID identifies a node of a topology by its kind and name
+func nodeID(kind, name string) string {
+	return kind + ":" + name
+}
@@ -0,0 +1,357 @@
+package kafka_client
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// tracer creates the spans of Kafka operations
+var tracer = otel.Tracer("github.com/valeriouberti/maestro/internal/kafka_client")
+
+// Attributes of the spans of Kafka operations, after the OpenTelemetry semantic conventions for messaging
+var messagingSystem = attribute.String("messaging.system", "kafka")
+
+func topicAttribute(topicName string) attribute.KeyValue {
+	return attribute.String("messaging.destination.name", topicName)
+}
+
+// topicsAttribute names the topics of a bulk operation
+func topicsAttribute(topicNames []string) attribute.KeyValue {
+	return attribute.StringSlice("maestro.topics", topicNames)
+}
+
+func partitionAttribute(partition int32) attribute.KeyValue {
+	return attribute.Int("messaging.destination.partition.id", int(partition))
+}
+
+func groupAttribute(groupID string) attribute.KeyValue {
+	return attribute.String("messaging.consumer.group.name", groupID)
+}
+
+// groupsAttribute names the consumer groups of a bulk operation
+func groupsAttribute(groupIDs []string) attribute.KeyValue {
+	return attribute.StringSlice("maestro.groups", groupIDs)
+}
+
+// leaderAttribute names the broker leading a partition of a topic; -1 when the
+// partition is not known, e.g. when the producer picks the partition
+func leaderAttribute(topicMetadata kafka.TopicMetadata, partition int32) attribute.KeyValue {
+	leader := int32(-1)
+	for _, p := range topicMetadata.Partitions {
+		if p.ID == partition {
+			leader = p.Leader
+			break
+		}
+	}
+	return attribute.Int("messaging.kafka.broker.id", int(leader))
+}
+
+// endSpan records the error an operation failed with, if any, and ends its span
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// startCall starts the span of a call to librdkafka made by an operation of the client
+func (kc *KafkaClient) startCall(ctx context.Context, call string, attrs ...attribute.KeyValue) trace.Span {
+	_, span := tracer.Start(ctx, "rdkafka."+call, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		messagingSystem,
+		attribute.String("server.address", strings.Join(kc.Brokers, ",")),
+	), trace.WithAttributes(attrs...))
+	return span
+}
+
+// TraceID returns the ID of the trace whose W3C trace context is carried by the
+// traceparent header of a message, or an empty string
+func TraceID(headers map[string]string) string {
+	if headers["traceparent"] == "" {
+		return ""
+	}
+	ctx := propagation.TraceContext{}.Extract(context.Background(), propagation.MapCarrier(headers))
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
+
+// withTraceContext returns the headers of a message with the trace context of ctx
+// added. Headers set by the caller, including a traceparent of their own, are kept.
+func withTraceContext(ctx context.Context, headers map[string]string) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return headers
+	}
+
+	combined := make(map[string]string, len(headers)+len(carrier))
+	for key, value := range carrier {
+		combined[key] = value
+	}
+	for key, value := range headers {
+		combined[key] = value
+	}
+	return combined
+}
+
+// TracedClient records a span for every operation of a Client. The trace context is
+// added to the headers of published messages, and the trace ID carried by the
+// headers of read messages is set on the messages.
+type TracedClient struct {
+	client Client
+	attrs  []attribute.KeyValue
+}
+
+var _ Client = (*TracedClient)(nil)
+
+// NewTracedClient wraps client; cluster names the cluster in the spans
+func NewTracedClient(client Client, cluster string) *TracedClient {
+	return &TracedClient{
+		client: client,
+		attrs:  []attribute.KeyValue{messagingSystem, attribute.String("maestro.cluster", cluster)},
+	}
+}
+
+func (t *TracedClient) start(ctx context.Context, operation string, kind trace.SpanKind, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "kafka."+operation, trace.WithSpanKind(kind), trace.WithAttributes(t.attrs...), trace.WithAttributes(attrs...))
+}
+
+// GetBrokers implements Client
+func (t *TracedClient) GetBrokers(ctx context.Context) ([]domain.BrokerInfo, error) {
+	ctx, span := t.start(ctx, "GetBrokers", trace.SpanKindInternal)
+	brokers, err := t.client.GetBrokers(ctx)
+	endSpan(span, err)
+	return brokers, err
+}
+
+// ListTopics implements Client
+func (t *TracedClient) ListTopics(ctx context.Context) ([]domain.TopicInfo, error) {
+	ctx, span := t.start(ctx, "ListTopics", trace.SpanKindInternal)
+	topics, err := t.client.ListTopics(ctx)
+	span.SetAttributes(attribute.Int("maestro.topics", len(topics)))
+	endSpan(span, err)
+	return topics, err
+}
+
+// GetTopicDetails implements Client
+func (t *TracedClient) GetTopicDetails(ctx context.Context, topicName string) (*domain.TopicInfo, error) {
+	ctx, span := t.start(ctx, "GetTopicDetails", trace.SpanKindInternal, topicAttribute(topicName))
+	topic, err := t.client.GetTopicDetails(ctx, topicName)
+	endSpan(span, err)
+	return topic, err
+}
+
+// CreateTopic implements Client
+func (t *TracedClient) CreateTopic(ctx context.Context, topic domain.TopicInfo) error {
+	ctx, span := t.start(ctx, "CreateTopic", trace.SpanKindInternal, topicAttribute(topic.Name))
+	err := t.client.CreateTopic(ctx, topic)
+	endSpan(span, err)
+	return err
+}
+
+// DeleteTopic implements Client
+func (t *TracedClient) DeleteTopic(ctx context.Context, topicName string) error {
+	ctx, span := t.start(ctx, "DeleteTopic", trace.SpanKindInternal, topicAttribute(topicName))
+	err := t.client.DeleteTopic(ctx, topicName)
+	endSpan(span, err)
+	return err
+}
+
+// UpdateTopicConfig implements Client
+func (t *TracedClient) UpdateTopicConfig(ctx context.Context, topicName string, config map[string]string) error {
+	ctx, span := t.start(ctx, "UpdateTopicConfig", trace.SpanKindInternal, topicAttribute(topicName))
+	err := t.client.UpdateTopicConfig(ctx, topicName, config)
+	endSpan(span, err)
+	return err
+}
+
+// DeleteTopics implements Client
+func (t *TracedClient) DeleteTopics(ctx context.Context, topicNames []string) ([]domain.BulkTopicResult, error) {
+	ctx, span := t.start(ctx, "DeleteTopics", trace.SpanKindInternal, topicsAttribute(topicNames))
+	results, err := t.client.DeleteTopics(ctx, topicNames)
+	endSpan(span, err)
+	return results, err
+}
+
+// AlterTopicsConfig implements Client
+func (t *TracedClient) AlterTopicsConfig(ctx context.Context, topicNames []string, set map[string]string, deleted []string) ([]domain.BulkTopicResult, error) {
+	ctx, span := t.start(ctx, "AlterTopicsConfig", trace.SpanKindInternal, topicsAttribute(topicNames))
+	results, err := t.client.AlterTopicsConfig(ctx, topicNames, set, deleted)
+	endSpan(span, err)
+	return results, err
+}
+
+// CreatePartitions implements Client
+func (t *TracedClient) CreatePartitions(ctx context.Context, topicNames []string, partitions int32) ([]domain.BulkTopicResult, error) {
+	ctx, span := t.start(ctx, "CreatePartitions", trace.SpanKindInternal, topicsAttribute(topicNames))
+	results, err := t.client.CreatePartitions(ctx, topicNames, partitions)
+	endSpan(span, err)
+	return results, err
+}
+
+// ListConsumerGroups implements Client
+func (t *TracedClient) ListConsumerGroups(ctx context.Context, states ...string) ([]domain.ConsumerGroupInfo, error) {
+	ctx, span := t.start(ctx, "ListConsumerGroups", trace.SpanKindInternal)
+	groups, err := t.client.ListConsumerGroups(ctx, states...)
+	endSpan(span, err)
+	return groups, err
+}
+
+// GetConsumerGroupDetails implements Client
+func (t *TracedClient) GetConsumerGroupDetails(ctx context.Context, groupID string) (*domain.ConsumerGroupDetails, error) {
+	ctx, span := t.start(ctx, "GetConsumerGroupDetails", trace.SpanKindInternal, groupAttribute(groupID))
+	group, err := t.client.GetConsumerGroupDetails(ctx, groupID)
+	endSpan(span, err)
+	return group, err
+}
+
+// GetConsumerGroupLag implements Client
+func (t *TracedClient) GetConsumerGroupLag(ctx context.Context, groupID string) (*domain.ConsumerGroupLag, error) {
+	ctx, span := t.start(ctx, "GetConsumerGroupLag", trace.SpanKindInternal, groupAttribute(groupID))
+	lag, err := t.client.GetConsumerGroupLag(ctx, groupID)
+	endSpan(span, err)
+	return lag, err
+}
+
+// ResetConsumerGroupOffsets implements Client
+func (t *TracedClient) ResetConsumerGroupOffsets(ctx context.Context, groupID string, spec domain.OffsetResetSpec) ([]domain.OffsetReset, error) {
+	ctx, span := t.start(ctx, "ResetConsumerGroupOffsets", trace.SpanKindInternal, groupAttribute(groupID), topicAttribute(spec.Topic))
+	resets, err := t.client.ResetConsumerGroupOffsets(ctx, groupID, spec)
+	endSpan(span, err)
+	return resets, err
+}
+
+// DeleteConsumerGroup implements Client
+func (t *TracedClient) DeleteConsumerGroup(ctx context.Context, groupID string) error {
+	ctx, span := t.start(ctx, "DeleteConsumerGroup", trace.SpanKindInternal, groupAttribute(groupID))
+	err := t.client.DeleteConsumerGroup(ctx, groupID)
+	endSpan(span, err)
+	return err
+}
+
+// DeleteConsumerGroups implements Client
+func (t *TracedClient) DeleteConsumerGroups(ctx context.Context, groupIDs []string) ([]domain.GroupDeletion, error) {
+	ctx, span := t.start(ctx, "DeleteConsumerGroups", trace.SpanKindInternal, groupsAttribute(groupIDs))
+	deletions, err := t.client.DeleteConsumerGroups(ctx, groupIDs)
+	endSpan(span, err)
+	return deletions, err
+}
+
+// DeleteConsumerGroupOffsets implements Client
+func (t *TracedClient) DeleteConsumerGroupOffsets(ctx context.Context, groupID string, spec domain.OffsetDeletionSpec) ([]domain.CommittedOffset, error) {
+	ctx, span := t.start(ctx, "DeleteConsumerGroupOffsets", trace.SpanKindInternal, groupAttribute(groupID), topicAttribute(spec.Topic))
+	deleted, err := t.client.DeleteConsumerGroupOffsets(ctx, groupID, spec)
+	endSpan(span, err)
+	return deleted, err
+}
+
+// RemoveConsumerGroupMembers implements Client
+func (t *TracedClient) RemoveConsumerGroupMembers(ctx context.Context, groupID string, instanceIDs []string) ([]domain.MemberRemoval, error) {
+	ctx, span := t.start(ctx, "RemoveConsumerGroupMembers", trace.SpanKindInternal, groupAttribute(groupID))
+	removals, err := t.client.RemoveConsumerGroupMembers(ctx, groupID, instanceIDs)
+	endSpan(span, err)
+	return removals, err
+}
+
+// GetActiveProducers implements Client
+func (t *TracedClient) GetActiveProducers(ctx context.Context, topicNames []string) ([]domain.ActiveProducer, error) {
+	ctx, span := t.start(ctx, "GetActiveProducers", trace.SpanKindInternal)
+	producers, err := t.client.GetActiveProducers(ctx, topicNames)
+	endSpan(span, err)
+	return producers, err
+}
+
+// GetTopicMessages implements Client
+func (t *TracedClient) GetTopicMessages(ctx context.Context, topicName string, partition int32, offset int64, limit int) ([]domain.TopicMessage, error) {
+	ctx, span := t.start(ctx, "GetTopicMessages", trace.SpanKindConsumer, topicAttribute(topicName), partitionAttribute(partition),
+		attribute.Int64("messaging.kafka.offset", offset))
+	messages, err := t.client.GetTopicMessages(ctx, topicName, partition, offset, limit)
+	for i := range messages {
+		messages[i].TraceID = TraceID(messages[i].Headers)
+	}
+	span.SetAttributes(attribute.Int("messaging.batch.message_count", len(messages)))
+	endSpan(span, err)
+	return messages, err
+}
+
+// StreamTopicMessages implements Client
+func (t *TracedClient) StreamTopicMessages(ctx context.Context, topicName string, rng domain.MessageRange, fn func(domain.TopicMessage) error) error {
+	ctx, span := t.start(ctx, "StreamTopicMessages", trace.SpanKindConsumer, topicAttribute(topicName))
+	count := 0
+	err := t.client.StreamTopicMessages(ctx, topicName, rng, func(message domain.TopicMessage) error {
+		count++
+		message.TraceID = TraceID(message.Headers)
+		return fn(message)
+	})
+	span.SetAttributes(attribute.Int("messaging.batch.message_count", count))
+	endSpan(span, err)
+	return err
+}
+
+// PublishMessage implements Client
+func (t *TracedClient) PublishMessage(ctx context.Context, topicName string, partition int32, key string, value string, headers map[string]string) error {
+	ctx, span := t.start(ctx, "PublishMessage", trace.SpanKindProducer, topicAttribute(topicName), partitionAttribute(partition))
+	err := t.client.PublishMessage(ctx, topicName, partition, key, value, withTraceContext(ctx, headers))
+	endSpan(span, err)
+	return err
+}
+
+// PublishMessages implements Client
+func (t *TracedClient) PublishMessages(ctx context.Context, topicName string, records []domain.ProduceRecord, ratePerSecond int) ([]domain.ProduceResult, error) {
+	ctx, span := t.start(ctx, "PublishMessages", trace.SpanKindProducer, topicAttribute(topicName),
+		attribute.Int("messaging.batch.message_count", len(records)))
+	traced := make([]domain.ProduceRecord, len(records))
+	for i, record := range records {
+		record.Headers = withTraceContext(ctx, record.Headers)
+		traced[i] = record
+	}
+	results, err := t.client.PublishMessages(ctx, topicName, traced, ratePerSecond)
+	endSpan(span, err)
+	return results, err
+}
+
+// NewTopicProducer implements Client. The records produced carry the trace context
+// given to Produce.
+func (t *TracedClient) NewTopicProducer(ctx context.Context, topicName string, onDelivery func(err error)) (Producer, error) {
+	ctx, span := t.start(ctx, "NewTopicProducer", trace.SpanKindInternal, topicAttribute(topicName))
+	producer, err := t.client.NewTopicProducer(ctx, topicName, onDelivery)
+	endSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return tracedProducer{producer}, nil
+}
+
+// OperationTimeout implements Client
+func (t *TracedClient) OperationTimeout() time.Duration {
+	return t.client.OperationTimeout()
+}
+
+// Close implements Client
+func (t *TracedClient) Close() {
+	t.client.Close()
+}
+
+// tracedProducer adds the trace context to the headers of the records it produces
+type tracedProducer struct {
+	Producer
+}
+
+// Produce implements Producer
+func (p tracedProducer) Produce(ctx context.Context, record domain.ProduceRecord) error {
+	record.Headers = withTraceContext(ctx, record.Headers)
+	return p.Producer.Produce(ctx, record)
+}
@@ -0,0 +1,322 @@
+// Package lag records the lag of consumer groups over time.
+//
+// A Sampler periodically reads the committed offsets and log end offsets of every
+// consumer group of every configured cluster and keeps them in a storage.Store for a
+// retention period. The history of a group shows whether it is catching up or falling
+// behind: the rates at which it consumes and messages are produced, and the time it
+// needs to reach zero lag at those rates.
+package lag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valeriouberti/maestro/internal/clusters"
+	"github.com/valeriouberti/maestro/internal/kafka_client"
+	"github.com/valeriouberti/maestro/internal/storage"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// keyTimeFormat is the fixed-width format of the time in sample keys, so that the keys
+// of a group sort in time order
+const keyTimeFormat = "2006-01-02T15:04:05.000Z"
+
+// pruneInterval is how often samples older than the retention period are removed
+const pruneInterval = time.Hour
+
+// groupConcurrency bounds the consumer groups of a cluster sampled at the same time
+const groupConcurrency = 8
+
+// ErrDisabled is returned when requesting the history while lag sampling is disabled
+var ErrDisabled = errors.New("lag history is disabled")
+
+// Options configures a Sampler
+type Options struct {
+	// Store keeps the samples
+	Store storage.Store
+	// Client reads the lag of the cluster selected by the context, see clusters.WithName
+	Client kafka_client.Client
+	// Clusters lists the clusters to sample
+	Clusters func() []domain.ClusterInfo
+	// Interval is the time between two samples of a group
+	Interval time.Duration
+	// Retention is how long samples are kept. Zero keeps them forever.
+	Retention time.Duration
+}
+
+// HistoryQuery selects the samples of a consumer group
+type HistoryQuery struct {
+	Since time.Time
+	Until time.Time
+	// Topic, when set, limits the samples to the partitions of a topic
+	Topic string
+	// Window is the period at the end of the range the trend is computed over
+	Window time.Duration
+}
+
+// Sampler records the lag of consumer groups periodically
+type Sampler struct {
+	opts      Options
+	ctx       context.Context
+	stop      context.CancelFunc
+	wg        sync.WaitGroup
+	lastPrune time.Time
+}
+
+// NewSampler creates a Sampler; sampling begins with Start
+func NewSampler(opts Options) *Sampler {
+	ctx, stop := context.WithCancel(context.Background())
+	return &Sampler{opts: opts, ctx: ctx, stop: stop}
+}
+
+// Start samples the lag of every group now and then at every interval
+func (s *Sampler) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.opts.Interval)
+		defer ticker.Stop()
+		for {
+			s.sample(time.Now())
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Shutdown stops sampling and waits for the current round to end
+func (s *Sampler) Shutdown() {
+	s.stop()
+	s.wg.Wait()
+}
+
+// sample records the lag of the groups of every cluster at the given time
+func (s *Sampler) sample(now time.Time) {
+	for _, cluster := range s.opts.Clusters() {
+		if s.ctx.Err() != nil {
+			return
+		}
+		if err := s.sampleCluster(cluster.Name, now); err != nil {
+			slog.Warn("Failed to sample consumer group lag", "cluster", cluster.Name, "error", err)
+		}
+	}
+
+	if s.opts.Retention > 0 && now.Sub(s.lastPrune) >= pruneInterval {
+		if err := s.prune(now.Add(-s.opts.Retention)); err != nil {
+			slog.Warn("Failed to remove expired lag samples", "error", err)
+		}
+		s.lastPrune = now
+	}
+}
+
+func (s *Sampler) sampleCluster(cluster string, now time.Time) error {
+	ctx := clusters.WithName(s.ctx, cluster)
+	groups, err := s.opts.Client.ListConsumerGroups(ctx)
+	if err != nil {
+		return err
+	}
+
+	samples := make([]*domain.LagSample, len(groups))
+	sem := make(chan struct{}, groupConcurrency)
+	var wg sync.WaitGroup
+	for i, group := range groups {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			lag, err := s.opts.Client.GetConsumerGroupLag(ctx, group.GroupID)
+			if err != nil {
+				if !errors.Is(err, kafka_client.ErrNotFound) && ctx.Err() == nil {
+					slog.Debug("Failed to sample consumer group lag", "cluster", cluster, "group", group.GroupID, "error", err)
+				}
+				return
+			}
+			// Groups without committed offsets, such as those of message readers, have no lag
+			if len(lag.Partitions) > 0 {
+				samples[i] = &domain.LagSample{Time: now.UTC(), TotalLag: lag.TotalLag, Partitions: lag.Partitions}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return s.opts.Store.Update(func(tx storage.Tx) error {
+		for i, sample := range samples {
+			if sample == nil {
+				continue
+			}
+			if err := storage.PutJSON(tx, storage.BucketLag, sampleKey(cluster, groups[i].GroupID, now), sample); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// prune removes the samples taken before cutoff
+func (s *Sampler) prune(cutoff time.Time) error {
+	var expired []string
+	err := s.opts.Store.ForEach(storage.BucketLag, func(key string, _ []byte) error {
+		t, err := keyTime(key)
+		if err != nil || t.Before(cutoff) {
+			expired = append(expired, key)
+		}
+		return nil
+	})
+	if err != nil || len(expired) == 0 {
+		return err
+	}
+
+	return s.opts.Store.Update(func(tx storage.Tx) error {
+		for _, key := range expired {
+			if err := tx.Delete(storage.BucketLag, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// History returns the samples of a consumer group of the cluster selected by ctx within
+// a time range, with the trend of its lag at the end of the range
+func (s *Sampler) History(ctx context.Context, groupID string, query HistoryQuery) (*domain.LagHistory, error) {
+	if s == nil {
+		return nil, ErrDisabled
+	}
+
+	cluster := clusters.NameFrom(ctx)
+	if cluster == "" {
+		for _, info := range s.opts.Clusters() {
+			if info.Default {
+				cluster = info.Name
+			}
+		}
+	}
+
+	history := &domain.LagHistory{Cluster: cluster, GroupID: groupID, Topic: query.Topic, Samples: make([]domain.LagSample, 0)}
+	err := s.opts.Store.ForEachPrefix(storage.BucketLag, groupPrefix(cluster, groupID), func(key string, value []byte) error {
+		t, err := keyTime(key)
+		if err != nil || t.Before(query.Since) || t.After(query.Until) {
+			return nil
+		}
+
+		var sample domain.LagSample
+		if err := json.Unmarshal(value, &sample); err != nil {
+			return fmt.Errorf("failed to decode lag sample '%s': %w", key, err)
+		}
+		if query.Topic != "" {
+			sample = topicSample(sample, query.Topic)
+		}
+		history.Samples = append(history.Samples, sample)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lag history: %w", err)
+	}
+
+	history.Trend = Trend(history.Samples, query.Window)
+	return history, nil
+}
+
+// topicSample limits a sample to the partitions of a topic
+func topicSample(sample domain.LagSample, topic string) domain.LagSample {
+	limited := domain.LagSample{Time: sample.Time}
+	for _, partition := range sample.Partitions {
+		if partition.Topic == topic {
+			limited.Partitions = append(limited.Partitions, partition)
+			limited.TotalLag += partition.Lag
+		}
+	}
+	return limited
+}
+
+// Trend computes the rates of a consumer group from the first and last samples within
+// the window ending at the last sample. It returns nil without two such samples.
+func Trend(samples []domain.LagSample, window time.Duration) *domain.LagTrend {
+	if len(samples) < 2 {
+		return nil
+	}
+	last := samples[len(samples)-1]
+	first := samples[0]
+	for _, sample := range samples {
+		if window <= 0 || !sample.Time.Before(last.Time.Add(-window)) {
+			first = sample
+			break
+		}
+	}
+	elapsed := last.Time.Sub(first.Time).Seconds()
+	if elapsed <= 0 {
+		return nil
+	}
+
+	// Offsets that moved backwards, after an offset reset or a recreated topic, count as no progress
+	type partitionKey struct {
+		topic     string
+		partition int32
+	}
+	previous := make(map[partitionKey]domain.PartitionLag, len(first.Partitions))
+	for _, partition := range first.Partitions {
+		previous[partitionKey{partition.Topic, partition.Partition}] = partition
+	}
+	var consumed, produced int64
+	for _, partition := range last.Partitions {
+		before, ok := previous[partitionKey{partition.Topic, partition.Partition}]
+		if !ok {
+			continue
+		}
+		consumed += max(partition.CommittedOffset-before.CommittedOffset, 0)
+		produced += max(partition.LogEndOffset-before.LogEndOffset, 0)
+	}
+
+	trend := &domain.LagTrend{
+		Since:           first.Time,
+		Until:           last.Time,
+		ConsumptionRate: float64(consumed) / elapsed,
+		ProductionRate:  float64(produced) / elapsed,
+	}
+	trend.LagRate = trend.ProductionRate - trend.ConsumptionRate
+
+	switch {
+	case last.TotalLag == 0:
+		trend.Status = domain.LagCaughtUp
+	case consumed == 0:
+		trend.Status = domain.LagStalled
+	case trend.LagRate < 0:
+		trend.Status = domain.LagCatchingUp
+		eta := float64(last.TotalLag) / -trend.LagRate
+		catchUpAt := last.Time.Add(time.Duration(eta * float64(time.Second)))
+		trend.ETASeconds, trend.CatchUpAt = &eta, &catchUpAt
+	case trend.LagRate > 0:
+		trend.Status = domain.LagFallingBehind
+	default:
+		trend.Status = domain.LagSteady
+	}
+	return trend
+}
+
+// groupPrefix is the prefix of the keys of the samples of a group. The names are
+// escaped so that no prefix of a group is the prefix of another.
+func groupPrefix(cluster, groupID string) string {
+	return url.PathEscape(cluster) + "/" + url.PathEscape(groupID) + "/"
+}
+
+func sampleKey(cluster, groupID string, t time.Time) string {
+	return groupPrefix(cluster, groupID) + t.UTC().Format(keyTimeFormat)
+}
+
+// keyTime returns the time of the sample stored under a key
+func keyTime(key string) (time.Time, error) {
+	return time.Parse(keyTimeFormat, key[strings.LastIndex(key, "/")+1:])
+}
@@ -0,0 +1,114 @@
+// Package logging configures structured logging with log/slog.
+//
+// Attributes added to a context with With, such as the request ID, the user and the
+// cluster of a request, are added to every record logged with that context, e.g.
+// with slog.InfoContext. The level of the default logger can be changed at runtime.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Formats of the log output
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Options configure the default logger
+type Options struct {
+	Level  string    // debug, info, warn or error
+	Format string    // text or json
+	Output io.Writer // Default: os.Stderr
+}
+
+// Setup installs a default slog logger, to which the standard log package is also
+// redirected, and returns the variable controlling its level
+func Setup(opts Options) (*slog.LevelVar, error) {
+	level := new(slog.LevelVar)
+	parsed, err := ParseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+	level.Set(parsed)
+
+	output := opts.Output
+	if output == nil {
+		output = os.Stderr
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch opts.Format {
+	case FormatText, "":
+		handler = slog.NewTextHandler(output, handlerOpts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(output, handlerOpts)
+	default:
+		return nil, fmt.Errorf("unknown log format %q (supported: text, json)", opts.Format)
+	}
+
+	slog.SetDefault(slog.New(contextHandler{handler}))
+	return level, nil
+}
+
+// ParseLevel parses a level name: debug, info, warn or error
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q (supported: debug, info, warn, error)", name)
+	}
+}
+
+// LevelName returns the name of a level as accepted by ParseLevel
+func LevelName(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
+
+type attrsKey struct{}
+
+// With returns a context whose log records carry the given attributes in addition to
+// those already added to ctx
+func With(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	combined := make([]slog.Attr, 0, len(existing)+len(attrs))
+	combined = append(combined, existing...)
+	combined = append(combined, attrs...)
+	return context.WithValue(ctx, attrsKey{}, combined)
+}
+
+// contextHandler adds the attributes of the context of a record to the record
+type contextHandler struct {
+	slog.Handler
+}
+
+// Handle implements slog.Handler
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if attrs, ok := ctx.Value(attrsKey{}).([]slog.Attr); ok {
+		record.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs implements slog.Handler
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,323 @@
+// Package openapi builds the OpenAPI 3 document of the API from a description of its
+// endpoints. Request and response schemas are generated from the Go types the handlers
+// use, and Check verifies that the described endpoints match the registered gin routes.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Version is the OpenAPI version of the generated documents
+const Version = "3.0.3"
+
+// Endpoint describes an API operation
+type Endpoint struct {
+	Method  string
+	Path    string // gin route path relative to the API base path, e.g. "/topics/:topicName"
+	ID      string // Operation ID, e.g. "getTopic"; the Go client names its method after it
+	Summary string
+	Tag     string
+	Query   []Param
+
+	// Request is the zero value of the JSON request body type; nil when there is no body
+	Request any
+	// Status is the status of a successful response; zero means 200 OK
+	Status int
+	// Response is the zero value of the JSON response type, or a Fields object describing it
+	Response any
+	// Download marks a response that is a file rather than JSON
+	Download bool
+	// Public marks an operation that does not require authentication
+	Public bool
+}
+
+// Param describes a query parameter
+type Param struct {
+	Name        string
+	Description string
+	Type        any // Zero value of the parameter type, e.g. "" or 0
+}
+
+// Fields describes a JSON object by the zero values of its properties, like the gin.H
+// maps the handlers respond with. Keys ending with "?" are optional properties; values
+// may be Fields themselves.
+type Fields map[string]any
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Servers    []Server              `json:"servers,omitempty"`
+	Security   []map[string][]string `json:"security,omitempty"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	Tags       []Tag                 `json:"tags,omitempty"`
+}
+
+// Info is the info object of a Document
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Server is a server object of a Document
+type Server struct {
+	URL string `json:"url"`
+}
+
+// Tag is a tag object of a Document
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// PathItem maps lower-case HTTP methods to the operations of a path
+type PathItem map[string]*Operation
+
+// Operation is an operation object of a Document
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+	// Security overrides the security requirements of the document; empty means none
+	Security *[]map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter is a parameter object of an Operation
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // path, query or header
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody is a request body object of an Operation
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is a response object of an Operation
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType is a media type object of a request body or response
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the reusable schemas and security schemes of a Document
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme is a security scheme object of a Document
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Name         string `json:"name,omitempty"`
+	In           string `json:"in,omitempty"`
+}
+
+// Media types of request and response bodies
+const (
+	MediaTypeJSON    = "application/json"
+	MediaTypeProblem = "application/problem+json"
+	MediaTypeBinary  = "application/octet-stream"
+)
+
+// Options configures the document built by Build
+type Options struct {
+	Info     Info
+	BasePath string // Path the endpoints are served under, e.g. "/api/v1"
+	// Problem is the zero value of the body of error responses
+	Problem any
+	// SecuritySchemes are the accepted ways to authenticate, any one of which suffices
+	SecuritySchemes map[string]SecurityScheme
+	// Headers are request headers accepted by every endpoint that is not public
+	Headers []Param
+}
+
+// Build creates the document describing the endpoints
+func Build(opts Options, endpoints []Endpoint) *Document {
+	g := &schemas{components: make(map[string]*Schema)}
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    opts.Info,
+		Servers: []Server{{URL: opts.BasePath}},
+		Paths:   make(map[string]PathItem),
+	}
+
+	var problem *Schema
+	if opts.Problem != nil {
+		problem = g.of(opts.Problem, "")
+	}
+
+	var tags []string
+	for _, e := range endpoints {
+		op := &Operation{
+			OperationID: e.ID,
+			Summary:     e.Summary,
+			Responses:   make(map[string]Response),
+		}
+		if e.Public {
+			op.Security = &[]map[string][]string{}
+		}
+		if e.Tag != "" {
+			op.Tags = []string{e.Tag}
+			if !slices.Contains(tags, e.Tag) {
+				tags = append(tags, e.Tag)
+			}
+		}
+
+		for _, name := range PathParams(e.Path) {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+		}
+		for _, param := range e.Query {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:        param.Name,
+				In:          "query",
+				Description: param.Description,
+				Schema:      g.forType(reflect.TypeOf(param.Type)),
+			})
+		}
+		if !e.Public {
+			for _, header := range opts.Headers {
+				op.Parameters = append(op.Parameters, Parameter{
+					Name:        header.Name,
+					In:          "header",
+					Description: header.Description,
+					Schema:      g.forType(reflect.TypeOf(header.Type)),
+				})
+			}
+		}
+
+		if e.Request != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{MediaTypeJSON: {Schema: g.of(e.Request, exportedName(e.ID)+"Request")}},
+			}
+		}
+
+		status := e.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		response := Response{Description: http.StatusText(status)}
+		switch {
+		case e.Download:
+			response.Content = map[string]MediaType{MediaTypeBinary: {Schema: &Schema{Type: "string", Format: "binary"}}}
+		case e.Response != nil:
+			response.Content = map[string]MediaType{MediaTypeJSON: {Schema: g.of(e.Response, exportedName(e.ID)+"Response")}}
+		}
+		op.Responses[fmt.Sprint(status)] = response
+		if problem != nil {
+			op.Responses["default"] = Response{
+				Description: "Error",
+				Content:     map[string]MediaType{MediaTypeProblem: {Schema: problem}},
+			}
+		}
+
+		path := openAPIPath(e.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(PathItem)
+		}
+		doc.Paths[path][strings.ToLower(e.Method)] = op
+	}
+
+	for _, tag := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: tag})
+	}
+
+	doc.Components.Schemas = g.components
+	if len(opts.SecuritySchemes) > 0 {
+		doc.Components.SecuritySchemes = opts.SecuritySchemes
+		for name := range opts.SecuritySchemes {
+			doc.Security = append(doc.Security, map[string][]string{name: {}})
+		}
+		slices.SortFunc(doc.Security, func(a, b map[string][]string) int {
+			return strings.Compare(firstKey(a), firstKey(b))
+		})
+	}
+
+	return doc
+}
+
+// Check reports the gin routes under basePath that no endpoint describes and the
+// endpoints without a route
+func Check(routes gin.RoutesInfo, basePath string, endpoints []Endpoint) error {
+	described := make(map[string]bool, len(endpoints))
+	for _, e := range endpoints {
+		described[e.Method+" "+basePath+e.Path] = true
+	}
+
+	var problems []string
+	for _, route := range routes {
+		if !strings.HasPrefix(route.Path, basePath+"/") {
+			continue
+		}
+		key := route.Method + " " + route.Path
+		if !described[key] {
+			problems = append(problems, "undocumented route "+key)
+		}
+		delete(described, key)
+	}
+	for key := range described {
+		problems = append(problems, "documented endpoint without route "+key)
+	}
+
+	if len(problems) > 0 {
+		slices.Sort(problems)
+		return fmt.Errorf("API description does not match the routes: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// PathParams returns the names of the parameters of a gin route path
+func PathParams(path string) []string {
+	var params []string
+	for _, segment := range strings.Split(path, "/") {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			params = append(params, name)
+		}
+	}
+	return params
+}
+
+// openAPIPath turns a gin route path such as /topics/:topicName into /topics/{topicName}
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func firstKey(m map[string][]string) string {
+	for key := range m {
+		return key
+	}
+	return ""
+}
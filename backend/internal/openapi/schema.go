@@ -0,0 +1,174 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Schema is an OpenAPI schema object
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+
+	// GoType is the Go type the schema was generated from, as "import/path.Name"
+	GoType string `json:"x-go-type,omitempty"`
+}
+
+// refPrefix prefixes the names of component schemas in references
+const refPrefix = "#/components/schemas/"
+
+// RefName returns the name of the component schema a schema refers to, or "" when it is not a reference
+func (s *Schema) RefName() string {
+	name, ok := strings.CutPrefix(s.Ref, refPrefix)
+	if !ok {
+		return ""
+	}
+	return name
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemas generates schemas from Go types and collects the named struct types as components
+type schemas struct {
+	components map[string]*Schema
+}
+
+// of returns the schema of a value, e.g. the zero value of a request type, or of a Fields object
+// that is registered as a component under name
+func (g *schemas) of(value any, name string) *Schema {
+	if fields, ok := value.(Fields); ok {
+		return g.fields(fields, name)
+	}
+	return g.forType(reflect.TypeOf(value))
+}
+
+// fields registers the inline object described by fields as a component
+func (g *schemas) fields(fields Fields, name string) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for key, value := range fields {
+		property, optional := strings.CutSuffix(key, "?")
+		schema.Properties[property] = g.of(value, name+exportedName(property))
+		if !optional {
+			schema.Required = append(schema.Required, property)
+		}
+	}
+	slices.Sort(schema.Required)
+
+	g.components[name] = schema
+	return &Schema{Ref: refPrefix + name}
+}
+
+// forType returns the schema of a Go type. Named struct types are registered as
+// components and referred to.
+func (g *schemas) forType(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time", GoType: "time.Time"}
+	case rawMessageType:
+		return &Schema{GoType: "encoding/json.RawMessage"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := g.forType(t.Elem())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+		return schema
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.forType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.forType(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		if _, exists := g.components[t.Name()]; !exists {
+			// Register before generating the properties so that recursive types terminate
+			g.components[t.Name()] = &Schema{}
+			schema := g.structSchema(t)
+			schema.GoType = t.PkgPath() + "." + t.Name()
+			g.components[t.Name()] = schema
+		}
+		return &Schema{Ref: refPrefix + t.Name()}
+	default:
+		return &Schema{}
+	}
+}
+
+// structSchema returns the object schema of the JSON fields of a struct. Fields with
+// a binding:"required" tag are required.
+func (g *schemas) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	for field := range fieldsOf(t) {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = g.forType(field.Type)
+		if slices.Contains(strings.Split(field.Tag.Get("binding"), ","), "required") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
+
+// fieldsOf yields the exported fields of a struct, including those of embedded structs
+func fieldsOf(t reflect.Type) func(yield func(reflect.StructField) bool) {
+	return func(yield func(reflect.StructField) bool) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+				for embedded := range fieldsOf(field.Type) {
+					if !yield(embedded) {
+						return
+					}
+				}
+				continue
+			}
+			if !field.IsExported() {
+				continue
+			}
+			if !yield(field) {
+				return
+			}
+		}
+	}
+}
+
+// exportedName turns a JSON property or operation ID such as "publishedMessage" into "PublishedMessage"
+func exportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -0,0 +1,240 @@
+// Package policy checks topics against topic policies: the naming conventions,
+// partition counts, replication factors and configurations allowed when creating topics
+// and changing their configuration.
+//
+// A policy applies to the clusters it lists, or to every cluster, and to the users in
+// one of the groups it lists, or to everyone. Policies listing groups are typically the
+// conventions of a team, such as the prefixes of its topics. A topic must satisfy every
+// policy that applies, except for prefixes: its name must start with a prefix of any of
+// the policies that apply.
+package policy
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/valeriouberti/maestro/internal/auth"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// Rules of topic policies, reported in violations
+const (
+	RuleNamePattern          = "name-pattern"
+	RulePrefix               = "prefix"
+	RuleMinPartitions        = "min-partitions"
+	RuleMaxPartitions        = "max-partitions"
+	RuleMinReplicationFactor = "min-replication-factor"
+	RuleRequiredConfig       = "required-config"
+	RuleBannedConfig         = "banned-config"
+)
+
+// AnyValue in the banned values of a configuration entry bans setting the entry at all
+const AnyValue = "*"
+
+// TopicPolicy restricts the topics that may be created. Zero values do not restrict.
+type TopicPolicy struct {
+	Name string
+	// Clusters the policy applies to; all when empty
+	Clusters []string
+	// Groups are the groups of the users the policy applies to; everyone when empty
+	Groups []string
+
+	NamePattern          *regexp.Regexp
+	Prefixes             []string
+	MinPartitions        int32
+	MaxPartitions        int32
+	MinReplicationFactor int
+	// RequiredConfigs are configuration entries every topic must set
+	RequiredConfigs []string
+	// BannedConfigs are values, or AnyValue, configuration entries must not have
+	BannedConfigs map[string][]string
+}
+
+// appliesTo reports whether the policy applies to a user on a cluster
+func (p TopicPolicy) appliesTo(cluster string, principal *auth.Principal) bool {
+	if len(p.Clusters) > 0 && !slices.Contains(p.Clusters, cluster) {
+		return false
+	}
+	if len(p.Groups) == 0 {
+		return true
+	}
+	if principal == nil {
+		return false
+	}
+	for _, group := range principal.Groups {
+		if slices.Contains(p.Groups, group) {
+			return true
+		}
+	}
+	return false
+}
+
+// Checker checks topics against the policies applying to the request
+type Checker struct {
+	policies []TopicPolicy
+	// cluster returns the name of the cluster a request operates on
+	cluster func(ctx context.Context) string
+}
+
+// NewChecker creates a Checker. cluster returns the name of the cluster selected by the
+// context of a request, such as clusters.Registry.Selected.
+func NewChecker(policies []TopicPolicy, cluster func(ctx context.Context) string) *Checker {
+	return &Checker{policies: policies, cluster: cluster}
+}
+
+// applicable returns the policies applying to a request
+func (c *Checker) applicable(ctx context.Context, principal *auth.Principal) []TopicPolicy {
+	if c == nil {
+		return nil
+	}
+	cluster := c.cluster(ctx)
+	var policies []TopicPolicy
+	for _, p := range c.policies {
+		if p.appliesTo(cluster, principal) {
+			policies = append(policies, p)
+		}
+	}
+	return policies
+}
+
+// CheckTopic returns every rule a new topic breaks. A nil Checker allows every topic.
+func (c *Checker) CheckTopic(ctx context.Context, principal *auth.Principal, topic domain.TopicInfo) []domain.PolicyViolation {
+	policies := c.applicable(ctx, principal)
+
+	var violations []domain.PolicyViolation
+	var prefixes, prefixPolicies []string
+	for _, p := range policies {
+		if p.NamePattern != nil && !p.NamePattern.MatchString(topic.Name) {
+			violations = append(violations, domain.PolicyViolation{
+				Policy: p.Name, Rule: RuleNamePattern, Field: "name",
+				Message: fmt.Sprintf("topic name %q does not match the pattern %s", topic.Name, p.NamePattern),
+			})
+		}
+		if len(p.Prefixes) > 0 {
+			prefixes = append(prefixes, p.Prefixes...)
+			prefixPolicies = append(prefixPolicies, p.Name)
+		}
+		violations = append(violations, checkPartitions(p, topic.NumPartitions)...)
+		if p.MinReplicationFactor > 0 && topic.ReplicationFactor < p.MinReplicationFactor {
+			violations = append(violations, domain.PolicyViolation{
+				Policy: p.Name, Rule: RuleMinReplicationFactor, Field: "replicationFactor",
+				Message: fmt.Sprintf("topic must have a replication factor of at least %d, got %d", p.MinReplicationFactor, topic.ReplicationFactor),
+			})
+		}
+		violations = append(violations, checkConfig(p, topic.Config)...)
+	}
+
+	if len(prefixes) > 0 && !slices.ContainsFunc(prefixes, func(prefix string) bool { return strings.HasPrefix(topic.Name, prefix) }) {
+		violations = append(violations, domain.PolicyViolation{
+			Policy: strings.Join(prefixPolicies, ","), Rule: RulePrefix, Field: "name",
+			Message: fmt.Sprintf("topic name %q must start with one of %s", topic.Name, strings.Join(prefixes, ", ")),
+		})
+	}
+	return violations
+}
+
+// CheckConfig returns every rule the new configuration overrides of a topic break. A nil
+// Checker allows every configuration.
+func (c *Checker) CheckConfig(ctx context.Context, principal *auth.Principal, config map[string]string) []domain.PolicyViolation {
+	var violations []domain.PolicyViolation
+	for _, p := range c.applicable(ctx, principal) {
+		violations = append(violations, checkConfig(p, config)...)
+	}
+	return violations
+}
+
+// CheckConfigChange returns every rule setting and removing configuration overrides of
+// topics breaks, leaving their other overrides aside. A nil Checker allows every change.
+func (c *Checker) CheckConfigChange(ctx context.Context, principal *auth.Principal, set map[string]string, deleted []string) []domain.PolicyViolation {
+	var violations []domain.PolicyViolation
+	for _, p := range c.applicable(ctx, principal) {
+		violations = append(violations, checkRequired(p, func(key string) bool { return !slices.Contains(deleted, key) })...)
+		violations = append(violations, checkBanned(p, set)...)
+	}
+	return violations
+}
+
+// CheckPartitions returns every rule a new number of partitions of topics breaks. A nil
+// Checker allows every number.
+func (c *Checker) CheckPartitions(ctx context.Context, principal *auth.Principal, partitions int32) []domain.PolicyViolation {
+	var violations []domain.PolicyViolation
+	for _, p := range c.applicable(ctx, principal) {
+		violations = append(violations, checkPartitions(p, partitions)...)
+	}
+	return violations
+}
+
+// checkPartitions checks a number of partitions against the bounds of a policy
+func checkPartitions(p TopicPolicy, partitions int32) []domain.PolicyViolation {
+	var violations []domain.PolicyViolation
+	if p.MinPartitions > 0 && partitions < p.MinPartitions {
+		violations = append(violations, domain.PolicyViolation{
+			Policy: p.Name, Rule: RuleMinPartitions, Field: "numPartitions",
+			Message: fmt.Sprintf("topic must have at least %d partitions, got %d", p.MinPartitions, partitions),
+		})
+	}
+	if p.MaxPartitions > 0 && partitions > p.MaxPartitions {
+		violations = append(violations, domain.PolicyViolation{
+			Policy: p.Name, Rule: RuleMaxPartitions, Field: "numPartitions",
+			Message: fmt.Sprintf("topic must have at most %d partitions, got %d", p.MaxPartitions, partitions),
+		})
+	}
+	return violations
+}
+
+// checkConfig checks configuration overrides against the required and banned entries of a policy
+func checkConfig(p TopicPolicy, config map[string]string) []domain.PolicyViolation {
+	violations := checkRequired(p, func(key string) bool {
+		_, ok := config[key]
+		return ok
+	})
+	return append(violations, checkBanned(p, config)...)
+}
+
+// checkRequired checks that the required entries of a policy are set
+func checkRequired(p TopicPolicy, isSet func(key string) bool) []domain.PolicyViolation {
+	var violations []domain.PolicyViolation
+	for _, key := range p.RequiredConfigs {
+		if !isSet(key) {
+			violations = append(violations, domain.PolicyViolation{
+				Policy: p.Name, Rule: RuleRequiredConfig, Field: "config." + key,
+				Message: fmt.Sprintf("configuration entry %s is required", key),
+			})
+		}
+	}
+	return violations
+}
+
+// checkBanned checks configuration entries against the banned values of a policy
+func checkBanned(p TopicPolicy, config map[string]string) []domain.PolicyViolation {
+	var violations []domain.PolicyViolation
+	keys := make([]string, 0, len(p.BannedConfigs))
+	for key := range p.BannedConfigs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value, ok := config[key]
+		if !ok {
+			continue
+		}
+		banned := p.BannedConfigs[key]
+		switch {
+		case slices.Contains(banned, AnyValue):
+			violations = append(violations, domain.PolicyViolation{
+				Policy: p.Name, Rule: RuleBannedConfig, Field: "config." + key,
+				Message: fmt.Sprintf("configuration entry %s must not be set", key),
+			})
+		case slices.Contains(banned, value):
+			violations = append(violations, domain.PolicyViolation{
+				Policy: p.Name, Rule: RuleBannedConfig, Field: "config." + key,
+				Message: fmt.Sprintf("configuration entry %s must not be %q", key, value),
+			})
+		}
+	}
+	return violations
+}
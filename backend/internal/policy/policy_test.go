@@ -0,0 +1,206 @@
+package policy
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/valeriouberti/maestro/internal/auth"
+	"github.com/valeriouberti/maestro/internal/problem"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// newTestChecker creates a Checker of the policies on the cluster "primary"
+func newTestChecker(policies ...TopicPolicy) *Checker {
+	return NewChecker(policies, func(context.Context) string { return "primary" })
+}
+
+// rules returns the policy and rule of every violation, e.g. "sizes/max-partitions"
+func rules(violations []domain.PolicyViolation) []string {
+	found := make([]string, len(violations))
+	for i, violation := range violations {
+		found[i] = violation.Policy + "/" + violation.Rule
+	}
+	return found
+}
+
+func TestCheckTopic(t *testing.T) {
+	checker := newTestChecker(
+		TopicPolicy{Name: "naming", NamePattern: regexp.MustCompile(`^[a-z]+\.[a-z-]+$`)},
+		TopicPolicy{Name: "billing", Groups: []string{"billing"}, Prefixes: []string{"billing."}},
+		TopicPolicy{Name: "payments", Groups: []string{"payments"}, Prefixes: []string{"payments.", "psp."}},
+		TopicPolicy{Name: "sizes", MinPartitions: 3, MaxPartitions: 12, MinReplicationFactor: 3},
+		TopicPolicy{Name: "staging", Clusters: []string{"staging"}, MaxPartitions: 1},
+	)
+	valid := domain.TopicInfo{Name: "billing.invoices", NumPartitions: 6, ReplicationFactor: 3}
+	billingTeam := &auth.Principal{Name: "alice", Groups: []string{"billing"}}
+	both := &auth.Principal{Name: "bob", Groups: []string{"billing", "payments"}}
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		change    func(topic *domain.TopicInfo)
+		want      []string
+	}{
+		{"valid", billingTeam, nil, nil},
+		{"no team prefix", nil, func(topic *domain.TopicInfo) { topic.Name = "orders.created" }, nil},
+		{"name pattern", billingTeam, func(topic *domain.TopicInfo) { topic.Name = "billing.Invoices" }, []string{"naming/name-pattern"}},
+		{"prefix", billingTeam, func(topic *domain.TopicInfo) { topic.Name = "orders.created" }, []string{"billing/prefix"}},
+		{"prefix of another team", both, func(topic *domain.TopicInfo) { topic.Name = "psp.refunds" }, nil},
+		{"prefix of no team", both, func(topic *domain.TopicInfo) { topic.Name = "orders.created" }, []string{"billing,payments/prefix"}},
+		{"min partitions", billingTeam, func(topic *domain.TopicInfo) { topic.NumPartitions = 2 }, []string{"sizes/min-partitions"}},
+		{"lowest partitions", billingTeam, func(topic *domain.TopicInfo) { topic.NumPartitions = 3 }, nil},
+		{"max partitions", billingTeam, func(topic *domain.TopicInfo) { topic.NumPartitions = 13 }, []string{"sizes/max-partitions"}},
+		{"highest partitions", billingTeam, func(topic *domain.TopicInfo) { topic.NumPartitions = 12 }, nil},
+		{"replication factor", billingTeam, func(topic *domain.TopicInfo) { topic.ReplicationFactor = 2 }, []string{"sizes/min-replication-factor"}},
+		{"every rule", billingTeam, func(topic *domain.TopicInfo) {
+			topic.Name = "Orders"
+			topic.NumPartitions = 24
+			topic.ReplicationFactor = 1
+		}, []string{"naming/name-pattern", "sizes/max-partitions", "sizes/min-replication-factor", "billing/prefix"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topic := valid
+			if tt.change != nil {
+				tt.change(&topic)
+			}
+			if got := rules(checker.CheckTopic(context.Background(), tt.principal, topic)); !slices.Equal(got, tt.want) {
+				t.Errorf("violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	checker := newTestChecker(
+		TopicPolicy{Name: "retention", RequiredConfigs: []string{"retention.ms"}},
+		TopicPolicy{Name: "durability", BannedConfigs: map[string][]string{
+			"unclean.leader.election.enable": {"true"},
+			"min.insync.replicas":            {"0", "1"},
+			"message.timestamp.type":         {AnyValue},
+		}},
+	)
+
+	tests := []struct {
+		name   string
+		config map[string]string
+		want   []string
+	}{
+		{"valid", map[string]string{"retention.ms": "86400000", "min.insync.replicas": "2", "unclean.leader.election.enable": "false"}, nil},
+		{"required", map[string]string{"cleanup.policy": "compact"}, []string{"retention/required-config"}},
+		{"banned value", map[string]string{"retention.ms": "86400000", "unclean.leader.election.enable": "true"}, []string{"durability/banned-config"}},
+		{"other banned value", map[string]string{"retention.ms": "86400000", "min.insync.replicas": "0"}, []string{"durability/banned-config"}},
+		{"banned entry", map[string]string{"retention.ms": "86400000", "message.timestamp.type": "CreateTime"}, []string{"durability/banned-config"}},
+		{"every rule", map[string]string{"min.insync.replicas": "1", "message.timestamp.type": "LogAppendTime"}, []string{
+			"retention/required-config", "durability/banned-config", "durability/banned-config",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := checker.CheckConfig(context.Background(), nil, tt.config)
+			if got := rules(violations); !slices.Equal(got, tt.want) {
+				t.Errorf("violations = %v, want %v", got, tt.want)
+			}
+			for _, violation := range violations {
+				if !strings.HasPrefix(violation.Field, "config.") {
+					t.Errorf("violation field = %q, want the configuration entry", violation.Field)
+				}
+			}
+		})
+	}
+
+	violations := checker.CheckConfig(context.Background(), nil, map[string]string{"min.insync.replicas": "1", "message.timestamp.type": "LogAppendTime"})
+	if want := "config.message.timestamp.type"; violations[1].Field != want || !strings.Contains(violations[1].Message, "must not be set") {
+		t.Errorf("banned entry violation = %+v, want field %s", violations[1], want)
+	}
+	if want := "config.min.insync.replicas"; violations[2].Field != want || !strings.Contains(violations[2].Message, `must not be "1"`) {
+		t.Errorf("banned value violation = %+v, want field %s", violations[2], want)
+	}
+}
+
+func TestCheckConfigChange(t *testing.T) {
+	checker := newTestChecker(
+		TopicPolicy{Name: "retention", RequiredConfigs: []string{"retention.ms"}},
+		TopicPolicy{Name: "durability", BannedConfigs: map[string][]string{"unclean.leader.election.enable": {"true"}}},
+	)
+
+	tests := []struct {
+		name    string
+		set     map[string]string
+		deleted []string
+		want    []string
+	}{
+		{"other overrides", map[string]string{"cleanup.policy": "compact"}, nil, nil},
+		{"delete other overrides", nil, []string{"cleanup.policy"}, nil},
+		{"delete required", nil, []string{"retention.ms"}, []string{"retention/required-config"}},
+		{"banned value", map[string]string{"unclean.leader.election.enable": "true"}, nil, []string{"durability/banned-config"}},
+		{"every rule", map[string]string{"unclean.leader.election.enable": "true"}, []string{"retention.ms"}, []string{
+			"retention/required-config", "durability/banned-config",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rules(checker.CheckConfigChange(context.Background(), nil, tt.set, tt.deleted)); !slices.Equal(got, tt.want) {
+				t.Errorf("violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPartitions(t *testing.T) {
+	checker := newTestChecker(
+		TopicPolicy{Name: "sizes", MinPartitions: 3, MaxPartitions: 12},
+		TopicPolicy{Name: "billing", Groups: []string{"billing"}, MaxPartitions: 6},
+	)
+	billingTeam := &auth.Principal{Name: "alice", Groups: []string{"billing"}}
+
+	tests := []struct {
+		name       string
+		principal  *auth.Principal
+		partitions int32
+		want       []string
+	}{
+		{"within bounds", nil, 12, nil},
+		{"too few", nil, 2, []string{"sizes/min-partitions"}},
+		{"too many", nil, 13, []string{"sizes/max-partitions"}},
+		{"team bound", billingTeam, 8, []string{"billing/max-partitions"}},
+		{"every bound", billingTeam, 13, []string{"sizes/max-partitions", "billing/max-partitions"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rules(checker.CheckPartitions(context.Background(), tt.principal, tt.partitions)); !slices.Equal(got, tt.want) {
+				t.Errorf("violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNilChecker(t *testing.T) {
+	var checker *Checker
+	if violations := checker.CheckTopic(context.Background(), nil, domain.TopicInfo{Name: "Any Name"}); violations != nil {
+		t.Errorf("CheckTopic = %v, want no violations", violations)
+	}
+	if violations := checker.CheckPartitions(context.Background(), nil, 1000); violations != nil {
+		t.Errorf("CheckPartitions = %v, want no violations", violations)
+	}
+}
+
+func TestViolationsProblem(t *testing.T) {
+	checker := newTestChecker(TopicPolicy{Name: "sizes", MaxPartitions: 12, MinReplicationFactor: 3})
+	violations := checker.CheckTopic(context.Background(), nil, domain.TopicInfo{Name: "orders", NumPartitions: 24, ReplicationFactor: 1})
+
+	p := problem.PolicyViolation("Topic breaks topic policies", violations)
+	if p.Status != http.StatusUnprocessableEntity || p.Code != problem.CodePolicyViolation {
+		t.Fatalf("problem = %d %s, want 422 %s", p.Status, p.Code, problem.CodePolicyViolation)
+	}
+	if !slices.Equal(p.Violations, violations) || len(p.Violations) != 2 {
+		t.Fatalf("problem violations = %+v, want %+v", p.Violations, violations)
+	}
+	if want := violations[0].Message + "; " + violations[1].Message; p.Detail != want {
+		t.Errorf("problem detail = %q, want %q", p.Detail, want)
+	}
+}
@@ -0,0 +1,39 @@
+package problem
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// titleKey is the context key of the title given to Abort
+const titleKey = "problem.title"
+
+// Middleware renders the last error attached to the request context, typically with
+// Abort, as problem details. It does nothing when a response has already been written.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		p := *FromError(c.Errors.Last().Err, c.GetString(titleKey))
+		p.Instance = c.Request.URL.Path
+		c.Header("Content-Type", ContentType)
+		c.JSON(p.Status, p)
+	}
+}
+
+// Abort stops the chain of handlers of the request with a problem, which the Middleware renders
+func Abort(c *gin.Context, p *Problem) {
+	_ = c.Error(p)
+	c.Abort()
+}
+
+// AbortWithError stops the chain of handlers of the request with err, which the Middleware
+// maps with FromError and renders. title summarizes what failed, e.g. "Failed to create topic".
+func AbortWithError(c *gin.Context, err error, title string) {
+	c.Set(titleKey, title)
+	_ = c.Error(err)
+	c.Abort()
+}
@@ -0,0 +1,147 @@
+// Package problem renders API errors as RFC 7807 problem details
+// (application/problem+json) with a stable, machine-readable error code.
+package problem
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/valeriouberti/maestro/internal/kafka_client"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// ContentType is the media type of problem details
+const ContentType = "application/problem+json"
+
+// Error codes. They are part of the API and must not change once released.
+const (
+	CodeInvalidArgument   = "invalid_argument"
+	CodeUnauthenticated   = "unauthenticated"
+	CodeForbidden         = "forbidden"
+	CodeNotFound          = "not_found"
+	CodeAlreadyExists     = "already_exists"
+	CodeConflict          = "conflict"
+	CodeTooLarge          = "too_large"
+	CodePolicyViolation   = "policy_violation"
+	CodeProtectedTopic    = "protected_topic"
+	CodeUnauthorized      = "unauthorized" // Maestro itself is not authorized by Kafka
+	CodeTimeout           = "timeout"
+	CodeBrokerUnavailable = "broker_unavailable"
+	CodeNotImplemented    = "not_implemented"
+	CodeInternal          = "internal"
+)
+
+// typePrefix prefixes the code in the type URI of a problem
+const typePrefix = "urn:maestro:problem:"
+
+// Problem is an RFC 7807 problem details object, the body of every API error response.
+// It implements error so that handlers can hand it to Abort.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code"`
+	KafkaCode int    `json:"kafkaCode,omitempty"` // Kafka error code the problem originates from
+
+	// Violations lists every policy rule a request breaks
+	Violations []domain.PolicyViolation `json:"violations,omitempty"`
+}
+
+// New creates a Problem
+func New(status int, code, title, detail string) *Problem {
+	return &Problem{
+		Type:   typePrefix + code,
+		Title:  title,
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// Error implements error
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return p.Title
+	}
+	return p.Title + ": " + p.Detail
+}
+
+// BadRequest creates a 400 invalid_argument problem
+func BadRequest(title, detail string) *Problem {
+	return New(http.StatusBadRequest, CodeInvalidArgument, title, detail)
+}
+
+// NotFound creates a 404 not_found problem
+func NotFound(title, detail string) *Problem {
+	return New(http.StatusNotFound, CodeNotFound, title, detail)
+}
+
+// Conflict creates a 409 conflict problem
+func Conflict(title, detail string) *Problem {
+	return New(http.StatusConflict, CodeConflict, title, detail)
+}
+
+// ProtectedTopic creates a 409 protected_topic problem, for changes to protected topics
+// that lack a confirmation
+func ProtectedTopic(title, detail string) *Problem {
+	return New(http.StatusConflict, CodeProtectedTopic, title, detail)
+}
+
+// PolicyViolation creates a 422 policy_violation problem listing the broken rules
+func PolicyViolation(title string, violations []domain.PolicyViolation) *Problem {
+	messages := make([]string, len(violations))
+	for i, violation := range violations {
+		messages[i] = violation.Message
+	}
+	p := New(http.StatusUnprocessableEntity, CodePolicyViolation, title, strings.Join(messages, "; "))
+	p.Violations = violations
+	return p
+}
+
+// Internal creates a 500 internal problem
+func Internal(title, detail string) *Problem {
+	return New(http.StatusInternalServerError, CodeInternal, title, detail)
+}
+
+// FromError maps an error to a Problem with the given title. Problems are returned
+// as they are; errors of a kafka_client kind get the matching status and code, and
+// any other error is an internal error. An empty title uses the status text.
+func FromError(err error, title string) *Problem {
+	var p *Problem
+	if errors.As(err, &p) {
+		return p
+	}
+
+	status, code := http.StatusInternalServerError, CodeInternal
+	switch {
+	case errors.Is(err, kafka_client.ErrNotFound):
+		status, code = http.StatusNotFound, CodeNotFound
+	case errors.Is(err, kafka_client.ErrAlreadyExists):
+		status, code = http.StatusConflict, CodeAlreadyExists
+	case errors.Is(err, kafka_client.ErrConflict):
+		status, code = http.StatusConflict, CodeConflict
+	case errors.Is(err, kafka_client.ErrInvalidArgument):
+		status, code = http.StatusBadRequest, CodeInvalidArgument
+	case errors.Is(err, kafka_client.ErrUnauthorized):
+		status, code = http.StatusForbidden, CodeUnauthorized
+	case errors.Is(err, kafka_client.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		status, code = http.StatusGatewayTimeout, CodeTimeout
+	case errors.Is(err, kafka_client.ErrBrokerUnavailable):
+		status, code = http.StatusServiceUnavailable, CodeBrokerUnavailable
+	}
+
+	if title == "" {
+		title = http.StatusText(status)
+	}
+	p = New(status, code, title, err.Error())
+
+	var kafkaErr *kafka_client.Error
+	if errors.As(err, &kafkaErr) {
+		p.KafkaCode = int(kafkaErr.Code)
+	}
+	return p
+}
@@ -0,0 +1,82 @@
+// Package protection tells which topics are protected: the topics internal to Kafka,
+// such as __consumer_offsets, and the topics matching the glob patterns of the
+// configuration, such as _schemas. Deleting a protected topic, adding partitions to it
+// or changing its risky configuration entries requires the topic:protected permission
+// and a confirmation. Topics are protected by name only; there are no topic labels.
+package protection
+
+import (
+	"path"
+	"slices"
+
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// AnyConfig in the risky configuration entries makes every change risky
+const AnyConfig = "*"
+
+// DefaultRiskyConfigs are the configuration entries whose change is risky when the
+// configuration lists none: those that drop data or weaken durability
+var DefaultRiskyConfigs = []string{
+	"cleanup.policy",
+	"retention.ms",
+	"retention.bytes",
+	"min.insync.replicas",
+	"unclean.leader.election.enable",
+}
+
+// Guard tells which topics and configuration changes are protected. A nil Guard only
+// protects internal topics, with the default risky entries.
+type Guard struct {
+	patterns     []string
+	riskyConfigs []string
+}
+
+// New creates a Guard protecting the topics whose names match the glob patterns, which
+// must be valid. Empty riskyConfigs default to DefaultRiskyConfigs.
+func New(patterns, riskyConfigs []string) *Guard {
+	if len(riskyConfigs) == 0 {
+		riskyConfigs = DefaultRiskyConfigs
+	}
+	return &Guard{patterns: patterns, riskyConfigs: riskyConfigs}
+}
+
+// Protected reports whether a topic is protected
+func (g *Guard) Protected(topic domain.TopicInfo) bool {
+	if topic.IsInternal {
+		return true
+	}
+	if g == nil {
+		return false
+	}
+	for _, pattern := range g.patterns {
+		if ok, _ := path.Match(pattern, topic.Name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Mark sets IsProtected on the protected topics
+func (g *Guard) Mark(topics []domain.TopicInfo) {
+	for i := range topics {
+		topics[i].IsProtected = g.Protected(topics[i])
+	}
+}
+
+// Risky returns the risky configuration entries among the given ones, sorted
+func (g *Guard) Risky(keys ...string) []string {
+	risky := DefaultRiskyConfigs
+	if g != nil {
+		risky = g.riskyConfigs
+	}
+
+	var found []string
+	for _, key := range keys {
+		if (slices.Contains(risky, AnyConfig) || slices.Contains(risky, key)) && !slices.Contains(found, key) {
+			found = append(found, key)
+		}
+	}
+	slices.Sort(found)
+	return found
+}
@@ -0,0 +1,77 @@
+package protection
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+func TestProtected(t *testing.T) {
+	guard := New([]string{"prod.*", "_schemas"}, nil)
+
+	tests := []struct {
+		topic domain.TopicInfo
+		want  bool
+	}{
+		{domain.TopicInfo{Name: "__consumer_offsets", IsInternal: true}, true},
+		{domain.TopicInfo{Name: "prod.payments"}, true},
+		{domain.TopicInfo{Name: "prod."}, true},
+		{domain.TopicInfo{Name: "_schemas"}, true},
+		{domain.TopicInfo{Name: "_schemas.backup"}, false},
+		{domain.TopicInfo{Name: "preprod.payments"}, false},
+		{domain.TopicInfo{Name: "orders"}, false},
+	}
+	for _, tt := range tests {
+		if got := guard.Protected(tt.topic); got != tt.want {
+			t.Errorf("Protected(%s) = %v, want %v", tt.topic.Name, got, tt.want)
+		}
+	}
+
+	var none *Guard
+	if !none.Protected(domain.TopicInfo{Name: "__consumer_offsets", IsInternal: true}) {
+		t.Error("a nil Guard does not protect internal topics")
+	}
+	if none.Protected(domain.TopicInfo{Name: "prod.payments"}) {
+		t.Error("a nil Guard protects a topic by name")
+	}
+}
+
+func TestMark(t *testing.T) {
+	topics := []domain.TopicInfo{
+		{Name: "__consumer_offsets", IsInternal: true},
+		{Name: "orders", IsProtected: true},
+		{Name: "prod.payments"},
+	}
+	New([]string{"prod.*"}, nil).Mark(topics)
+
+	for i, want := range []bool{true, false, true} {
+		if topics[i].IsProtected != want {
+			t.Errorf("%s: IsProtected = %v, want %v", topics[i].Name, topics[i].IsProtected, want)
+		}
+	}
+}
+
+func TestRisky(t *testing.T) {
+	tests := []struct {
+		name  string
+		guard *Guard
+		keys  []string
+		want  []string
+	}{
+		{"default entries", New(nil, nil), []string{"retention.ms", "max.message.bytes", "cleanup.policy"}, []string{"cleanup.policy", "retention.ms"}},
+		{"safe entries", New(nil, nil), []string{"max.message.bytes", "segment.ms"}, nil},
+		{"duplicates", New(nil, nil), []string{"retention.ms", "retention.ms"}, []string{"retention.ms"}},
+		{"nil guard", nil, []string{"min.insync.replicas", "segment.ms"}, []string{"min.insync.replicas"}},
+		{"configured entries", New(nil, []string{"segment.ms"}), []string{"retention.ms", "segment.ms"}, []string{"segment.ms"}},
+		{"any entry", New(nil, []string{AnyConfig}), []string{"segment.ms", "max.message.bytes"}, []string{"max.message.bytes", "segment.ms"}},
+		{"no entries", New(nil, nil), nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.guard.Risky(tt.keys...); !slices.Equal(got, tt.want) {
+				t.Errorf("Risky(%v) = %v, want %v", tt.keys, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/valeriouberti/maestro/internal/auth"
+	"github.com/valeriouberti/maestro/internal/problem"
+)
+
+// Gin context keys of the authorizer and of the cluster resolver
+const (
+	authorizerKey = "maestro.authorizer"
+	clusterKey    = "maestro.rbac.cluster"
+)
+
+// Middleware returns a gin middleware that makes the authorizer available to
+// Require and Allowed. A nil authorizer disables access control. cluster returns the
+// name of the cluster a context selects, and of the default cluster when it selects
+// none; roles limited to clusters are checked against it.
+func Middleware(a *Authorizer, cluster func(ctx context.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if a != nil {
+			c.Set(authorizerKey, a)
+			c.Set(clusterKey, cluster)
+		}
+		c.Next()
+	}
+}
+
+// Require returns a gin middleware that rejects the request with 403 Forbidden unless
+// the principal may perform the action. The resource is read from the given route
+// parameter, e.g. "topicName"; with an empty parameter the action must be granted on
+// at least one resource and handlers check the individual resources with Allowed.
+func Require(action Action, param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := ""
+		if param != "" {
+			name = c.Param(param)
+		}
+
+		if !Authorize(c, action, name) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// Allowed reports whether the principal of the request may perform the action on the
+// named resource of the cluster of the request. It always returns true when access
+// control is disabled.
+func Allowed(c *gin.Context, action Action, name string) bool {
+	return allowed(c, cluster(c, c.Request.Context()), action, name)
+}
+
+// AllowedOn is like Allowed for a resource of the named cluster; an empty name is the
+// default cluster
+func AllowedOn(c *gin.Context, clusterName string, action Action, name string) bool {
+	if clusterName == "" {
+		clusterName = cluster(c, context.Background())
+	}
+	return allowed(c, clusterName, action, name)
+}
+
+// cluster returns the name of the cluster ctx selects
+func cluster(c *gin.Context, ctx context.Context) string {
+	if resolve, ok := c.Value(clusterKey).(func(context.Context) string); ok && resolve != nil {
+		return resolve(ctx)
+	}
+	return ""
+}
+
+func allowed(c *gin.Context, cluster string, action Action, name string) bool {
+	value, ok := c.Get(authorizerKey)
+	if !ok {
+		return true
+	}
+	return value.(*Authorizer).Allowed(auth.PrincipalFrom(c), action, cluster, name)
+}
+
+// Authorize is like Allowed but also aborts the request with 403 Forbidden when the
+// action is not allowed
+func Authorize(c *gin.Context, action Action, name string) bool {
+	return authorize(c, Allowed(c, action, name), "", action, name)
+}
+
+// AuthorizeOn is like AllowedOn but also aborts the request with 403 Forbidden when the
+// action is not allowed
+func AuthorizeOn(c *gin.Context, cluster string, action Action, name string) bool {
+	return authorize(c, AllowedOn(c, cluster, action, name), cluster, action, name)
+}
+
+func authorize(c *gin.Context, allowed bool, cluster string, action Action, name string) bool {
+	if allowed {
+		return true
+	}
+
+	detail := fmt.Sprintf("%s is not allowed", action)
+	if name != "" {
+		detail = fmt.Sprintf("%s is not allowed on %q", action, name)
+	}
+	if cluster != "" {
+		detail += fmt.Sprintf(" of cluster %q", cluster)
+	}
+	problem.Abort(c, problem.New(http.StatusForbidden, problem.CodeForbidden, "Permission denied", detail))
+	return false
+}
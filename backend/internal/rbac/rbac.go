@@ -0,0 +1,217 @@
+// Package rbac authorizes API operations with role-based access control.
+//
+// A policy defines roles as sets of actions, optionally limited to topics or consumer
+// groups whose names match glob patterns and to some of the configured clusters, and
+// binds roles to users or groups of authenticated principals.
+package rbac
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/valeriouberti/maestro/internal/auth"
+)
+
+// Action is an operation that can be granted to a role
+type Action string
+
+// Actions that can be granted to a role
+const (
+	ActionTopicRead      Action = "topic:read"
+	ActionTopicCreate    Action = "topic:create"
+	ActionTopicDelete    Action = "topic:delete"
+	ActionTopicConfig    Action = "topic:config"
+	ActionTopicProtected Action = "topic:protected" // Delete and make risky changes to protected topics
+	ActionMessageRead    Action = "message:read"
+	ActionMessagePublish Action = "message:publish"
+	ActionGroupRead      Action = "group:read"
+	ActionGroupReset     Action = "group:reset"
+	ActionGroupDelete    Action = "group:delete"
+	ActionAuditRead      Action = "audit:read"
+	ActionConfigRead     Action = "config:read"
+	ActionConfigWrite    Action = "config:write"
+
+	// ActionAll grants every action
+	ActionAll Action = "*"
+)
+
+// Actions lists every action that can be granted
+var Actions = []Action{
+	ActionTopicRead,
+	ActionTopicCreate,
+	ActionTopicDelete,
+	ActionTopicConfig,
+	ActionTopicProtected,
+	ActionMessageRead,
+	ActionMessagePublish,
+	ActionGroupRead,
+	ActionGroupReset,
+	ActionGroupDelete,
+	ActionAuditRead,
+	ActionConfigRead,
+	ActionConfigWrite,
+}
+
+// onGroups reports whether the action applies to consumer groups rather than topics
+func (a Action) onGroups() bool {
+	return a == ActionGroupRead || a == ActionGroupReset || a == ActionGroupDelete
+}
+
+// onResources reports whether the action applies to topics or consumer groups, which
+// belong to a cluster
+func (a Action) onResources() bool {
+	return strings.HasPrefix(string(a), "topic:") || strings.HasPrefix(string(a), "message:") || a.onGroups()
+}
+
+// Role is a named set of actions. Topic actions (topic:* and message:*) are limited
+// to the topics matching Topics, group actions to the groups matching Groups, and both
+// to the clusters matching Clusters; an empty list of patterns matches every name.
+type Role struct {
+	Actions  []Action `yaml:"actions" json:"actions"`
+	Topics   []string `yaml:"topics,omitempty" json:"topics,omitempty"`
+	Groups   []string `yaml:"groups,omitempty" json:"groups,omitempty"`
+	Clusters []string `yaml:"clusters,omitempty" json:"clusters,omitempty"`
+}
+
+// Binding grants a role to users, matched by principal name or subject, and to
+// members of groups, matched against the principal's groups (e.g. OIDC groups)
+type Binding struct {
+	Role   string   `yaml:"role" json:"role"`
+	Users  []string `yaml:"users,omitempty" json:"users,omitempty"`
+	Groups []string `yaml:"groups,omitempty" json:"groups,omitempty"`
+}
+
+// Policy is the content of a policy file
+type Policy struct {
+	Roles    map[string]Role `yaml:"roles" json:"roles"`
+	Bindings []Binding       `yaml:"bindings" json:"bindings"`
+	// DefaultRoles are granted to every authenticated principal
+	DefaultRoles []string `yaml:"defaultRoles,omitempty" json:"defaultRoles,omitempty"`
+}
+
+// Authorizer decides whether a principal may perform an action
+type Authorizer struct {
+	policy Policy
+}
+
+// LoadPolicyFile reads a YAML (or JSON) policy file and creates an Authorizer
+func LoadPolicyFile(file string) (*Authorizer, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read RBAC policy file: %w", err)
+	}
+
+	var policy Policy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse RBAC policy file: %w", err)
+	}
+
+	return NewAuthorizer(policy)
+}
+
+// NewAuthorizer validates a policy and creates an Authorizer
+func NewAuthorizer(policy Policy) (*Authorizer, error) {
+	for name, role := range policy.Roles {
+		if len(role.Actions) == 0 {
+			return nil, fmt.Errorf("role %q grants no actions", name)
+		}
+		for _, action := range role.Actions {
+			if action != ActionAll && !slices.Contains(Actions, action) {
+				return nil, fmt.Errorf("role %q: unknown action %q", name, action)
+			}
+		}
+		for _, pattern := range slices.Concat(role.Topics, role.Groups, role.Clusters) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("role %q: invalid pattern %q: %w", name, pattern, err)
+			}
+		}
+	}
+
+	for i, binding := range policy.Bindings {
+		if _, ok := policy.Roles[binding.Role]; !ok {
+			return nil, fmt.Errorf("binding %d: unknown role %q", i, binding.Role)
+		}
+		if len(binding.Users) == 0 && len(binding.Groups) == 0 {
+			return nil, fmt.Errorf("binding %d: role %q is not bound to any user or group", i, binding.Role)
+		}
+	}
+
+	for _, name := range policy.DefaultRoles {
+		if _, ok := policy.Roles[name]; !ok {
+			return nil, fmt.Errorf("unknown default role %q", name)
+		}
+	}
+
+	return &Authorizer{policy: policy}, nil
+}
+
+// Allowed reports whether the principal may perform the action on the named topic
+// or consumer group of a cluster. An empty name asks whether the action is granted on
+// any resource of the cluster, which is used for listings whose items are then filtered
+// one by one.
+func (a *Authorizer) Allowed(p *auth.Principal, action Action, cluster, name string) bool {
+	if p == nil {
+		return false
+	}
+
+	for _, role := range a.roles(p) {
+		if !slices.Contains(role.Actions, action) && !slices.Contains(role.Actions, ActionAll) {
+			continue
+		}
+		if action.onResources() && !matchesAny(role.Clusters, cluster) {
+			continue
+		}
+		if name == "" {
+			return true
+		}
+
+		patterns := role.Topics
+		if action.onGroups() {
+			patterns = role.Groups
+		}
+		if matchesAny(patterns, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// roles returns the roles granted to a principal
+func (a *Authorizer) roles(p *auth.Principal) []Role {
+	var roles []Role
+	for _, name := range a.policy.DefaultRoles {
+		roles = append(roles, a.policy.Roles[name])
+	}
+
+	for _, binding := range a.policy.Bindings {
+		bound := slices.Contains(binding.Users, p.Name) ||
+			slices.Contains(binding.Users, p.Subject) ||
+			slices.ContainsFunc(p.Groups, func(group string) bool {
+				return slices.Contains(binding.Groups, group)
+			})
+		if bound {
+			roles = append(roles, a.policy.Roles[binding.Role])
+		}
+	}
+
+	return roles
+}
+
+// matchesAny reports whether the name matches one of the glob patterns; an empty list matches everything
+func matchesAny(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,282 @@
+package rbac
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/valeriouberti/maestro/internal/auth"
+	"github.com/valeriouberti/maestro/internal/problem"
+)
+
+// testPolicy grants roles limited by topic, group and cluster patterns
+var testPolicy = Policy{
+	Roles: map[string]Role{
+		"admin":         {Actions: []Action{ActionAll}},
+		"orders-writer": {Actions: []Action{ActionTopicRead, ActionMessagePublish}, Topics: []string{"orders.*", "payments"}},
+		"billing-ops":   {Actions: []Action{ActionGroupRead, ActionGroupReset}, Groups: []string{"billing-*"}},
+		"staging-admin": {Actions: []Action{ActionAll}, Clusters: []string{"staging*"}},
+		"auditor":       {Actions: []Action{ActionAuditRead, ActionConfigRead}, Clusters: []string{"staging"}},
+		"public-reader": {Actions: []Action{ActionTopicRead, ActionMessageRead}, Topics: []string{"public.*"}},
+	},
+	Bindings: []Binding{
+		{Role: "admin", Users: []string{"root"}},
+		{Role: "orders-writer", Groups: []string{"orders-team"}},
+		{Role: "billing-ops", Users: []string{"svc-billing"}},
+		{Role: "staging-admin", Groups: []string{"developers"}},
+		{Role: "auditor", Users: []string{"auditor"}},
+	},
+	DefaultRoles: []string{"public-reader"},
+}
+
+func newTestAuthorizer(t *testing.T) *Authorizer {
+	t.Helper()
+	a, err := NewAuthorizer(testPolicy)
+	if err != nil {
+		t.Fatalf("NewAuthorizer: %v", err)
+	}
+	return a
+}
+
+// user returns a principal with the name and groups
+func user(name string, groups ...string) *auth.Principal {
+	return &auth.Principal{Subject: "sub-" + name, Name: name, Groups: groups}
+}
+
+func TestAllowed(t *testing.T) {
+	a := newTestAuthorizer(t)
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		action    Action
+		cluster   string
+		resource  string
+		want      bool
+	}{
+		// Every action
+		{"admin deletes any topic", user("root"), ActionTopicDelete, "prod", "orders.created", true},
+		{"admin writes the configuration", user("root"), ActionConfigWrite, "", "", true},
+		{"no principal", nil, ActionTopicRead, "prod", "public.news", false},
+
+		// Topic patterns
+		{"topic matching the pattern", user("alice", "orders-team"), ActionMessagePublish, "prod", "orders.created", true},
+		{"topic matching a literal", user("alice", "orders-team"), ActionMessagePublish, "prod", "payments", true},
+		{"topic not matching", user("alice", "orders-team"), ActionMessagePublish, "prod", "payments.refunds", false},
+		{"pattern requires its separator", user("alice", "orders-team"), ActionMessagePublish, "prod", "orders", false},
+		{"action not granted", user("alice", "orders-team"), ActionTopicDelete, "prod", "orders.created", false},
+		{"any topic for listings", user("alice", "orders-team"), ActionMessagePublish, "prod", "", true},
+		{"topic patterns do not grant groups", user("alice", "orders-team"), ActionGroupRead, "prod", "orders.created", false},
+
+		// Group patterns
+		{"user not bound", user("billing-bot"), ActionGroupReset, "prod", "billing-invoices", false},
+		{"bound by subject", &auth.Principal{Subject: "svc-billing", Name: "Billing"}, ActionGroupReset, "prod", "billing-invoices", true},
+		{"group not matching", &auth.Principal{Subject: "svc-billing"}, ActionGroupReset, "prod", "shipping", false},
+		{"group action not granted", &auth.Principal{Subject: "svc-billing"}, ActionGroupDelete, "prod", "billing-invoices", false},
+		{"group patterns do not grant topics", &auth.Principal{Subject: "svc-billing"}, ActionTopicRead, "prod", "billing-invoices", false},
+
+		// Cluster scoping
+		{"role limited to its cluster", user("dave", "developers"), ActionTopicDelete, "staging", "orders.created", true},
+		{"cluster matching the pattern", user("dave", "developers"), ActionGroupDelete, "staging-eu", "billing-invoices", true},
+		{"role limited to another cluster", user("dave", "developers"), ActionTopicDelete, "prod", "orders.created", false},
+		{"listing on another cluster", user("dave", "developers"), ActionTopicDelete, "prod", "", false},
+		{"other roles still apply on another cluster", user("dave", "developers", "orders-team"), ActionTopicRead, "prod", "orders.created", true},
+		{"clusters do not limit actions outside clusters", user("auditor"), ActionAuditRead, "prod", "", true},
+		{"cluster-wide role does not grant other actions", user("auditor"), ActionConfigWrite, "staging", "", false},
+
+		// Default roles
+		{"default role for anyone", user("nobody"), ActionMessageRead, "prod", "public.news", true},
+		{"default role limited by pattern", user("nobody"), ActionMessageRead, "prod", "orders.created", false},
+		{"default role grants no other action", user("nobody"), ActionMessagePublish, "prod", "public.news", false},
+		{"bindings add to default roles", user("alice", "orders-team"), ActionMessageRead, "prod", "public.news", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := a.Allowed(tt.principal, tt.action, tt.cluster, tt.resource); got != tt.want {
+				t.Errorf("Allowed(%s, %q, %q) = %v, want %v", tt.action, tt.cluster, tt.resource, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewAuthorizerErrors(t *testing.T) {
+	tests := map[string]Policy{
+		"no actions":         {Roles: map[string]Role{"empty": {}}},
+		"unknown action":     {Roles: map[string]Role{"r": {Actions: []Action{"topic:truncate"}}}},
+		"invalid pattern":    {Roles: map[string]Role{"r": {Actions: []Action{ActionTopicRead}, Topics: []string{"orders.["}}}},
+		"unknown role":       {Bindings: []Binding{{Role: "missing", Users: []string{"alice"}}}},
+		"unbound binding":    {Roles: map[string]Role{"r": {Actions: []Action{ActionTopicRead}}}, Bindings: []Binding{{Role: "r"}}},
+		"unknown default":    {DefaultRoles: []string{"missing"}},
+		"invalid cluster":    {Roles: map[string]Role{"r": {Actions: []Action{ActionTopicRead}, Clusters: []string{"["}}}},
+		"invalid group glob": {Roles: map[string]Role{"r": {Actions: []Action{ActionGroupRead}, Groups: []string{"billing-["}}}},
+	}
+	for name, policy := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewAuthorizer(policy); err == nil {
+				t.Error("NewAuthorizer accepted the policy")
+			}
+		})
+	}
+}
+
+func TestLoadPolicyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rbac.yaml")
+	policy := `
+roles:
+  operator:
+    actions: [topic:read, group:read, group:reset]
+    groups: ["billing-*"]
+    clusters: [prod]
+bindings:
+  - role: operator
+    groups: [ops]
+`
+	if err := os.WriteFile(path, []byte(policy), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	a, err := LoadPolicyFile(path)
+	if err != nil {
+		t.Fatalf("LoadPolicyFile: %v", err)
+	}
+	if !a.Allowed(user("erin", "ops"), ActionGroupReset, "prod", "billing-invoices") {
+		t.Error("the loaded policy does not grant its role")
+	}
+	if a.Allowed(user("erin", "ops"), ActionGroupReset, "staging", "billing-invoices") {
+		t.Error("the loaded policy grants its role on another cluster")
+	}
+
+	if err := os.WriteFile(path, []byte("roles: [operator"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if _, err := LoadPolicyFile(path); err == nil {
+		t.Error("LoadPolicyFile accepted invalid YAML")
+	}
+}
+
+// principalHeader names the user of a test request, and groupsHeader its groups
+const (
+	principalHeader = "X-Test-User"
+	groupsHeader    = "X-Test-Groups"
+)
+
+// headerAuthenticator authenticates requests by the user named in principalHeader
+type headerAuthenticator struct{}
+
+func (headerAuthenticator) Authenticate(r *http.Request) (*auth.Principal, error) {
+	name := r.Header.Get(principalHeader)
+	if name == "" {
+		return nil, auth.ErrNoCredentials
+	}
+	return user(name, strings.Split(r.Header.Get(groupsHeader), ",")...), nil
+}
+
+func (headerAuthenticator) Challenge() string {
+	return "Test"
+}
+
+// newTestRouter serves topics of the cluster named by the cluster query parameter,
+// "prod" by default, with access control by the authorizer
+func newTestRouter(a *Authorizer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(problem.Middleware())
+	r.Use(auth.Middleware(headerAuthenticator{}), func(c *gin.Context) {
+		cluster := c.DefaultQuery("cluster", "prod")
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), clusterContextKey{}, cluster))
+	})
+	r.Use(Middleware(a, func(ctx context.Context) string {
+		if cluster, ok := ctx.Value(clusterContextKey{}).(string); ok {
+			return cluster
+		}
+		return "prod"
+	}))
+
+	r.DELETE("/topics/:topicName", Require(ActionTopicDelete, "topicName"), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	r.GET("/topics", Require(ActionTopicRead, ""), func(c *gin.Context) {
+		var visible []string
+		for _, topic := range []string{"orders.created", "payments", "public.news", "secrets"} {
+			if Allowed(c, ActionTopicRead, topic) {
+				visible = append(visible, topic)
+			}
+		}
+		c.String(http.StatusOK, strings.Join(visible, ","))
+	})
+	r.POST("/clusters/:cluster/groups/:group/reset", func(c *gin.Context) {
+		if !AuthorizeOn(c, c.Param("cluster"), ActionGroupReset, c.Param("group")) {
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+	return r
+}
+
+// clusterContextKey holds the cluster a test request selects
+type clusterContextKey struct{}
+
+func TestMiddleware(t *testing.T) {
+	r := newTestRouter(newTestAuthorizer(t))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		user   string
+		groups string
+		status int
+		body   string
+	}{
+		{"allowed", http.MethodDelete, "/topics/orders.created", "root", "", http.StatusNoContent, ""},
+		{"denied on the resource", http.MethodDelete, "/topics/orders.created", "alice", "orders-team", http.StatusForbidden, `topic:delete is not allowed on \"orders.created\"`},
+		{"unauthenticated", http.MethodDelete, "/topics/orders.created", "", "", http.StatusUnauthorized, ""},
+		{"allowed on the selected cluster", http.MethodDelete, "/topics/orders.created?cluster=staging", "dave", "developers", http.StatusNoContent, ""},
+		{"denied on another cluster", http.MethodDelete, "/topics/orders.created", "dave", "developers", http.StatusForbidden, "topic:delete is not allowed"},
+		{"listing filtered by pattern", http.MethodGet, "/topics", "alice", "orders-team", http.StatusOK, "orders.created,payments,public.news"},
+		{"listing with a default role", http.MethodGet, "/topics", "nobody", "", http.StatusOK, "public.news"},
+		{"listing for admins", http.MethodGet, "/topics", "root", "", http.StatusOK, "orders.created,payments,public.news,secrets"},
+		{"listing on the cluster of the role", http.MethodGet, "/topics?cluster=staging", "dave", "developers", http.StatusOK, "orders.created,payments,public.news,secrets"},
+		{"named cluster allowed", http.MethodPost, "/clusters/staging-eu/groups/billing-invoices/reset", "dave", "developers", http.StatusNoContent, ""},
+		{"named cluster denied", http.MethodPost, "/clusters/prod/groups/billing-invoices/reset", "dave", "developers", http.StatusForbidden, `group:reset is not allowed on \"billing-invoices\" of cluster \"prod\"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.user != "" {
+				req.Header.Set(principalHeader, tt.user)
+				req.Header.Set(groupsHeader, tt.groups)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d; body: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.status == http.StatusOK && w.Body.String() != tt.body {
+				t.Errorf("body = %q, want %q", w.Body, tt.body)
+			}
+			if tt.status == http.StatusForbidden && !strings.Contains(w.Body.String(), tt.body) {
+				t.Errorf("body = %s, want it to contain %s", w.Body, tt.body)
+			}
+		})
+	}
+}
+
+func TestMiddlewareWithoutAuthorizer(t *testing.T) {
+	r := newTestRouter(nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/topics/secrets", nil)
+	req.Header.Set(principalHeader, "nobody")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want %d without access control", w.Code, http.StatusNoContent)
+	}
+}
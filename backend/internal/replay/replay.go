@@ -0,0 +1,244 @@
+// Package replay copies ranges of messages from one topic to another as background jobs.
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/valeriouberti/maestro/internal/clusters"
+	"github.com/valeriouberti/maestro/internal/filter"
+	"github.com/valeriouberti/maestro/internal/jobs"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// JobType is the job type under which replays run
+const JobType = "replay"
+
+// Partitioning strategies for replayed messages
+const (
+	PartitioningHash  = "hash"
+	PartitioningKeep  = "keep"
+	PartitioningFixed = "fixed"
+)
+
+// ReplayedFromHeader is added to replayed messages to identify their source record
+// as "<topic>/<partition>/<offset>"
+const ReplayedFromHeader = "maestro-replayed-from"
+
+// drainTimeout bounds how long a finished or cancelled job waits for outstanding deliveries
+const drainTimeout = 30 * time.Second
+
+// Progress counters reported by replay jobs
+const (
+	CounterRead     = "read"
+	CounterMatched  = "matched"
+	CounterProduced = "produced"
+	CounterFailed   = "failed"
+)
+
+// Runner runs replay jobs on the configured clusters
+type Runner struct {
+	clusters *clusters.Registry
+}
+
+// NewRunner creates a Runner that reads and produces messages through the clients of
+// the registry, so that replays use the security settings of the configured clusters
+func NewRunner(registry *clusters.Registry) *Runner {
+	return &Runner{clusters: registry}
+}
+
+// Validate checks that params hold a valid domain.ReplaySpec targeting a configured cluster
+func (r *Runner) Validate(params json.RawMessage) error {
+	spec, _, err := parseSpec(params)
+	if err != nil {
+		return err
+	}
+	if spec.TargetCluster != "" && !r.clusters.Has(spec.TargetCluster) {
+		return fmt.Errorf("unknown target cluster %q", spec.TargetCluster)
+	}
+	return nil
+}
+
+// Run copies the messages selected by the spec in params to the target topic
+func (r *Runner) Run(ctx context.Context, h *jobs.Handle, params json.RawMessage) error {
+	spec, match, err := parseSpec(params)
+	if err != nil {
+		return err
+	}
+
+	// The clients are resolved once, so that a reload removing the cluster fails the job
+	// now rather than halfway through
+	source, err := r.clusters.Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	target := source
+	if spec.TargetCluster != "" {
+		if spec.TargetCluster == r.clusters.Selected(ctx) && spec.SourceTopic == spec.TargetTopic {
+			return fmt.Errorf("source and target topic must differ when replaying within the same cluster")
+		}
+		target, err = r.clusters.Client(clusters.WithName(ctx, spec.TargetCluster))
+		if err != nil {
+			return fmt.Errorf("failed to resolve target cluster: %w", err)
+		}
+	}
+
+	producer, err := target.NewTopicProducer(ctx, spec.TargetTopic, func(err error) {
+		if err != nil {
+			h.Count(CounterFailed, 1)
+		} else {
+			h.Count(CounterProduced, 1)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// Outstanding deliveries are waited for even when the job was cancelled
+		drainCtx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+		defer cancel()
+		if undelivered := producer.Close(drainCtx); undelivered > 0 {
+			h.Count(CounterFailed, int64(undelivered))
+		}
+	}()
+
+	var throttle <-chan time.Time
+	if spec.RatePerSecond > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(spec.RatePerSecond))
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+
+	return source.StreamTopicMessages(ctx, spec.SourceTopic, spec.Range, func(message domain.TopicMessage) error {
+		matched := match(message)
+		h.Progress(func(progress *domain.JobProgress) {
+			progress.Done++
+			if progress.Counters == nil {
+				progress.Counters = make(map[string]int64)
+			}
+			progress.Counters[CounterRead]++
+			if matched {
+				progress.Counters[CounterMatched]++
+			}
+		})
+		if !matched {
+			return nil
+		}
+
+		if throttle != nil {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-throttle:
+			}
+		}
+
+		if err := producer.Produce(ctx, buildRecord(spec, message)); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			h.Count(CounterFailed, 1)
+		}
+		return nil
+	})
+}
+
+// parseSpec decodes, validates and compiles the spec of a replay job
+func parseSpec(params json.RawMessage) (domain.ReplaySpec, filter.Matcher, error) {
+	var spec domain.ReplaySpec
+	if err := json.Unmarshal(params, &spec); err != nil {
+		return spec, nil, fmt.Errorf("invalid replay spec: %w", err)
+	}
+	if err := normalizeSpec(&spec); err != nil {
+		return spec, nil, err
+	}
+
+	match, err := filter.Compile(spec.Filters)
+	if err != nil {
+		return spec, nil, err
+	}
+	return spec, match, nil
+}
+
+// buildRecord turns a source message into the record produced to the target topic
+func buildRecord(spec domain.ReplaySpec, message domain.TopicMessage) domain.ProduceRecord {
+	record := domain.ProduceRecord{
+		Key:   message.Key,
+		Value: message.Value,
+	}
+
+	if spec.Key != nil {
+		record.Key = *spec.Key
+	}
+
+	switch spec.Partitioning {
+	case PartitioningKeep:
+		partition := message.Partition
+		record.Partition = &partition
+	case PartitioningFixed:
+		record.Partition = spec.Partition
+	}
+
+	if *spec.KeepHeaders && len(message.Headers) > 0 {
+		record.Headers = make(map[string]string, len(message.Headers)+1)
+		for k, v := range message.Headers {
+			record.Headers[k] = v
+		}
+	}
+	if *spec.AddReplayedFrom {
+		if record.Headers == nil {
+			record.Headers = make(map[string]string, 1)
+		}
+		record.Headers[ReplayedFromHeader] = fmt.Sprintf("%s/%d/%d", message.Topic, message.Partition, message.Offset)
+	}
+
+	if spec.PreserveTimestamps {
+		timestamp := message.Timestamp
+		record.Timestamp = &timestamp
+	}
+
+	return record
+}
+
+// normalizeSpec validates a replay spec and fills in its defaults
+func normalizeSpec(spec *domain.ReplaySpec) error {
+	if spec.SourceTopic == "" {
+		return fmt.Errorf("source topic is required")
+	}
+	if spec.TargetTopic == "" {
+		return fmt.Errorf("target topic is required")
+	}
+	if spec.TargetCluster == "" && spec.SourceTopic == spec.TargetTopic {
+		return fmt.Errorf("source and target topic must differ when replaying within the same cluster")
+	}
+
+	switch spec.Partitioning {
+	case "":
+		spec.Partitioning = PartitioningHash
+	case PartitioningHash, PartitioningKeep:
+	case PartitioningFixed:
+		if spec.Partition == nil || *spec.Partition < 0 {
+			return fmt.Errorf("fixed partitioning requires a non-negative partition")
+		}
+	default:
+		return fmt.Errorf("unknown partitioning %q (supported: hash, keep, fixed)", spec.Partitioning)
+	}
+
+	if spec.RatePerSecond < 0 {
+		return fmt.Errorf("rate per second must not be negative")
+	}
+
+	if spec.KeepHeaders == nil {
+		keep := true
+		spec.KeepHeaders = &keep
+	}
+	if spec.AddReplayedFrom == nil {
+		add := true
+		spec.AddReplayedFrom = &add
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+// Package secrets resolves references to secrets that are kept outside the
+// configuration: files, environment variables and the KV secrets engine of a
+// Vault-compatible server.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Prefixes of the supported references
+const (
+	FilePrefix  = "file:"  // file:/path/to/file, the file's content without trailing newlines
+	EnvPrefix   = "env:"   // env:NAME, the value of an environment variable
+	VaultPrefix = "vault:" // vault:secret/data/path#key, a key of a secret read from Vault
+)
+
+// IsReference reports whether a value refers to a secret rather than being the secret
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, FilePrefix) || strings.HasPrefix(value, EnvPrefix) || strings.HasPrefix(value, VaultPrefix)
+}
+
+// IsVaultReference reports whether a value refers to a secret kept in Vault
+func IsVaultReference(value string) bool {
+	return strings.HasPrefix(value, VaultPrefix)
+}
+
+// VaultOptions locates the Vault-compatible server holding vault: references
+type VaultOptions struct {
+	Address   string // Base URL of the server, e.g. https://vault.example.com:8200
+	Token     string // Token sent in the X-Vault-Token header
+	Namespace string // Namespace sent in the X-Vault-Namespace header; none when empty
+}
+
+// Resolver resolves secret references
+type Resolver struct {
+	vault  VaultOptions
+	client *http.Client
+}
+
+// NewResolver creates a Resolver; vault: references can only be resolved when a Vault address is set
+func NewResolver(vault VaultOptions) *Resolver {
+	return &Resolver{
+		vault:  vault,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Resolve returns the secret a reference refers to. Values that are not references
+// are returned unchanged. Errors never contain the secret.
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, FilePrefix):
+		return readFile(strings.TrimPrefix(value, FilePrefix))
+	case strings.HasPrefix(value, EnvPrefix):
+		return lookupEnv(strings.TrimPrefix(value, EnvPrefix))
+	case strings.HasPrefix(value, VaultPrefix):
+		return r.readVault(ctx, strings.TrimPrefix(value, VaultPrefix))
+	default:
+		return value, nil
+	}
+}
+
+// readFile returns the content of a secret file, as mounted by Docker or Kubernetes
+func readFile(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("file reference without a path")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func lookupEnv(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("env reference without a variable name")
+	}
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+// readVault reads a key of a secret through the Vault HTTP API. The path is the API
+// path after /v1/, e.g. "secret/data/kafka" for the KV version 2 engine mounted at
+// "secret", or "kv/kafka" for a version 1 engine mounted at "kv".
+func (r *Resolver) readVault(ctx context.Context, reference string) (string, error) {
+	path, key, ok := strings.Cut(reference, "#")
+	path = strings.Trim(path, "/")
+	if !ok || path == "" || key == "" {
+		return "", fmt.Errorf("invalid vault reference %q, expected vault:<path>#<key>", VaultPrefix+reference)
+	}
+	if r.vault.Address == "" {
+		return "", fmt.Errorf("vault reference %q requires a Vault address", VaultPrefix+reference)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(r.vault.Address, "/")+"/v1/"+path, nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid Vault address: %w", err)
+	}
+	if r.vault.Token != "" {
+		req.Header.Set("X-Vault-Token", r.vault.Token)
+	}
+	if r.vault.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", r.vault.Namespace)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %s from Vault: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("secret %s does not exist in Vault", path)
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized:
+		return "", fmt.Errorf("access to secret %s denied by Vault (status %d)", path, resp.StatusCode)
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("failed to read secret %s from Vault: status %d", path, resp.StatusCode)
+	}
+
+	var body struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("invalid response of Vault for secret %s: %w", path, err)
+	}
+	data := body.Data
+	// The KV version 2 engine nests the secret in data.data, next to its metadata
+	if nested, ok := data["data"]; ok {
+		if _, ok := data["metadata"]; ok {
+			data = nil
+			if err := json.Unmarshal(nested, &data); err != nil {
+				return "", fmt.Errorf("invalid response of Vault for secret %s: %w", path, err)
+			}
+		}
+	}
+
+	raw, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no key %q", path, key)
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		// Numbers and booleans are used as they are written
+		return strings.TrimSpace(string(raw)), nil
+	}
+	return value, nil
+}
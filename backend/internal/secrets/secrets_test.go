@@ -0,0 +1,212 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Secrets served by the Vault stand-in; no error may contain them
+const (
+	passwordV1 = "kv1-Pa55word"
+	passwordV2 = "kv2-Pa55word"
+	token      = "test-token"
+)
+
+// newVault starts a Vault stand-in serving a KV version 1 engine mounted at "kv" and
+// a KV version 2 engine mounted at "secret". Requests without the token are denied.
+func newVault(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	secrets := map[string]any{
+		// KV version 1 returns the secret in data
+		"/v1/kv/kafka": map[string]any{
+			"data": map[string]any{"username": "maestro", "password": passwordV1},
+		},
+		// A version 1 secret with a key named data is not unwrapped
+		"/v1/kv/nested": map[string]any{
+			"data": map[string]any{"data": "not-nested"},
+		},
+		// KV version 2 nests the secret in data.data, next to its metadata
+		"/v1/secret/data/kafka": map[string]any{
+			"data": map[string]any{
+				"data":     map[string]any{"password": passwordV2, "port": 9093, "tls": true},
+				"metadata": map[string]any{"version": 3, "created_time": "2026-01-01T00:00:00Z"},
+			},
+		},
+		// Vault answers 403 to requests for paths the token may not read
+		"/v1/secret/data/forbidden": nil,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != token || r.URL.Path == "/v1/secret/data/forbidden" {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]any{"errors": []string{"permission denied", passwordV2}})
+			return
+		}
+		if r.URL.Path == "/v1/kv/garbage" {
+			w.Write([]byte("{" + passwordV1))
+			return
+		}
+		secret, ok := secrets[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]any{"errors": []string{}})
+			return
+		}
+		json.NewEncoder(w).Encode(secret)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// expectNoSecret fails the test if an error reveals a secret
+func expectNoSecret(t *testing.T, err error) {
+	t.Helper()
+	for _, secret := range []string{passwordV1, passwordV2, token} {
+		if strings.Contains(err.Error(), secret) {
+			t.Errorf("error %q reveals a secret", err)
+		}
+	}
+}
+
+func TestResolveVault(t *testing.T) {
+	vault := newVault(t)
+	r := NewResolver(VaultOptions{Address: vault.URL + "/", Token: token})
+
+	tests := []struct {
+		reference string
+		want      string
+	}{
+		{"vault:kv/kafka#password", passwordV1},
+		{"vault:kv/kafka#username", "maestro"},
+		{"vault:/kv/nested/#data", "not-nested"},
+		{"vault:secret/data/kafka#password", passwordV2},
+		{"vault:secret/data/kafka#port", "9093"},
+		{"vault:secret/data/kafka#tls", "true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reference, func(t *testing.T) {
+			got, err := r.Resolve(context.Background(), tt.reference)
+			if err != nil {
+				t.Fatalf("Resolve: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("Resolve = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveVaultErrors(t *testing.T) {
+	vault := newVault(t)
+	r := NewResolver(VaultOptions{Address: vault.URL, Token: token})
+
+	tests := []struct {
+		name      string
+		resolver  *Resolver
+		reference string
+		want      string
+	}{
+		{"forbidden", r, "vault:secret/data/forbidden#password", "access to secret secret/data/forbidden denied by Vault (status 403)"},
+		{"missing token", NewResolver(VaultOptions{Address: vault.URL}), "vault:kv/kafka#password", "denied by Vault (status 403)"},
+		{"not found", r, "vault:kv/missing#password", "secret kv/missing does not exist in Vault"},
+		{"missing key", r, "vault:secret/data/kafka#user", `secret secret/data/kafka has no key "user"`},
+		{"metadata is not a key", r, "vault:secret/data/kafka#metadata", `has no key "metadata"`},
+		{"invalid response", r, "vault:kv/garbage#password", "invalid response of Vault for secret kv/garbage"},
+		{"no key", r, "vault:kv/kafka", "invalid vault reference"},
+		{"no path", r, "vault:#password", "invalid vault reference"},
+		{"no address", NewResolver(VaultOptions{Token: token}), "vault:kv/kafka#password", "requires a Vault address"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.resolver.Resolve(context.Background(), tt.reference)
+			if err == nil {
+				t.Fatalf("Resolve = %q, want an error", got)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.want)
+			}
+			expectNoSecret(t, err)
+		})
+	}
+}
+
+func TestResolveVaultNamespace(t *testing.T) {
+	var namespace string
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		namespace = r.Header.Get("X-Vault-Namespace")
+		json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"password": passwordV1}})
+	}))
+	defer vault.Close()
+
+	r := NewResolver(VaultOptions{Address: vault.URL, Token: token, Namespace: "team-a"})
+	if _, err := r.Resolve(context.Background(), "vault:kv/kafka#password"); err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if namespace != "team-a" {
+		t.Errorf("X-Vault-Namespace = %q, want team-a", namespace)
+	}
+}
+
+func TestResolveFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(path, []byte(passwordV1+"\r\n\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	r := NewResolver(VaultOptions{})
+
+	got, err := r.Resolve(context.Background(), FilePrefix+path)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if got != passwordV1 {
+		t.Errorf("Resolve = %q, want %q without trailing newlines", got, passwordV1)
+	}
+
+	for _, reference := range []string{FilePrefix, FilePrefix + filepath.Join(t.TempDir(), "missing")} {
+		if _, err := r.Resolve(context.Background(), reference); err == nil {
+			t.Errorf("Resolve(%q) succeeded, want an error", reference)
+		}
+	}
+}
+
+func TestResolveEnv(t *testing.T) {
+	t.Setenv("MAESTRO_TEST_PASSWORD", passwordV1)
+	r := NewResolver(VaultOptions{})
+
+	got, err := r.Resolve(context.Background(), "env:MAESTRO_TEST_PASSWORD")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if got != passwordV1 {
+		t.Errorf("Resolve = %q, want %q", got, passwordV1)
+	}
+
+	for _, reference := range []string{EnvPrefix, "env:MAESTRO_TEST_UNSET"} {
+		_, err := r.Resolve(context.Background(), reference)
+		if err == nil {
+			t.Fatalf("Resolve(%q) succeeded, want an error", reference)
+		}
+		expectNoSecret(t, err)
+	}
+}
+
+func TestResolvePlainValue(t *testing.T) {
+	r := NewResolver(VaultOptions{})
+
+	for _, value := range []string{"", "plain-password", "vault", "file", "https://vault:8200"} {
+		if IsReference(value) {
+			t.Errorf("IsReference(%q) = true", value)
+		}
+		got, err := r.Resolve(context.Background(), value)
+		if err != nil || got != value {
+			t.Errorf("Resolve(%q) = %q, %v, want the value unchanged", value, got, err)
+		}
+	}
+}
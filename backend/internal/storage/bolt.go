@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// BoltStore is a Store backed by an embedded bbolt database file
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// OpenBolt opens, or creates, a bbolt database. Only one process can open the
+// database at a time.
+func OpenBolt(path string) (*BoltStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open storage %s: %w", path, err)
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+// Get implements Reader
+func (s *BoltStore) Get(bucket, key string) ([]byte, error) {
+	var value []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		value, err = boltTx{tx}.Get(bucket, key)
+		return err
+	})
+	return value, err
+}
+
+// ForEach implements Reader
+func (s *BoltStore) ForEach(bucket string, fn func(key string, value []byte) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return boltTx{tx}.ForEach(bucket, fn)
+	})
+}
+
+// ForEachPrefix implements Reader
+func (s *BoltStore) ForEachPrefix(bucket, prefix string, fn func(key string, value []byte) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return boltTx{tx}.ForEachPrefix(bucket, prefix, fn)
+	})
+}
+
+// Put implements Tx
+func (s *BoltStore) Put(bucket, key string, value []byte) error {
+	return s.Update(func(tx Tx) error {
+		return tx.Put(bucket, key, value)
+	})
+}
+
+// Delete implements Tx
+func (s *BoltStore) Delete(bucket, key string) error {
+	return s.Update(func(tx Tx) error {
+		return tx.Delete(bucket, key)
+	})
+}
+
+// Update implements Store
+func (s *BoltStore) Update(fn func(tx Tx) error) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return fn(boltTx{tx})
+	})
+}
+
+// Close implements Store
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// boltTx adapts a bbolt transaction to Tx
+type boltTx struct {
+	tx *bolt.Tx
+}
+
+func (t boltTx) Get(bucket, key string) ([]byte, error) {
+	b := t.tx.Bucket([]byte(bucket))
+	if b == nil {
+		return nil, ErrNotFound
+	}
+	value := b.Get([]byte(key))
+	if value == nil {
+		return nil, ErrNotFound
+	}
+	// Values are only valid during the transaction
+	return append([]byte(nil), value...), nil
+}
+
+func (t boltTx) ForEach(bucket string, fn func(key string, value []byte) error) error {
+	b := t.tx.Bucket([]byte(bucket))
+	if b == nil {
+		return nil
+	}
+	return b.ForEach(func(k, v []byte) error {
+		if v == nil {
+			// Nested bucket
+			return nil
+		}
+		return fn(string(k), append([]byte(nil), v...))
+	})
+}
+
+func (t boltTx) ForEachPrefix(bucket, prefix string, fn func(key string, value []byte) error) error {
+	b := t.tx.Bucket([]byte(bucket))
+	if b == nil {
+		return nil
+	}
+	c := b.Cursor()
+	for k, v := c.Seek([]byte(prefix)); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, v = c.Next() {
+		if v == nil {
+			// Nested bucket
+			continue
+		}
+		if err := fn(string(k), append([]byte(nil), v...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t boltTx) Put(bucket, key string, value []byte) error {
+	b, err := t.tx.CreateBucketIfNotExists([]byte(bucket))
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(key), value)
+}
+
+func (t boltTx) Delete(bucket, key string) error {
+	b := t.tx.Bucket([]byte(bucket))
+	if b == nil {
+		return nil
+	}
+	return b.Delete([]byte(key))
+}
+
+func (t boltTx) CreateBucket(name string) error {
+	_, err := t.tx.CreateBucketIfNotExists([]byte(name))
+	return err
+}
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// MemoryStore is a Store keeping its data in memory, for tests and setups that
+// do not need state to survive a restart
+type MemoryStore struct {
+	mu      sync.RWMutex
+	buckets map[string]map[string][]byte
+}
+
+// NewMemory creates an empty MemoryStore. Unlike Open, it does not apply the migrations.
+func NewMemory() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]map[string][]byte)}
+}
+
+// Get implements Reader
+func (s *MemoryStore) Get(bucket, key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return memoryTx{s.buckets}.Get(bucket, key)
+}
+
+// ForEach implements Reader
+func (s *MemoryStore) ForEach(bucket string, fn func(key string, value []byte) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return memoryTx{s.buckets}.ForEach(bucket, fn)
+}
+
+// ForEachPrefix implements Reader
+func (s *MemoryStore) ForEachPrefix(bucket, prefix string, fn func(key string, value []byte) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return memoryTx{s.buckets}.ForEachPrefix(bucket, prefix, fn)
+}
+
+// Put implements Tx
+func (s *MemoryStore) Put(bucket, key string, value []byte) error {
+	return s.Update(func(tx Tx) error {
+		return tx.Put(bucket, key, value)
+	})
+}
+
+// Delete implements Tx
+func (s *MemoryStore) Delete(bucket, key string) error {
+	return s.Update(func(tx Tx) error {
+		return tx.Delete(bucket, key)
+	})
+}
+
+// Update implements Store. Changes are applied to a copy of the data that
+// replaces it only when fn succeeds.
+func (s *MemoryStore) Update(fn func(tx Tx) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	staged := make(map[string]map[string][]byte, len(s.buckets))
+	for name, bucket := range s.buckets {
+		staged[name] = maps.Clone(bucket)
+	}
+
+	if err := fn(memoryTx{staged}); err != nil {
+		return err
+	}
+	s.buckets = staged
+	return nil
+}
+
+// Close implements Store
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// memoryTx operates on the buckets of a MemoryStore
+type memoryTx struct {
+	buckets map[string]map[string][]byte
+}
+
+func (t memoryTx) Get(bucket, key string) ([]byte, error) {
+	value, ok := t.buckets[bucket][key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return slices.Clone(value), nil
+}
+
+func (t memoryTx) ForEach(bucket string, fn func(key string, value []byte) error) error {
+	b := t.buckets[bucket]
+	for _, key := range slices.Sorted(maps.Keys(b)) {
+		if err := fn(key, slices.Clone(b[key])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t memoryTx) ForEachPrefix(bucket, prefix string, fn func(key string, value []byte) error) error {
+	return t.ForEach(bucket, func(key string, value []byte) error {
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		return fn(key, value)
+	})
+}
+
+func (t memoryTx) Put(bucket, key string, value []byte) error {
+	b, ok := t.buckets[bucket]
+	if !ok {
+		b = make(map[string][]byte)
+		t.buckets[bucket] = b
+	}
+	b[key] = slices.Clone(value)
+	return nil
+}
+
+func (t memoryTx) Delete(bucket, key string) error {
+	delete(t.buckets[bucket], key)
+	return nil
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+)
+
+// schemaVersionKey holds the version of the last applied migration in BucketMeta
+const schemaVersionKey = "schemaVersion"
+
+// Migration upgrades the schema of a store by one version
+type Migration struct {
+	Version     int
+	Description string
+	Apply       func(tx Tx) error
+}
+
+// Migrations lists the schema migrations in order. Migrations are never edited
+// once released; changes to the schema are made by appending new ones.
+var Migrations = []Migration{
+	{
+		Version:     1,
+		Description: "create buckets",
+		Apply: func(tx Tx) error {
+			for _, bucket := range []string{BucketJobs, BucketAudit, BucketClusters, BucketSearches, BucketPreferences} {
+				if err := createBucket(tx, bucket); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     2,
+		Description: "create lag history bucket",
+		Apply: func(tx Tx) error {
+			return createBucket(tx, BucketLag)
+		},
+	},
+	{
+		Version:     3,
+		Description: "create topic templates bucket",
+		Apply: func(tx Tx) error {
+			return createBucket(tx, BucketTemplates)
+		},
+	},
+}
+
+// bucketCreator is implemented by transactions of stores that need buckets to be created explicitly
+type bucketCreator interface {
+	CreateBucket(name string) error
+}
+
+func createBucket(tx Tx, name string) error {
+	if creator, ok := tx.(bucketCreator); ok {
+		return creator.CreateBucket(name)
+	}
+	return nil
+}
+
+// SchemaVersion returns the version of the last migration applied to a store
+func SchemaVersion(r Reader) (int, error) {
+	data, err := r.Get(BucketMeta, schemaVersionKey)
+	if errors.Is(err, ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	version, err := strconv.Atoi(string(data))
+	if err != nil {
+		return 0, fmt.Errorf("invalid schema version %q", data)
+	}
+	return version, nil
+}
+
+// Migrate applies the pending migrations, each in its own transaction. It refuses
+// to open a store written by a newer version of Maestro.
+func Migrate(s Store) error {
+	current, err := SchemaVersion(s)
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	latest := Migrations[len(Migrations)-1].Version
+	if current > latest {
+		return fmt.Errorf("storage schema version %d is newer than the supported version %d", current, latest)
+	}
+
+	for _, migration := range Migrations {
+		if migration.Version <= current {
+			continue
+		}
+
+		err := s.Update(func(tx Tx) error {
+			if err := migration.Apply(tx); err != nil {
+				return err
+			}
+			return tx.Put(BucketMeta, schemaVersionKey, []byte(strconv.Itoa(migration.Version)))
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Description, err)
+		}
+		slog.Info("Applied storage migration", "version", migration.Version, "description", migration.Description)
+	}
+
+	return nil
+}
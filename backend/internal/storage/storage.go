@@ -0,0 +1,115 @@
+// Package storage persists Maestro's own state, such as background jobs, audit
+// records, consumer lag history, topic templates, saved searches and user preferences.
+//
+// A Store is a small transactional key-value store organised in buckets. The bolt
+// implementation keeps the data in a single embedded database file; the memory
+// implementation is meant for tests and ephemeral setups. Both apply the schema
+// migrations of this package when they are opened.
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Store drivers
+const (
+	DriverBolt   = "bolt"
+	DriverMemory = "memory"
+)
+
+// Buckets
+const (
+	BucketMeta        = "meta"
+	BucketJobs        = "jobs"
+	BucketAudit       = "audit"
+	BucketClusters    = "clusters"
+	BucketSearches    = "searches"
+	BucketPreferences = "preferences"
+	BucketLag         = "lag"
+	BucketTemplates   = "templates"
+)
+
+// ErrNotFound is returned when a key does not exist
+var ErrNotFound = errors.New("not found")
+
+// Reader reads entries of a store
+type Reader interface {
+	// Get returns the value of a key, or ErrNotFound
+	Get(bucket, key string) ([]byte, error)
+	// ForEach calls fn for every entry of a bucket in key order, stopping at the first error
+	ForEach(bucket string, fn func(key string, value []byte) error) error
+	// ForEachPrefix is like ForEach for the keys starting with prefix
+	ForEachPrefix(bucket, prefix string, fn func(key string, value []byte) error) error
+}
+
+// Tx reads and writes entries of a store
+type Tx interface {
+	Reader
+	Put(bucket, key string, value []byte) error
+	// Delete removes a key; deleting a missing key is not an error
+	Delete(bucket, key string) error
+}
+
+// Store is a transactional key-value store. Its Tx methods each run in their own
+// transaction; Update groups several changes in a single one. The function passed
+// to Update must only use the given Tx, not the Store itself.
+type Store interface {
+	Tx
+	Update(fn func(tx Tx) error) error
+	Close() error
+}
+
+// Options configures the store created by Open
+type Options struct {
+	Driver string // bolt or memory
+	Path   string // Database file of the bolt driver
+}
+
+// Open opens a store and migrates it to the current schema
+func Open(opts Options) (Store, error) {
+	var (
+		store Store
+		err   error
+	)
+	switch opts.Driver {
+	case DriverBolt:
+		store, err = OpenBolt(opts.Path)
+	case DriverMemory:
+		store = NewMemory()
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q (supported: bolt, memory)", opts.Driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := Migrate(store); err != nil {
+		store.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// GetJSON reads a JSON encoded value
+func GetJSON[T any](r Reader, bucket, key string) (T, error) {
+	var value T
+	data, err := r.Get(bucket, key)
+	if err != nil {
+		return value, err
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, fmt.Errorf("failed to decode %s/%s: %w", bucket, key, err)
+	}
+	return value, nil
+}
+
+// PutJSON writes a value encoded as JSON
+func PutJSON(tx Tx, bucket, key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s/%s: %w", bucket, key, err)
+	}
+	return tx.Put(bucket, key, data)
+}
@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// stores returns a freshly migrated store of every driver
+func stores(t *testing.T) map[string]Store {
+	t.Helper()
+
+	bolt, err := OpenBolt(filepath.Join(t.TempDir(), "maestro.db"))
+	if err != nil {
+		t.Fatalf("OpenBolt: %v", err)
+	}
+	t.Cleanup(func() { bolt.Close() })
+
+	result := map[string]Store{
+		DriverMemory: NewMemory(),
+		DriverBolt:   bolt,
+	}
+	for name, store := range result {
+		if err := Migrate(store); err != nil {
+			t.Fatalf("Migrate(%s): %v", name, err)
+		}
+	}
+	return result
+}
+
+func TestMigrate(t *testing.T) {
+	latest := Migrations[len(Migrations)-1].Version
+
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			version, err := SchemaVersion(store)
+			if err != nil {
+				t.Fatalf("SchemaVersion: %v", err)
+			}
+			if version != latest {
+				t.Fatalf("schema version = %d, want %d", version, latest)
+			}
+
+			// Applying the migrations again is a no-op
+			if err := Migrate(store); err != nil {
+				t.Fatalf("second Migrate: %v", err)
+			}
+			if version, _ := SchemaVersion(store); version != latest {
+				t.Fatalf("schema version after second Migrate = %d, want %d", version, latest)
+			}
+
+			// Every bucket created by the migrations accepts writes
+			for _, bucket := range []string{BucketJobs, BucketAudit, BucketClusters, BucketSearches, BucketPreferences, BucketLag, BucketTemplates} {
+				if err := store.Put(bucket, "key", []byte("value")); err != nil {
+					t.Errorf("Put(%s): %v", bucket, err)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrateRefusesNewerSchema(t *testing.T) {
+	store := NewMemory()
+	newer := Migrations[len(Migrations)-1].Version + 1
+	if err := store.Put(BucketMeta, schemaVersionKey, []byte(strconv.Itoa(newer))); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	if err := Migrate(store); err == nil {
+		t.Fatal("Migrate accepted a store with a newer schema version")
+	}
+}
+
+func TestForEachPrefix(t *testing.T) {
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, key := range []string{"orders/2", "payments/1", "orders/1", "orders", "ordersX/1"} {
+				if err := store.Put(BucketLag, key, []byte(key)); err != nil {
+					t.Fatalf("Put(%s): %v", key, err)
+				}
+			}
+
+			var keys []string
+			err := store.ForEachPrefix(BucketLag, "orders/", func(key string, value []byte) error {
+				if string(value) != key {
+					t.Errorf("value of %s = %q", key, value)
+				}
+				keys = append(keys, key)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("ForEachPrefix: %v", err)
+			}
+			if want := []string{"orders/1", "orders/2"}; !slices.Equal(keys, want) {
+				t.Fatalf("keys = %v, want %v", keys, want)
+			}
+
+			// An error returned by fn stops the iteration
+			stop := errors.New("stop")
+			calls := 0
+			err = store.ForEachPrefix(BucketLag, "orders/", func(string, []byte) error {
+				calls++
+				return stop
+			})
+			if !errors.Is(err, stop) || calls != 1 {
+				t.Fatalf("ForEachPrefix = %v after %d calls, want %v after 1 call", err, calls, stop)
+			}
+		})
+	}
+}
+
+func TestUpdateRollsBackOnError(t *testing.T) {
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := store.Put(BucketJobs, "kept", []byte("old")); err != nil {
+				t.Fatalf("Put: %v", err)
+			}
+
+			failure := errors.New("failure")
+			err := store.Update(func(tx Tx) error {
+				if err := tx.Put(BucketJobs, "kept", []byte("new")); err != nil {
+					return err
+				}
+				if err := tx.Put(BucketJobs, "added", []byte("new")); err != nil {
+					return err
+				}
+				return failure
+			})
+			if !errors.Is(err, failure) {
+				t.Fatalf("Update = %v, want %v", err, failure)
+			}
+
+			if value, err := store.Get(BucketJobs, "kept"); err != nil || string(value) != "old" {
+				t.Errorf("Get(kept) = %q, %v, want \"old\"", value, err)
+			}
+			if _, err := store.Get(BucketJobs, "added"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get(added) error = %v, want %v", err, ErrNotFound)
+			}
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	type search struct {
+		Name  string `json:"name"`
+		Query string `json:"query"`
+	}
+
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			want := search{Name: "errors", Query: "level:error"}
+			err := store.Update(func(tx Tx) error {
+				return PutJSON(tx, BucketSearches, want.Name, want)
+			})
+			if err != nil {
+				t.Fatalf("PutJSON: %v", err)
+			}
+
+			got, err := GetJSON[search](store, BucketSearches, want.Name)
+			if err != nil {
+				t.Fatalf("GetJSON: %v", err)
+			}
+			if got != want {
+				t.Fatalf("GetJSON = %+v, want %+v", got, want)
+			}
+
+			if _, err := GetJSON[search](store, BucketSearches, "missing"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("GetJSON(missing) error = %v, want %v", err, ErrNotFound)
+			}
+		})
+	}
+}
+
+func TestOpenMemoryMigrates(t *testing.T) {
+	store, err := Open(Options{Driver: DriverMemory})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer store.Close()
+
+	version, err := SchemaVersion(store)
+	if err != nil {
+		t.Fatalf("SchemaVersion: %v", err)
+	}
+	if latest := Migrations[len(Migrations)-1].Version; version != latest {
+		t.Fatalf("schema version = %d, want %d", version, latest)
+	}
+}
@@ -0,0 +1,224 @@
+// Package templates manages topic templates: named defaults for the partitions,
+// replication factor and configuration of topics, such as those of a compacted
+// changelog or a dead letter queue, and a pattern naming the topics created from them.
+//
+// Templates are either defined in the configuration file, which makes them read-only,
+// or created through the API and kept in storage. The names of stored templates must
+// not collide with configured ones.
+package templates
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/valeriouberti/maestro/internal/storage"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+var (
+	// ErrNotFound is returned for templates that don't exist
+	ErrNotFound = errors.New("topic template not found")
+	// ErrExists is returned when creating a template whose name is taken
+	ErrExists = errors.New("topic template already exists")
+	// ErrReadOnly is returned when changing a template defined in the configuration file
+	ErrReadOnly = errors.New("topic template is defined in the configuration file and cannot be changed")
+)
+
+var (
+	// namePattern restricts template names to characters that need no escaping in URLs
+	namePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+	// placeholderPattern matches the {placeholders} of topic names
+	placeholderPattern = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+)
+
+// Validate checks the fields of a template
+func Validate(t domain.TopicTemplate) error {
+	var errs []error
+	if !namePattern.MatchString(t.Name) {
+		errs = append(errs, fmt.Errorf("template name %q must only contain letters, digits, '.', '_' and '-'", t.Name))
+	}
+	if t.NumPartitions < 1 {
+		errs = append(errs, errors.New("numPartitions must be at least 1"))
+	}
+	if t.ReplicationFactor < 1 {
+		errs = append(errs, errors.New("replicationFactor must be at least 1"))
+	}
+	if rest := placeholderPattern.ReplaceAllString(t.TopicName, ""); strings.ContainsAny(rest, "{}") {
+		errs = append(errs, fmt.Errorf("topicName %q has a malformed placeholder; placeholders are names of letters, digits and '_' in braces", t.TopicName))
+	}
+	return errors.Join(errs...)
+}
+
+// Placeholders returns the names of the placeholders of a template's topic name, in order
+func Placeholders(t domain.TopicTemplate) []string {
+	var names []string
+	for _, match := range placeholderPattern.FindAllStringSubmatch(t.TopicName, -1) {
+		if !slices.Contains(names, match[1]) {
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+// Apply returns the topic created from a template. The non-zero fields of the request
+// override the defaults of the template, and its configuration entries are merged over
+// those of the template. Unless the request names the topic, the name is the topic name
+// of the template with its placeholders replaced by the parameters.
+func Apply(t domain.TopicTemplate, request domain.TopicInfo, params map[string]string) (domain.TopicInfo, error) {
+	topic := domain.TopicInfo{
+		Name:              request.Name,
+		NumPartitions:     t.NumPartitions,
+		ReplicationFactor: int(t.ReplicationFactor),
+		Config:            make(map[string]string, len(t.Config)+len(request.Config)),
+	}
+	if request.NumPartitions > 0 {
+		topic.NumPartitions = request.NumPartitions
+	}
+	if request.ReplicationFactor > 0 {
+		topic.ReplicationFactor = request.ReplicationFactor
+	}
+	maps.Copy(topic.Config, t.Config)
+	maps.Copy(topic.Config, request.Config)
+
+	if topic.Name != "" {
+		return topic, nil
+	}
+	if t.TopicName == "" {
+		return topic, fmt.Errorf("template %q has no topic name: the request must name the topic", t.Name)
+	}
+	var missing []string
+	for _, name := range Placeholders(t) {
+		if params[name] == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return topic, fmt.Errorf("missing parameters of template %q: %s", t.Name, strings.Join(missing, ", "))
+	}
+	topic.Name = placeholderPattern.ReplaceAllStringFunc(t.TopicName, func(placeholder string) string {
+		return params[placeholder[1:len(placeholder)-1]]
+	})
+	return topic, nil
+}
+
+// Store lists, reads and changes the configured and stored templates
+type Store struct {
+	store      storage.Store
+	configured map[string]domain.TopicTemplate
+}
+
+// NewStore creates a Store of the templates kept in store and of the templates defined
+// in the configuration file, which it validates
+func NewStore(store storage.Store, configured []domain.TopicTemplate) (*Store, error) {
+	s := &Store{store: store, configured: make(map[string]domain.TopicTemplate, len(configured))}
+	for _, t := range configured {
+		if err := Validate(t); err != nil {
+			return nil, fmt.Errorf("invalid topic template %q: %w", t.Name, err)
+		}
+		t.Source = domain.TemplateSourceConfig
+		t.UpdatedAt = nil
+		s.configured[t.Name] = t
+	}
+	return s, nil
+}
+
+// List returns every template, sorted by name
+func (s *Store) List() ([]domain.TopicTemplate, error) {
+	templates := slices.Collect(maps.Values(s.configured))
+	err := s.store.ForEach(storage.BucketTemplates, func(key string, value []byte) error {
+		t, err := decode(key, value)
+		if err != nil {
+			return err
+		}
+		templates = append(templates, t)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list topic templates: %w", err)
+	}
+	slices.SortFunc(templates, func(a, b domain.TopicTemplate) int { return strings.Compare(a.Name, b.Name) })
+	return templates, nil
+}
+
+// Get returns a template, or ErrNotFound
+func (s *Store) Get(name string) (domain.TopicTemplate, error) {
+	if t, ok := s.configured[name]; ok {
+		return t, nil
+	}
+	t, err := storage.GetJSON[domain.TopicTemplate](s.store, storage.BucketTemplates, name)
+	if errors.Is(err, storage.ErrNotFound) {
+		return t, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return t, err
+}
+
+// Create stores a new template. It returns ErrExists if a template has the same name.
+func (s *Store) Create(t domain.TopicTemplate) (domain.TopicTemplate, error) {
+	return s.put(t, false)
+}
+
+// Update replaces a stored template. It returns ErrNotFound if the template doesn't
+// exist and ErrReadOnly if it is defined in the configuration file.
+func (s *Store) Update(t domain.TopicTemplate) (domain.TopicTemplate, error) {
+	return s.put(t, true)
+}
+
+func (s *Store) put(t domain.TopicTemplate, replace bool) (domain.TopicTemplate, error) {
+	if err := Validate(t); err != nil {
+		return t, err
+	}
+	if _, ok := s.configured[t.Name]; ok {
+		if replace {
+			return t, ErrReadOnly
+		}
+		return t, ErrExists
+	}
+
+	now := time.Now().UTC()
+	t.Source = domain.TemplateSourceStorage
+	t.UpdatedAt = &now
+	err := s.store.Update(func(tx storage.Tx) error {
+		_, err := tx.Get(storage.BucketTemplates, t.Name)
+		switch {
+		case err == nil && !replace:
+			return ErrExists
+		case errors.Is(err, storage.ErrNotFound) && replace:
+			return fmt.Errorf("%w: %s", ErrNotFound, t.Name)
+		case err != nil && !errors.Is(err, storage.ErrNotFound):
+			return err
+		}
+		return storage.PutJSON(tx, storage.BucketTemplates, t.Name, t)
+	})
+	return t, err
+}
+
+// Delete removes a stored template. It returns ErrNotFound if the template doesn't exist
+// and ErrReadOnly if it is defined in the configuration file.
+func (s *Store) Delete(name string) error {
+	if _, ok := s.configured[name]; ok {
+		return ErrReadOnly
+	}
+	return s.store.Update(func(tx storage.Tx) error {
+		if _, err := tx.Get(storage.BucketTemplates, name); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return fmt.Errorf("%w: %s", ErrNotFound, name)
+			}
+			return err
+		}
+		return tx.Delete(storage.BucketTemplates, name)
+	})
+}
+
+func decode(key string, value []byte) (domain.TopicTemplate, error) {
+	var t domain.TopicTemplate
+	if err := json.Unmarshal(value, &t); err != nil {
+		return t, fmt.Errorf("failed to decode %s/%s: %w", storage.BucketTemplates, key, err)
+	}
+	return t, nil
+}
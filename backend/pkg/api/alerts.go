@@ -0,0 +1,81 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/valeriouberti/maestro/internal/alerting"
+	"github.com/valeriouberti/maestro/internal/problem"
+	"github.com/valeriouberti/maestro/internal/rbac"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// alertingDisabled is the problem of alerting requests when no alert rule is configured
+func alertingDisabled() *problem.Problem {
+	return problem.New(http.StatusNotImplemented, problem.CodeNotImplemented,
+		"Alerting is disabled", "define alert rules under alerting.rules in the configuration file")
+}
+
+// ListAlertsHandler creates a Gin HTTP handler that lists the pending and firing alerts,
+// most recent first. Alerts on topics and consumer groups the caller may not read are
+// left out.
+//
+// Returns:
+// - 200 OK with the alerts
+// - 501 Not Implemented if no alert rule is configured
+func ListAlertsHandler(e *alerting.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if e == nil {
+			problem.Abort(c, alertingDisabled())
+			return
+		}
+
+		alerts := make([]domain.Alert, 0)
+		for _, alert := range e.Alerts() {
+			action := rbac.ActionTopicRead
+			if alert.Type == domain.AlertConsumerLag || alert.Type == domain.AlertGroupEmpty {
+				action = rbac.ActionGroupRead
+			}
+			if rbac.Allowed(c, action, alert.Subject) {
+				alerts = append(alerts, alert)
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{"alerts": alerts})
+	}
+}
+
+// TestNotifierHandler creates a Gin HTTP handler that sends a test notification to a
+// notifier, to check its configuration.
+//
+// Returns:
+// - 200 OK if the notification was accepted
+// - 404 Not Found if the notifier doesn't exist
+// - 501 Not Implemented if no alert rule is configured
+// - 502 Bad Gateway if the notification failed
+func TestNotifierHandler(e *alerting.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if e == nil {
+			problem.Abort(c, alertingDisabled())
+			return
+		}
+
+		name := c.Param("notifier")
+		if err := e.TestNotifier(c.Request.Context(), name); err != nil {
+			if errors.Is(err, alerting.ErrUnknownNotifier) {
+				problem.Abort(c, problem.NotFound("Notifier not found", err.Error()))
+				return
+			}
+
+			problem.Abort(c, problem.New(http.StatusBadGateway, problem.CodeInternal, "Failed to send test notification", err.Error()))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":  "Test notification sent",
+			"notifier": name,
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/valeriouberti/maestro/internal/audit"
+	"github.com/valeriouberti/maestro/internal/problem"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// SearchAuditHandler returns a Gin HTTP handler that searches the audit trail, most recent first.
+//
+// Query parameters (all optional):
+// - user: Name of the user who performed the operation
+// - action: Part of the action, e.g. "DELETE" or "/topics/:topicName/config"
+// - resource: Topic, consumer group or job the operation was performed on
+// - outcome: success, failure or denied
+// - since / until: RFC3339 time range
+// - limit: Maximum number of records (default 100, max 1000)
+//
+// Returns:
+// - 200 OK with the matching records
+// - 400 Bad Request if a parameter is invalid
+// - 501 Not Implemented if no configured audit sink can be searched
+// - 500 Internal Server Error for other failures
+func SearchAuditHandler(l *audit.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := domain.AuditQuery{
+			User:     c.Query("user"),
+			Action:   c.Query("action"),
+			Resource: c.Query("resource"),
+			Outcome:  c.Query("outcome"),
+			Limit:    defaultAuditLimit,
+		}
+
+		if limitStr := c.Query("limit"); limitStr != "" {
+			limit, err := strconv.Atoi(limitStr)
+			if err != nil || limit <= 0 || limit > maxAuditLimit {
+				problem.Abort(c, problem.BadRequest("Invalid limit", "limit must be between 1 and 1000"))
+				return
+			}
+			query.Limit = limit
+		}
+
+		for param, target := range map[string]**time.Time{"since": &query.Since, "until": &query.Until} {
+			value := c.Query(param)
+			if value == "" {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				problem.Abort(c, problem.BadRequest("Invalid "+param+" time", err.Error()))
+				return
+			}
+			*target = &t
+		}
+
+		records, err := l.Search(query)
+		if err != nil {
+			if errors.Is(err, audit.ErrNotSearchable) {
+				problem.Abort(c, problem.New(http.StatusNotImplemented, problem.CodeNotImplemented,
+					"Audit trail cannot be searched", "enable the store or file audit sink to search the audit trail"))
+				return
+			}
+
+			problem.AbortWithError(c, err, "Failed to search audit trail")
+			return
+		}
+
+		if records == nil {
+			records = []domain.AuditRecord{}
+		}
+		c.JSON(http.StatusOK, gin.H{"records": records})
+	}
+}
@@ -0,0 +1,451 @@
+package api
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/valeriouberti/maestro/internal/kafka_client"
+	"github.com/valeriouberti/maestro/internal/problem"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+const (
+	// maxBatchRecords caps the number of records accepted in a single batch
+	maxBatchRecords = 50000
+	// maxBatchBodyBytes caps the size of a batch request body or uploaded file
+	maxBatchBodyBytes = 64 << 20 // 64MB
+)
+
+// Supported batch payload formats
+const (
+	batchFormatJSON   = "json"
+	batchFormatNDJSON = "ndjson"
+	batchFormatCSV    = "csv"
+)
+
+// BatchPublishRequest is the JSON object form of a batch publish request body.
+// A bare JSON array of records is accepted as well.
+type BatchPublishRequest struct {
+	Records []domain.ProduceRecord `json:"records"`
+}
+
+// PublishBatchHandler creates a Gin HTTP handler for publishing many records to a Kafka topic at once.
+//
+// The records can be supplied in one of the following forms, selected by the Content-Type
+// header or by the optional "format" query parameter (json, ndjson, csv):
+//   - application/json: a JSON array of records, or an object with a "records" array
+//   - application/x-ndjson: one JSON record per line
+//   - text/csv: a header row followed by one record per line
+//   - multipart/form-data: an uploaded file in the "file" field, whose format is
+//     taken from the "format" field or the file extension
+//
+// Every record may carry its own key, value, headers, partition and timestamp. Records
+// with the base64 encoding, as exported for messages that are not valid UTF-8, carry
+// their key, value and header values base64 encoded.
+// CSV files use the columns key, value, partition, timestamp, headers (a JSON object)
+// and encoding, and may add individual headers as "header.<name>" columns.
+//
+// Optional query parameters:
+//   - rate: maximum number of records published per second (default: unlimited)
+//   - results: which per-record results to return: all (default), failed or none
+//
+// Returns:
+// - 200 OK with a summary and the per-record delivery results
+// - 400 Bad Request if the topic name is missing or the payload cannot be parsed
+// - 404 Not Found if the topic doesn't exist
+// - 413 Request Entity Too Large if the batch exceeds the record or size limits
+// - 500 Internal Server Error for failures that prevent the batch from being published
+func PublishBatchHandler(k kafka_client.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		topicName := c.Param("topicName")
+		if topicName == "" {
+			problem.Abort(c, problem.BadRequest("Topic name is required", ""))
+			return
+		}
+
+		rate := 0
+		if rateStr := c.Query("rate"); rateStr != "" {
+			rateInt, err := strconv.Atoi(rateStr)
+			if err != nil || rateInt < 0 {
+				problem.Abort(c, problem.BadRequest("Invalid rate parameter", "rate must be a non-negative integer number of records per second"))
+				return
+			}
+			rate = rateInt
+		}
+
+		resultsMode := c.DefaultQuery("results", "all")
+		if resultsMode != "all" && resultsMode != "failed" && resultsMode != "none" {
+			problem.Abort(c, problem.BadRequest("Invalid results parameter", "results must be one of: all, failed, none"))
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBatchBodyBytes)
+
+		records, err := readBatchRecords(c)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) || err == errTooManyRecords {
+				problem.Abort(c, problem.New(http.StatusRequestEntityTooLarge, problem.CodeTooLarge, "Invalid batch request", err.Error()))
+				return
+			}
+			problem.Abort(c, problem.BadRequest("Invalid batch request", err.Error()))
+			return
+		}
+
+		if len(records) == 0 {
+			problem.Abort(c, problem.BadRequest("Batch must contain at least one record", ""))
+			return
+		}
+
+		start := time.Now()
+		results, err := k.PublishMessages(c.Request.Context(), topicName, records, rate)
+		if err != nil {
+			problem.AbortWithError(c, err, "Failed to publish batch")
+			return
+		}
+
+		summary := domain.BatchPublishSummary{
+			Total:      len(results),
+			DurationMs: time.Since(start).Milliseconds(),
+		}
+		failed := make([]domain.ProduceResult, 0)
+		for _, result := range results {
+			if result.Error != "" {
+				summary.Failed++
+				failed = append(failed, result)
+			} else {
+				summary.Succeeded++
+			}
+		}
+
+		response := gin.H{
+			"message": fmt.Sprintf("Published %d of %d records", summary.Succeeded, summary.Total),
+			"topic":   topicName,
+			"summary": summary,
+		}
+		switch resultsMode {
+		case "all":
+			response["results"] = results
+		case "failed":
+			response["results"] = failed
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+var errTooManyRecords = fmt.Errorf("batch exceeds the maximum of %d records", maxBatchRecords)
+
+// readBatchRecords decodes the records of a batch request according to its format
+func readBatchRecords(c *gin.Context) ([]domain.ProduceRecord, error) {
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+
+	if mediaType == "multipart/form-data" {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return nil, fmt.Errorf("multipart upload must contain a \"file\" field: %w", err)
+		}
+
+		format := c.PostForm("format")
+		if format == "" {
+			format = c.Query("format")
+		}
+		if format == "" {
+			format = batchFormatFromExtension(fileHeader.Filename)
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open uploaded file: %w", err)
+		}
+		defer file.Close()
+
+		return decodeBatchRecords(file, format)
+	}
+
+	format := c.Query("format")
+	if format == "" {
+		format = batchFormatFromMediaType(mediaType)
+	}
+	return decodeBatchRecords(c.Request.Body, format)
+}
+
+// decodeBatchRecords decodes records from r in the given format
+func decodeBatchRecords(r io.Reader, format string) ([]domain.ProduceRecord, error) {
+	var records []domain.ProduceRecord
+	var err error
+	switch format {
+	case batchFormatJSON:
+		records, err = decodeJSONRecords(r)
+	case batchFormatNDJSON:
+		records, err = decodeNDJSONRecords(r)
+	case batchFormatCSV:
+		records, err = decodeCSVRecords(r)
+	default:
+		return nil, fmt.Errorf("unsupported batch format %q (supported: json, ndjson, csv)", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range records {
+		if err := decodeRecordEncoding(&records[i]); err != nil {
+			return nil, fmt.Errorf("record %d: %w", i, err)
+		}
+	}
+	return records, nil
+}
+
+// decodeRecordEncoding decodes the key, value and header values of a base64 encoded
+// record, as exported for messages that are not valid UTF-8
+func decodeRecordEncoding(record *domain.ProduceRecord) error {
+	switch record.Encoding {
+	case "":
+		return nil
+	case domain.EncodingBase64:
+	default:
+		return fmt.Errorf("unsupported encoding %q (supported: %s)", record.Encoding, domain.EncodingBase64)
+	}
+
+	decode := func(field, value string) (string, error) {
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return "", fmt.Errorf("%s is not valid base64", field)
+		}
+		return string(decoded), nil
+	}
+	var err error
+	if record.Key, err = decode("key", record.Key); err != nil {
+		return err
+	}
+	if record.Value, err = decode("value", record.Value); err != nil {
+		return err
+	}
+	for name, value := range record.Headers {
+		if record.Headers[name], err = decode(fmt.Sprintf("header %q", name), value); err != nil {
+			return err
+		}
+	}
+	record.Encoding = ""
+	return nil
+}
+
+func batchFormatFromMediaType(mediaType string) string {
+	switch mediaType {
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/x-jsonlines":
+		return batchFormatNDJSON
+	case "text/csv", "application/csv":
+		return batchFormatCSV
+	default:
+		return batchFormatJSON
+	}
+}
+
+func batchFormatFromExtension(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".ndjson", ".jsonl":
+		return batchFormatNDJSON
+	case ".csv":
+		return batchFormatCSV
+	default:
+		return batchFormatJSON
+	}
+}
+
+// decodeJSONRecords accepts either a JSON array of records or a BatchPublishRequest object
+func decodeJSONRecords(r io.Reader) ([]domain.ProduceRecord, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	trimmed := strings.TrimSpace(string(data))
+	var records []domain.ProduceRecord
+	if strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal(data, &records); err != nil {
+			return nil, fmt.Errorf("invalid JSON array: %w", err)
+		}
+	} else {
+		var request BatchPublishRequest
+		if err := json.Unmarshal(data, &request); err != nil {
+			return nil, fmt.Errorf("invalid JSON body: %w", err)
+		}
+		records = request.Records
+	}
+
+	if len(records) > maxBatchRecords {
+		return nil, errTooManyRecords
+	}
+	return records, nil
+}
+
+// decodeNDJSONRecords decodes one JSON record per line, skipping blank lines
+func decodeNDJSONRecords(r io.Reader) ([]domain.ProduceRecord, error) {
+	records := make([]domain.ProduceRecord, 0)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16<<20) // Allow large single records
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var record domain.ProduceRecord
+		if err := json.Unmarshal([]byte(text), &record); err != nil {
+			return nil, fmt.Errorf("invalid JSON on line %d: %w", line, err)
+		}
+		records = append(records, record)
+
+		if len(records) > maxBatchRecords {
+			return nil, errTooManyRecords
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// decodeCSVRecords decodes records from CSV with a mandatory header row
+func decodeCSVRecords(r io.Reader) ([]domain.ProduceRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return []domain.ProduceRecord{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+
+	for i, column := range header {
+		column = strings.TrimSpace(column)
+		header[i] = column
+		switch {
+		case column == "key", column == "value", column == "partition",
+			column == "timestamp", column == "headers", column == "encoding":
+		case column == "topic", column == "offset":
+			// Present in exported files, ignored on import
+		case strings.HasPrefix(column, "header."):
+		default:
+			return nil, fmt.Errorf("unknown CSV column %q", column)
+		}
+	}
+
+	records := make([]domain.ProduceRecord, 0)
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV on line %d: %w", line, err)
+		}
+
+		record, err := csvRowToRecord(header, row)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV on line %d: %w", line, err)
+		}
+		records = append(records, record)
+
+		if len(records) > maxBatchRecords {
+			return nil, errTooManyRecords
+		}
+	}
+
+	return records, nil
+}
+
+func csvRowToRecord(header, row []string) (domain.ProduceRecord, error) {
+	var record domain.ProduceRecord
+
+	for i, value := range row {
+		if i >= len(header) {
+			return record, fmt.Errorf("row has more fields than the header")
+		}
+
+		column := header[i]
+		switch {
+		case column == "key":
+			record.Key = value
+		case column == "value":
+			record.Value = value
+		case column == "encoding":
+			record.Encoding = value
+		case column == "partition":
+			if value == "" {
+				continue
+			}
+			partition, err := strconv.ParseInt(value, 10, 32)
+			if err != nil {
+				return record, fmt.Errorf("invalid partition %q", value)
+			}
+			p := int32(partition)
+			record.Partition = &p
+		case column == "timestamp":
+			if value == "" {
+				continue
+			}
+			timestamp, err := parseRecordTimestamp(value)
+			if err != nil {
+				return record, err
+			}
+			record.Timestamp = &timestamp
+		case column == "headers":
+			if value == "" {
+				continue
+			}
+			headers := make(map[string]string)
+			if err := json.Unmarshal([]byte(value), &headers); err != nil {
+				return record, fmt.Errorf("headers must be a JSON object of strings: %w", err)
+			}
+			if record.Headers == nil {
+				record.Headers = headers
+			} else {
+				for k, v := range headers {
+					record.Headers[k] = v
+				}
+			}
+		case strings.HasPrefix(column, "header."):
+			if value == "" {
+				continue
+			}
+			if record.Headers == nil {
+				record.Headers = make(map[string]string)
+			}
+			record.Headers[strings.TrimPrefix(column, "header.")] = value
+		}
+	}
+
+	return record, nil
+}
+
+// parseRecordTimestamp accepts RFC 3339 timestamps or Unix epoch milliseconds
+func parseRecordTimestamp(value string) (time.Time, error) {
+	if millis, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(millis), nil
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q: use RFC 3339 or epoch milliseconds", value)
+	}
+	return timestamp, nil
+}
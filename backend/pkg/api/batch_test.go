@@ -0,0 +1,109 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valeriouberti/maestro/internal/export"
+	"github.com/valeriouberti/maestro/internal/kafka_client/fake"
+	"github.com/valeriouberti/maestro/internal/problem"
+	"github.com/valeriouberti/maestro/pkg/api"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// exportedMessages are a text message and a binary one, with a header that is not
+// valid UTF-8
+var exportedMessages = []domain.TopicMessage{
+	{Topic: "orders", Offset: 0, Key: "order-1", Value: `{"status":"created"}`, Headers: map[string]string{"source": "web"}},
+	{Topic: "orders", Offset: 1, Key: "\xff\x00\x01", Value: "\x00\x9f\x92\x96payload\xfe", Headers: map[string]string{"trace": "\xc3\x28"}},
+}
+
+// exportMessages encodes the messages in an export file of the format
+func exportMessages(t *testing.T, format string, messages []domain.TopicMessage) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := export.NewWriter(format, &buf)
+	if err != nil {
+		t.Fatalf("NewWriter(%s): %v", format, err)
+	}
+	for _, message := range messages {
+		message.Timestamp = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		if err := w.Write(message); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// publishBatch sends a batch file to the batch endpoint of the topic as the admin
+func (s *testServer) publishBatch(t *testing.T, topic, contentType string, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, api.BasePath+"/topics/"+topic+"/messages/batch", bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set(userHeader, admin)
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestBatchImportsBinaryExports(t *testing.T) {
+	for _, format := range []string{export.FormatNDJSON, export.FormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			s := newTestServer(t, allFeatures)
+			createTopic(t, s, "orders", 1)
+
+			file := exportMessages(t, format, exportedMessages)
+			if !strings.Contains(string(file), domain.EncodingBase64) {
+				t.Fatalf("the export does not mark the binary message as base64:\n%s", file)
+			}
+			if strings.Contains(string(file), "\xff") {
+				t.Fatalf("the export contains invalid UTF-8:\n%s", file)
+			}
+
+			w := s.publishBatch(t, "orders", export.ContentType(format), file)
+			expectStatus(t, w, http.StatusOK)
+
+			messages, err := s.cluster.GetTopicMessages(context.Background(), "orders", 0, fake.OffsetBeginning, 10)
+			if err != nil {
+				t.Fatalf("GetTopicMessages: %v", err)
+			}
+			if len(messages) != len(exportedMessages) {
+				t.Fatalf("published %d messages, want %d", len(messages), len(exportedMessages))
+			}
+			for i, want := range exportedMessages {
+				got := messages[i]
+				if got.Key != want.Key || got.Value != want.Value || len(got.Headers) != len(want.Headers) {
+					t.Errorf("message %d = %q %q %q, want %q %q %q", i, got.Key, got.Value, got.Headers, want.Key, want.Value, want.Headers)
+				}
+				for name, value := range want.Headers {
+					if got.Headers[name] != value {
+						t.Errorf("message %d header %s = %q, want %q", i, name, got.Headers[name], value)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBatchRejectsInvalidEncodings(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+	createTopic(t, s, "orders", 1)
+
+	for name, line := range map[string]string{
+		"unknown encoding": `{"key":"a","value":"b","encoding":"hex"}`,
+		"invalid base64":   `{"key":"a","value":"not base64!","encoding":"base64"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := s.publishBatch(t, "orders", "application/x-ndjson", []byte(line+"\n"))
+			expectProblem(t, w, http.StatusBadRequest, problem.CodeInvalidArgument)
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/valeriouberti/maestro/internal/audit"
+	"github.com/valeriouberti/maestro/internal/auth"
+	"github.com/valeriouberti/maestro/internal/kafka_client"
+	"github.com/valeriouberti/maestro/internal/policy"
+	"github.com/valeriouberti/maestro/internal/problem"
+	"github.com/valeriouberti/maestro/internal/protection"
+	"github.com/valeriouberti/maestro/internal/rbac"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// bulkOperation describes an operation applied to the topics selected by a bulk request
+type bulkOperation struct {
+	// name and params identify the operation in its confirmation
+	name   string
+	params string
+	// done describes the applied operation, e.g. "deleted"
+	done   string
+	action rbac.Action
+	// risky operations change protected topics only with the topic:protected permission
+	risky bool
+	// skip returns why the operation leaves a topic alone, if it does; optional
+	skip  func(topic domain.TopicInfo) string
+	apply func(ctx context.Context, topicNames []string) ([]domain.BulkTopicResult, error)
+}
+
+// bulkConfirmation returns the confirmation of a bulk operation on topics or consumer
+// groups. It changes with the operation and with the names it applies to, so that an
+// operation is only applied to what its dry run listed.
+func bulkConfirmation(op bulkOperation, names []string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00", op.name, op.params)
+	for _, name := range names {
+		fmt.Fprintf(h, "%s\x00", name)
+	}
+	return hex.EncodeToString(h.Sum(nil)[:12])
+}
+
+// confirmBulk checks the confirmation of a bulk operation that is not a dry run
+// against the one its dry run returned. what names the selected items, e.g. "Topics".
+func confirmBulk(c *gin.Context, opts domain.BulkOptions, confirmation, what string) bool {
+	switch opts.Confirmation {
+	case "":
+		problem.Abort(c, problem.BadRequest("Confirmation required",
+			"preview the operation with dryRun and pass the confirmation it returns"))
+		return false
+	case confirmation:
+		return true
+	default:
+		problem.Abort(c, problem.Conflict(what+" changed since the preview",
+			fmt.Sprintf("the operation no longer applies to the %s of its dry run; preview it again", strings.ToLower(what))))
+		return false
+	}
+}
+
+// selectTopics returns the outcome of a bulk operation for the topics a selector names
+// or matches, skipping those the caller may not change, and the names of the topics the
+// operation applies to. Patterns never match protected topics, which must be named.
+func selectTopics(c *gin.Context, k kafka_client.Client, guard *protection.Guard, selector domain.TopicSelector, op bulkOperation) ([]domain.BulkTopicResult, []string, error) {
+	topics, err := k.ListTopics(c.Request.Context())
+	if err != nil {
+		return nil, nil, err
+	}
+	byName := make(map[string]domain.TopicInfo, len(topics))
+	for _, topic := range topics {
+		byName[topic.Name] = topic
+	}
+
+	var names []string
+	if selector.Pattern != "" {
+		// The pattern was checked by bulkSelector
+		pattern := regexp.MustCompile("^(?:" + selector.Pattern + ")$")
+		for _, topic := range topics {
+			if !guard.Protected(topic) && pattern.MatchString(topic.Name) && rbac.Allowed(c, op.action, topic.Name) {
+				names = append(names, topic.Name)
+			}
+		}
+	} else {
+		for _, name := range selector.Topics {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+
+	results := make([]domain.BulkTopicResult, 0, len(names))
+	var applicable []string
+	for _, name := range names {
+		result := domain.BulkTopicResult{Topic: name}
+		topic, exists := byName[name]
+		switch {
+		case !exists:
+			result.Skipped = "the topic does not exist"
+		case !rbac.Allowed(c, op.action, name):
+			result.Skipped = "not allowed"
+		case op.risky && guard.Protected(topic) && !rbac.Allowed(c, rbac.ActionTopicProtected, name):
+			result.Skipped = "the topic is protected"
+		case op.skip != nil:
+			result.Skipped = op.skip(topic)
+		}
+		if result.Skipped == "" {
+			applicable = append(applicable, name)
+		}
+		results = append(results, result)
+	}
+	return results, applicable, nil
+}
+
+// bulkSelector checks the selector of a bulk request and names it in the audit record
+func bulkSelector(c *gin.Context, selector domain.TopicSelector) bool {
+	switch {
+	case len(selector.Topics) > 0 && selector.Pattern != "":
+		problem.Abort(c, problem.BadRequest("Invalid topic selection", "select topics with either topics or pattern, not both"))
+		return false
+	case len(selector.Topics) == 0 && selector.Pattern == "":
+		problem.Abort(c, problem.BadRequest("Invalid topic selection", "select topics with topics or pattern"))
+		return false
+	case selector.Pattern != "":
+		if _, err := regexp.Compile(selector.Pattern); err != nil {
+			problem.Abort(c, problem.BadRequest("Invalid topic pattern", err.Error()))
+			return false
+		}
+		audit.SetResource(c, selector.Pattern)
+	default:
+		audit.SetResource(c, strings.Join(selector.Topics, ","))
+	}
+	return true
+}
+
+// runBulk previews or applies a bulk operation. Operations are applied with a single
+// request to the cluster, and only with the confirmation of their dry run, which also
+// confirms risky operations on the protected topics it lists.
+func runBulk(c *gin.Context, k kafka_client.Client, guard *protection.Guard, selector domain.TopicSelector, opts domain.BulkOptions, op bulkOperation) {
+	results, applicable, err := selectTopics(c, k, guard, selector, op)
+	if err != nil {
+		problem.AbortWithError(c, err, "Failed to list topics")
+		return
+	}
+	confirmation := bulkConfirmation(op, applicable)
+
+	if opts.DryRun {
+		for i := range results {
+			results[i].Applied = results[i].Skipped == ""
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message":      fmt.Sprintf("Dry run: %d topics would be %s", len(applicable), op.done),
+			"dryRun":       true,
+			"confirmation": confirmation,
+			"topics":       results,
+		})
+		return
+	}
+
+	if !confirmBulk(c, opts, confirmation, "Topics") {
+		return
+	}
+
+	applied := 0
+	if len(applicable) > 0 {
+		outcomes, err := op.apply(c.Request.Context(), applicable)
+		if err != nil {
+			problem.AbortWithError(c, err, "Failed to apply the bulk operation")
+			return
+		}
+		byTopic := make(map[string]domain.BulkTopicResult, len(outcomes))
+		for _, outcome := range outcomes {
+			byTopic[outcome.Topic] = outcome
+			if outcome.Applied {
+				applied++
+			}
+		}
+		for i, result := range results {
+			if outcome, ok := byTopic[result.Topic]; ok {
+				results[i] = outcome
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("%d topics %s", applied, op.done),
+		"dryRun":  false,
+		"topics":  results,
+	})
+}
+
+// BulkDeleteTopicsHandler creates a Gin HTTP handler that deletes the topics listed or
+// matched by a regular expression, with a single request to the cluster.
+//
+// The request body is a domain.BulkTopicDeletionSpec. A dry run lists the topics that
+// would be deleted and returns a confirmation; the deletion requires it, and fails if
+// the selected topics changed in between. Topics the caller may not delete are skipped,
+// as are protected topics unless the caller holds the topic:protected permission.
+// Patterns never match protected topics.
+//
+// Returns:
+// - 200 OK with the outcome for every selected topic
+// - 400 Bad Request if the request is malformed, the pattern is invalid or the confirmation is missing
+// - 409 Conflict if the selected topics changed since the dry run
+func BulkDeleteTopicsHandler(k kafka_client.Client, guard *protection.Guard) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var spec domain.BulkTopicDeletionSpec
+		if err := c.ShouldBindJSON(&spec); err != nil {
+			problem.Abort(c, problem.BadRequest("Invalid bulk topic deletion request", err.Error()))
+			return
+		}
+		if !bulkSelector(c, spec.TopicSelector) {
+			return
+		}
+
+		runBulk(c, k, guard, spec.TopicSelector, spec.BulkOptions, bulkOperation{
+			name:   "delete",
+			done:   "deleted",
+			action: rbac.ActionTopicDelete,
+			risky:  true,
+			apply:  k.DeleteTopics,
+		})
+	}
+}
+
+// BulkUpdateTopicsConfigHandler creates a Gin HTTP handler that sets and removes
+// configuration overrides of the topics listed or matched by a regular expression, with
+// a single request to the cluster. The other overrides of the topics are left in place.
+//
+// The request body is a domain.BulkTopicConfigSpec, previewed and confirmed like bulk
+// deletions. The change is checked against the topic policies; when it touches risky
+// entries, protected topics are skipped unless the caller may change them.
+//
+// Returns:
+// - 200 OK with the outcome for every selected topic
+// - 400 Bad Request if the request is malformed, the pattern is invalid or the confirmation is missing
+// - 409 Conflict if the selected topics changed since the dry run
+// - 422 Unprocessable Entity if the change breaks topic policies
+func BulkUpdateTopicsConfigHandler(k kafka_client.Client, policies *policy.Checker, guard *protection.Guard) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var spec domain.BulkTopicConfigSpec
+		if err := c.ShouldBindJSON(&spec); err != nil {
+			problem.Abort(c, problem.BadRequest("Invalid bulk topic configuration request", err.Error()))
+			return
+		}
+		if len(spec.Set) == 0 && len(spec.Delete) == 0 {
+			problem.Abort(c, problem.BadRequest("Invalid bulk topic configuration request", "set or delete at least one configuration entry"))
+			return
+		}
+		for _, key := range spec.Delete {
+			if _, ok := spec.Set[key]; ok {
+				problem.Abort(c, problem.BadRequest("Invalid bulk topic configuration request",
+					fmt.Sprintf("configuration entry %s is both set and deleted", key)))
+				return
+			}
+		}
+		if !bulkSelector(c, spec.TopicSelector) {
+			return
+		}
+		if violations := policies.CheckConfigChange(c.Request.Context(), auth.PrincipalFrom(c), spec.Set, spec.Delete); len(violations) > 0 {
+			problem.Abort(c, problem.PolicyViolation("Configuration change breaks topic policies", violations))
+			return
+		}
+
+		deleted := slices.Sorted(slices.Values(spec.Delete))
+		changed := append(slices.Collect(maps.Keys(spec.Set)), deleted...)
+		runBulk(c, k, guard, spec.TopicSelector, spec.BulkOptions, bulkOperation{
+			name:   "config",
+			params: fmt.Sprint(spec.Set, deleted),
+			done:   "updated",
+			action: rbac.ActionTopicConfig,
+			risky:  len(guard.Risky(changed...)) > 0,
+			apply: func(ctx context.Context, topicNames []string) ([]domain.BulkTopicResult, error) {
+				return k.AlterTopicsConfig(ctx, topicNames, spec.Set, deleted)
+			},
+		})
+	}
+}
+
+// BulkCreatePartitionsHandler creates a Gin HTTP handler that increases the number of
+// partitions of the topics listed or matched by a regular expression, with a single
+// request to the cluster. Topics with as many partitions already are skipped.
+//
+// The request body is a domain.BulkPartitionsSpec, previewed and confirmed like bulk
+// deletions. The number of partitions is checked against the topic policies. Protected
+// topics are skipped unless the caller may change them.
+//
+// Returns:
+// - 200 OK with the outcome for every selected topic
+// - 400 Bad Request if the request is malformed, the pattern is invalid or the confirmation is missing
+// - 409 Conflict if the selected topics changed since the dry run
+// - 422 Unprocessable Entity if the number of partitions breaks topic policies
+func BulkCreatePartitionsHandler(k kafka_client.Client, policies *policy.Checker, guard *protection.Guard) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var spec domain.BulkPartitionsSpec
+		if err := c.ShouldBindJSON(&spec); err != nil {
+			problem.Abort(c, problem.BadRequest("Invalid bulk partition creation request", err.Error()))
+			return
+		}
+		if !bulkSelector(c, spec.TopicSelector) {
+			return
+		}
+		if violations := policies.CheckPartitions(c.Request.Context(), auth.PrincipalFrom(c), spec.Partitions); len(violations) > 0 {
+			problem.Abort(c, problem.PolicyViolation("Number of partitions breaks topic policies", violations))
+			return
+		}
+
+		runBulk(c, k, guard, spec.TopicSelector, spec.BulkOptions, bulkOperation{
+			name:   "partitions",
+			params: strconv.Itoa(int(spec.Partitions)),
+			done:   "repartitioned",
+			action: rbac.ActionTopicConfig,
+			risky:  true,
+			skip: func(topic domain.TopicInfo) string {
+				if topic.NumPartitions >= spec.Partitions {
+					return fmt.Sprintf("the topic has %d partitions already", topic.NumPartitions)
+				}
+				return ""
+			},
+			apply: func(ctx context.Context, topicNames []string) ([]domain.BulkTopicResult, error) {
+				return k.CreatePartitions(ctx, topicNames, spec.Partitions)
+			},
+		})
+	}
+}
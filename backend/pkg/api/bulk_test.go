@@ -0,0 +1,264 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/valeriouberti/maestro/internal/problem"
+	"github.com/valeriouberti/maestro/pkg/api"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// bulkResponse is the response of bulk topic operations
+type bulkResponse struct {
+	DryRun       bool                     `json:"dryRun"`
+	Confirmation string                   `json:"confirmation"`
+	Topics       []domain.BulkTopicResult `json:"topics"`
+}
+
+// topicNames returns the names of the topics of the cluster, sorted
+func topicNames(t *testing.T, s *testServer) []string {
+	t.Helper()
+	topics, err := s.cluster.ListTopics(context.Background())
+	if err != nil {
+		t.Fatalf("ListTopics: %v", err)
+	}
+	names := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		names = append(names, topic.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// dryRun previews a bulk operation as user and returns its confirmation
+func dryRun(t *testing.T, s *testServer, user, path string, selector domain.TopicSelector) bulkResponse {
+	t.Helper()
+	w := s.do(t, user, http.MethodPost, path, domain.BulkTopicDeletionSpec{
+		TopicSelector: selector,
+		BulkOptions:   domain.BulkOptions{DryRun: true},
+	})
+	expectStatus(t, w, http.StatusOK)
+	preview := decode[bulkResponse](t, w)
+	if !preview.DryRun || preview.Confirmation == "" {
+		t.Fatalf("dry run = %+v, want a confirmation", preview)
+	}
+	return preview
+}
+
+func TestBulkDeleteTopics(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+	for _, name := range []string{"billing.invoices", "billing.payments", "orders", "prod.billing.ledger"} {
+		createTopic(t, s, name, 1)
+	}
+	path := api.BasePath + "/topics/delete"
+	selector := domain.TopicSelector{Pattern: `(billing|prod\.billing)\..*`}
+
+	// Patterns never match protected topics, even for callers who may delete them
+	preview := dryRun(t, s, admin, path, selector)
+	want := []domain.BulkTopicResult{
+		{Topic: "billing.invoices", Applied: true},
+		{Topic: "billing.payments", Applied: true},
+	}
+	if !slices.Equal(preview.Topics, want) {
+		t.Fatalf("dry run topics = %+v, want %+v", preview.Topics, want)
+	}
+	if names := topicNames(t, s); len(names) != 4 {
+		t.Fatalf("the dry run deleted topics: %v left", names)
+	}
+
+	// Deleting requires the confirmation of a dry run
+	w := s.do(t, admin, http.MethodPost, path, domain.BulkTopicDeletionSpec{TopicSelector: selector})
+	expectProblem(t, w, http.StatusBadRequest, problem.CodeInvalidArgument)
+
+	// The confirmation does not apply once the matching topics changed
+	createTopic(t, s, "billing.refunds", 1)
+	w = s.do(t, admin, http.MethodPost, path, domain.BulkTopicDeletionSpec{
+		TopicSelector: selector,
+		BulkOptions:   domain.BulkOptions{Confirmation: preview.Confirmation},
+	})
+	expectProblem(t, w, http.StatusConflict, problem.CodeConflict)
+
+	// Nor to another operation on the same topics
+	w = s.do(t, admin, http.MethodPost, api.BasePath+"/topics/partitions", domain.BulkPartitionsSpec{
+		TopicSelector: selector,
+		BulkOptions:   domain.BulkOptions{Confirmation: preview.Confirmation},
+		Partitions:    2,
+	})
+	expectProblem(t, w, http.StatusConflict, problem.CodeConflict)
+
+	preview = dryRun(t, s, admin, path, selector)
+	w = s.do(t, admin, http.MethodPost, path, domain.BulkTopicDeletionSpec{
+		TopicSelector: selector,
+		BulkOptions:   domain.BulkOptions{Confirmation: preview.Confirmation},
+	})
+	expectStatus(t, w, http.StatusOK)
+	deleted := decode[bulkResponse](t, w)
+	want = []domain.BulkTopicResult{
+		{Topic: "billing.invoices", Applied: true},
+		{Topic: "billing.payments", Applied: true},
+		{Topic: "billing.refunds", Applied: true},
+	}
+	if deleted.DryRun || !slices.Equal(deleted.Topics, want) {
+		t.Fatalf("deletion = %+v, want topics %+v", deleted, want)
+	}
+	if names := topicNames(t, s); !slices.Equal(names, []string{"orders", "prod.billing.ledger"}) {
+		t.Fatalf("topics left = %v, want orders and prod.billing.ledger", names)
+	}
+}
+
+func TestBulkDeleteSkipsDeniedTopics(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+	for _, name := range []string{"billing.invoices", "orders", "prod.billing.ledger"} {
+		createTopic(t, s, name, 1)
+	}
+	path := api.BasePath + "/topics/delete"
+
+	// Patterns only match the topics the caller may delete
+	preview := dryRun(t, s, billing, path, domain.TopicSelector{Pattern: ".*"})
+	want := []domain.BulkTopicResult{{Topic: "billing.invoices", Applied: true}}
+	if !slices.Equal(preview.Topics, want) {
+		t.Fatalf("dry run topics = %+v, want %+v", preview.Topics, want)
+	}
+
+	// Named topics the caller may not delete are listed as skipped
+	selector := domain.TopicSelector{Topics: []string{"prod.billing.ledger", "orders", "missing", "billing.invoices"}}
+	preview = dryRun(t, s, billing, path, selector)
+	want = []domain.BulkTopicResult{
+		{Topic: "billing.invoices", Applied: true},
+		{Topic: "missing", Skipped: "the topic does not exist"},
+		{Topic: "orders", Skipped: "not allowed"},
+		{Topic: "prod.billing.ledger", Skipped: "the topic is protected"},
+	}
+	if !slices.Equal(preview.Topics, want) {
+		t.Fatalf("dry run topics = %+v, want %+v", preview.Topics, want)
+	}
+
+	w := s.do(t, billing, http.MethodPost, path, domain.BulkTopicDeletionSpec{
+		TopicSelector: selector,
+		BulkOptions:   domain.BulkOptions{Confirmation: preview.Confirmation},
+	})
+	expectStatus(t, w, http.StatusOK)
+	if names := topicNames(t, s); !slices.Equal(names, []string{"orders", "prod.billing.ledger"}) {
+		t.Fatalf("topics left = %v, want orders and prod.billing.ledger", names)
+	}
+
+	// The admin may delete a protected topic by naming it
+	preview = dryRun(t, s, admin, path, domain.TopicSelector{Topics: []string{"prod.billing.ledger"}})
+	if want := []domain.BulkTopicResult{{Topic: "prod.billing.ledger", Applied: true}}; !slices.Equal(preview.Topics, want) {
+		t.Fatalf("dry run topics = %+v, want %+v", preview.Topics, want)
+	}
+
+	w = s.do(t, viewer, http.MethodPost, path, domain.BulkTopicDeletionSpec{
+		TopicSelector: domain.TopicSelector{Pattern: ".*"},
+		BulkOptions:   domain.BulkOptions{DryRun: true},
+	})
+	expectProblem(t, w, http.StatusForbidden, problem.CodeForbidden)
+}
+
+func TestBulkInvalidSelection(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+	path := api.BasePath + "/topics/delete"
+
+	for name, selector := range map[string]domain.TopicSelector{
+		"none":            {},
+		"both":            {Topics: []string{"orders"}, Pattern: "orders"},
+		"invalid pattern": {Pattern: "orders("},
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := s.do(t, admin, http.MethodPost, path, domain.BulkTopicDeletionSpec{
+				TopicSelector: selector,
+				BulkOptions:   domain.BulkOptions{DryRun: true},
+			})
+			expectProblem(t, w, http.StatusBadRequest, problem.CodeInvalidArgument)
+		})
+	}
+}
+
+func TestBulkCreatePartitions(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+	createTopic(t, s, "billing.invoices", 1)
+	createTopic(t, s, "billing.payments", 6)
+	path := api.BasePath + "/topics/partitions"
+	selector := domain.TopicSelector{Pattern: `billing\..*`}
+
+	w := s.do(t, admin, http.MethodPost, path, domain.BulkPartitionsSpec{
+		TopicSelector: selector,
+		BulkOptions:   domain.BulkOptions{DryRun: true},
+		Partitions:    24,
+	})
+	expectProblem(t, w, http.StatusUnprocessableEntity, problem.CodePolicyViolation)
+
+	w = s.do(t, admin, http.MethodPost, path, domain.BulkPartitionsSpec{
+		TopicSelector: selector,
+		BulkOptions:   domain.BulkOptions{DryRun: true},
+		Partitions:    6,
+	})
+	expectStatus(t, w, http.StatusOK)
+	preview := decode[bulkResponse](t, w)
+	want := []domain.BulkTopicResult{
+		{Topic: "billing.invoices", Applied: true},
+		{Topic: "billing.payments", Skipped: "the topic has 6 partitions already"},
+	}
+	if !slices.Equal(preview.Topics, want) {
+		t.Fatalf("dry run topics = %+v, want %+v", preview.Topics, want)
+	}
+
+	w = s.do(t, admin, http.MethodPost, path, domain.BulkPartitionsSpec{
+		TopicSelector: selector,
+		BulkOptions:   domain.BulkOptions{Confirmation: preview.Confirmation},
+		Partitions:    6,
+	})
+	expectStatus(t, w, http.StatusOK)
+	topic, err := s.cluster.GetTopicDetails(context.Background(), "billing.invoices")
+	if err != nil {
+		t.Fatalf("GetTopicDetails: %v", err)
+	}
+	if topic.NumPartitions != 6 {
+		t.Fatalf("billing.invoices has %d partitions, want 6", topic.NumPartitions)
+	}
+}
+
+func TestDeleteConsumerGroupsSkipsDeniedGroups(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+	s.cluster.AddConsumerGroup("billing-invoicer", "Empty")
+	s.cluster.AddConsumerGroup("billing-reporter", "Empty")
+	s.cluster.AddConsumerGroup("orders-reader", "Empty")
+
+	type response struct {
+		Confirmation string                 `json:"confirmation"`
+		Groups       []domain.GroupDeletion `json:"groups"`
+	}
+	path := api.BasePath + "/consumergroups/delete"
+
+	// Only the groups the caller may delete match
+	w := s.do(t, billing, http.MethodPost, path, domain.GroupDeletionSpec{Pattern: "*", BulkOptions: domain.BulkOptions{DryRun: true}})
+	expectStatus(t, w, http.StatusOK)
+	preview := decode[response](t, w)
+	want := []domain.GroupDeletion{
+		{GroupID: "billing-invoicer", Deleted: true},
+		{GroupID: "billing-reporter", Deleted: true},
+	}
+	if !slices.Equal(preview.Groups, want) {
+		t.Fatalf("dry run groups = %+v, want %+v", preview.Groups, want)
+	}
+
+	// The admin's confirmation covers other groups
+	w = s.do(t, admin, http.MethodPost, path, domain.GroupDeletionSpec{Pattern: "*", BulkOptions: domain.BulkOptions{Confirmation: preview.Confirmation}})
+	expectProblem(t, w, http.StatusConflict, problem.CodeConflict)
+
+	w = s.do(t, billing, http.MethodPost, path, domain.GroupDeletionSpec{Pattern: "*", BulkOptions: domain.BulkOptions{Confirmation: preview.Confirmation}})
+	expectStatus(t, w, http.StatusOK)
+	groups, err := s.cluster.ListConsumerGroups(context.Background())
+	if err != nil {
+		t.Fatalf("ListConsumerGroups: %v", err)
+	}
+	if len(groups) != 1 || groups[0].GroupID != "orders-reader" {
+		t.Fatalf("groups left = %+v, want orders-reader", groups)
+	}
+
+	w = s.do(t, admin, http.MethodPost, path, domain.GroupDeletionSpec{Pattern: "[", BulkOptions: domain.BulkOptions{DryRun: true}})
+	expectProblem(t, w, http.StatusBadRequest, problem.CodeInvalidArgument)
+}
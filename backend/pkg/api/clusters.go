@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/valeriouberti/maestro/internal/clusters"
+	"github.com/valeriouberti/maestro/internal/problem"
+)
+
+// ListConfiguredClustersHandler returns a Gin HTTP handler that lists the Kafka clusters
+// defined in the configuration. Requests select one of them with the X-Maestro-Cluster
+// header or the "cluster" query parameter.
+//
+// Returns:
+// - 200 OK with the clusters, in configuration order
+func ListConfiguredClustersHandler(r *clusters.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"clusters": r.List()})
+	}
+}
+
+// RequireFeature returns a middleware that rejects requests with 403 Forbidden when a
+// feature is turned off in the configuration. reason is the detail of the response,
+// e.g. "Topic deletion is disabled".
+func RequireFeature(enabled bool, reason string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !enabled {
+			problem.Abort(c, problem.New(http.StatusForbidden, problem.CodeForbidden, "Feature disabled", reason))
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,22 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/valeriouberti/maestro/internal/config"
+)
+
+// GetConfigHandler returns a Gin HTTP handler that shows the configuration in effect:
+// the settings read at startup with the cluster definitions of the last reload.
+// Secret references are shown as written; secrets written in the configuration are
+// replaced by a placeholder.
+//
+// Returns:
+// - 200 OK with the configuration
+func GetConfigHandler(current func() *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"config": current()})
+	}
+}
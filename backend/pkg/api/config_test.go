@@ -0,0 +1,62 @@
+package api_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/valeriouberti/maestro/internal/config"
+	"github.com/valeriouberti/maestro/internal/problem"
+	"github.com/valeriouberti/maestro/pkg/api"
+)
+
+// secret returns a Secret set as if written in the configuration
+func secret(t *testing.T, value string) *config.Secret {
+	t.Helper()
+	var s config.Secret
+	if err := s.UnmarshalText([]byte(value)); err != nil {
+		t.Fatalf("UnmarshalText: %v", err)
+	}
+	return &s
+}
+
+func TestGetConfigRedactsSecrets(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+	s.cfg.Alerting.Notifiers = []config.NotifierConfig{{
+		Name: "oncall",
+		Type: "webhook",
+		URL:  *secret(t, "https://events.example.com/maestro"),
+		Headers: map[string]*config.Secret{
+			"Authorization": secret(t, "Bearer webhook-token"),
+			"X-Api-Key":     secret(t, "env:ONCALL_API_KEY"),
+		},
+	}}
+	s.cfg.Clusters[0].Properties = config.Properties{
+		"sasl.password":     secret(t, "kafka-password"),
+		"ssl.key.password":  secret(t, "file:/run/secrets/key-password"),
+		"socket.timeout.ms": secret(t, "30000"),
+	}
+
+	w := s.do(t, admin, http.MethodGet, api.BasePath+"/config", nil)
+	expectStatus(t, w, http.StatusOK)
+	body := w.Body.String()
+	for _, secret := range []string{"webhook-token", "kafka-password"} {
+		if strings.Contains(body, secret) {
+			t.Errorf("the configuration reveals %q: %s", secret, body)
+		}
+	}
+	for _, want := range []string{
+		`"Authorization":"[REDACTED]"`,
+		`"X-Api-Key":"env:ONCALL_API_KEY"`,
+		`"sasl.password":"[REDACTED]"`,
+		`"ssl.key.password":"file:/run/secrets/key-password"`,
+		`"socket.timeout.ms":"30000"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("the configuration lacks %s: %s", want, body)
+		}
+	}
+
+	w = s.do(t, viewer, http.MethodGet, api.BasePath+"/config", nil)
+	expectProblem(t, w, http.StatusForbidden, problem.CodeForbidden)
+}
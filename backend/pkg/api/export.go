@@ -0,0 +1,188 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/valeriouberti/maestro/internal/export"
+	"github.com/valeriouberti/maestro/internal/kafka_client"
+	"github.com/valeriouberti/maestro/internal/problem"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// exportFlushInterval is the number of messages written between flushes of the response
+const exportFlushInterval = 1000
+
+// ExportTopicMessagesHandler creates a Gin HTTP handler that streams a range of a topic
+// as a downloadable file.
+//
+// Query parameters:
+//   - format: ndjson (default), csv, avro or parquet
+//   - partition: a partition or comma-separated list of partitions (default: all)
+//   - startOffset / endOffset: offset range per partition, end exclusive
+//   - startTime / endTime: time range as RFC 3339 or epoch milliseconds, end exclusive
+//   - limit: maximum number of messages to export (default: unlimited)
+//
+// The response is streamed while the topic is read, so it is not bound by the
+// server write timeout. Errors detected before the first message is written are
+// returned as JSON; later errors truncate the download.
+//
+// Returns:
+// - 200 OK with the export file as an attachment
+// - 400 Bad Request if the topic name is missing or parameters are invalid
+// - 404 Not Found if the topic or a partition doesn't exist
+// - 500 Internal Server Error for other failures
+func ExportTopicMessagesHandler(k kafka_client.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		topicName := c.Param("topicName")
+		if topicName == "" {
+			problem.Abort(c, problem.BadRequest("Topic name is required", ""))
+			return
+		}
+
+		format := c.DefaultQuery("format", export.FormatNDJSON)
+		if !export.IsSupported(format) {
+			problem.Abort(c, problem.BadRequest("Invalid format parameter", "format must be one of: "+strings.Join(export.Formats, ", ")))
+			return
+		}
+
+		rng, err := parseMessageRange(c)
+		if err != nil {
+			problem.Abort(c, problem.BadRequest("Invalid export range", err.Error()))
+			return
+		}
+
+		// Exports may run far longer than the server write timeout
+		if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+			slog.WarnContext(c.Request.Context(), "Unable to clear write deadline for export", "topic", topicName, "error", err)
+		}
+
+		filename := fmt.Sprintf("%s-%s%s", topicName, time.Now().UTC().Format("20060102T150405Z"), export.FileExtension(format))
+
+		var writer export.Writer
+		startWriter := func() error {
+			c.Header("Content-Type", export.ContentType(format))
+			c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+			c.Header("X-Accel-Buffering", "no")
+			c.Status(http.StatusOK)
+
+			w, err := export.NewWriter(format, c.Writer)
+			if err != nil {
+				return err
+			}
+			writer = w
+			return nil
+		}
+
+		count := 0
+		err = k.StreamTopicMessages(c.Request.Context(), topicName, rng, func(message domain.TopicMessage) error {
+			if writer == nil {
+				if err := startWriter(); err != nil {
+					return err
+				}
+			}
+			if err := writer.Write(message); err != nil {
+				return err
+			}
+			count++
+			if count%exportFlushInterval == 0 {
+				c.Writer.Flush()
+			}
+			return nil
+		})
+
+		if err != nil {
+			if writer != nil {
+				// Headers are already sent: all we can do is stop writing
+				slog.WarnContext(c.Request.Context(), "Export aborted", "topic", topicName, "messages", count, "error", err)
+				return
+			}
+
+			problem.AbortWithError(c, err, "Failed to export messages")
+			return
+		}
+
+		// An empty range still produces a valid, empty file
+		if writer == nil {
+			if err := startWriter(); err != nil {
+				slog.ErrorContext(c.Request.Context(), "Export failed", "topic", topicName, "error", err)
+				return
+			}
+		}
+
+		if err := writer.Close(); err != nil {
+			slog.ErrorContext(c.Request.Context(), "Export failed to finalize", "topic", topicName, "messages", count, "error", err)
+		}
+	}
+}
+
+// parseMessageRange builds a domain.MessageRange from the partition, startOffset,
+// endOffset, startTime, endTime and limit query parameters
+func parseMessageRange(c *gin.Context) (domain.MessageRange, error) {
+	var rng domain.MessageRange
+
+	if partitionStr := c.Query("partition"); partitionStr != "" {
+		for _, part := range strings.Split(partitionStr, ",") {
+			partition, err := strconv.ParseInt(strings.TrimSpace(part), 10, 32)
+			if err != nil || partition < 0 {
+				return rng, fmt.Errorf("invalid partition %q", part)
+			}
+			rng.Partitions = append(rng.Partitions, int32(partition))
+		}
+	}
+
+	for _, param := range []struct {
+		name   string
+		target **int64
+	}{
+		{"startOffset", &rng.StartOffset},
+		{"endOffset", &rng.EndOffset},
+	} {
+		if value := c.Query(param.name); value != "" {
+			offset, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || offset < 0 {
+				return rng, fmt.Errorf("invalid %s %q", param.name, value)
+			}
+			*param.target = &offset
+		}
+	}
+
+	for _, param := range []struct {
+		name   string
+		target **time.Time
+	}{
+		{"startTime", &rng.StartTime},
+		{"endTime", &rng.EndTime},
+	} {
+		if value := c.Query(param.name); value != "" {
+			timestamp, err := parseRecordTimestamp(value)
+			if err != nil {
+				return rng, fmt.Errorf("invalid %s: %w", param.name, err)
+			}
+			*param.target = &timestamp
+		}
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.ParseInt(limitStr, 10, 64)
+		if err != nil || limit < 0 {
+			return rng, fmt.Errorf("invalid limit %q", limitStr)
+		}
+		rng.Limit = limit
+	}
+
+	if rng.StartOffset != nil && rng.EndOffset != nil && *rng.EndOffset < *rng.StartOffset {
+		return rng, fmt.Errorf("endOffset must not be lower than startOffset")
+	}
+	if rng.StartTime != nil && rng.EndTime != nil && rng.EndTime.Before(*rng.StartTime) {
+		return rng, fmt.Errorf("endTime must not be before startTime")
+	}
+
+	return rng, nil
+}
@@ -0,0 +1,315 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/valeriouberti/maestro/internal/audit"
+	"github.com/valeriouberti/maestro/internal/kafka_client"
+	"github.com/valeriouberti/maestro/internal/problem"
+	"github.com/valeriouberti/maestro/internal/rbac"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// GetConsumerGroupLagHandler creates a Gin HTTP handler that reports the committed
+// offsets of a consumer group and how far they are behind the end of their partitions.
+//
+// Returns:
+// - 200 OK with the lag per partition and in total
+// - 400 Bad Request if the group ID is missing
+// - 404 Not Found if the consumer group doesn't exist
+// - 500 Internal Server Error for other failures
+func GetConsumerGroupLagHandler(k kafka_client.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groupID := c.Param("groupId")
+		if groupID == "" {
+			problem.Abort(c, problem.BadRequest("Consumer group ID is required", ""))
+			return
+		}
+
+		lag, err := k.GetConsumerGroupLag(c.Request.Context(), groupID)
+		if err != nil {
+			problem.AbortWithError(c, err, "Failed to get consumer group lag")
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"lag": lag,
+		})
+	}
+}
+
+// ResetConsumerGroupOffsetsHandler creates a Gin HTTP handler that moves the committed
+// offsets of a consumer group on a topic to the earliest or latest offset, to a given
+// offset or to the first message at or after a timestamp.
+//
+// The request body is a domain.OffsetResetSpec. With "dryRun" the new offsets are
+// returned without being committed. Kafka only accepts new offsets for a group
+// without active members.
+//
+// Returns:
+// - 200 OK with the previous and new offset of every partition
+// - 400 Bad Request if the request is malformed or the target is invalid
+// - 404 Not Found if the topic or a partition doesn't exist
+// - 409 Conflict if the group has active members
+// - 500 Internal Server Error for other failures
+func ResetConsumerGroupOffsetsHandler(k kafka_client.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groupID := c.Param("groupId")
+		if groupID == "" {
+			problem.Abort(c, problem.BadRequest("Consumer group ID is required", ""))
+			return
+		}
+
+		var spec domain.OffsetResetSpec
+		if err := c.ShouldBindJSON(&spec); err != nil {
+			problem.Abort(c, problem.BadRequest("Invalid offset reset request", err.Error()))
+			return
+		}
+
+		resets, err := k.ResetConsumerGroupOffsets(c.Request.Context(), groupID, spec)
+		if err != nil {
+			problem.AbortWithError(c, err, "Failed to reset consumer group offsets")
+			return
+		}
+
+		message := fmt.Sprintf("Offsets of %d partitions reset", len(resets))
+		if spec.DryRun {
+			message = fmt.Sprintf("Dry run: offsets of %d partitions would be reset", len(resets))
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message": message,
+			"groupId": groupID,
+			"dryRun":  spec.DryRun,
+			"offsets": resets,
+		})
+	}
+}
+
+// MemberRemovalRequest represents a request to remove static members from a consumer
+// group. Members are identified by the group.instance.id of their consumers.
+type MemberRemovalRequest struct {
+	GroupInstanceIDs []string `json:"groupInstanceIds" binding:"required,min=1"`
+}
+
+// DeleteConsumerGroupHandler creates a Gin HTTP handler that deletes a consumer group
+// and its committed offsets. Kafka only deletes groups without active members.
+//
+// Returns:
+// - 200 OK when the group is deleted
+// - 400 Bad Request if the group ID is missing
+// - 404 Not Found if the consumer group doesn't exist
+// - 409 Conflict if the group has active members
+// - 500 Internal Server Error for other failures
+func DeleteConsumerGroupHandler(k kafka_client.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groupID := c.Param("groupId")
+		if groupID == "" {
+			problem.Abort(c, problem.BadRequest("Consumer group ID is required", ""))
+			return
+		}
+
+		if err := k.DeleteConsumerGroup(c.Request.Context(), groupID); err != nil {
+			problem.AbortWithError(c, err, "Failed to delete consumer group")
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Consumer group deleted successfully",
+			"groupId": groupID,
+		})
+	}
+}
+
+// DeleteConsumerGroupsHandler creates a Gin HTTP handler that deletes the consumer
+// groups whose ID matches a glob pattern, e.g. the stale maestro-message-reader-*
+// groups, with a single request to the cluster.
+//
+// The request body is a domain.GroupDeletionSpec. A dry run lists the groups that would
+// be deleted and returns a confirmation; the deletion requires it, and fails if the
+// matching groups changed in between. Only the groups the caller may delete are
+// considered, and groups with members are skipped rather than deleted.
+//
+// Returns:
+// - 200 OK with the outcome for every matching group
+// - 400 Bad Request if the request is malformed, the pattern is invalid or the confirmation is missing
+// - 409 Conflict if the matching groups changed since the dry run
+// - 500 Internal Server Error if the groups cannot be listed
+func DeleteConsumerGroupsHandler(k kafka_client.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var spec domain.GroupDeletionSpec
+		if err := c.ShouldBindJSON(&spec); err != nil {
+			problem.Abort(c, problem.BadRequest("Invalid consumer group deletion request", err.Error()))
+			return
+		}
+		if _, err := path.Match(spec.Pattern, ""); err != nil {
+			problem.Abort(c, problem.BadRequest("Invalid consumer group pattern", err.Error()))
+			return
+		}
+		audit.SetResource(c, spec.Pattern)
+
+		ctx := c.Request.Context()
+		groups, err := k.ListConsumerGroups(ctx)
+		if err != nil {
+			problem.AbortWithError(c, err, "Failed to list consumer groups")
+			return
+		}
+		slices.SortFunc(groups, func(a, b domain.ConsumerGroupInfo) int {
+			return strings.Compare(a.GroupID, b.GroupID)
+		})
+
+		results := make([]domain.GroupDeletion, 0)
+		var applicable []string
+		for _, group := range groups {
+			if matched, _ := path.Match(spec.Pattern, group.GroupID); !matched || !rbac.Allowed(c, rbac.ActionGroupDelete, group.GroupID) {
+				continue
+			}
+
+			result := domain.GroupDeletion{GroupID: group.GroupID}
+			if group.MemberCount > 0 {
+				result.Skipped = kafka_client.GroupSkippedActive
+			} else {
+				applicable = append(applicable, group.GroupID)
+			}
+			results = append(results, result)
+		}
+		confirmation := bulkConfirmation(bulkOperation{name: "deleteGroups", params: spec.Pattern}, applicable)
+
+		if spec.DryRun {
+			for i := range results {
+				results[i].Deleted = results[i].Skipped == ""
+			}
+			c.JSON(http.StatusOK, gin.H{
+				"message":      fmt.Sprintf("Dry run: %d consumer groups would be deleted", len(applicable)),
+				"pattern":      spec.Pattern,
+				"dryRun":       true,
+				"confirmation": confirmation,
+				"groups":       results,
+			})
+			return
+		}
+		if !confirmBulk(c, spec.BulkOptions, confirmation, "Consumer groups") {
+			return
+		}
+
+		deleted := 0
+		if len(applicable) > 0 {
+			outcomes, err := k.DeleteConsumerGroups(ctx, applicable)
+			if err != nil {
+				problem.AbortWithError(c, err, "Failed to delete consumer groups")
+				return
+			}
+			byGroup := make(map[string]domain.GroupDeletion, len(outcomes))
+			for _, outcome := range outcomes {
+				byGroup[outcome.GroupID] = outcome
+				if outcome.Deleted {
+					deleted++
+				}
+			}
+			for i, result := range results {
+				if outcome, ok := byGroup[result.GroupID]; ok {
+					results[i] = outcome
+				}
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": fmt.Sprintf("%d consumer groups deleted", deleted),
+			"pattern": spec.Pattern,
+			"dryRun":  false,
+			"groups":  results,
+		})
+	}
+}
+
+// DeleteConsumerGroupOffsetsHandler creates a Gin HTTP handler that deletes the
+// committed offsets of a consumer group on a topic it no longer consumes, so that the
+// group stops reporting lag on it.
+//
+// The request body is a domain.OffsetDeletionSpec. Without partitions, the offsets of
+// every partition of the topic are deleted. Kafka refuses the deletion while members
+// of the group are subscribed to the topic.
+//
+// Returns:
+// - 200 OK with the deleted offsets
+// - 400 Bad Request if the request is malformed
+// - 404 Not Found if the consumer group doesn't exist
+// - 409 Conflict if the group is subscribed to the topic
+// - 500 Internal Server Error for other failures
+func DeleteConsumerGroupOffsetsHandler(k kafka_client.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groupID := c.Param("groupId")
+		if groupID == "" {
+			problem.Abort(c, problem.BadRequest("Consumer group ID is required", ""))
+			return
+		}
+
+		var spec domain.OffsetDeletionSpec
+		if err := c.ShouldBindJSON(&spec); err != nil {
+			problem.Abort(c, problem.BadRequest("Invalid offset deletion request", err.Error()))
+			return
+		}
+
+		offsets, err := k.DeleteConsumerGroupOffsets(c.Request.Context(), groupID, spec)
+		if err != nil {
+			problem.AbortWithError(c, err, "Failed to delete consumer group offsets")
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": fmt.Sprintf("Offsets of %d partitions deleted", len(offsets)),
+			"groupId": groupID,
+			"offsets": offsets,
+		})
+	}
+}
+
+// RemoveConsumerGroupMembersHandler creates a Gin HTTP handler that forces static
+// members out of a consumer group without waiting for their session to time out, e.g.
+// consumers of instances that were decommissioned.
+//
+// The request body is a MemberRemovalRequest. The outcome is reported per member.
+//
+// Returns:
+// - 200 OK with the outcome for every member
+// - 400 Bad Request if the request is malformed
+// - 404 Not Found if the consumer group doesn't exist
+// - 500 Internal Server Error for other failures
+func RemoveConsumerGroupMembersHandler(k kafka_client.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groupID := c.Param("groupId")
+		if groupID == "" {
+			problem.Abort(c, problem.BadRequest("Consumer group ID is required", ""))
+			return
+		}
+
+		var req MemberRemovalRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			problem.Abort(c, problem.BadRequest("Invalid member removal request", err.Error()))
+			return
+		}
+
+		removals, err := k.RemoveConsumerGroupMembers(c.Request.Context(), groupID, req.GroupInstanceIDs)
+		if err != nil {
+			problem.AbortWithError(c, err, "Failed to remove consumer group members")
+			return
+		}
+
+		removed := 0
+		for _, removal := range removals {
+			if removal.Removed {
+				removed++
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message": fmt.Sprintf("%d of %d members removed", removed, len(removals)),
+			"groupId": groupID,
+			"members": removals,
+		})
+	}
+}
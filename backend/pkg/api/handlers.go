@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -10,32 +11,34 @@ import (
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 	"github.com/gin-gonic/gin"
 
+	"github.com/valeriouberti/maestro/internal/audit"
+	"github.com/valeriouberti/maestro/internal/auth"
 	"github.com/valeriouberti/maestro/internal/kafka_client"
+	"github.com/valeriouberti/maestro/internal/policy"
+	"github.com/valeriouberti/maestro/internal/problem"
+	"github.com/valeriouberti/maestro/internal/protection"
+	"github.com/valeriouberti/maestro/internal/rbac"
+	"github.com/valeriouberti/maestro/internal/templates"
 	"github.com/valeriouberti/maestro/pkg/domain"
 )
 
-// ErrorResponse represents a standardized error response structure for API endpoints.
-// It contains HTTP status code, a general error message, and optional detailed information
-// about the error that can be omitted when empty.
-type ErrorResponse struct {
-	Status  int    `json:"status"`
-	Message string `json:"message"`
-	Detail  string `json:"detail,omitempty"`
-}
-
 // TopicCreationRequest contains the parameters needed to create a new Kafka topic.
-// It validates that essential fields are provided through JSON binding tags.
+// It validates the fields provided through JSON binding tags.
 //
 // Fields:
-//   - Name: The name of the Kafka topic to create (required)
-//   - NumPartitions: The number of partitions for the topic, must be at least 1 (required)
-//   - ReplicationFactor: The replication factor for the topic, must be at least 1 (required)
+//   - Name: The name of the Kafka topic to create (required without a template)
+//   - NumPartitions: The number of partitions for the topic, must be at least 1 (required without a template)
+//   - ReplicationFactor: The replication factor for the topic, must be at least 1 (required without a template)
 //   - Config: Optional map of configuration parameters for the topic with string keys and values
+//   - Template: Optional name of the topic template providing the defaults of the fields above
+//   - Parameters: Values of the placeholders of the template's topic name, when Name is empty
 type TopicCreationRequest struct {
-	Name              string            `json:"name" binding:"required"`
-	NumPartitions     int32             `json:"numPartitions" binding:"required,min=1"`
-	ReplicationFactor int16             `json:"replicationFactor" binding:"required,min=1"`
+	Name              string            `json:"name,omitempty"`
+	NumPartitions     int32             `json:"numPartitions,omitempty" binding:"omitempty,min=1"`
+	ReplicationFactor int16             `json:"replicationFactor,omitempty" binding:"omitempty,min=1"`
 	Config            map[string]string `json:"config,omitempty"`
+	Template          string            `json:"template,omitempty"`
+	Parameters        map[string]string `json:"parameters,omitempty"`
 }
 
 // TopicConfigUpdateRequest represents a request to update configuration for a topic.
@@ -61,19 +64,15 @@ type MessagePublishRequest struct {
 //   - Returns an appropriate error response if the operation fails
 //
 // Parameters:
-//   - k: A pointer to a KafkaClient that provides access to Kafka cluster information
+//   - k: A Kafka client that provides access to Kafka cluster information
 //
 // Returns:
 //   - A Gin handler function that handles HTTP requests for Kafka cluster information
-func GetClustersHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
+func GetClustersHandler(k kafka_client.Client) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		brokerMetadata, err := k.GetBrokers(c.Request.Context())
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Status:  http.StatusInternalServerError,
-				Message: "Failed to get broker metadata",
-				Detail:  err.Error(),
-			})
+			problem.AbortWithError(c, err, "Failed to get broker metadata")
 			return
 		}
 
@@ -84,27 +83,34 @@ func GetClustersHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
 // ListTopicsHandler creates a gin HTTP handler for retrieving Kafka topics.
 // It takes a Kafka client and returns a handler function that:
 //   - Fetches all available topics from Kafka
+//   - Omits the topics the caller is not allowed to read when access control is enabled
+//   - Marks the protected topics
 //   - Returns the topics as JSON with a 200 OK status on success
 //   - Returns a 500 Internal Server Error with error details if the operation fails
 //
 // Parameters:
-//   - k: A pointer to a kafka.KafkaClient used to interact with Kafka
+//   - k: A Kafka client used to interact with Kafka
+//   - guard: Tells which topics are protected
 //
 // Returns:
 //   - A gin.HandlerFunc that handles HTTP requests for listing Kafka topics
-func ListTopicsHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
+func ListTopicsHandler(k kafka_client.Client, guard *protection.Guard) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		topics, err := k.ListTopics(c.Request.Context())
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Status:  http.StatusInternalServerError,
-				Message: "Failed to list topics",
-				Detail:  err.Error(),
-			})
+			problem.AbortWithError(c, err, "Failed to list topics")
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{"topics": topics})
+		visible := make([]domain.TopicInfo, 0, len(topics))
+		for _, topic := range topics {
+			if rbac.Allowed(c, rbac.ActionTopicRead, topic.Name) {
+				visible = append(visible, topic)
+			}
+		}
+		guard.Mark(visible)
+
+		c.JSON(http.StatusOK, gin.H{"topics": visible})
 	}
 }
 
@@ -114,34 +120,29 @@ func ListTopicsHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
 // - Extracts the topic name from URL parameters
 // - Validates that the topic name is provided
 // - Fetches topic details using the provided Kafka client
-// - Returns the topic details as JSON on success
+// - Returns the topic details as JSON on success, telling whether the topic is protected
 // - Returns appropriate error responses when the topic name is missing or when the fetch operation fails
 //
 // Parameters:
-//   - k: A pointer to a Kafka client used to retrieve topic information
+//   - k: A Kafka client used to retrieve topic information
+//   - guard: Tells which topics are protected
 //
 // Returns:
 //   - A gin.HandlerFunc that handles HTTP requests for Kafka topic details
-func GetTopicHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
+func GetTopicHandler(k kafka_client.Client, guard *protection.Guard) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		topicName := c.Param("topicName")
 		if topicName == "" {
-			c.JSON(http.StatusBadRequest, ErrorResponse{
-				Status:  http.StatusBadRequest,
-				Message: "Topic name is required",
-			})
+			problem.Abort(c, problem.BadRequest("Topic name is required", ""))
 			return
 		}
 
 		topic, err := k.GetTopicDetails(c.Request.Context(), topicName)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Status:  http.StatusInternalServerError,
-				Message: "Failed to get topic details",
-				Detail:  err.Error(),
-			})
+			problem.AbortWithError(c, err, "Failed to get topic details")
 			return
 		}
+		topic.IsProtected = guard.Protected(*topic)
 
 		c.JSON(http.StatusOK, gin.H{"topic": topic})
 	}
@@ -150,26 +151,30 @@ func GetTopicHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
 // CreateTopicHandler returns a Gin handler function that processes HTTP requests for Kafka topic creation.
 //
 // The handler accepts JSON requests containing topic details (name, partition count, replication factor, and optional config),
+// or the name of a topic template with the fields overriding its defaults and the parameters of its topic name,
 // validates the input, and attempts to create the topic via the provided Kafka client.
 //
 // It returns appropriate HTTP responses based on the operation result:
 // - 201 Created: When the topic is successfully created, including the topic details
 // - 400 Bad Request: When the request JSON is invalid or malformed
+// - 403 Forbidden: When the caller is not allowed to create a topic with this name
+// - 404 Not Found: When the topic template doesn't exist
 // - 409 Conflict: When the topic already exists
+// - 422 Unprocessable Entity: When the topic breaks topic policies, listing every violation
 // - 500 Internal Server Error: When the topic creation fails for other reasons
 //
 // Parameters:
-//   - k: A pointer to a Kafka client that handles the actual topic creation
+//   - k: A Kafka client that handles the actual topic creation
+//   - policies: The topic policies checked before the topic is created
+//   - topicTemplates: The topic templates requests may create the topic from
 //
 // Returns:
 //   - A Gin handler function that processes the HTTP request and generates the appropriate response
-func CreateTopicHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
+func CreateTopicHandler(k kafka_client.Client, policies *policy.Checker, topicTemplates *templates.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var request TopicCreationRequest
 		if err := c.ShouldBindJSON(&request); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Invalid request: " + err.Error(),
-			})
+			problem.Abort(c, problem.BadRequest("Invalid topic creation request", err.Error()))
 			return
 		}
 
@@ -179,30 +184,44 @@ func CreateTopicHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
 			ReplicationFactor: int(request.ReplicationFactor),
 			Config:            request.Config,
 		}
-
-		err := k.CreateTopic(c.Request.Context(), topicInfo)
-		if err != nil {
-			if strings.Contains(err.Error(), "already exists") {
-				c.JSON(http.StatusConflict, gin.H{
-					"error": "Topic already exists: " + err.Error(),
-				})
+		if request.Template != "" {
+			template, err := topicTemplates.Get(request.Template)
+			if err != nil {
+				abortTemplateError(c, err, "Failed to get topic template")
+				return
+			}
+			if topicInfo, err = templates.Apply(template, topicInfo, request.Parameters); err != nil {
+				problem.Abort(c, problem.BadRequest("Invalid topic creation request", err.Error()))
 				return
 			}
+		} else if request.Name == "" || request.NumPartitions == 0 || request.ReplicationFactor == 0 {
+			problem.Abort(c, problem.BadRequest("Invalid topic creation request", "name, numPartitions and replicationFactor are required without a template"))
+			return
+		}
 
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to create topic: " + err.Error(),
-			})
+		audit.SetResource(c, topicInfo.Name)
+		if !rbac.Authorize(c, rbac.ActionTopicCreate, topicInfo.Name) {
 			return
 		}
 
-		topic, err := k.GetTopicDetails(c.Request.Context(), request.Name)
+		if violations := policies.CheckTopic(c.Request.Context(), auth.PrincipalFrom(c), topicInfo); len(violations) > 0 {
+			problem.Abort(c, problem.PolicyViolation("Topic breaks topic policies", violations))
+			return
+		}
+
+		if err := k.CreateTopic(c.Request.Context(), topicInfo); err != nil {
+			problem.AbortWithError(c, err, "Failed to create topic")
+			return
+		}
+
+		topic, err := k.GetTopicDetails(c.Request.Context(), topicInfo.Name)
 		if err != nil {
 			c.JSON(http.StatusCreated, gin.H{
 				"message": "Topic created successfully",
 				"topic": gin.H{
-					"name":              request.Name,
-					"numPartitions":     request.NumPartitions,
-					"replicationFactor": request.ReplicationFactor,
+					"name":              topicInfo.Name,
+					"numPartitions":     topicInfo.NumPartitions,
+					"replicationFactor": topicInfo.ReplicationFactor,
 				},
 			})
 			return
@@ -219,45 +238,42 @@ func CreateTopicHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
 // It takes a Kafka client and returns a handler function that:
 // - Extracts the topic name from the URL path parameter
 // - Validates the topic name is not empty
+// - Requires the topic:protected permission and confirm=<topic name> for protected topics
 // - Attempts to delete the topic using the Kafka client
 // - Returns appropriate HTTP responses:
 //   - 200 OK with success message when topic is deleted successfully
 //   - 400 Bad Request when topic name is missing
+//   - 403 Forbidden when the topic is protected and the caller may not change protected topics
 //   - 404 Not Found when the topic doesn't exist
+//   - 409 Conflict when the topic is protected and the deletion isn't confirmed
 //   - 500 Internal Server Error for other failures
 //
 // Parameters:
-//   - k: A pointer to a kafka.KafkaClient instance used for topic operations
+//   - k: A Kafka client used for topic operations
+//   - guard: Tells which topics are protected
 //
 // Returns:
 //   - A Gin handler function for the DELETE topic endpoint
-func DeleteTopicHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
+func DeleteTopicHandler(k kafka_client.Client, guard *protection.Guard) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		topicName := c.Param("topicName")
 		if topicName == "" {
-			c.JSON(http.StatusBadRequest, ErrorResponse{
-				Status:  http.StatusBadRequest,
-				Message: "Topic name is required",
-			})
+			problem.Abort(c, problem.BadRequest("Topic name is required", ""))
 			return
 		}
 
-		err := k.DeleteTopic(c.Request.Context(), topicName)
+		topic, err := k.GetTopicDetails(c.Request.Context(), topicName)
 		if err != nil {
-			if strings.Contains(err.Error(), "not found") {
-				c.JSON(http.StatusNotFound, ErrorResponse{
-					Status:  http.StatusNotFound,
-					Message: "Topic not found",
-					Detail:  err.Error(),
-				})
-				return
-			}
+			problem.AbortWithError(c, err, "Failed to delete topic")
+			return
+		}
+		if !confirmProtected(c, guard, *topic, "deletion") {
+			return
+		}
 
-			c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Status:  http.StatusInternalServerError,
-				Message: "Failed to delete topic",
-				Detail:  err.Error(),
-			})
+		err = k.DeleteTopic(c.Request.Context(), topicName)
+		if err != nil {
+			problem.AbortWithError(c, err, "Failed to delete topic")
 			return
 		}
 
@@ -269,7 +285,7 @@ func DeleteTopicHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
 }
 
 // UpdateTopicConfigHandler creates a gin HTTP handler for updating Kafka topic configurations.
-// It accepts a KafkaClient instance to interact with the Kafka cluster.
+// It accepts a Kafka client to interact with the Kafka cluster.
 //
 // The handler expects a topic name as a URL parameter and a JSON request body with
 // the following structure:
@@ -285,59 +301,61 @@ func DeleteTopicHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
 // HTTP Responses:
 // - 200 OK: Configuration updated successfully, returns the updated topic details
 // - 400 Bad Request: Missing topic name, invalid request format, or empty configuration
+// - 403 Forbidden: The change is risky, the topic is protected and the caller may not change protected topics
 // - 404 Not Found: Topic doesn't exist in the Kafka cluster
+// - 409 Conflict: The change is risky, the topic is protected and the change isn't confirmed with confirm=<topic name>
+// - 422 Unprocessable Entity: The configuration breaks the required or banned entries of topic policies
 // - 500 Internal Server Error: Failed to update topic configuration
 //
 // If the update succeeds but retrieving updated details fails, it still returns 200 OK
 // with a success message and the requested configuration changes.
-func UpdateTopicConfigHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
+func UpdateTopicConfigHandler(k kafka_client.Client, policies *policy.Checker, guard *protection.Guard) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		topicName := c.Param("topicName")
 		if topicName == "" {
-			c.JSON(http.StatusBadRequest, ErrorResponse{
-				Status:  http.StatusBadRequest,
-				Message: "Topic name is required",
-			})
+			problem.Abort(c, problem.BadRequest("Topic name is required", ""))
 			return
 		}
 
 		var request TopicConfigUpdateRequest
 		if err := c.ShouldBindJSON(&request); err != nil {
-			c.JSON(http.StatusBadRequest, ErrorResponse{
-				Status:  http.StatusBadRequest,
-				Message: "Invalid configuration update request",
-				Detail:  err.Error(),
-			})
+			problem.Abort(c, problem.BadRequest("Invalid configuration update request", err.Error()))
 			return
 		}
 
 		// Validate that config is not empty
 		if len(request.Config) == 0 {
-			c.JSON(http.StatusBadRequest, ErrorResponse{
-				Status:  http.StatusBadRequest,
-				Message: "Configuration cannot be empty",
-			})
+			problem.Abort(c, problem.BadRequest("Configuration cannot be empty", ""))
 			return
 		}
 
-		// Update the topic configuration
-		err := k.UpdateTopicConfig(c.Request.Context(), topicName, request.Config)
+		if violations := policies.CheckConfig(c.Request.Context(), auth.PrincipalFrom(c), request.Config); len(violations) > 0 {
+			problem.Abort(c, problem.PolicyViolation("Topic configuration breaks topic policies", violations))
+			return
+		}
+
+		// Keep the current configuration to check risky changes and record the changes in
+		// the audit trail
+		before, err := k.GetTopicDetails(c.Request.Context(), topicName)
 		if err != nil {
-			// Check for specific error types
-			if strings.Contains(err.Error(), "not found") {
-				c.JSON(http.StatusNotFound, ErrorResponse{
-					Status:  http.StatusNotFound,
-					Message: "Topic not found",
-					Detail:  err.Error(),
-				})
+			problem.AbortWithError(c, err, "Failed to update topic configuration")
+			return
+		}
+
+		var changed []string
+		for _, change := range audit.DiffConfig(before.Config, request.Config) {
+			changed = append(changed, change.Key)
+		}
+		if risky := guard.Risky(changed...); len(risky) > 0 {
+			if !confirmProtected(c, guard, *before, "change of "+strings.Join(risky, ", ")) {
 				return
 			}
+		}
 
-			c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Status:  http.StatusInternalServerError,
-				Message: "Failed to update topic configuration",
-				Detail:  err.Error(),
-			})
+		// Update the topic configuration
+		err = k.UpdateTopicConfig(c.Request.Context(), topicName, request.Config)
+		if err != nil {
+			problem.AbortWithError(c, err, "Failed to update topic configuration")
 			return
 		}
 
@@ -355,6 +373,8 @@ func UpdateTopicConfigHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
 			return
 		}
 
+		audit.SetChanges(c, audit.DiffConfig(before.Config, topic.Config))
+
 		c.JSON(http.StatusOK, gin.H{
 			"message": "Topic configuration updated successfully",
 			"topic":   topic,
@@ -366,29 +386,44 @@ func UpdateTopicConfigHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
 // It takes a Kafka client as input and when the handler is called, it queries for all consumer groups
 // from the Kafka cluster.
 //
-// If successful, it returns a JSON response with HTTP 200 status code containing the list of consumer groups.
+// The optional "state" query parameter takes a comma-separated list of states, e.g. Stable,Empty,
+// and limits the listing to the groups in one of them.
+//
+// If successful, it returns a JSON response with HTTP 200 status code containing the list of consumer groups,
+// without the groups the caller is not allowed to read when access control is enabled.
+// An unknown state returns HTTP 400 status code.
 // If an error occurs during the operation, it returns a JSON error response with HTTP 500 status code
 // along with the error details.
 //
 // Parameters:
-//   - k: A pointer to a KafkaClient instance used to interact with the Kafka cluster
+//   - k: A Kafka client used to interact with the Kafka cluster
 //
 // Returns:
 //   - A Gin handler function that processes HTTP requests for listing consumer groups
-func ListConsumerGroupsHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
+func ListConsumerGroupsHandler(k kafka_client.Client) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		groups, err := k.ListConsumerGroups(c.Request.Context())
+		var states []string
+		for _, state := range strings.Split(c.Query("state"), ",") {
+			if state = strings.TrimSpace(state); state != "" {
+				states = append(states, state)
+			}
+		}
+
+		groups, err := k.ListConsumerGroups(c.Request.Context(), states...)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Status:  http.StatusInternalServerError,
-				Message: "Failed to list consumer groups",
-				Detail:  err.Error(),
-			})
+			problem.AbortWithError(c, err, "Failed to list consumer groups")
 			return
 		}
 
+		visible := make([]domain.ConsumerGroupInfo, 0, len(groups))
+		for _, group := range groups {
+			if rbac.Allowed(c, rbac.ActionGroupRead, group.GroupID) {
+				visible = append(visible, group)
+			}
+		}
+
 		c.JSON(http.StatusOK, gin.H{
-			"groups": groups,
+			"groups": visible,
 		})
 	}
 }
@@ -405,38 +440,21 @@ func ListConsumerGroupsHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
 //   - 500 Internal Server Error for other failures
 //
 // Parameters:
-//   - k: A pointer to a Kafka client used to interact with Kafka
+//   - k: A Kafka client used to interact with Kafka
 //
 // Returns:
 //   - A Gin HTTP handler function
-func GetConsumerGroupHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
+func GetConsumerGroupHandler(k kafka_client.Client) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		groupID := c.Param("groupId")
 		if groupID == "" {
-			c.JSON(http.StatusBadRequest, ErrorResponse{
-				Status:  http.StatusBadRequest,
-				Message: "Consumer group ID is required",
-			})
+			problem.Abort(c, problem.BadRequest("Consumer group ID is required", ""))
 			return
 		}
 
 		group, err := k.GetConsumerGroupDetails(c.Request.Context(), groupID)
 		if err != nil {
-			// Check for common errors
-			if strings.Contains(err.Error(), "not found") {
-				c.JSON(http.StatusNotFound, ErrorResponse{
-					Status:  http.StatusNotFound,
-					Message: "Consumer group not found",
-					Detail:  err.Error(),
-				})
-				return
-			}
-
-			c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Status:  http.StatusInternalServerError,
-				Message: "Failed to get consumer group details",
-				Detail:  err.Error(),
-			})
+			problem.AbortWithError(c, err, "Failed to get consumer group details")
 			return
 		}
 
@@ -460,18 +478,15 @@ func GetConsumerGroupHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
 // - 404 Not Found if the topic doesn't exist
 // - 500 Internal Server Error for other failures
 // GetTopicMessagesHandler returns a HTTP handler function that retrieves messages from a specific Kafka topic.
-func GetTopicMessagesHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
+func GetTopicMessagesHandler(k kafka_client.Client) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Set a longer timeout for this specific request
-		ctx, cancel := context.WithTimeout(c.Request.Context(), k.Timeout*3) // Triple the timeout
+		ctx, cancel := context.WithTimeout(c.Request.Context(), k.OperationTimeout()*3) // Triple the timeout
 		defer cancel()
 
 		topicName := c.Param("topicName")
 		if topicName == "" {
-			c.JSON(http.StatusBadRequest, ErrorResponse{
-				Status:  http.StatusBadRequest,
-				Message: "Topic name is required",
-			})
+			problem.Abort(c, problem.BadRequest("Topic name is required", ""))
 			return
 		}
 
@@ -480,11 +495,7 @@ func GetTopicMessagesHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
 		if partitionStr := c.Query("partition"); partitionStr != "" {
 			partitionInt, err := strconv.ParseInt(partitionStr, 10, 32)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, ErrorResponse{
-					Status:  http.StatusBadRequest,
-					Message: "Invalid partition parameter",
-					Detail:  err.Error(),
-				})
+				problem.Abort(c, problem.BadRequest("Invalid partition parameter", err.Error()))
 				return
 			}
 			partition = int32(partitionInt)
@@ -500,11 +511,7 @@ func GetTopicMessagesHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
 			} else {
 				offsetInt, err := strconv.ParseInt(offsetStr, 10, 64)
 				if err != nil {
-					c.JSON(http.StatusBadRequest, ErrorResponse{
-						Status:  http.StatusBadRequest,
-						Message: "Invalid offset parameter",
-						Detail:  err.Error(),
-					})
+					problem.Abort(c, problem.BadRequest("Invalid offset parameter", err.Error()))
 					return
 				}
 				offset = kafka.Offset(offsetInt)
@@ -515,18 +522,11 @@ func GetTopicMessagesHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
 		if limitStr := c.Query("limit"); limitStr != "" {
 			limitInt, err := strconv.Atoi(limitStr)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, ErrorResponse{
-					Status:  http.StatusBadRequest,
-					Message: "Invalid limit parameter",
-					Detail:  err.Error(),
-				})
+				problem.Abort(c, problem.BadRequest("Invalid limit parameter", err.Error()))
 				return
 			}
 			if limitInt <= 0 {
-				c.JSON(http.StatusBadRequest, ErrorResponse{
-					Status:  http.StatusBadRequest,
-					Message: "Limit must be a positive integer",
-				})
+				problem.Abort(c, problem.BadRequest("Limit must be a positive integer", ""))
 				return
 			}
 			// Cap the maximum limit to avoid excessive resource usage
@@ -542,31 +542,14 @@ func GetTopicMessagesHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
 		// Use our extended timeout context
 		messages, err := k.GetTopicMessages(ctx, topicName, partition, int64(offset), limit)
 		if err != nil {
-			if ctx.Err() == context.DeadlineExceeded ||
-				strings.Contains(err.Error(), "context deadline exceeded") ||
-				strings.Contains(err.Error(), "timed out") {
-				c.JSON(http.StatusGatewayTimeout, ErrorResponse{
-					Status:  http.StatusGatewayTimeout,
-					Message: "Request timed out while retrieving messages",
-					Detail:  "Try reducing the number of messages or use an explicit offset instead of 'latest'",
-				})
-				return
-			}
-
-			if strings.Contains(err.Error(), "not found") {
-				c.JSON(http.StatusNotFound, ErrorResponse{
-					Status:  http.StatusNotFound,
-					Message: "Topic or partition not found",
-					Detail:  err.Error(),
-				})
+			if ctx.Err() == context.DeadlineExceeded || errors.Is(err, kafka_client.ErrTimeout) {
+				problem.Abort(c, problem.New(http.StatusGatewayTimeout, problem.CodeTimeout,
+					"Request timed out while retrieving messages",
+					"Try reducing the number of messages, use an explicit offset instead of 'latest', or run an export job through POST /api/v1/jobs for large ranges"))
 				return
 			}
 
-			c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Status:  http.StatusInternalServerError,
-				Message: "Failed to retrieve messages",
-				Detail:  err.Error(),
-			})
+			problem.AbortWithError(c, err, "Failed to retrieve messages")
 			return
 		}
 
@@ -593,24 +576,17 @@ func GetTopicMessagesHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
 // - 400 Bad Request if the topic name is missing or the request is invalid
 // - 404 Not Found if the topic or specified partition doesn't exist
 // - 500 Internal Server Error for other failures during message production
-func PublishMessageHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
+func PublishMessageHandler(k kafka_client.Client) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		topicName := c.Param("topicName")
 		if topicName == "" {
-			c.JSON(http.StatusBadRequest, ErrorResponse{
-				Status:  http.StatusBadRequest,
-				Message: "Topic name is required",
-			})
+			problem.Abort(c, problem.BadRequest("Topic name is required", ""))
 			return
 		}
 
 		var request MessagePublishRequest
 		if err := c.ShouldBindJSON(&request); err != nil {
-			c.JSON(http.StatusBadRequest, ErrorResponse{
-				Status:  http.StatusBadRequest,
-				Message: "Invalid request format",
-				Detail:  err.Error(),
-			})
+			problem.Abort(c, problem.BadRequest("Invalid request format", err.Error()))
 			return
 		}
 
@@ -631,21 +607,7 @@ func PublishMessageHandler(k *kafka_client.KafkaClient) gin.HandlerFunc {
 		)
 
 		if err != nil {
-			// Check for specific error types
-			if strings.Contains(err.Error(), "not found") {
-				c.JSON(http.StatusNotFound, ErrorResponse{
-					Status:  http.StatusNotFound,
-					Message: "Topic or partition not found",
-					Detail:  err.Error(),
-				})
-				return
-			}
-
-			c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Status:  http.StatusInternalServerError,
-				Message: "Failed to publish message",
-				Detail:  err.Error(),
-			})
+			problem.AbortWithError(c, err, "Failed to publish message")
 			return
 		}
 
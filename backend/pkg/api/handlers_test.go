@@ -0,0 +1,405 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/valeriouberti/maestro/internal/problem"
+	"github.com/valeriouberti/maestro/pkg/api"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// createTopic creates a topic directly on the fake cluster
+func createTopic(t *testing.T, s *testServer, name string, partitions int32) {
+	t.Helper()
+	err := s.cluster.CreateTopic(context.Background(), domain.TopicInfo{Name: name, NumPartitions: partitions, ReplicationFactor: 1})
+	if err != nil {
+		t.Fatalf("CreateTopic(%s): %v", name, err)
+	}
+}
+
+func TestListTopics(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+	createTopic(t, s, "orders", 3)
+	createTopic(t, s, "prod.payments", 3)
+
+	w := s.do(t, viewer, http.MethodGet, api.BasePath+"/topics", nil)
+	expectStatus(t, w, http.StatusOK)
+
+	response := decode[struct {
+		Topics []domain.TopicInfo `json:"topics"`
+	}](t, w)
+	protected := make(map[string]bool)
+	for _, topic := range response.Topics {
+		protected[topic.Name] = topic.IsProtected
+	}
+	if len(protected) != 2 || protected["orders"] || !protected["prod.payments"] {
+		t.Fatalf("topics = %+v, want orders and the protected prod.payments", response.Topics)
+	}
+}
+
+func TestGetTopic(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+	createTopic(t, s, "orders", 3)
+
+	w := s.do(t, viewer, http.MethodGet, api.BasePath+"/topics/orders", nil)
+	expectStatus(t, w, http.StatusOK)
+	response := decode[struct {
+		Topic domain.TopicInfo `json:"topic"`
+	}](t, w)
+	if response.Topic.Name != "orders" || response.Topic.NumPartitions != 3 {
+		t.Fatalf("topic = %+v, want orders with 3 partitions", response.Topic)
+	}
+
+	w = s.do(t, viewer, http.MethodGet, api.BasePath+"/topics/missing", nil)
+	expectProblem(t, w, http.StatusNotFound, problem.CodeNotFound)
+}
+
+func TestCreateTopic(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+
+	request := api.TopicCreationRequest{Name: "orders", NumPartitions: 3, ReplicationFactor: 1}
+	w := s.do(t, admin, http.MethodPost, api.BasePath+"/topics", request)
+	expectStatus(t, w, http.StatusCreated)
+	if _, err := s.cluster.GetTopicDetails(context.Background(), "orders"); err != nil {
+		t.Fatalf("topic was not created: %v", err)
+	}
+
+	w = s.do(t, admin, http.MethodPost, api.BasePath+"/topics", request)
+	expectProblem(t, w, http.StatusConflict, problem.CodeAlreadyExists)
+
+	w = s.do(t, viewer, http.MethodPost, api.BasePath+"/topics", api.TopicCreationRequest{Name: "customers", NumPartitions: 1, ReplicationFactor: 1})
+	expectProblem(t, w, http.StatusForbidden, problem.CodeForbidden)
+
+	w = s.do(t, admin, http.MethodPost, api.BasePath+"/topics", api.TopicCreationRequest{Name: "customers"})
+	expectProblem(t, w, http.StatusBadRequest, problem.CodeInvalidArgument)
+}
+
+func TestCreateTopicPolicyViolation(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+
+	w := s.do(t, admin, http.MethodPost, api.BasePath+"/topics", api.TopicCreationRequest{Name: "payments", NumPartitions: 24, ReplicationFactor: 1})
+	p := expectProblem(t, w, http.StatusUnprocessableEntity, problem.CodePolicyViolation)
+	if len(p.Violations) != 1 || p.Violations[0].Policy != "partitions" {
+		t.Fatalf("violations = %+v, want one of policy partitions", p.Violations)
+	}
+
+	// Every broken rule is reported in a single problem
+	w = s.do(t, admin, http.MethodPost, api.BasePath+"/topics", api.TopicCreationRequest{
+		Name: "payments", NumPartitions: 24, ReplicationFactor: 1,
+		Config: map[string]string{"unclean.leader.election.enable": "true"},
+	})
+	p = expectProblem(t, w, http.StatusUnprocessableEntity, problem.CodePolicyViolation)
+	if len(p.Violations) != 2 || p.Violations[0].Policy != "partitions" || p.Violations[1].Policy != "durability" {
+		t.Fatalf("violations = %+v, want those of policies partitions and durability", p.Violations)
+	}
+	if !strings.Contains(p.Detail, p.Violations[0].Message) || !strings.Contains(p.Detail, p.Violations[1].Message) {
+		t.Fatalf("detail %q lacks a violation", p.Detail)
+	}
+	if _, err := s.cluster.GetTopicDetails(context.Background(), "payments"); err == nil {
+		t.Fatal("the topic breaking the policies was created")
+	}
+}
+
+func TestCreateTopicFromTemplate(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+
+	w := s.do(t, admin, http.MethodPost, api.BasePath+"/topics", api.TopicCreationRequest{
+		Template: "events", Parameters: map[string]string{"team": "billing"},
+	})
+	expectStatus(t, w, http.StatusCreated)
+
+	topic, err := s.cluster.GetTopicDetails(context.Background(), "billing.events")
+	if err != nil {
+		t.Fatalf("topic was not created: %v", err)
+	}
+	if topic.NumPartitions != 3 {
+		t.Errorf("partitions = %d, want the 3 of the template", topic.NumPartitions)
+	}
+
+	w = s.do(t, admin, http.MethodPost, api.BasePath+"/topics", api.TopicCreationRequest{Template: "missing"})
+	expectProblem(t, w, http.StatusNotFound, problem.CodeNotFound)
+}
+
+func TestDeleteTopic(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+	createTopic(t, s, "orders", 1)
+
+	w := s.do(t, viewer, http.MethodDelete, api.BasePath+"/topics/orders", nil)
+	expectProblem(t, w, http.StatusForbidden, problem.CodeForbidden)
+
+	w = s.do(t, admin, http.MethodDelete, api.BasePath+"/topics/orders", nil)
+	expectStatus(t, w, http.StatusOK)
+
+	w = s.do(t, admin, http.MethodDelete, api.BasePath+"/topics/orders", nil)
+	expectProblem(t, w, http.StatusNotFound, problem.CodeNotFound)
+}
+
+func TestDeleteProtectedTopic(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+	createTopic(t, s, "prod.payments", 1)
+
+	w := s.do(t, admin, http.MethodDelete, api.BasePath+"/topics/prod.payments", nil)
+	expectProblem(t, w, http.StatusConflict, problem.CodeProtectedTopic)
+
+	w = s.do(t, admin, http.MethodDelete, api.BasePath+"/topics/prod.payments?confirm=prod", nil)
+	expectProblem(t, w, http.StatusConflict, problem.CodeProtectedTopic)
+
+	if _, err := s.cluster.GetTopicDetails(context.Background(), "prod.payments"); err != nil {
+		t.Fatalf("the unconfirmed deletion deleted the topic: %v", err)
+	}
+
+	w = s.do(t, admin, http.MethodDelete, api.BasePath+"/topics/prod.payments?confirm=prod.payments", nil)
+	expectStatus(t, w, http.StatusOK)
+
+	// Confirming is not enough without the topic:protected permission
+	createTopic(t, s, "prod.billing.ledger", 1)
+	w = s.do(t, billing, http.MethodDelete, api.BasePath+"/topics/prod.billing.ledger?confirm=prod.billing.ledger", nil)
+	expectProblem(t, w, http.StatusForbidden, problem.CodeForbidden)
+}
+
+func TestUpdateProtectedTopicConfig(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+	createTopic(t, s, "prod.payments", 1)
+	path := api.BasePath + "/topics/prod.payments/config"
+
+	// Only changes of risky entries need a confirmation
+	w := s.do(t, admin, http.MethodPut, path, api.TopicConfigUpdateRequest{Config: map[string]string{"max.message.bytes": "2097152"}})
+	expectStatus(t, w, http.StatusOK)
+
+	risky := api.TopicConfigUpdateRequest{Config: map[string]string{"max.message.bytes": "2097152", "retention.ms": "3600000"}}
+	w = s.do(t, admin, http.MethodPut, path, risky)
+	p := expectProblem(t, w, http.StatusConflict, problem.CodeProtectedTopic)
+	if !strings.Contains(p.Detail, "retention.ms") || strings.Contains(p.Detail, "max.message.bytes") {
+		t.Errorf("detail = %q, want the risky entry only", p.Detail)
+	}
+
+	w = s.do(t, admin, http.MethodPut, path+"?confirm=prod.orders", risky)
+	expectProblem(t, w, http.StatusConflict, problem.CodeProtectedTopic)
+
+	w = s.do(t, admin, http.MethodPut, path+"?confirm=prod.payments", risky)
+	expectStatus(t, w, http.StatusOK)
+
+	topic, err := s.cluster.GetTopicDetails(context.Background(), "prod.payments")
+	if err != nil {
+		t.Fatalf("GetTopicDetails: %v", err)
+	}
+	if topic.Config["retention.ms"] != "3600000" {
+		t.Errorf("retention.ms = %q, want 3600000", topic.Config["retention.ms"])
+	}
+}
+
+func TestUpdateTopicConfig(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+	createTopic(t, s, "orders", 1)
+
+	w := s.do(t, admin, http.MethodPut, api.BasePath+"/topics/orders/config", api.TopicConfigUpdateRequest{
+		Config: map[string]string{"retention.ms": "3600000"},
+	})
+	expectStatus(t, w, http.StatusOK)
+
+	topic, err := s.cluster.GetTopicDetails(context.Background(), "orders")
+	if err != nil {
+		t.Fatalf("GetTopicDetails: %v", err)
+	}
+	if topic.Config["retention.ms"] != "3600000" {
+		t.Errorf("retention.ms = %q, want 3600000", topic.Config["retention.ms"])
+	}
+
+	w = s.do(t, admin, http.MethodPut, api.BasePath+"/topics/missing/config", api.TopicConfigUpdateRequest{
+		Config: map[string]string{"retention.ms": "3600000"},
+	})
+	expectProblem(t, w, http.StatusNotFound, problem.CodeNotFound)
+
+	w = s.do(t, viewer, http.MethodPut, api.BasePath+"/topics/orders/config", api.TopicConfigUpdateRequest{
+		Config: map[string]string{"retention.ms": "3600000"},
+	})
+	expectProblem(t, w, http.StatusForbidden, problem.CodeForbidden)
+}
+
+func TestPublishAndReadMessages(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+	createTopic(t, s, "orders", 1)
+
+	w := s.do(t, admin, http.MethodPost, api.BasePath+"/topics/orders/messages", api.MessagePublishRequest{Key: "1", Value: "created"})
+	expectStatus(t, w, http.StatusOK)
+
+	w = s.do(t, viewer, http.MethodGet, api.BasePath+"/topics/orders/messages?partition=0", nil)
+	expectStatus(t, w, http.StatusOK)
+	response := decode[struct {
+		Messages []domain.TopicMessage `json:"messages"`
+	}](t, w)
+	if len(response.Messages) != 1 || response.Messages[0].Value != "created" {
+		t.Fatalf("messages = %+v, want the published message", response.Messages)
+	}
+
+	w = s.do(t, viewer, http.MethodPost, api.BasePath+"/topics/orders/messages", api.MessagePublishRequest{Value: "created"})
+	expectProblem(t, w, http.StatusForbidden, problem.CodeForbidden)
+
+	w = s.do(t, admin, http.MethodPost, api.BasePath+"/topics/missing/messages", api.MessagePublishRequest{Value: "created"})
+	expectProblem(t, w, http.StatusNotFound, problem.CodeNotFound)
+}
+
+func TestConsumerGroups(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+	s.cluster.AddConsumerGroup("billing", "Stable", domain.ConsumerGroupMemberInfo{ClientID: "billing-1", ConsumerID: "billing-1-a", Host: "/10.0.0.1"})
+	s.cluster.AddConsumerGroup("stale", "Empty")
+
+	w := s.do(t, viewer, http.MethodGet, api.BasePath+"/consumergroups", nil)
+	expectStatus(t, w, http.StatusOK)
+	response := decode[struct {
+		Groups []domain.ConsumerGroupInfo `json:"groups"`
+	}](t, w)
+	ids := make([]string, 0, len(response.Groups))
+	for _, group := range response.Groups {
+		ids = append(ids, group.GroupID)
+	}
+	slices.Sort(ids)
+	if !slices.Equal(ids, []string{"billing", "stale"}) {
+		t.Fatalf("groups = %v, want billing and stale", ids)
+	}
+
+	w = s.do(t, viewer, http.MethodGet, api.BasePath+"/consumergroups/billing", nil)
+	expectStatus(t, w, http.StatusOK)
+
+	w = s.do(t, viewer, http.MethodGet, api.BasePath+"/consumergroups/missing", nil)
+	expectProblem(t, w, http.StatusNotFound, problem.CodeNotFound)
+}
+
+func TestDeleteConsumerGroup(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+	s.cluster.AddConsumerGroup("billing", "Stable", domain.ConsumerGroupMemberInfo{ClientID: "billing-1", ConsumerID: "billing-1-a", Host: "/10.0.0.1"})
+	s.cluster.AddConsumerGroup("stale", "Empty")
+
+	w := s.do(t, viewer, http.MethodDelete, api.BasePath+"/consumergroups/stale", nil)
+	expectProblem(t, w, http.StatusForbidden, problem.CodeForbidden)
+
+	w = s.do(t, admin, http.MethodDelete, api.BasePath+"/consumergroups/billing", nil)
+	expectProblem(t, w, http.StatusConflict, problem.CodeConflict)
+
+	w = s.do(t, admin, http.MethodDelete, api.BasePath+"/consumergroups/stale", nil)
+	expectStatus(t, w, http.StatusOK)
+
+	w = s.do(t, admin, http.MethodDelete, api.BasePath+"/consumergroups/stale", nil)
+	expectProblem(t, w, http.StatusNotFound, problem.CodeNotFound)
+}
+
+func TestTopicTemplates(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+
+	template := api.TopicTemplateRequest{Name: "changelog", TopicName: "{entity}.changelog", NumPartitions: 6, ReplicationFactor: 1}
+	w := s.do(t, admin, http.MethodPost, api.BasePath+"/topictemplates", template)
+	expectStatus(t, w, http.StatusCreated)
+
+	w = s.do(t, admin, http.MethodPost, api.BasePath+"/topictemplates", template)
+	expectProblem(t, w, http.StatusConflict, problem.CodeConflict)
+
+	w = s.do(t, viewer, http.MethodGet, api.BasePath+"/topictemplates/changelog", nil)
+	expectStatus(t, w, http.StatusOK)
+
+	w = s.do(t, viewer, http.MethodGet, api.BasePath+"/topictemplates/missing", nil)
+	expectProblem(t, w, http.StatusNotFound, problem.CodeNotFound)
+
+	// Templates of the configuration file are read-only
+	w = s.do(t, admin, http.MethodPut, api.BasePath+"/topictemplates/events", template)
+	expectProblem(t, w, http.StatusConflict, problem.CodeConflict)
+
+	w = s.do(t, viewer, http.MethodDelete, api.BasePath+"/topictemplates/changelog", nil)
+	expectProblem(t, w, http.StatusForbidden, problem.CodeForbidden)
+
+	w = s.do(t, admin, http.MethodDelete, api.BasePath+"/topictemplates/changelog", nil)
+	expectStatus(t, w, http.StatusOK)
+}
+
+func TestJobs(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+
+	w := s.do(t, viewer, http.MethodGet, api.BasePath+"/jobs", nil)
+	expectStatus(t, w, http.StatusOK)
+
+	w = s.do(t, viewer, http.MethodGet, api.BasePath+"/jobs/missing", nil)
+	expectProblem(t, w, http.StatusNotFound, problem.CodeNotFound)
+
+	w = s.do(t, viewer, http.MethodPost, api.BasePath+"/jobs/missing/cancel", nil)
+	expectProblem(t, w, http.StatusNotFound, problem.CodeNotFound)
+
+	w = s.do(t, viewer, http.MethodPost, api.BasePath+"/jobs", gin.H{"type": "unknown", "params": gin.H{}})
+	expectProblem(t, w, http.StatusBadRequest, problem.CodeInvalidArgument)
+}
+
+func TestDeleteConsumerGroups(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+	s.cluster.AddConsumerGroup("reader-1", "Empty")
+	s.cluster.AddConsumerGroup("reader-2", "Empty")
+	s.cluster.AddConsumerGroup("reader-3", "Stable", domain.ConsumerGroupMemberInfo{ClientID: "reader-3", ConsumerID: "reader-3-a", Host: "/10.0.0.1"})
+	s.cluster.AddConsumerGroup("billing", "Empty")
+
+	type response struct {
+		DryRun       bool                   `json:"dryRun"`
+		Confirmation string                 `json:"confirmation"`
+		Groups       []domain.GroupDeletion `json:"groups"`
+	}
+	path := api.BasePath + "/consumergroups/delete"
+
+	// Deleting requires the confirmation of a dry run
+	w := s.do(t, admin, http.MethodPost, path, domain.GroupDeletionSpec{Pattern: "*"})
+	expectProblem(t, w, http.StatusBadRequest, problem.CodeInvalidArgument)
+
+	w = s.do(t, viewer, http.MethodPost, path, domain.GroupDeletionSpec{Pattern: "reader-*", BulkOptions: domain.BulkOptions{DryRun: true}})
+	expectProblem(t, w, http.StatusForbidden, problem.CodeForbidden)
+
+	w = s.do(t, admin, http.MethodPost, path, domain.GroupDeletionSpec{Pattern: "reader-*", BulkOptions: domain.BulkOptions{DryRun: true}})
+	expectStatus(t, w, http.StatusOK)
+	preview := decode[response](t, w)
+	if !preview.DryRun || preview.Confirmation == "" {
+		t.Fatalf("dry run = %+v, want a confirmation", preview)
+	}
+	want := []domain.GroupDeletion{
+		{GroupID: "reader-1", Deleted: true},
+		{GroupID: "reader-2", Deleted: true},
+		{GroupID: "reader-3", Skipped: "the group has active members"},
+	}
+	if !slices.Equal(preview.Groups, want) {
+		t.Fatalf("dry run groups = %+v, want %+v", preview.Groups, want)
+	}
+	if _, err := s.cluster.GetConsumerGroupDetails(context.Background(), "reader-1"); err != nil {
+		t.Fatalf("the dry run deleted reader-1: %v", err)
+	}
+
+	// The confirmation of another pattern does not apply
+	w = s.do(t, admin, http.MethodPost, path, domain.GroupDeletionSpec{Pattern: "*", BulkOptions: domain.BulkOptions{Confirmation: preview.Confirmation}})
+	expectProblem(t, w, http.StatusConflict, problem.CodeConflict)
+
+	// Neither does it once the matching groups changed
+	s.cluster.AddConsumerGroup("reader-4", "Empty")
+	w = s.do(t, admin, http.MethodPost, path, domain.GroupDeletionSpec{Pattern: "reader-*", BulkOptions: domain.BulkOptions{Confirmation: preview.Confirmation}})
+	expectProblem(t, w, http.StatusConflict, problem.CodeConflict)
+
+	w = s.do(t, admin, http.MethodPost, path, domain.GroupDeletionSpec{Pattern: "reader-*", BulkOptions: domain.BulkOptions{DryRun: true}})
+	preview = decode[response](t, w)
+	w = s.do(t, admin, http.MethodPost, path, domain.GroupDeletionSpec{Pattern: "reader-*", BulkOptions: domain.BulkOptions{Confirmation: preview.Confirmation}})
+	expectStatus(t, w, http.StatusOK)
+	deleted := decode[response](t, w)
+	want = []domain.GroupDeletion{
+		{GroupID: "reader-1", Deleted: true},
+		{GroupID: "reader-2", Deleted: true},
+		{GroupID: "reader-3", Skipped: "the group has active members"},
+		{GroupID: "reader-4", Deleted: true},
+	}
+	if deleted.DryRun || !slices.Equal(deleted.Groups, want) {
+		t.Fatalf("deletion = %+v, want groups %+v", deleted, want)
+	}
+
+	groups, err := s.cluster.ListConsumerGroups(context.Background())
+	if err != nil {
+		t.Fatalf("ListConsumerGroups: %v", err)
+	}
+	if len(groups) != 2 {
+		t.Fatalf("groups left = %+v, want billing and reader-3", groups)
+	}
+}
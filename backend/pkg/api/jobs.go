@@ -0,0 +1,266 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/valeriouberti/maestro/internal/clusters"
+	"github.com/valeriouberti/maestro/internal/export"
+	"github.com/valeriouberti/maestro/internal/jobs"
+	"github.com/valeriouberti/maestro/internal/problem"
+	"github.com/valeriouberti/maestro/internal/rbac"
+	"github.com/valeriouberti/maestro/internal/replay"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// JobSubmitRequest represents a request to start a background job.
+// Params holds the type-specific parameters, e.g. a domain.ExportSpec for "export"
+// jobs or a domain.ReplaySpec for "replay" jobs.
+type JobSubmitRequest struct {
+	Type   string          `json:"type" binding:"required"`
+	Params json.RawMessage `json:"params" binding:"required"`
+}
+
+// SubmitJobHandler creates a Gin HTTP handler that starts a background job.
+//
+// Returns:
+// - 202 Accepted with the created job, which may still wait for a free slot
+// - 400 Bad Request if the request is malformed, the job type is unknown or its parameters are invalid
+// - 403 Forbidden if the caller is not allowed to access the topics of the job
+// - 500 Internal Server Error for other failures
+func SubmitJobHandler(m *jobs.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request JobSubmitRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			problem.Abort(c, problem.BadRequest("Invalid job request", err.Error()))
+			return
+		}
+
+		job := domain.Job{Type: request.Type, Cluster: clusters.NameFrom(c.Request.Context()), Params: request.Params}
+		if !authorizeJob(c, job) {
+			return
+		}
+
+		submitJob(c, m, request.Type, request.Params)
+	}
+}
+
+// ListJobsHandler returns a Gin HTTP handler that lists background jobs, most recent first.
+// The optional "type" and "status" query parameters narrow down the list. Jobs on topics
+// the caller is not allowed to access are omitted.
+func ListJobsHandler(m *jobs.Manager) gin.HandlerFunc {
+	return listJobsOfType(m, "")
+}
+
+// GetJobHandler returns a Gin HTTP handler that reports the status and progress of a job.
+//
+// Returns:
+// - 200 OK with the job
+// - 403 Forbidden if the caller is not allowed to access the topics of the job
+// - 404 Not Found if no job exists with the given ID
+func GetJobHandler(m *jobs.Manager) gin.HandlerFunc {
+	return getJobOfType(m, "")
+}
+
+// CancelJobHandler returns a Gin HTTP handler that cancels a pending or running job.
+//
+// Returns:
+// - 202 Accepted with the job as it was when cancellation was requested
+// - 403 Forbidden if the caller is not allowed to access the topics of the job
+// - 404 Not Found if no job exists with the given ID
+func CancelJobHandler(m *jobs.Manager) gin.HandlerFunc {
+	return cancelJobOfType(m, "")
+}
+
+// GetJobResultHandler returns a Gin HTTP handler that downloads the result of a finished job.
+// Jobs that produce a file, such as exports, return it as an attachment; other jobs
+// return their JSON result.
+//
+// Returns:
+// - 200 OK with the result
+// - 403 Forbidden if the caller is not allowed to access the topics of the job
+// - 404 Not Found if no job exists with the given ID or it has no result
+// - 409 Conflict if the job has not finished yet
+func GetJobResultHandler(m *jobs.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := m.Get(c.Param("jobId"))
+		if err != nil {
+			respondJobError(c, err)
+			return
+		}
+		if !authorizeJob(c, job) {
+			return
+		}
+
+		path, err := m.ResultPath(job.ID)
+		if err == nil {
+			c.FileAttachment(path, filepath.Base(path))
+			return
+		}
+		if !errors.Is(err, jobs.ErrNoResult) {
+			respondJobError(c, err)
+			return
+		}
+		if len(job.Result) == 0 {
+			respondJobError(c, jobs.ErrNoResult)
+			return
+		}
+
+		c.Data(http.StatusOK, "application/json", job.Result)
+	}
+}
+
+// submitJob submits a job on the cluster of the request and writes the response
+func submitJob(c *gin.Context, m *jobs.Manager, jobType string, params any) {
+	job, err := m.Submit(clusters.NameFrom(c.Request.Context()), jobType, params)
+	if err != nil {
+		respondJobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Job submitted",
+		"job":     job,
+	})
+}
+
+// getJobOfType returns a handler reporting a job, restricted to the given type when not empty
+func getJobOfType(m *jobs.Manager, jobType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := m.Get(c.Param("jobId"))
+		if err == nil && jobType != "" && job.Type != jobType {
+			err = jobs.ErrJobNotFound
+		}
+		if err != nil {
+			respondJobError(c, err)
+			return
+		}
+		if !authorizeJob(c, job) {
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"job": job,
+		})
+	}
+}
+
+// cancelJobOfType returns a handler cancelling a job, restricted to the given type when not empty
+func cancelJobOfType(m *jobs.Manager, jobType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := m.Get(c.Param("jobId"))
+		if err == nil && jobType != "" && job.Type != jobType {
+			err = jobs.ErrJobNotFound
+		}
+		if err != nil {
+			respondJobError(c, err)
+			return
+		}
+		if !authorizeJob(c, job) {
+			return
+		}
+
+		job, err = m.Cancel(job.ID)
+		if err != nil {
+			respondJobError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"message": "Job cancellation requested",
+			"job":     job,
+		})
+	}
+}
+
+// listJobsOfType returns a handler listing the jobs the caller may access,
+// restricted to the given type when not empty
+func listJobsOfType(m *jobs.Manager, jobType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if jobType == "" {
+			jobType = c.Query("type")
+		}
+
+		visible := []domain.Job{}
+		for _, job := range m.List(jobType, c.Query("status")) {
+			if jobAllowed(c, job) {
+				visible = append(visible, job)
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"jobs": visible,
+		})
+	}
+}
+
+// jobPermission is an action a job performs on a topic of a cluster
+type jobPermission struct {
+	action  rbac.Action
+	cluster string // Empty is the default cluster
+	topic   string
+}
+
+// jobPermissions returns the actions a job performs, so that only callers who could
+// do the same through the other endpoints may submit, inspect or cancel it. They are
+// checked on the cluster of the job rather than on that of the request.
+func jobPermissions(job domain.Job) []jobPermission {
+	switch job.Type {
+	case export.JobType:
+		var spec domain.ExportSpec
+		_ = json.Unmarshal(job.Params, &spec)
+		return []jobPermission{{rbac.ActionMessageRead, job.Cluster, spec.Topic}}
+	case replay.JobType:
+		var spec domain.ReplaySpec
+		_ = json.Unmarshal(job.Params, &spec)
+		target := job.Cluster
+		if spec.TargetCluster != "" {
+			target = spec.TargetCluster
+		}
+		return []jobPermission{
+			{rbac.ActionMessageRead, job.Cluster, spec.SourceTopic},
+			{rbac.ActionMessagePublish, target, spec.TargetTopic},
+		}
+	default:
+		return nil
+	}
+}
+
+// jobAllowed reports whether the caller may access a job
+func jobAllowed(c *gin.Context, job domain.Job) bool {
+	for _, p := range jobPermissions(job) {
+		if !rbac.AllowedOn(c, p.cluster, p.action, p.topic) {
+			return false
+		}
+	}
+	return true
+}
+
+// authorizeJob is like jobAllowed but also responds with 403 Forbidden when the job is not accessible
+func authorizeJob(c *gin.Context, job domain.Job) bool {
+	for _, p := range jobPermissions(job) {
+		if !rbac.AuthorizeOn(c, p.cluster, p.action, p.topic) {
+			return false
+		}
+	}
+	return true
+}
+
+func respondJobError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, jobs.ErrJobNotFound):
+		problem.Abort(c, problem.NotFound("Job not found", err.Error()))
+	case errors.Is(err, jobs.ErrNoResult):
+		problem.Abort(c, problem.NotFound("Job has no result", err.Error()))
+	case errors.Is(err, jobs.ErrJobNotFinished):
+		problem.Abort(c, problem.Conflict("Job has not finished yet", err.Error()))
+	case errors.Is(err, jobs.ErrUnknownJobType), errors.Is(err, jobs.ErrInvalidParams):
+		problem.Abort(c, problem.BadRequest("Invalid job request", err.Error()))
+	default:
+		problem.AbortWithError(c, err, "Failed to access job")
+	}
+}
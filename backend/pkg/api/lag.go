@@ -0,0 +1,89 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/valeriouberti/maestro/internal/lag"
+	"github.com/valeriouberti/maestro/internal/problem"
+)
+
+const (
+	defaultLagHistoryRange  = 24 * time.Hour
+	defaultLagHistoryWindow = 15 * time.Minute
+)
+
+// GetConsumerGroupLagHistoryHandler creates a Gin HTTP handler that returns the lag
+// samples of a consumer group recorded by the sampler, with the rates at which the group
+// consumes and messages are produced and, when it is catching up, the estimated time
+// until it has no lag. The history of a deleted group is kept until it expires.
+//
+// Query parameters (all optional):
+// - since / until: RFC3339 time range (default the last 24 hours)
+// - topic: Limits the lag to the partitions of a topic
+// - window: Period at the end of the range the rates are computed over (default 15m)
+//
+// Returns:
+// - 200 OK with the samples and the trend, which is absent with fewer than two samples
+// - 400 Bad Request if a parameter is invalid
+// - 501 Not Implemented if lag history is disabled
+// - 500 Internal Server Error for other failures
+func GetConsumerGroupLagHistoryHandler(s *lag.Sampler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groupID := c.Param("groupId")
+		if groupID == "" {
+			problem.Abort(c, problem.BadRequest("Consumer group ID is required", ""))
+			return
+		}
+
+		query := lag.HistoryQuery{
+			Until:  time.Now(),
+			Topic:  c.Query("topic"),
+			Window: defaultLagHistoryWindow,
+		}
+		for param, target := range map[string]*time.Time{"since": &query.Since, "until": &query.Until} {
+			value := c.Query(param)
+			if value == "" {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				problem.Abort(c, problem.BadRequest("Invalid "+param+" time", err.Error()))
+				return
+			}
+			*target = t
+		}
+		if query.Since.IsZero() {
+			query.Since = query.Until.Add(-defaultLagHistoryRange)
+		}
+		if query.Since.After(query.Until) {
+			problem.Abort(c, problem.BadRequest("Invalid time range", "since must not be after until"))
+			return
+		}
+		if windowStr := c.Query("window"); windowStr != "" {
+			window, err := time.ParseDuration(windowStr)
+			if err != nil || window <= 0 {
+				problem.Abort(c, problem.BadRequest("Invalid window", "window must be a positive duration such as 15m"))
+				return
+			}
+			query.Window = window
+		}
+
+		history, err := s.History(c.Request.Context(), groupID, query)
+		if err != nil {
+			if errors.Is(err, lag.ErrDisabled) {
+				problem.Abort(c, problem.New(http.StatusNotImplemented, problem.CodeNotImplemented,
+					"Lag history is disabled", "set LAG_HISTORY_ENABLED to record consumer group lag"))
+				return
+			}
+
+			problem.AbortWithError(c, err, "Failed to get consumer group lag history")
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"history": history})
+	}
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/valeriouberti/maestro/internal/logging"
+	"github.com/valeriouberti/maestro/internal/problem"
+)
+
+// LogLevelRequest changes the level of the server log
+type LogLevelRequest struct {
+	Level string `json:"level" binding:"required"` // debug, info, warn or error
+}
+
+// GetLogLevelHandler returns a Gin HTTP handler that shows the level of the server log.
+//
+// Returns:
+// - 200 OK with the level
+func GetLogLevelHandler(level *slog.LevelVar) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"level": logging.LevelName(level.Level())})
+	}
+}
+
+// SetLogLevelHandler returns a Gin HTTP handler that changes the level of the server
+// log until the next restart, e.g. to debug a problem without restarting the server.
+//
+// Returns:
+// - 200 OK with the new level
+// - 400 Bad Request if the level is unknown
+func SetLogLevelHandler(level *slog.LevelVar) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request LogLevelRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			problem.Abort(c, problem.BadRequest("Invalid log level request", err.Error()))
+			return
+		}
+
+		parsed, err := logging.ParseLevel(request.Level)
+		if err != nil {
+			problem.Abort(c, problem.BadRequest("Invalid log level", err.Error()))
+			return
+		}
+
+		previous := level.Level()
+		level.Set(parsed)
+		slog.InfoContext(c.Request.Context(), "Log level changed", "from", logging.LevelName(previous), "to", logging.LevelName(parsed))
+
+		c.JSON(http.StatusOK, gin.H{"level": logging.LevelName(parsed)})
+	}
+}
@@ -0,0 +1,405 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/valeriouberti/maestro/internal/auth"
+	"github.com/valeriouberti/maestro/internal/clusters"
+	"github.com/valeriouberti/maestro/internal/openapi"
+	"github.com/valeriouberti/maestro/internal/problem"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// BasePath is the path the API routes are registered under
+const BasePath = "/api/v1"
+
+// Tags grouping the operations of the OpenAPI document
+const (
+	tagClusters = "clusters"
+	tagTopics   = "topics"
+	tagMessages = "messages"
+	tagGroups   = "consumergroups"
+	tagJobs     = "jobs"
+	tagAudit    = "audit"
+	tagAlerts   = "alerts"
+	tagAdmin    = "admin"
+	tagMeta     = "meta"
+)
+
+// jobResponse is the response of the endpoints returning a single job
+var jobResponse = openapi.Fields{"job": domain.Job{}}
+
+// submittedJobResponse is the response of the endpoints starting or cancelling a job
+var submittedJobResponse = openapi.Fields{"message": "", "job": domain.Job{}}
+
+// bulkTopicsResponse is the response of bulk topic operations; confirmation is only returned by dry runs
+var bulkTopicsResponse = openapi.Fields{"message": "", "dryRun": false, "confirmation": "", "topics": []domain.BulkTopicResult{}}
+
+// topicTemplateResponse is the response of the endpoints creating or replacing a topic template
+var topicTemplateResponse = openapi.Fields{"message": "", "template": domain.TopicTemplate{}}
+
+// confirmParam confirms changes to protected topics
+var confirmParam = openapi.Param{Name: "confirm", Type: "", Description: "Name of the topic, confirming the change when the topic is protected"}
+
+// messageRangeParams are the query parameters selecting the messages to export
+var messageRangeParams = []openapi.Param{
+	{Name: "partition", Type: "", Description: "Partition or comma-separated list of partitions (default: all)"},
+	{Name: "startOffset", Type: int64(0), Description: "First offset per partition"},
+	{Name: "endOffset", Type: int64(0), Description: "Offset per partition to stop at, exclusive"},
+	{Name: "startTime", Type: "", Description: "Start of the time range as RFC 3339 or epoch milliseconds"},
+	{Name: "endTime", Type: "", Description: "End of the time range as RFC 3339 or epoch milliseconds, exclusive"},
+	{Name: "limit", Type: int64(0), Description: "Maximum number of messages (default: unlimited)"},
+}
+
+// Endpoints describes the routes registered under BasePath. It is the source of the
+// OpenAPI document and of the generated Go client in pkg/client; the server checks at
+// startup that it matches the registered routes, so a new route must be added here.
+var Endpoints = []openapi.Endpoint{
+	{
+		Method: http.MethodGet, Path: "/clusters", ID: "getClusters", Tag: tagClusters,
+		Summary:  "List the brokers of the cluster",
+		Response: openapi.Fields{"brokers": []domain.BrokerInfo{}},
+	},
+	{
+		Method: http.MethodGet, Path: "/clusters/configured", ID: "listConfiguredClusters", Tag: tagClusters,
+		Summary:  "List the Kafka clusters defined in the configuration",
+		Response: openapi.Fields{"clusters": []domain.ClusterInfo{}},
+	},
+	{
+		Method: http.MethodGet, Path: "/topics", ID: "listTopics", Tag: tagTopics,
+		Summary:  "List topics",
+		Response: openapi.Fields{"topics": []domain.TopicInfo{}},
+	},
+	{
+		Method: http.MethodGet, Path: "/topics/:topicName", ID: "getTopic", Tag: tagTopics,
+		Summary:  "Get the details of a topic",
+		Response: openapi.Fields{"topic": domain.TopicInfo{}},
+	},
+	{
+		Method: http.MethodPost, Path: "/topics", ID: "createTopic", Tag: tagTopics,
+		Summary:  "Create a topic",
+		Request:  TopicCreationRequest{},
+		Status:   http.StatusCreated,
+		Response: openapi.Fields{"message": "", "topic": domain.TopicInfo{}},
+	},
+	{
+		Method: http.MethodDelete, Path: "/topics/:topicName", ID: "deleteTopic", Tag: tagTopics,
+		Summary:  "Delete a topic",
+		Query:    []openapi.Param{confirmParam},
+		Response: openapi.Fields{"message": "", "topic": ""},
+	},
+	{
+		Method: http.MethodPut, Path: "/topics/:topicName/config", ID: "updateTopicConfig", Tag: tagTopics,
+		Summary:  "Update the configuration of a topic",
+		Query:    []openapi.Param{confirmParam},
+		Request:  TopicConfigUpdateRequest{},
+		Response: openapi.Fields{"message": "", "topic": domain.TopicInfo{}},
+	},
+	{
+		Method: http.MethodGet, Path: "/topics/:topicName/messages", ID: "getTopicMessages", Tag: tagMessages,
+		Summary: "Read messages of a topic partition",
+		Query: []openapi.Param{
+			{Name: "partition", Type: int32(0), Description: "Partition to read (default: 0)"},
+			{Name: "offset", Type: "", Description: "Offset to start from, earliest (default) or latest"},
+			{Name: "limit", Type: 0, Description: "Maximum number of messages (default: 100)"},
+		},
+		Response: openapi.Fields{
+			"topic":     "",
+			"partition": int32(0),
+			"offset":    int64(0),
+			"count":     0,
+			"messages":  []domain.TopicMessage{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/topics/:topicName/messages", ID: "publishMessage", Tag: tagMessages,
+		Summary: "Publish a message",
+		Request: MessagePublishRequest{},
+		Response: openapi.Fields{
+			"message": "",
+			"topic":   "",
+			"publishedMessage": openapi.Fields{
+				"key":       "",
+				"value":     "",
+				"topic":     "",
+				"partition": int32(0),
+				"timestamp": time.Time{},
+				"headers?":  map[string]string{},
+			},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/topics/:topicName/messages/batch", ID: "publishBatch", Tag: tagMessages,
+		Summary: "Publish a batch of records",
+		Query: []openapi.Param{
+			{Name: "rate", Type: 0, Description: "Maximum number of records published per second (default: unlimited)"},
+			{Name: "results", Type: "", Description: "Per-record results to return: all (default), failed or none"},
+			{Name: "format", Type: "", Description: "Payload format overriding the Content-Type: json, ndjson or csv"},
+		},
+		Request: BatchPublishRequest{},
+		Response: openapi.Fields{
+			"message":  "",
+			"topic":    "",
+			"summary":  domain.BatchPublishSummary{},
+			"results?": []domain.ProduceResult{},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/topics/:topicName/consumers", ID: "getTopicConsumers", Tag: tagTopics,
+		Summary:  "List the consumer groups of a topic with their lag",
+		Response: openapi.Fields{"topic": "", "consumers": []domain.TopicConsumer{}},
+	},
+	{
+		Method: http.MethodGet, Path: "/topology", ID: "getTopology", Tag: tagClusters,
+		Summary:  "Get the graph of the producers, topics and consumer groups of the cluster",
+		Response: openapi.Fields{"topology": domain.Topology{}},
+	},
+	{
+		Method: http.MethodGet, Path: "/topics/:topicName/export", ID: "exportTopicMessages", Tag: tagMessages,
+		Summary: "Download a range of messages of a topic",
+		Query: append([]openapi.Param{
+			{Name: "format", Type: "", Description: "ndjson (default), csv, avro or parquet"},
+		}, messageRangeParams...),
+		Download: true,
+	},
+	{
+		Method: http.MethodPost, Path: "/topics/delete", ID: "bulkDeleteTopics", Tag: tagTopics,
+		Summary:  "Delete the topics listed or matching a regular expression",
+		Request:  domain.BulkTopicDeletionSpec{},
+		Response: bulkTopicsResponse,
+	},
+	{
+		Method: http.MethodPost, Path: "/topics/config", ID: "bulkUpdateTopicsConfig", Tag: tagTopics,
+		Summary:  "Set and remove configuration overrides of the topics listed or matching a regular expression",
+		Request:  domain.BulkTopicConfigSpec{},
+		Response: bulkTopicsResponse,
+	},
+	{
+		Method: http.MethodPost, Path: "/topics/partitions", ID: "bulkCreatePartitions", Tag: tagTopics,
+		Summary:  "Increase the number of partitions of the topics listed or matching a regular expression",
+		Request:  domain.BulkPartitionsSpec{},
+		Response: bulkTopicsResponse,
+	},
+	{
+		Method: http.MethodGet, Path: "/topictemplates", ID: "listTopicTemplates", Tag: tagTopics,
+		Summary:  "List the topic templates",
+		Response: openapi.Fields{"templates": []domain.TopicTemplate{}},
+	},
+	{
+		Method: http.MethodPost, Path: "/topictemplates", ID: "createTopicTemplate", Tag: tagTopics,
+		Summary:  "Create a topic template",
+		Request:  TopicTemplateRequest{},
+		Status:   http.StatusCreated,
+		Response: topicTemplateResponse,
+	},
+	{
+		Method: http.MethodGet, Path: "/topictemplates/:templateName", ID: "getTopicTemplate", Tag: tagTopics,
+		Summary:  "Get a topic template",
+		Response: openapi.Fields{"template": domain.TopicTemplate{}},
+	},
+	{
+		Method: http.MethodPut, Path: "/topictemplates/:templateName", ID: "updateTopicTemplate", Tag: tagTopics,
+		Summary:  "Replace a topic template created through the API",
+		Request:  TopicTemplateRequest{},
+		Response: topicTemplateResponse,
+	},
+	{
+		Method: http.MethodDelete, Path: "/topictemplates/:templateName", ID: "deleteTopicTemplate", Tag: tagTopics,
+		Summary:  "Delete a topic template created through the API",
+		Response: openapi.Fields{"message": "", "template": ""},
+	},
+	{
+		Method: http.MethodGet, Path: "/consumergroups", ID: "listConsumerGroups", Tag: tagGroups,
+		Summary:  "List consumer groups",
+		Query:    []openapi.Param{{Name: "state", Type: "", Description: "Comma-separated states the groups must be in, e.g. Stable,Empty"}},
+		Response: openapi.Fields{"groups": []domain.ConsumerGroupInfo{}},
+	},
+	{
+		Method: http.MethodGet, Path: "/consumergroups/:groupId", ID: "getConsumerGroup", Tag: tagGroups,
+		Summary:  "Get the details of a consumer group",
+		Response: openapi.Fields{"group": domain.ConsumerGroupDetails{}},
+	},
+	{
+		Method: http.MethodGet, Path: "/consumergroups/:groupId/lag", ID: "getConsumerGroupLag", Tag: tagGroups,
+		Summary:  "Get the lag of a consumer group",
+		Response: openapi.Fields{"lag": domain.ConsumerGroupLag{}},
+	},
+	{
+		Method: http.MethodGet, Path: "/consumergroups/:groupId/lag/history", ID: "getConsumerGroupLagHistory", Tag: tagGroups,
+		Summary: "Get the recorded lag of a consumer group with its consumption rate and catch-up estimate",
+		Query: []openapi.Param{
+			{Name: "since", Type: "", Description: "Start of the time range as RFC 3339 (default: 24 hours before until)"},
+			{Name: "until", Type: "", Description: "End of the time range as RFC 3339 (default: now)"},
+			{Name: "topic", Type: "", Description: "Only the partitions of this topic"},
+			{Name: "window", Type: "", Description: "Period at the end of the range the rates are computed over (default: 15m)"},
+		},
+		Response: openapi.Fields{"history": domain.LagHistory{}},
+	},
+	{
+		Method: http.MethodPost, Path: "/consumergroups/:groupId/offsets/reset", ID: "resetConsumerGroupOffsets", Tag: tagGroups,
+		Summary:  "Reset the committed offsets of a consumer group",
+		Request:  domain.OffsetResetSpec{},
+		Response: openapi.Fields{"message": "", "groupId": "", "dryRun": false, "offsets": []domain.OffsetReset{}},
+	},
+	{
+		Method: http.MethodDelete, Path: "/consumergroups/:groupId", ID: "deleteConsumerGroup", Tag: tagGroups,
+		Summary:  "Delete a consumer group without members",
+		Response: openapi.Fields{"message": "", "groupId": ""},
+	},
+	{
+		Method: http.MethodPost, Path: "/consumergroups/delete", ID: "deleteConsumerGroups", Tag: tagGroups,
+		Summary:  "Delete the consumer groups without members matching a pattern",
+		Request:  domain.GroupDeletionSpec{},
+		Response: openapi.Fields{"message": "", "pattern": "", "dryRun": false, "confirmation": "", "groups": []domain.GroupDeletion{}},
+	},
+	{
+		Method: http.MethodPost, Path: "/consumergroups/:groupId/offsets/delete", ID: "deleteConsumerGroupOffsets", Tag: tagGroups,
+		Summary:  "Delete the committed offsets of a consumer group on a topic",
+		Request:  domain.OffsetDeletionSpec{},
+		Response: openapi.Fields{"message": "", "groupId": "", "offsets": []domain.CommittedOffset{}},
+	},
+	{
+		Method: http.MethodPost, Path: "/consumergroups/:groupId/members/remove", ID: "removeConsumerGroupMembers", Tag: tagGroups,
+		Summary:  "Remove static members from a consumer group",
+		Request:  MemberRemovalRequest{},
+		Response: openapi.Fields{"message": "", "groupId": "", "members": []domain.MemberRemoval{}},
+	},
+	{
+		Method: http.MethodPost, Path: "/replays", ID: "startReplay", Tag: tagJobs,
+		Summary:  "Start a replay job",
+		Request:  domain.ReplaySpec{},
+		Status:   http.StatusAccepted,
+		Response: submittedJobResponse,
+	},
+	{
+		Method: http.MethodGet, Path: "/replays", ID: "listReplays", Tag: tagJobs,
+		Summary:  "List replay jobs",
+		Query:    []openapi.Param{{Name: "status", Type: "", Description: "Only jobs with this status"}},
+		Response: openapi.Fields{"jobs": []domain.Job{}},
+	},
+	{
+		Method: http.MethodGet, Path: "/replays/:jobId", ID: "getReplay", Tag: tagJobs,
+		Summary:  "Get a replay job",
+		Response: jobResponse,
+	},
+	{
+		Method: http.MethodPost, Path: "/replays/:jobId/cancel", ID: "cancelReplay", Tag: tagJobs,
+		Summary:  "Cancel a replay job",
+		Status:   http.StatusAccepted,
+		Response: submittedJobResponse,
+	},
+	{
+		Method: http.MethodPost, Path: "/jobs", ID: "submitJob", Tag: tagJobs,
+		Summary:  "Start a background job",
+		Request:  JobSubmitRequest{},
+		Status:   http.StatusAccepted,
+		Response: submittedJobResponse,
+	},
+	{
+		Method: http.MethodGet, Path: "/jobs", ID: "listJobs", Tag: tagJobs,
+		Summary: "List background jobs",
+		Query: []openapi.Param{
+			{Name: "type", Type: "", Description: "Only jobs of this type"},
+			{Name: "status", Type: "", Description: "Only jobs with this status"},
+		},
+		Response: openapi.Fields{"jobs": []domain.Job{}},
+	},
+	{
+		Method: http.MethodGet, Path: "/jobs/:jobId", ID: "getJob", Tag: tagJobs,
+		Summary:  "Get a background job",
+		Response: jobResponse,
+	},
+	{
+		Method: http.MethodPost, Path: "/jobs/:jobId/cancel", ID: "cancelJob", Tag: tagJobs,
+		Summary:  "Cancel a background job",
+		Status:   http.StatusAccepted,
+		Response: submittedJobResponse,
+	},
+	{
+		Method: http.MethodGet, Path: "/jobs/:jobId/result", ID: "getJobResult", Tag: tagJobs,
+		Summary:  "Download the result of a completed job",
+		Download: true,
+	},
+	{
+		Method: http.MethodGet, Path: "/alerts", ID: "listAlerts", Tag: tagAlerts,
+		Summary:  "List the pending and firing alerts",
+		Response: openapi.Fields{"alerts": []domain.Alert{}},
+	},
+	{
+		Method: http.MethodPost, Path: "/alerts/notifiers/:notifier/test", ID: "testNotifier", Tag: tagAlerts,
+		Summary:  "Send a test notification to a notifier",
+		Response: openapi.Fields{"message": "", "notifier": ""},
+	},
+	{
+		Method: http.MethodGet, Path: "/audit", ID: "searchAudit", Tag: tagAudit,
+		Summary: "Search the audit trail",
+		Query: []openapi.Param{
+			{Name: "user", Type: "", Description: "User who performed the operation"},
+			{Name: "action", Type: "", Description: "Part of the action"},
+			{Name: "resource", Type: "", Description: "Topic, consumer group or job the operation was performed on"},
+			{Name: "outcome", Type: "", Description: "success, failure or denied"},
+			{Name: "since", Type: "", Description: "Start of the time range as RFC 3339"},
+			{Name: "until", Type: "", Description: "End of the time range as RFC 3339"},
+			{Name: "limit", Type: 0, Description: "Maximum number of records (default 100, max 1000)"},
+		},
+		Response: openapi.Fields{"records": []domain.AuditRecord{}},
+	},
+	{
+		Method: http.MethodGet, Path: "/config", ID: "getConfig", Tag: tagAdmin,
+		Summary:  "Get the configuration in effect, with secrets redacted",
+		Response: openapi.Fields{"config": map[string]any{}},
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/log-level", ID: "getLogLevel", Tag: tagAdmin,
+		Summary:  "Get the level of the server log",
+		Response: openapi.Fields{"level": ""},
+	},
+	{
+		Method: http.MethodPut, Path: "/admin/log-level", ID: "setLogLevel", Tag: tagAdmin,
+		Summary:  "Change the level of the server log until the next restart",
+		Request:  LogLevelRequest{},
+		Response: openapi.Fields{"level": ""},
+	},
+	{
+		Method: http.MethodGet, Path: "/openapi.json", ID: "getOpenAPI", Tag: tagMeta,
+		Summary:  "Get this OpenAPI document",
+		Response: map[string]any{},
+		Public:   true,
+	},
+}
+
+// OpenAPIDocument builds the OpenAPI document of the API from Endpoints
+func OpenAPIDocument() *openapi.Document {
+	return openapi.Build(openapi.Options{
+		Info: openapi.Info{
+			Title:       "Maestro API",
+			Description: "REST API of Maestro, the web console for Apache Kafka",
+			Version:     "v1",
+		},
+		BasePath: BasePath,
+		Problem:  problem.Problem{},
+		SecuritySchemes: map[string]openapi.SecurityScheme{
+			auth.MethodBasic:  {Type: "http", Scheme: "basic"},
+			auth.MethodAPIKey: {Type: "apiKey", Name: auth.APIKeyHeader, In: "header"},
+			auth.MethodOIDC:   {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		},
+		Headers: []openapi.Param{
+			{Name: clusters.Header, Description: "Kafka cluster to operate on (default: the default cluster); the cluster query parameter may be used instead", Type: ""},
+		},
+	}, Endpoints)
+}
+
+// OpenAPIHandler returns a Gin HTTP handler serving the OpenAPI document of the API.
+// The document is built once, when the handler is created.
+//
+// Returns:
+// - 200 OK with the OpenAPI 3 document
+func OpenAPIHandler() gin.HandlerFunc {
+	doc := OpenAPIDocument()
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, doc)
+	}
+}
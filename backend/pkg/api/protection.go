@@ -0,0 +1,30 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/valeriouberti/maestro/internal/problem"
+	"github.com/valeriouberti/maestro/internal/protection"
+	"github.com/valeriouberti/maestro/internal/rbac"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// confirmProtected checks a change to a topic, aborting the request unless the topic is
+// unprotected or the caller may change protected topics and confirmed the change by
+// passing the name of the topic in the confirm query parameter
+func confirmProtected(c *gin.Context, guard *protection.Guard, topic domain.TopicInfo, change string) bool {
+	if !guard.Protected(topic) {
+		return true
+	}
+	if !rbac.Authorize(c, rbac.ActionTopicProtected, topic.Name) {
+		return false
+	}
+	if c.Query("confirm") != topic.Name {
+		problem.Abort(c, problem.ProtectedTopic("Topic is protected",
+			fmt.Sprintf("%s is protected: confirm the %s by passing confirm=%s", topic.Name, change, topic.Name)))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/valeriouberti/maestro/internal/clusters"
+	"github.com/valeriouberti/maestro/internal/jobs"
+	"github.com/valeriouberti/maestro/internal/problem"
+	"github.com/valeriouberti/maestro/internal/replay"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// StartReplayHandler creates a Gin HTTP handler that starts a replay job copying a range
+// of messages from a source topic to a target topic, on the same or another configured
+// cluster.
+//
+// The request body is a domain.ReplaySpec. The job runs in the background; its progress
+// can be followed through GetReplayHandler or the generic job endpoints.
+//
+// Returns:
+// - 202 Accepted with the created job
+// - 400 Bad Request if the request is malformed, the spec is invalid or the target cluster is not configured
+// - 403 Forbidden if the caller may not read the source topic or publish to the target topic of the target cluster
+func StartReplayHandler(m *jobs.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var spec domain.ReplaySpec
+		if err := c.ShouldBindJSON(&spec); err != nil {
+			problem.Abort(c, problem.BadRequest("Invalid replay request", err.Error()))
+			return
+		}
+
+		job := domain.Job{Type: replay.JobType, Cluster: clusters.NameFrom(c.Request.Context())}
+		job.Params, _ = json.Marshal(spec)
+		if !authorizeJob(c, job) {
+			return
+		}
+
+		submitJob(c, m, replay.JobType, spec)
+	}
+}
+
+// ListReplaysHandler returns a Gin HTTP handler that lists replay jobs, most recent first.
+// The optional "status" query parameter narrows down the list.
+func ListReplaysHandler(m *jobs.Manager) gin.HandlerFunc {
+	return listJobsOfType(m, replay.JobType)
+}
+
+// GetReplayHandler returns a Gin HTTP handler that reports the status and progress of a replay job.
+//
+// Returns:
+// - 200 OK with the job
+// - 404 Not Found if no replay job exists with the given ID
+func GetReplayHandler(m *jobs.Manager) gin.HandlerFunc {
+	return getJobOfType(m, replay.JobType)
+}
+
+// CancelReplayHandler returns a Gin HTTP handler that cancels a replay job.
+// Messages already handed to the producer are still delivered.
+//
+// Returns:
+// - 202 Accepted with the job as it was when cancellation was requested
+// - 404 Not Found if no replay job exists with the given ID
+func CancelReplayHandler(m *jobs.Manager) gin.HandlerFunc {
+	return cancelJobOfType(m, replay.JobType)
+}
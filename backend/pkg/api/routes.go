@@ -0,0 +1,112 @@
+package api
+
+import (
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/valeriouberti/maestro/internal/alerting"
+	"github.com/valeriouberti/maestro/internal/audit"
+	"github.com/valeriouberti/maestro/internal/auth"
+	"github.com/valeriouberti/maestro/internal/clusters"
+	"github.com/valeriouberti/maestro/internal/config"
+	"github.com/valeriouberti/maestro/internal/jobs"
+	"github.com/valeriouberti/maestro/internal/kafka_client"
+	"github.com/valeriouberti/maestro/internal/lag"
+	"github.com/valeriouberti/maestro/internal/openapi"
+	"github.com/valeriouberti/maestro/internal/policy"
+	"github.com/valeriouberti/maestro/internal/problem"
+	"github.com/valeriouberti/maestro/internal/protection"
+	"github.com/valeriouberti/maestro/internal/rbac"
+	"github.com/valeriouberti/maestro/internal/templates"
+)
+
+// Services are the dependencies of the API handlers. Lag, Alerts, Authenticator and
+// Authorizer may be nil when the feature they provide is disabled.
+type Services struct {
+	// Client forwards Kafka operations to the cluster selected by the request
+	Client   kafka_client.Client
+	Clusters *clusters.Registry
+	// Config returns the configuration in effect
+	Config    func() *config.Config
+	Features  config.FeaturesConfig
+	LogLevel  *slog.LevelVar
+	Jobs      *jobs.Manager
+	Lag       *lag.Sampler
+	Alerts    *alerting.Engine
+	Templates *templates.Store
+	Policies  *policy.Checker
+	Protected *protection.Guard
+
+	Authenticator auth.Authenticator
+	Authorizer    *rbac.Authorizer
+	Audit         *audit.Logger
+}
+
+// RegisterRoutes registers the OpenAPI document and the API routes with their
+// middleware under BasePath. It fails when the routes do not match Endpoints.
+func RegisterRoutes(r *gin.Engine, s Services) error {
+	// The API description is public so that clients can be generated without credentials
+	r.GET(BasePath+"/openapi.json", OpenAPIHandler())
+
+	group := r.Group(BasePath)
+	// Errors are rendered as problem details before the audit middleware records the response
+	group.Use(audit.Middleware(s.Audit), problem.Middleware(), auth.Middleware(s.Authenticator), rbac.Middleware(s.Authorizer, s.Clusters.Selected), clusters.Middleware(s.Clusters))
+
+	features := s.Features
+	readOnly := RequireFeature(!features.ReadOnly, "Maestro is in read-only mode")
+	topicDeletion := RequireFeature(features.TopicDeletion, "Topic deletion is disabled")
+	publishing := RequireFeature(features.MessagePublishing, "Message publishing is disabled")
+	offsetReset := RequireFeature(features.OffsetReset, "Resetting consumer group offsets is disabled")
+	groupDeletion := RequireFeature(features.GroupDeletion, "Deleting consumer groups is disabled")
+
+	// Routes without a resource in the path require the action on at least one
+	// resource; their handlers check or filter the individual topics and groups
+	group.GET("/clusters", rbac.Require(rbac.ActionTopicRead, ""), GetClustersHandler(s.Client))
+	group.GET("/clusters/configured", rbac.Require(rbac.ActionTopicRead, ""), ListConfiguredClustersHandler(s.Clusters))
+	group.GET("/topics", rbac.Require(rbac.ActionTopicRead, ""), ListTopicsHandler(s.Client, s.Protected))
+	group.GET("/topics/:topicName", rbac.Require(rbac.ActionTopicRead, "topicName"), GetTopicHandler(s.Client, s.Protected))
+	group.POST("/topics/delete", rbac.Require(rbac.ActionTopicDelete, ""), readOnly, topicDeletion, BulkDeleteTopicsHandler(s.Client, s.Protected))
+	group.POST("/topics/config", rbac.Require(rbac.ActionTopicConfig, ""), readOnly, BulkUpdateTopicsConfigHandler(s.Client, s.Policies, s.Protected))
+	group.POST("/topics/partitions", rbac.Require(rbac.ActionTopicConfig, ""), readOnly, BulkCreatePartitionsHandler(s.Client, s.Policies, s.Protected))
+	group.POST("/topics", rbac.Require(rbac.ActionTopicCreate, ""), readOnly, CreateTopicHandler(s.Client, s.Policies, s.Templates))
+	group.DELETE("/topics/:topicName", rbac.Require(rbac.ActionTopicDelete, "topicName"), readOnly, topicDeletion, DeleteTopicHandler(s.Client, s.Protected))
+	group.PUT("/topics/:topicName/config", rbac.Require(rbac.ActionTopicConfig, "topicName"), readOnly, UpdateTopicConfigHandler(s.Client, s.Policies, s.Protected))
+	group.GET("/topics/:topicName/messages", rbac.Require(rbac.ActionMessageRead, "topicName"), GetTopicMessagesHandler(s.Client))
+	group.POST("/topics/:topicName/messages", rbac.Require(rbac.ActionMessagePublish, "topicName"), readOnly, publishing, PublishMessageHandler(s.Client))
+	group.POST("/topics/:topicName/messages/batch", rbac.Require(rbac.ActionMessagePublish, "topicName"), readOnly, publishing, PublishBatchHandler(s.Client))
+	group.GET("/topics/:topicName/consumers", rbac.Require(rbac.ActionTopicRead, "topicName"), GetTopicConsumersHandler(s.Client))
+	group.GET("/topics/:topicName/export", rbac.Require(rbac.ActionMessageRead, "topicName"), ExportTopicMessagesHandler(s.Client))
+	group.GET("/topictemplates", rbac.Require(rbac.ActionTopicRead, ""), ListTopicTemplatesHandler(s.Templates))
+	group.POST("/topictemplates", rbac.Require(rbac.ActionConfigWrite, ""), CreateTopicTemplateHandler(s.Templates))
+	group.GET("/topictemplates/:templateName", rbac.Require(rbac.ActionTopicRead, ""), GetTopicTemplateHandler(s.Templates))
+	group.PUT("/topictemplates/:templateName", rbac.Require(rbac.ActionConfigWrite, ""), UpdateTopicTemplateHandler(s.Templates))
+	group.DELETE("/topictemplates/:templateName", rbac.Require(rbac.ActionConfigWrite, ""), DeleteTopicTemplateHandler(s.Templates))
+	group.GET("/topology", rbac.Require(rbac.ActionTopicRead, ""), GetTopologyHandler(s.Client))
+	group.GET("/consumergroups", rbac.Require(rbac.ActionGroupRead, ""), ListConsumerGroupsHandler(s.Client))
+	group.POST("/consumergroups/delete", rbac.Require(rbac.ActionGroupDelete, ""), readOnly, groupDeletion, DeleteConsumerGroupsHandler(s.Client))
+	group.GET("/consumergroups/:groupId", rbac.Require(rbac.ActionGroupRead, "groupId"), GetConsumerGroupHandler(s.Client))
+	group.GET("/consumergroups/:groupId/lag", rbac.Require(rbac.ActionGroupRead, "groupId"), GetConsumerGroupLagHandler(s.Client))
+	group.GET("/consumergroups/:groupId/lag/history", rbac.Require(rbac.ActionGroupRead, "groupId"), GetConsumerGroupLagHistoryHandler(s.Lag))
+	group.POST("/consumergroups/:groupId/offsets/reset", rbac.Require(rbac.ActionGroupReset, "groupId"), readOnly, offsetReset, ResetConsumerGroupOffsetsHandler(s.Client))
+	group.DELETE("/consumergroups/:groupId", rbac.Require(rbac.ActionGroupDelete, "groupId"), readOnly, groupDeletion, DeleteConsumerGroupHandler(s.Client))
+	group.POST("/consumergroups/:groupId/offsets/delete", rbac.Require(rbac.ActionGroupDelete, "groupId"), readOnly, groupDeletion, DeleteConsumerGroupOffsetsHandler(s.Client))
+	group.POST("/consumergroups/:groupId/members/remove", rbac.Require(rbac.ActionGroupDelete, "groupId"), readOnly, groupDeletion, RemoveConsumerGroupMembersHandler(s.Client))
+	group.POST("/replays", rbac.Require(rbac.ActionMessagePublish, ""), readOnly, publishing, StartReplayHandler(s.Jobs))
+	group.GET("/replays", rbac.Require(rbac.ActionMessageRead, ""), ListReplaysHandler(s.Jobs))
+	group.GET("/replays/:jobId", rbac.Require(rbac.ActionMessageRead, ""), GetReplayHandler(s.Jobs))
+	group.POST("/replays/:jobId/cancel", rbac.Require(rbac.ActionMessagePublish, ""), CancelReplayHandler(s.Jobs))
+	group.POST("/jobs", rbac.Require(rbac.ActionMessageRead, ""), SubmitJobHandler(s.Jobs))
+	group.GET("/jobs", rbac.Require(rbac.ActionMessageRead, ""), ListJobsHandler(s.Jobs))
+	group.GET("/jobs/:jobId", rbac.Require(rbac.ActionMessageRead, ""), GetJobHandler(s.Jobs))
+	group.POST("/jobs/:jobId/cancel", rbac.Require(rbac.ActionMessageRead, ""), CancelJobHandler(s.Jobs))
+	group.GET("/jobs/:jobId/result", rbac.Require(rbac.ActionMessageRead, ""), GetJobResultHandler(s.Jobs))
+	group.GET("/alerts", rbac.Require(rbac.ActionTopicRead, ""), ListAlertsHandler(s.Alerts))
+	group.POST("/alerts/notifiers/:notifier/test", rbac.Require(rbac.ActionConfigWrite, ""), TestNotifierHandler(s.Alerts))
+	group.GET("/audit", rbac.Require(rbac.ActionAuditRead, ""), SearchAuditHandler(s.Audit))
+	group.GET("/config", rbac.Require(rbac.ActionConfigRead, ""), GetConfigHandler(s.Config))
+	group.GET("/admin/log-level", rbac.Require(rbac.ActionConfigRead, ""), GetLogLevelHandler(s.LogLevel))
+	group.PUT("/admin/log-level", rbac.Require(rbac.ActionConfigWrite, ""), SetLogLevelHandler(s.LogLevel))
+
+	return openapi.Check(r.Routes(), BasePath, Endpoints)
+}
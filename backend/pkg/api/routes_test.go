@@ -0,0 +1,273 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/valeriouberti/maestro/internal/audit"
+	"github.com/valeriouberti/maestro/internal/auth"
+	"github.com/valeriouberti/maestro/internal/clusters"
+	"github.com/valeriouberti/maestro/internal/config"
+	"github.com/valeriouberti/maestro/internal/jobs"
+	"github.com/valeriouberti/maestro/internal/kafka_client/fake"
+	"github.com/valeriouberti/maestro/internal/policy"
+	"github.com/valeriouberti/maestro/internal/problem"
+	"github.com/valeriouberti/maestro/internal/protection"
+	"github.com/valeriouberti/maestro/internal/rbac"
+	"github.com/valeriouberti/maestro/internal/storage"
+	"github.com/valeriouberti/maestro/internal/templates"
+	"github.com/valeriouberti/maestro/pkg/api"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// Users of the test server
+const (
+	admin   = "admin"
+	viewer  = "viewer"
+	billing = "billing"
+)
+
+// userHeader names the user a test request is authenticated as
+const userHeader = "X-Test-User"
+
+// headerAuthenticator authenticates requests by the user named in userHeader
+type headerAuthenticator struct{}
+
+func (headerAuthenticator) Authenticate(r *http.Request) (*auth.Principal, error) {
+	name := r.Header.Get(userHeader)
+	if name == "" {
+		return nil, auth.ErrNoCredentials
+	}
+	return &auth.Principal{Subject: name, Name: name, Method: "test"}, nil
+}
+
+func (headerAuthenticator) Challenge() string {
+	return "Test"
+}
+
+// testServer is the API router on an in-memory Kafka cluster
+type testServer struct {
+	router  http.Handler
+	cluster *fake.Cluster
+	cfg     *config.Config // Served by the config endpoint
+}
+
+// newTestServer builds the API router on an empty fake cluster. The admin may do
+// everything, the viewer may only read and the billing team may manage the billing.*
+// topics and billing-* consumer groups, except for protected topics.
+func newTestServer(t *testing.T, features config.FeaturesConfig) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		Clusters: []config.ClusterConfig{{Name: "primary", Demo: true, Default: true}},
+		Features: features,
+	}
+	registry, err := clusters.NewRegistry(cfg)
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	t.Cleanup(registry.Close)
+
+	store := storage.NewMemory()
+	if err := storage.Migrate(store); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	topicTemplates, err := templates.NewStore(store, []domain.TopicTemplate{
+		{Name: "events", TopicName: "{team}.events", NumPartitions: 3, ReplicationFactor: 1},
+	})
+	if err != nil {
+		t.Fatalf("templates.NewStore: %v", err)
+	}
+
+	jobManager, err := jobs.NewManager(jobs.Options{})
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	jobManager.Start()
+	t.Cleanup(jobManager.Shutdown)
+
+	authorizer, err := rbac.NewAuthorizer(rbac.Policy{
+		Roles: map[string]rbac.Role{
+			"admin":  {Actions: []rbac.Action{rbac.ActionAll}},
+			"viewer": {Actions: []rbac.Action{rbac.ActionTopicRead, rbac.ActionMessageRead, rbac.ActionGroupRead}},
+			"billing": {
+				Actions: []rbac.Action{rbac.ActionTopicDelete, rbac.ActionTopicConfig, rbac.ActionGroupDelete},
+				Topics:  []string{"billing.*", "prod.billing.*"},
+				Groups:  []string{"billing-*"},
+			},
+		},
+		Bindings: []rbac.Binding{
+			{Role: "admin", Users: []string{admin}},
+			{Role: "viewer", Users: []string{viewer}},
+			{Role: "billing", Users: []string{billing}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewAuthorizer: %v", err)
+	}
+
+	cluster := fake.New()
+	r := gin.New()
+	err = api.RegisterRoutes(r, api.Services{
+		Client:    cluster,
+		Clusters:  registry,
+		Config:    func() *config.Config { return cfg },
+		Features:  features,
+		LogLevel:  new(slog.LevelVar),
+		Jobs:      jobManager,
+		Templates: topicTemplates,
+		Policies: policy.NewChecker([]policy.TopicPolicy{
+			{Name: "partitions", MaxPartitions: 12},
+			{Name: "durability", BannedConfigs: map[string][]string{"unclean.leader.election.enable": {"true"}}},
+		}, registry.Selected),
+		Protected:     protection.New([]string{"prod.*"}, nil),
+		Authenticator: headerAuthenticator{},
+		Authorizer:    authorizer,
+		Audit:         audit.NewLogger(),
+	})
+	if err != nil {
+		t.Fatalf("RegisterRoutes: %v", err)
+	}
+
+	return &testServer{router: r, cluster: cluster, cfg: cfg}
+}
+
+// allFeatures enables every feature that can change the cluster
+var allFeatures = config.FeaturesConfig{
+	TopicDeletion:     true,
+	MessagePublishing: true,
+	OffsetReset:       true,
+	GroupDeletion:     true,
+}
+
+// do sends a request to the server as user, encoding body as JSON unless it is nil
+func (s *testServer) do(t *testing.T, user, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatalf("failed to encode request body: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(method, path, &buf)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if user != "" {
+		req.Header.Set(userHeader, user)
+	}
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+// expectStatus fails the test unless the response has the status
+func expectStatus(t *testing.T, w *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if w.Code != status {
+		t.Fatalf("status = %d, want %d; body: %s", w.Code, status, w.Body)
+	}
+}
+
+// expectProblem fails the test unless the response is a problem with the status and code
+func expectProblem(t *testing.T, w *httptest.ResponseRecorder, status int, code string) problem.Problem {
+	t.Helper()
+	expectStatus(t, w, status)
+
+	if contentType := w.Header().Get("Content-Type"); contentType != problem.ContentType {
+		t.Fatalf("content type = %q, want %q", contentType, problem.ContentType)
+	}
+	var p problem.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatalf("failed to decode problem: %v; body: %s", err, w.Body)
+	}
+	if p.Status != status || p.Code != code {
+		t.Fatalf("problem status %d code %q, want %d %q; body: %s", p.Status, p.Code, status, code, w.Body)
+	}
+	if p.Type != "urn:maestro:problem:"+code || p.Title == "" {
+		t.Fatalf("incomplete problem: %+v", p)
+	}
+	return p
+}
+
+// decode decodes a JSON response body
+func decode[T any](t *testing.T, w *httptest.ResponseRecorder) T {
+	t.Helper()
+	var value T
+	if err := json.Unmarshal(w.Body.Bytes(), &value); err != nil {
+		t.Fatalf("failed to decode response: %v; body: %s", err, w.Body)
+	}
+	return value
+}
+
+func TestOpenAPIDocumentIsPublic(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+
+	w := s.do(t, "", http.MethodGet, api.BasePath+"/openapi.json", nil)
+	expectStatus(t, w, http.StatusOK)
+}
+
+func TestAuthentication(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+
+	w := s.do(t, "", http.MethodGet, api.BasePath+"/topics", nil)
+	expectProblem(t, w, http.StatusUnauthorized, problem.CodeUnauthenticated)
+	if challenge := w.Header().Get("WWW-Authenticate"); challenge != "Test" {
+		t.Errorf("WWW-Authenticate = %q, want %q", challenge, "Test")
+	}
+
+	// A user without any role may not list topics
+	w = s.do(t, "nobody", http.MethodGet, api.BasePath+"/topics", nil)
+	expectProblem(t, w, http.StatusForbidden, problem.CodeForbidden)
+}
+
+func TestUnknownCluster(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+
+	w := s.do(t, viewer, http.MethodGet, api.BasePath+"/topics?cluster=missing", nil)
+	expectProblem(t, w, http.StatusNotFound, problem.CodeNotFound)
+}
+
+func TestReadOnlyMode(t *testing.T) {
+	features := allFeatures
+	features.ReadOnly = true
+	s := newTestServer(t, features)
+
+	w := s.do(t, admin, http.MethodPost, api.BasePath+"/topics", api.TopicCreationRequest{
+		Name: "orders", NumPartitions: 1, ReplicationFactor: 1,
+	})
+	expectProblem(t, w, http.StatusForbidden, problem.CodeForbidden)
+}
+
+func TestDisabledFeature(t *testing.T) {
+	features := allFeatures
+	features.TopicDeletion = false
+	s := newTestServer(t, features)
+	createTopic(t, s, "orders", 1)
+
+	w := s.do(t, admin, http.MethodDelete, api.BasePath+"/topics/orders", nil)
+	expectProblem(t, w, http.StatusForbidden, problem.CodeForbidden)
+}
+
+func TestLogLevel(t *testing.T) {
+	s := newTestServer(t, allFeatures)
+
+	w := s.do(t, admin, http.MethodPut, api.BasePath+"/admin/log-level", gin.H{"level": "debug"})
+	expectStatus(t, w, http.StatusOK)
+
+	w = s.do(t, admin, http.MethodPut, api.BasePath+"/admin/log-level", gin.H{"level": "loud"})
+	expectProblem(t, w, http.StatusBadRequest, problem.CodeInvalidArgument)
+
+	w = s.do(t, viewer, http.MethodPut, api.BasePath+"/admin/log-level", gin.H{"level": "debug"})
+	expectProblem(t, w, http.StatusForbidden, problem.CodeForbidden)
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/valeriouberti/maestro/internal/audit"
+	"github.com/valeriouberti/maestro/internal/problem"
+	"github.com/valeriouberti/maestro/internal/templates"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// TopicTemplateRequest holds the fields of a topic template created or replaced through
+// the API. TopicName may contain {placeholders}, filled with the parameters of topic
+// creation requests.
+type TopicTemplateRequest struct {
+	Name              string            `json:"name"` // Required on creation; the name in the path is used on update
+	Description       string            `json:"description,omitempty"`
+	TopicName         string            `json:"topicName,omitempty"`
+	NumPartitions     int32             `json:"numPartitions" binding:"required,min=1"`
+	ReplicationFactor int16             `json:"replicationFactor" binding:"required,min=1"`
+	Config            map[string]string `json:"config,omitempty"`
+}
+
+func (r TopicTemplateRequest) template() domain.TopicTemplate {
+	return domain.TopicTemplate{
+		Name:              r.Name,
+		Description:       r.Description,
+		TopicName:         r.TopicName,
+		NumPartitions:     r.NumPartitions,
+		ReplicationFactor: r.ReplicationFactor,
+		Config:            r.Config,
+	}
+}
+
+// abortTemplateError responds to the errors of template changes
+func abortTemplateError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, templates.ErrNotFound):
+		problem.Abort(c, problem.NotFound("Topic template not found", err.Error()))
+	case errors.Is(err, templates.ErrExists), errors.Is(err, templates.ErrReadOnly):
+		problem.Abort(c, problem.Conflict(msg, err.Error()))
+	default:
+		problem.AbortWithError(c, err, msg)
+	}
+}
+
+// ListTopicTemplatesHandler creates a Gin HTTP handler that lists the topic templates,
+// both those of the configuration file and those created through the API.
+//
+// Returns:
+// - 200 OK with the templates, sorted by name
+// - 500 Internal Server Error if the stored templates can't be read
+func ListTopicTemplatesHandler(s *templates.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		list, err := s.List()
+		if err != nil {
+			problem.AbortWithError(c, err, "Failed to list topic templates")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"templates": list})
+	}
+}
+
+// GetTopicTemplateHandler creates a Gin HTTP handler that returns a topic template.
+//
+// Returns:
+// - 200 OK with the template
+// - 404 Not Found if the template doesn't exist
+func GetTopicTemplateHandler(s *templates.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		t, err := s.Get(c.Param("templateName"))
+		if err != nil {
+			abortTemplateError(c, err, "Failed to get topic template")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"template": t})
+	}
+}
+
+// CreateTopicTemplateHandler creates a Gin HTTP handler that stores a new topic template.
+//
+// Returns:
+// - 201 Created with the template
+// - 400 Bad Request if the template is invalid
+// - 409 Conflict if a template with the same name exists
+func CreateTopicTemplateHandler(s *templates.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request TopicTemplateRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			problem.Abort(c, problem.BadRequest("Invalid topic template", err.Error()))
+			return
+		}
+		audit.SetResource(c, request.Name)
+
+		t := request.template()
+		if err := templates.Validate(t); err != nil {
+			problem.Abort(c, problem.BadRequest("Invalid topic template", err.Error()))
+			return
+		}
+		t, err := s.Create(t)
+		if err != nil {
+			abortTemplateError(c, err, "Failed to create topic template")
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"message":  "Topic template created successfully",
+			"template": t,
+		})
+	}
+}
+
+// UpdateTopicTemplateHandler creates a Gin HTTP handler that replaces a stored topic
+// template. Templates of the configuration file are read-only.
+//
+// Returns:
+// - 200 OK with the template
+// - 400 Bad Request if the template is invalid
+// - 404 Not Found if the template doesn't exist
+// - 409 Conflict if the template is defined in the configuration file
+func UpdateTopicTemplateHandler(s *templates.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request TopicTemplateRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			problem.Abort(c, problem.BadRequest("Invalid topic template", err.Error()))
+			return
+		}
+		request.Name = c.Param("templateName")
+
+		t := request.template()
+		if err := templates.Validate(t); err != nil {
+			problem.Abort(c, problem.BadRequest("Invalid topic template", err.Error()))
+			return
+		}
+		t, err := s.Update(t)
+		if err != nil {
+			abortTemplateError(c, err, "Failed to update topic template")
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":  "Topic template updated successfully",
+			"template": t,
+		})
+	}
+}
+
+// DeleteTopicTemplateHandler creates a Gin HTTP handler that deletes a stored topic
+// template. Templates of the configuration file are read-only.
+//
+// Returns:
+// - 200 OK if the template was deleted
+// - 404 Not Found if the template doesn't exist
+// - 409 Conflict if the template is defined in the configuration file
+func DeleteTopicTemplateHandler(s *templates.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("templateName")
+		if err := s.Delete(name); err != nil {
+			abortTemplateError(c, err, "Failed to delete topic template")
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":  "Topic template deleted successfully",
+			"template": name,
+		})
+	}
+}
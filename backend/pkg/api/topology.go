@@ -0,0 +1,109 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/valeriouberti/maestro/internal/kafka_client"
+	"github.com/valeriouberti/maestro/internal/problem"
+	"github.com/valeriouberti/maestro/internal/rbac"
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// GetTopicConsumersHandler creates a Gin HTTP handler that lists the consumer groups
+// consuming a topic: those with committed offsets or assigned partitions on it, with
+// their lag on the topic. Only the groups the caller may read are listed.
+//
+// Returns:
+// - 200 OK with the consumer groups of the topic
+// - 400 Bad Request if the topic name is missing
+// - 404 Not Found if the topic doesn't exist
+// - 500 Internal Server Error for other failures
+func GetTopicConsumersHandler(k kafka_client.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		topicName := c.Param("topicName")
+		if topicName == "" {
+			problem.Abort(c, problem.BadRequest("Topic name is required", ""))
+			return
+		}
+
+		ctx := c.Request.Context()
+		if _, err := k.GetTopicDetails(ctx, topicName); err != nil {
+			problem.AbortWithError(c, err, "Failed to get topic")
+			return
+		}
+		groups, err := readableGroups(c, k)
+		if err != nil {
+			problem.AbortWithError(c, err, "Failed to list consumer groups")
+			return
+		}
+
+		consumers, err := kafka_client.TopicConsumers(ctx, k, topicName, groups)
+		if err != nil {
+			problem.AbortWithError(c, err, "Failed to get the consumers of the topic")
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"topic":     topicName,
+			"consumers": consumers,
+		})
+	}
+}
+
+// GetTopologyHandler creates a Gin HTTP handler that returns the graph of the cluster
+// for visualisation: topics, the consumer groups reading them with their lag and the
+// idempotent or transactional producers that recently wrote to them. Only the topics
+// and groups the caller may read are included.
+//
+// Returns:
+// - 200 OK with the nodes and edges of the graph
+// - 500 Internal Server Error if the cluster cannot be described
+func GetTopologyHandler(k kafka_client.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		topics, err := k.ListTopics(ctx)
+		if err != nil {
+			problem.AbortWithError(c, err, "Failed to list topics")
+			return
+		}
+		var topicNames []string
+		for _, topic := range topics {
+			if rbac.Allowed(c, rbac.ActionTopicRead, topic.Name) {
+				topicNames = append(topicNames, topic.Name)
+			}
+		}
+		groups, err := readableGroups(c, k)
+		if err != nil {
+			problem.AbortWithError(c, err, "Failed to list consumer groups")
+			return
+		}
+
+		topology, err := kafka_client.BuildTopology(ctx, k, topicNames, groups)
+		if err != nil {
+			problem.AbortWithError(c, err, "Failed to build the topology")
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"topology": topology,
+		})
+	}
+}
+
+// readableGroups lists the consumer groups the caller may read
+func readableGroups(c *gin.Context, k kafka_client.Client) ([]domain.ConsumerGroupInfo, error) {
+	groups, err := k.ListConsumerGroups(c.Request.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	readable := make([]domain.ConsumerGroupInfo, 0, len(groups))
+	for _, group := range groups {
+		if rbac.Allowed(c, rbac.ActionGroupRead, group.GroupID) {
+			readable = append(readable, group)
+		}
+	}
+	return readable, nil
+}
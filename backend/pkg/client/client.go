@@ -0,0 +1,169 @@
+// Package client is a Go client of the Maestro REST API.
+//
+// The request and response types and one method per API operation are generated
+// into client_gen.go from the OpenAPI document of the API, which is also written to
+// api/openapi.json. Regenerate both after changing the API:
+//
+//	go generate ./pkg/client
+//
+// Errors returned by the API are reported as *Problem.
+package client
+
+//go:generate go run ../../cmd/clientgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client calls the Maestro API
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	authorize  func(*http.Request)
+	cluster    string
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient makes the client send its requests with h instead of http.DefaultClient
+func WithHTTPClient(h *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = h
+	}
+}
+
+// WithBearerToken authenticates the requests with an OIDC bearer token
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.authorize = func(r *http.Request) {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+}
+
+// WithAPIKey authenticates the requests with an API key
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.authorize = func(r *http.Request) {
+			r.Header.Set("X-API-Key", key)
+		}
+	}
+}
+
+// WithBasicAuth authenticates the requests with a user name and password
+func WithBasicAuth(user, password string) Option {
+	return func(c *Client) {
+		c.authorize = func(r *http.Request) {
+			r.SetBasicAuth(user, password)
+		}
+	}
+}
+
+// WithCluster sends the requests to the named Kafka cluster instead of the default one
+func WithCluster(name string) Option {
+	return func(c *Client) {
+		c.cluster = name
+	}
+}
+
+// New creates a client of the Maestro server at baseURL, e.g. "http://localhost:8080"
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/") + BasePath,
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Error implements error
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return p.Title
+	}
+	return p.Title + ": " + p.Detail
+}
+
+// do sends a request with body encoded as JSON, when not nil, and decodes the JSON
+// response into out, when not nil
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	resp, err := c.send(ctx, method, path, query, reader)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// download sends a request and returns the body of the response, which the caller must close
+func (c *Client) download(ctx context.Context, method, path string, query url.Values) (io.ReadCloser, error) {
+	resp, err := c.send(ctx, method, path, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// send sends a request and returns the response when its status is successful,
+// or the problem it reports otherwise
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, body io.Reader) (*http.Response, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.authorize != nil {
+		c.authorize(req)
+	}
+	if c.cluster != "" {
+		req.Header.Set("X-Maestro-Cluster", c.cluster)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	p := &Problem{Status: int64(resp.StatusCode)}
+	if err := json.NewDecoder(resp.Body).Decode(p); err != nil || p.Title == "" {
+		p.Title = http.StatusText(resp.StatusCode)
+	}
+	return nil, p
+}
@@ -0,0 +1,976 @@
+// Code generated by clientgen. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/valeriouberti/maestro/pkg/domain"
+)
+
+// BasePath is the path the API is served under
+const BasePath = "/api/v1"
+
+// BatchPublishRequest is generated from the BatchPublishRequest schema
+type BatchPublishRequest struct {
+	Records []domain.ProduceRecord `json:"records,omitempty"`
+}
+
+// BulkCreatePartitionsResponse is generated from the BulkCreatePartitionsResponse schema
+type BulkCreatePartitionsResponse struct {
+	Confirmation string                   `json:"confirmation"`
+	DryRun       bool                     `json:"dryRun"`
+	Message      string                   `json:"message"`
+	Topics       []domain.BulkTopicResult `json:"topics"`
+}
+
+// BulkDeleteTopicsResponse is generated from the BulkDeleteTopicsResponse schema
+type BulkDeleteTopicsResponse struct {
+	Confirmation string                   `json:"confirmation"`
+	DryRun       bool                     `json:"dryRun"`
+	Message      string                   `json:"message"`
+	Topics       []domain.BulkTopicResult `json:"topics"`
+}
+
+// BulkUpdateTopicsConfigResponse is generated from the BulkUpdateTopicsConfigResponse schema
+type BulkUpdateTopicsConfigResponse struct {
+	Confirmation string                   `json:"confirmation"`
+	DryRun       bool                     `json:"dryRun"`
+	Message      string                   `json:"message"`
+	Topics       []domain.BulkTopicResult `json:"topics"`
+}
+
+// CancelJobResponse is generated from the CancelJobResponse schema
+type CancelJobResponse struct {
+	Job     domain.Job `json:"job"`
+	Message string     `json:"message"`
+}
+
+// CancelReplayResponse is generated from the CancelReplayResponse schema
+type CancelReplayResponse struct {
+	Job     domain.Job `json:"job"`
+	Message string     `json:"message"`
+}
+
+// CreateTopicResponse is generated from the CreateTopicResponse schema
+type CreateTopicResponse struct {
+	Message string           `json:"message"`
+	Topic   domain.TopicInfo `json:"topic"`
+}
+
+// CreateTopicTemplateResponse is generated from the CreateTopicTemplateResponse schema
+type CreateTopicTemplateResponse struct {
+	Message  string               `json:"message"`
+	Template domain.TopicTemplate `json:"template"`
+}
+
+// DeleteConsumerGroupOffsetsResponse is generated from the DeleteConsumerGroupOffsetsResponse schema
+type DeleteConsumerGroupOffsetsResponse struct {
+	GroupID string                   `json:"groupId"`
+	Message string                   `json:"message"`
+	Offsets []domain.CommittedOffset `json:"offsets"`
+}
+
+// DeleteConsumerGroupResponse is generated from the DeleteConsumerGroupResponse schema
+type DeleteConsumerGroupResponse struct {
+	GroupID string `json:"groupId"`
+	Message string `json:"message"`
+}
+
+// DeleteConsumerGroupsResponse is generated from the DeleteConsumerGroupsResponse schema
+type DeleteConsumerGroupsResponse struct {
+	Confirmation string                 `json:"confirmation"`
+	DryRun       bool                   `json:"dryRun"`
+	Groups       []domain.GroupDeletion `json:"groups"`
+	Message      string                 `json:"message"`
+	Pattern      string                 `json:"pattern"`
+}
+
+// DeleteTopicResponse is generated from the DeleteTopicResponse schema
+type DeleteTopicResponse struct {
+	Message string `json:"message"`
+	Topic   string `json:"topic"`
+}
+
+// DeleteTopicTemplateResponse is generated from the DeleteTopicTemplateResponse schema
+type DeleteTopicTemplateResponse struct {
+	Message  string `json:"message"`
+	Template string `json:"template"`
+}
+
+// GetClustersResponse is generated from the GetClustersResponse schema
+type GetClustersResponse struct {
+	Brokers []domain.BrokerInfo `json:"brokers"`
+}
+
+// GetConfigResponse is generated from the GetConfigResponse schema
+type GetConfigResponse struct {
+	Config map[string]any `json:"config"`
+}
+
+// GetConsumerGroupLagHistoryResponse is generated from the GetConsumerGroupLagHistoryResponse schema
+type GetConsumerGroupLagHistoryResponse struct {
+	History domain.LagHistory `json:"history"`
+}
+
+// GetConsumerGroupLagResponse is generated from the GetConsumerGroupLagResponse schema
+type GetConsumerGroupLagResponse struct {
+	Lag domain.ConsumerGroupLag `json:"lag"`
+}
+
+// GetConsumerGroupResponse is generated from the GetConsumerGroupResponse schema
+type GetConsumerGroupResponse struct {
+	Group domain.ConsumerGroupDetails `json:"group"`
+}
+
+// GetJobResponse is generated from the GetJobResponse schema
+type GetJobResponse struct {
+	Job domain.Job `json:"job"`
+}
+
+// GetLogLevelResponse is generated from the GetLogLevelResponse schema
+type GetLogLevelResponse struct {
+	Level string `json:"level"`
+}
+
+// GetReplayResponse is generated from the GetReplayResponse schema
+type GetReplayResponse struct {
+	Job domain.Job `json:"job"`
+}
+
+// GetTopicConsumersResponse is generated from the GetTopicConsumersResponse schema
+type GetTopicConsumersResponse struct {
+	Consumers []domain.TopicConsumer `json:"consumers"`
+	Topic     string                 `json:"topic"`
+}
+
+// GetTopicMessagesResponse is generated from the GetTopicMessagesResponse schema
+type GetTopicMessagesResponse struct {
+	Count     int64                 `json:"count"`
+	Messages  []domain.TopicMessage `json:"messages"`
+	Offset    int64                 `json:"offset"`
+	Partition int32                 `json:"partition"`
+	Topic     string                `json:"topic"`
+}
+
+// GetTopicResponse is generated from the GetTopicResponse schema
+type GetTopicResponse struct {
+	Topic domain.TopicInfo `json:"topic"`
+}
+
+// GetTopicTemplateResponse is generated from the GetTopicTemplateResponse schema
+type GetTopicTemplateResponse struct {
+	Template domain.TopicTemplate `json:"template"`
+}
+
+// GetTopologyResponse is generated from the GetTopologyResponse schema
+type GetTopologyResponse struct {
+	Topology domain.Topology `json:"topology"`
+}
+
+// JobSubmitRequest is generated from the JobSubmitRequest schema
+type JobSubmitRequest struct {
+	Params json.RawMessage `json:"params"`
+	Type   string          `json:"type"`
+}
+
+// ListAlertsResponse is generated from the ListAlertsResponse schema
+type ListAlertsResponse struct {
+	Alerts []domain.Alert `json:"alerts"`
+}
+
+// ListConfiguredClustersResponse is generated from the ListConfiguredClustersResponse schema
+type ListConfiguredClustersResponse struct {
+	Clusters []domain.ClusterInfo `json:"clusters"`
+}
+
+// ListConsumerGroupsResponse is generated from the ListConsumerGroupsResponse schema
+type ListConsumerGroupsResponse struct {
+	Groups []domain.ConsumerGroupInfo `json:"groups"`
+}
+
+// ListJobsResponse is generated from the ListJobsResponse schema
+type ListJobsResponse struct {
+	Jobs []domain.Job `json:"jobs"`
+}
+
+// ListReplaysResponse is generated from the ListReplaysResponse schema
+type ListReplaysResponse struct {
+	Jobs []domain.Job `json:"jobs"`
+}
+
+// ListTopicTemplatesResponse is generated from the ListTopicTemplatesResponse schema
+type ListTopicTemplatesResponse struct {
+	Templates []domain.TopicTemplate `json:"templates"`
+}
+
+// ListTopicsResponse is generated from the ListTopicsResponse schema
+type ListTopicsResponse struct {
+	Topics []domain.TopicInfo `json:"topics"`
+}
+
+// LogLevelRequest is generated from the LogLevelRequest schema
+type LogLevelRequest struct {
+	Level string `json:"level"`
+}
+
+// MemberRemovalRequest is generated from the MemberRemovalRequest schema
+type MemberRemovalRequest struct {
+	GroupInstanceIDs []string `json:"groupInstanceIds"`
+}
+
+// MessagePublishRequest is generated from the MessagePublishRequest schema
+type MessagePublishRequest struct {
+	Headers   map[string]string `json:"headers,omitempty"`
+	Key       string            `json:"key,omitempty"`
+	Partition int32             `json:"partition,omitempty"`
+	Value     string            `json:"value"`
+}
+
+// Problem is generated from the Problem schema
+type Problem struct {
+	Code       string                   `json:"code,omitempty"`
+	Detail     string                   `json:"detail,omitempty"`
+	Instance   string                   `json:"instance,omitempty"`
+	KafkaCode  int64                    `json:"kafkaCode,omitempty"`
+	Status     int64                    `json:"status,omitempty"`
+	Title      string                   `json:"title,omitempty"`
+	Type       string                   `json:"type,omitempty"`
+	Violations []domain.PolicyViolation `json:"violations,omitempty"`
+}
+
+// PublishBatchResponse is generated from the PublishBatchResponse schema
+type PublishBatchResponse struct {
+	Message string                     `json:"message"`
+	Results []domain.ProduceResult     `json:"results,omitempty"`
+	Summary domain.BatchPublishSummary `json:"summary"`
+	Topic   string                     `json:"topic"`
+}
+
+// PublishMessageResponse is generated from the PublishMessageResponse schema
+type PublishMessageResponse struct {
+	Message          string                                 `json:"message"`
+	PublishedMessage PublishMessageResponsePublishedMessage `json:"publishedMessage"`
+	Topic            string                                 `json:"topic"`
+}
+
+// PublishMessageResponsePublishedMessage is generated from the PublishMessageResponsePublishedMessage schema
+type PublishMessageResponsePublishedMessage struct {
+	Headers   map[string]string `json:"headers,omitempty"`
+	Key       string            `json:"key"`
+	Partition int32             `json:"partition"`
+	Timestamp time.Time         `json:"timestamp"`
+	Topic     string            `json:"topic"`
+	Value     string            `json:"value"`
+}
+
+// RemoveConsumerGroupMembersResponse is generated from the RemoveConsumerGroupMembersResponse schema
+type RemoveConsumerGroupMembersResponse struct {
+	GroupID string                 `json:"groupId"`
+	Members []domain.MemberRemoval `json:"members"`
+	Message string                 `json:"message"`
+}
+
+// ResetConsumerGroupOffsetsResponse is generated from the ResetConsumerGroupOffsetsResponse schema
+type ResetConsumerGroupOffsetsResponse struct {
+	DryRun  bool                 `json:"dryRun"`
+	GroupID string               `json:"groupId"`
+	Message string               `json:"message"`
+	Offsets []domain.OffsetReset `json:"offsets"`
+}
+
+// SearchAuditResponse is generated from the SearchAuditResponse schema
+type SearchAuditResponse struct {
+	Records []domain.AuditRecord `json:"records"`
+}
+
+// SetLogLevelResponse is generated from the SetLogLevelResponse schema
+type SetLogLevelResponse struct {
+	Level string `json:"level"`
+}
+
+// StartReplayResponse is generated from the StartReplayResponse schema
+type StartReplayResponse struct {
+	Job     domain.Job `json:"job"`
+	Message string     `json:"message"`
+}
+
+// SubmitJobResponse is generated from the SubmitJobResponse schema
+type SubmitJobResponse struct {
+	Job     domain.Job `json:"job"`
+	Message string     `json:"message"`
+}
+
+// TestNotifierResponse is generated from the TestNotifierResponse schema
+type TestNotifierResponse struct {
+	Message  string `json:"message"`
+	Notifier string `json:"notifier"`
+}
+
+// TopicConfigUpdateRequest is generated from the TopicConfigUpdateRequest schema
+type TopicConfigUpdateRequest struct {
+	Config map[string]string `json:"config"`
+}
+
+// TopicCreationRequest is generated from the TopicCreationRequest schema
+type TopicCreationRequest struct {
+	Config            map[string]string `json:"config,omitempty"`
+	Name              string            `json:"name,omitempty"`
+	NumPartitions     int32             `json:"numPartitions,omitempty"`
+	Parameters        map[string]string `json:"parameters,omitempty"`
+	ReplicationFactor int32             `json:"replicationFactor,omitempty"`
+	Template          string            `json:"template,omitempty"`
+}
+
+// TopicTemplateRequest is generated from the TopicTemplateRequest schema
+type TopicTemplateRequest struct {
+	Config            map[string]string `json:"config,omitempty"`
+	Description       string            `json:"description,omitempty"`
+	Name              string            `json:"name,omitempty"`
+	NumPartitions     int32             `json:"numPartitions"`
+	ReplicationFactor int32             `json:"replicationFactor"`
+	TopicName         string            `json:"topicName,omitempty"`
+}
+
+// UpdateTopicConfigResponse is generated from the UpdateTopicConfigResponse schema
+type UpdateTopicConfigResponse struct {
+	Message string           `json:"message"`
+	Topic   domain.TopicInfo `json:"topic"`
+}
+
+// UpdateTopicTemplateResponse is generated from the UpdateTopicTemplateResponse schema
+type UpdateTopicTemplateResponse struct {
+	Message  string               `json:"message"`
+	Template domain.TopicTemplate `json:"template"`
+}
+
+// GetLogLevel calls GET /admin/log-level: Get the level of the server log
+func (c *Client) GetLogLevel(ctx context.Context) (*GetLogLevelResponse, error) {
+	var out GetLogLevelResponse
+	if err := c.do(ctx, "GET", "/admin/log-level", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SetLogLevel calls PUT /admin/log-level: Change the level of the server log until the next restart
+func (c *Client) SetLogLevel(ctx context.Context, body LogLevelRequest) (*SetLogLevelResponse, error) {
+	var out SetLogLevelResponse
+	if err := c.do(ctx, "PUT", "/admin/log-level", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListAlerts calls GET /alerts: List the pending and firing alerts
+func (c *Client) ListAlerts(ctx context.Context) (*ListAlertsResponse, error) {
+	var out ListAlertsResponse
+	if err := c.do(ctx, "GET", "/alerts", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// TestNotifier calls POST /alerts/notifiers/{notifier}/test: Send a test notification to a notifier
+func (c *Client) TestNotifier(ctx context.Context, notifier string) (*TestNotifierResponse, error) {
+	var out TestNotifierResponse
+	if err := c.do(ctx, "POST", "/alerts/notifiers/"+url.PathEscape(notifier)+"/test", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SearchAuditParams are the query parameters of SearchAudit. Zero values are omitted.
+type SearchAuditParams struct {
+	// User who performed the operation
+	User string
+	// Part of the action
+	Action string
+	// Topic, consumer group or job the operation was performed on
+	Resource string
+	// success, failure or denied
+	Outcome string
+	// Start of the time range as RFC 3339
+	Since string
+	// End of the time range as RFC 3339
+	Until string
+	// Maximum number of records (default 100, max 1000)
+	Limit int64
+}
+
+// SearchAudit calls GET /audit: Search the audit trail
+func (c *Client) SearchAudit(ctx context.Context, params *SearchAuditParams) (*SearchAuditResponse, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.User != "" {
+			query.Set("user", params.User)
+		}
+		if params.Action != "" {
+			query.Set("action", params.Action)
+		}
+		if params.Resource != "" {
+			query.Set("resource", params.Resource)
+		}
+		if params.Outcome != "" {
+			query.Set("outcome", params.Outcome)
+		}
+		if params.Since != "" {
+			query.Set("since", params.Since)
+		}
+		if params.Until != "" {
+			query.Set("until", params.Until)
+		}
+		if params.Limit != 0 {
+			query.Set("limit", fmt.Sprint(params.Limit))
+		}
+	}
+	var out SearchAuditResponse
+	if err := c.do(ctx, "GET", "/audit", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetClusters calls GET /clusters: List the brokers of the cluster
+func (c *Client) GetClusters(ctx context.Context) (*GetClustersResponse, error) {
+	var out GetClustersResponse
+	if err := c.do(ctx, "GET", "/clusters", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListConfiguredClusters calls GET /clusters/configured: List the Kafka clusters defined in the configuration
+func (c *Client) ListConfiguredClusters(ctx context.Context) (*ListConfiguredClustersResponse, error) {
+	var out ListConfiguredClustersResponse
+	if err := c.do(ctx, "GET", "/clusters/configured", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetConfig calls GET /config: Get the configuration in effect, with secrets redacted
+func (c *Client) GetConfig(ctx context.Context) (*GetConfigResponse, error) {
+	var out GetConfigResponse
+	if err := c.do(ctx, "GET", "/config", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListConsumerGroupsParams are the query parameters of ListConsumerGroups. Zero values are omitted.
+type ListConsumerGroupsParams struct {
+	// Comma-separated states the groups must be in, e.g. Stable,Empty
+	State string
+}
+
+// ListConsumerGroups calls GET /consumergroups: List consumer groups
+func (c *Client) ListConsumerGroups(ctx context.Context, params *ListConsumerGroupsParams) (*ListConsumerGroupsResponse, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.State != "" {
+			query.Set("state", params.State)
+		}
+	}
+	var out ListConsumerGroupsResponse
+	if err := c.do(ctx, "GET", "/consumergroups", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteConsumerGroups calls POST /consumergroups/delete: Delete the consumer groups without members matching a pattern
+func (c *Client) DeleteConsumerGroups(ctx context.Context, body domain.GroupDeletionSpec) (*DeleteConsumerGroupsResponse, error) {
+	var out DeleteConsumerGroupsResponse
+	if err := c.do(ctx, "POST", "/consumergroups/delete", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteConsumerGroup calls DELETE /consumergroups/{groupId}: Delete a consumer group without members
+func (c *Client) DeleteConsumerGroup(ctx context.Context, groupID string) (*DeleteConsumerGroupResponse, error) {
+	var out DeleteConsumerGroupResponse
+	if err := c.do(ctx, "DELETE", "/consumergroups/"+url.PathEscape(groupID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetConsumerGroup calls GET /consumergroups/{groupId}: Get the details of a consumer group
+func (c *Client) GetConsumerGroup(ctx context.Context, groupID string) (*GetConsumerGroupResponse, error) {
+	var out GetConsumerGroupResponse
+	if err := c.do(ctx, "GET", "/consumergroups/"+url.PathEscape(groupID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetConsumerGroupLag calls GET /consumergroups/{groupId}/lag: Get the lag of a consumer group
+func (c *Client) GetConsumerGroupLag(ctx context.Context, groupID string) (*GetConsumerGroupLagResponse, error) {
+	var out GetConsumerGroupLagResponse
+	if err := c.do(ctx, "GET", "/consumergroups/"+url.PathEscape(groupID)+"/lag", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetConsumerGroupLagHistoryParams are the query parameters of GetConsumerGroupLagHistory. Zero values are omitted.
+type GetConsumerGroupLagHistoryParams struct {
+	// Start of the time range as RFC 3339 (default: 24 hours before until)
+	Since string
+	// End of the time range as RFC 3339 (default: now)
+	Until string
+	// Only the partitions of this topic
+	Topic string
+	// Period at the end of the range the rates are computed over (default: 15m)
+	Window string
+}
+
+// GetConsumerGroupLagHistory calls GET /consumergroups/{groupId}/lag/history: Get the recorded lag of a consumer group with its consumption rate and catch-up estimate
+func (c *Client) GetConsumerGroupLagHistory(ctx context.Context, groupID string, params *GetConsumerGroupLagHistoryParams) (*GetConsumerGroupLagHistoryResponse, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Since != "" {
+			query.Set("since", params.Since)
+		}
+		if params.Until != "" {
+			query.Set("until", params.Until)
+		}
+		if params.Topic != "" {
+			query.Set("topic", params.Topic)
+		}
+		if params.Window != "" {
+			query.Set("window", params.Window)
+		}
+	}
+	var out GetConsumerGroupLagHistoryResponse
+	if err := c.do(ctx, "GET", "/consumergroups/"+url.PathEscape(groupID)+"/lag/history", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RemoveConsumerGroupMembers calls POST /consumergroups/{groupId}/members/remove: Remove static members from a consumer group
+func (c *Client) RemoveConsumerGroupMembers(ctx context.Context, groupID string, body MemberRemovalRequest) (*RemoveConsumerGroupMembersResponse, error) {
+	var out RemoveConsumerGroupMembersResponse
+	if err := c.do(ctx, "POST", "/consumergroups/"+url.PathEscape(groupID)+"/members/remove", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteConsumerGroupOffsets calls POST /consumergroups/{groupId}/offsets/delete: Delete the committed offsets of a consumer group on a topic
+func (c *Client) DeleteConsumerGroupOffsets(ctx context.Context, groupID string, body domain.OffsetDeletionSpec) (*DeleteConsumerGroupOffsetsResponse, error) {
+	var out DeleteConsumerGroupOffsetsResponse
+	if err := c.do(ctx, "POST", "/consumergroups/"+url.PathEscape(groupID)+"/offsets/delete", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ResetConsumerGroupOffsets calls POST /consumergroups/{groupId}/offsets/reset: Reset the committed offsets of a consumer group
+func (c *Client) ResetConsumerGroupOffsets(ctx context.Context, groupID string, body domain.OffsetResetSpec) (*ResetConsumerGroupOffsetsResponse, error) {
+	var out ResetConsumerGroupOffsetsResponse
+	if err := c.do(ctx, "POST", "/consumergroups/"+url.PathEscape(groupID)+"/offsets/reset", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListJobsParams are the query parameters of ListJobs. Zero values are omitted.
+type ListJobsParams struct {
+	// Only jobs of this type
+	Type string
+	// Only jobs with this status
+	Status string
+}
+
+// ListJobs calls GET /jobs: List background jobs
+func (c *Client) ListJobs(ctx context.Context, params *ListJobsParams) (*ListJobsResponse, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Type != "" {
+			query.Set("type", params.Type)
+		}
+		if params.Status != "" {
+			query.Set("status", params.Status)
+		}
+	}
+	var out ListJobsResponse
+	if err := c.do(ctx, "GET", "/jobs", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SubmitJob calls POST /jobs: Start a background job
+func (c *Client) SubmitJob(ctx context.Context, body JobSubmitRequest) (*SubmitJobResponse, error) {
+	var out SubmitJobResponse
+	if err := c.do(ctx, "POST", "/jobs", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetJob calls GET /jobs/{jobId}: Get a background job
+func (c *Client) GetJob(ctx context.Context, jobID string) (*GetJobResponse, error) {
+	var out GetJobResponse
+	if err := c.do(ctx, "GET", "/jobs/"+url.PathEscape(jobID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CancelJob calls POST /jobs/{jobId}/cancel: Cancel a background job
+func (c *Client) CancelJob(ctx context.Context, jobID string) (*CancelJobResponse, error) {
+	var out CancelJobResponse
+	if err := c.do(ctx, "POST", "/jobs/"+url.PathEscape(jobID)+"/cancel", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetJobResult calls GET /jobs/{jobId}/result: Download the result of a completed job
+func (c *Client) GetJobResult(ctx context.Context, jobID string) (io.ReadCloser, error) {
+	return c.download(ctx, "GET", "/jobs/"+url.PathEscape(jobID)+"/result", nil)
+}
+
+// GetOpenAPI calls GET /openapi.json: Get this OpenAPI document
+func (c *Client) GetOpenAPI(ctx context.Context) (map[string]any, error) {
+	var out map[string]any
+	if err := c.do(ctx, "GET", "/openapi.json", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListReplaysParams are the query parameters of ListReplays. Zero values are omitted.
+type ListReplaysParams struct {
+	// Only jobs with this status
+	Status string
+}
+
+// ListReplays calls GET /replays: List replay jobs
+func (c *Client) ListReplays(ctx context.Context, params *ListReplaysParams) (*ListReplaysResponse, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Status != "" {
+			query.Set("status", params.Status)
+		}
+	}
+	var out ListReplaysResponse
+	if err := c.do(ctx, "GET", "/replays", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StartReplay calls POST /replays: Start a replay job
+func (c *Client) StartReplay(ctx context.Context, body domain.ReplaySpec) (*StartReplayResponse, error) {
+	var out StartReplayResponse
+	if err := c.do(ctx, "POST", "/replays", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetReplay calls GET /replays/{jobId}: Get a replay job
+func (c *Client) GetReplay(ctx context.Context, jobID string) (*GetReplayResponse, error) {
+	var out GetReplayResponse
+	if err := c.do(ctx, "GET", "/replays/"+url.PathEscape(jobID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CancelReplay calls POST /replays/{jobId}/cancel: Cancel a replay job
+func (c *Client) CancelReplay(ctx context.Context, jobID string) (*CancelReplayResponse, error) {
+	var out CancelReplayResponse
+	if err := c.do(ctx, "POST", "/replays/"+url.PathEscape(jobID)+"/cancel", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListTopics calls GET /topics: List topics
+func (c *Client) ListTopics(ctx context.Context) (*ListTopicsResponse, error) {
+	var out ListTopicsResponse
+	if err := c.do(ctx, "GET", "/topics", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateTopic calls POST /topics: Create a topic
+func (c *Client) CreateTopic(ctx context.Context, body TopicCreationRequest) (*CreateTopicResponse, error) {
+	var out CreateTopicResponse
+	if err := c.do(ctx, "POST", "/topics", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BulkUpdateTopicsConfig calls POST /topics/config: Set and remove configuration overrides of the topics listed or matching a regular expression
+func (c *Client) BulkUpdateTopicsConfig(ctx context.Context, body domain.BulkTopicConfigSpec) (*BulkUpdateTopicsConfigResponse, error) {
+	var out BulkUpdateTopicsConfigResponse
+	if err := c.do(ctx, "POST", "/topics/config", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BulkDeleteTopics calls POST /topics/delete: Delete the topics listed or matching a regular expression
+func (c *Client) BulkDeleteTopics(ctx context.Context, body domain.BulkTopicDeletionSpec) (*BulkDeleteTopicsResponse, error) {
+	var out BulkDeleteTopicsResponse
+	if err := c.do(ctx, "POST", "/topics/delete", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BulkCreatePartitions calls POST /topics/partitions: Increase the number of partitions of the topics listed or matching a regular expression
+func (c *Client) BulkCreatePartitions(ctx context.Context, body domain.BulkPartitionsSpec) (*BulkCreatePartitionsResponse, error) {
+	var out BulkCreatePartitionsResponse
+	if err := c.do(ctx, "POST", "/topics/partitions", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteTopicParams are the query parameters of DeleteTopic. Zero values are omitted.
+type DeleteTopicParams struct {
+	// Name of the topic, confirming the change when the topic is protected
+	Confirm string
+}
+
+// DeleteTopic calls DELETE /topics/{topicName}: Delete a topic
+func (c *Client) DeleteTopic(ctx context.Context, topicName string, params *DeleteTopicParams) (*DeleteTopicResponse, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Confirm != "" {
+			query.Set("confirm", params.Confirm)
+		}
+	}
+	var out DeleteTopicResponse
+	if err := c.do(ctx, "DELETE", "/topics/"+url.PathEscape(topicName), query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetTopic calls GET /topics/{topicName}: Get the details of a topic
+func (c *Client) GetTopic(ctx context.Context, topicName string) (*GetTopicResponse, error) {
+	var out GetTopicResponse
+	if err := c.do(ctx, "GET", "/topics/"+url.PathEscape(topicName), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateTopicConfigParams are the query parameters of UpdateTopicConfig. Zero values are omitted.
+type UpdateTopicConfigParams struct {
+	// Name of the topic, confirming the change when the topic is protected
+	Confirm string
+}
+
+// UpdateTopicConfig calls PUT /topics/{topicName}/config: Update the configuration of a topic
+func (c *Client) UpdateTopicConfig(ctx context.Context, topicName string, params *UpdateTopicConfigParams, body TopicConfigUpdateRequest) (*UpdateTopicConfigResponse, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Confirm != "" {
+			query.Set("confirm", params.Confirm)
+		}
+	}
+	var out UpdateTopicConfigResponse
+	if err := c.do(ctx, "PUT", "/topics/"+url.PathEscape(topicName)+"/config", query, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetTopicConsumers calls GET /topics/{topicName}/consumers: List the consumer groups of a topic with their lag
+func (c *Client) GetTopicConsumers(ctx context.Context, topicName string) (*GetTopicConsumersResponse, error) {
+	var out GetTopicConsumersResponse
+	if err := c.do(ctx, "GET", "/topics/"+url.PathEscape(topicName)+"/consumers", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ExportTopicMessagesParams are the query parameters of ExportTopicMessages. Zero values are omitted.
+type ExportTopicMessagesParams struct {
+	// ndjson (default), csv, avro or parquet
+	Format string
+	// Partition or comma-separated list of partitions (default: all)
+	Partition string
+	// First offset per partition
+	StartOffset int64
+	// Offset per partition to stop at, exclusive
+	EndOffset int64
+	// Start of the time range as RFC 3339 or epoch milliseconds
+	StartTime string
+	// End of the time range as RFC 3339 or epoch milliseconds, exclusive
+	EndTime string
+	// Maximum number of messages (default: unlimited)
+	Limit int64
+}
+
+// ExportTopicMessages calls GET /topics/{topicName}/export: Download a range of messages of a topic
+func (c *Client) ExportTopicMessages(ctx context.Context, topicName string, params *ExportTopicMessagesParams) (io.ReadCloser, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Format != "" {
+			query.Set("format", params.Format)
+		}
+		if params.Partition != "" {
+			query.Set("partition", params.Partition)
+		}
+		if params.StartOffset != 0 {
+			query.Set("startOffset", fmt.Sprint(params.StartOffset))
+		}
+		if params.EndOffset != 0 {
+			query.Set("endOffset", fmt.Sprint(params.EndOffset))
+		}
+		if params.StartTime != "" {
+			query.Set("startTime", params.StartTime)
+		}
+		if params.EndTime != "" {
+			query.Set("endTime", params.EndTime)
+		}
+		if params.Limit != 0 {
+			query.Set("limit", fmt.Sprint(params.Limit))
+		}
+	}
+	return c.download(ctx, "GET", "/topics/"+url.PathEscape(topicName)+"/export", query)
+}
+
+// GetTopicMessagesParams are the query parameters of GetTopicMessages. Zero values are omitted.
+type GetTopicMessagesParams struct {
+	// Partition to read (default: 0)
+	Partition int32
+	// Offset to start from, earliest (default) or latest
+	Offset string
+	// Maximum number of messages (default: 100)
+	Limit int64
+}
+
+// GetTopicMessages calls GET /topics/{topicName}/messages: Read messages of a topic partition
+func (c *Client) GetTopicMessages(ctx context.Context, topicName string, params *GetTopicMessagesParams) (*GetTopicMessagesResponse, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Partition != 0 {
+			query.Set("partition", fmt.Sprint(params.Partition))
+		}
+		if params.Offset != "" {
+			query.Set("offset", params.Offset)
+		}
+		if params.Limit != 0 {
+			query.Set("limit", fmt.Sprint(params.Limit))
+		}
+	}
+	var out GetTopicMessagesResponse
+	if err := c.do(ctx, "GET", "/topics/"+url.PathEscape(topicName)+"/messages", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PublishMessage calls POST /topics/{topicName}/messages: Publish a message
+func (c *Client) PublishMessage(ctx context.Context, topicName string, body MessagePublishRequest) (*PublishMessageResponse, error) {
+	var out PublishMessageResponse
+	if err := c.do(ctx, "POST", "/topics/"+url.PathEscape(topicName)+"/messages", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PublishBatchParams are the query parameters of PublishBatch. Zero values are omitted.
+type PublishBatchParams struct {
+	// Maximum number of records published per second (default: unlimited)
+	Rate int64
+	// Per-record results to return: all (default), failed or none
+	Results string
+	// Payload format overriding the Content-Type: json, ndjson or csv
+	Format string
+}
+
+// PublishBatch calls POST /topics/{topicName}/messages/batch: Publish a batch of records
+func (c *Client) PublishBatch(ctx context.Context, topicName string, params *PublishBatchParams, body BatchPublishRequest) (*PublishBatchResponse, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Rate != 0 {
+			query.Set("rate", fmt.Sprint(params.Rate))
+		}
+		if params.Results != "" {
+			query.Set("results", params.Results)
+		}
+		if params.Format != "" {
+			query.Set("format", params.Format)
+		}
+	}
+	var out PublishBatchResponse
+	if err := c.do(ctx, "POST", "/topics/"+url.PathEscape(topicName)+"/messages/batch", query, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListTopicTemplates calls GET /topictemplates: List the topic templates
+func (c *Client) ListTopicTemplates(ctx context.Context) (*ListTopicTemplatesResponse, error) {
+	var out ListTopicTemplatesResponse
+	if err := c.do(ctx, "GET", "/topictemplates", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateTopicTemplate calls POST /topictemplates: Create a topic template
+func (c *Client) CreateTopicTemplate(ctx context.Context, body TopicTemplateRequest) (*CreateTopicTemplateResponse, error) {
+	var out CreateTopicTemplateResponse
+	if err := c.do(ctx, "POST", "/topictemplates", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteTopicTemplate calls DELETE /topictemplates/{templateName}: Delete a topic template created through the API
+func (c *Client) DeleteTopicTemplate(ctx context.Context, templateName string) (*DeleteTopicTemplateResponse, error) {
+	var out DeleteTopicTemplateResponse
+	if err := c.do(ctx, "DELETE", "/topictemplates/"+url.PathEscape(templateName), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetTopicTemplate calls GET /topictemplates/{templateName}: Get a topic template
+func (c *Client) GetTopicTemplate(ctx context.Context, templateName string) (*GetTopicTemplateResponse, error) {
+	var out GetTopicTemplateResponse
+	if err := c.do(ctx, "GET", "/topictemplates/"+url.PathEscape(templateName), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateTopicTemplate calls PUT /topictemplates/{templateName}: Replace a topic template created through the API
+func (c *Client) UpdateTopicTemplate(ctx context.Context, templateName string, body TopicTemplateRequest) (*UpdateTopicTemplateResponse, error) {
+	var out UpdateTopicTemplateResponse
+	if err := c.do(ctx, "PUT", "/topictemplates/"+url.PathEscape(templateName), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetTopology calls GET /topology: Get the graph of the producers, topics and consumer groups of the cluster
+func (c *Client) GetTopology(ctx context.Context) (*GetTopologyResponse, error) {
+	var out GetTopologyResponse
+	if err := c.do(ctx, "GET", "/topology", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // BrokerInfo represents the information about a Kafka broker to be returned in the API response.
 type BrokerInfo struct {
@@ -9,6 +12,15 @@ type BrokerInfo struct {
 	Port int    `json:"port"`
 }
 
+// ClusterInfo describes a Kafka cluster configured in Maestro
+type ClusterInfo struct {
+	Name              string   `json:"name"`
+	Default           bool     `json:"default"`        // Used by requests that name no cluster
+	Demo              bool     `json:"demo,omitempty"` // In-memory cluster with sample data
+	Brokers           []string `json:"brokers,omitempty"`
+	SchemaRegistryURL string   `json:"schemaRegistryUrl,omitempty"`
+}
+
 // TopicInfo represents the information about a Kafka topic to be returned in the API response.
 type TopicInfo struct {
 	Name              string            `json:"name"`
@@ -16,6 +28,8 @@ type TopicInfo struct {
 	ReplicationFactor int               `json:"replicationFactor"` // Default replication factor
 	Config            map[string]string `json:"config,omitempty"`  // Configuration overrides
 	Partitions        []PartitionInfo   `json:"partitions,omitempty"`
+	IsInternal        bool              `json:"isInternal"`  // Internal to Kafka, e.g. __consumer_offsets
+	IsProtected       bool              `json:"isProtected"` // Internal or protected by the configuration; set by the API
 }
 
 // PartitionInfo represents information about a specific partition within a topic.
@@ -28,25 +42,35 @@ type PartitionInfo struct {
 
 // ConsumerGroupInfo represents basic information about a consumer group.
 type ConsumerGroupInfo struct {
-	GroupID string `json:"groupId"`
-	State   string `json:"state,omitempty"` // Example: Stable, Empty, PreparingRebalance
+	GroupID           string      `json:"groupId"`
+	State             string      `json:"state,omitempty"`             // Example: Stable, Empty, PreparingRebalance
+	Type              string      `json:"type,omitempty"`              // Classic, or Consumer for groups of the consumer rebalance protocol (KIP-848)
+	PartitionAssignor string      `json:"partitionAssignor,omitempty"` // Example: range, cooperative-sticky
+	Simple            bool        `json:"simple,omitempty"`            // Only commits offsets, without members joining the group
+	MemberCount       int         `json:"memberCount"`
+	Coordinator       *BrokerInfo `json:"coordinator,omitempty"`
 }
 
-// ConsumerGroupInfo represents information about a Kafka consumer group
+// ConsumerGroupDetails represents a Kafka consumer group with its members
 type ConsumerGroupDetails struct {
-	GroupID     string                    `json:"groupId"`
-	State       string                    `json:"state"`
-	Coordinator BrokerInfo                `json:"coordinator"`
-	Members     []ConsumerGroupMemberInfo `json:"members,omitempty"`
-	Topics      []string                  `json:"topics,omitempty"`
+	GroupID           string                    `json:"groupId"`
+	State             string                    `json:"state"`
+	Type              string                    `json:"type,omitempty"`
+	PartitionAssignor string                    `json:"partitionAssignor,omitempty"`
+	Simple            bool                      `json:"simple,omitempty"`
+	MemberCount       int                       `json:"memberCount"`
+	Coordinator       BrokerInfo                `json:"coordinator"`
+	Members           []ConsumerGroupMemberInfo `json:"members,omitempty"`
+	Topics            []string                  `json:"topics,omitempty"`
 }
 
 // ConsumerGroupMemberInfo represents a member of a consumer group
 type ConsumerGroupMemberInfo struct {
-	ClientID    string                     `json:"clientId"`
-	ConsumerID  string                     `json:"consumerId"`
-	Host        string                     `json:"host"`
-	Assignments []TopicPartitionAssignment `json:"assignments,omitempty"`
+	ClientID        string                     `json:"clientId"`
+	ConsumerID      string                     `json:"consumerId"`
+	GroupInstanceID string                     `json:"groupInstanceId,omitempty"` // Set for static members (group.instance.id)
+	Host            string                     `json:"host"`
+	Assignments     []TopicPartitionAssignment `json:"assignments,omitempty"`
 }
 
 // TopicPartitionAssignment represents a topic-partition assignment to a consumer
@@ -55,6 +79,226 @@ type TopicPartitionAssignment struct {
 	Partition int32  `json:"partition"`
 }
 
+// PartitionLag is the position of a consumer group on a partition
+type PartitionLag struct {
+	Topic           string `json:"topic"`
+	Partition       int32  `json:"partition"`
+	CommittedOffset int64  `json:"committedOffset"`
+	LogEndOffset    int64  `json:"logEndOffset"`
+	Lag             int64  `json:"lag"` // Messages between the committed offset and the log end
+}
+
+// ConsumerGroupLag is the lag of a consumer group on the partitions it has committed offsets for
+type ConsumerGroupLag struct {
+	GroupID    string         `json:"groupId"`
+	TotalLag   int64          `json:"totalLag"`
+	Partitions []PartitionLag `json:"partitions"`
+}
+
+// LagSample is the lag of a consumer group recorded by the lag history sampler
+type LagSample struct {
+	Time       time.Time      `json:"time"`
+	TotalLag   int64          `json:"totalLag"`
+	Partitions []PartitionLag `json:"partitions,omitempty"`
+}
+
+// Statuses of a LagTrend
+const (
+	LagCaughtUp      = "caught-up"      // No lag
+	LagCatchingUp    = "catching-up"    // The group consumes faster than messages are produced
+	LagFallingBehind = "falling-behind" // Messages are produced faster than the group consumes them
+	LagSteady        = "steady"         // The group consumes as fast as messages are produced
+	LagStalled       = "stalled"        // The group lags and commits no offsets
+)
+
+// LagTrend describes how the lag of a consumer group evolves, from the first and last
+// samples of a window
+type LagTrend struct {
+	Since           time.Time  `json:"since"`
+	Until           time.Time  `json:"until"`
+	ConsumptionRate float64    `json:"consumptionRate"` // Messages consumed per second
+	ProductionRate  float64    `json:"productionRate"`  // Messages produced per second
+	LagRate         float64    `json:"lagRate"`         // Change of the lag per second, negative when catching up
+	Status          string     `json:"status"`
+	ETASeconds      *float64   `json:"etaSeconds,omitempty"` // Time to reach zero lag at the current rates, when catching up
+	CatchUpAt       *time.Time `json:"catchUpAt,omitempty"`
+}
+
+// LagHistory is the lag of a consumer group over time
+type LagHistory struct {
+	Cluster string      `json:"cluster"`
+	GroupID string      `json:"groupId"`
+	Topic   string      `json:"topic,omitempty"` // Limits the samples and trend to the partitions of a topic
+	Samples []LagSample `json:"samples"`
+	Trend   *LagTrend   `json:"trend,omitempty"` // Unknown with fewer than two samples in the window
+}
+
+// TopicConsumer is a consumer group with committed offsets or assigned partitions on a topic
+type TopicConsumer struct {
+	GroupID            string         `json:"groupId"`
+	State              string         `json:"state,omitempty"`
+	MemberCount        int            `json:"memberCount"`                  // Members assigned partitions of the topic
+	AssignedPartitions []int32        `json:"assignedPartitions,omitempty"` // Partitions of the topic assigned to members
+	Lag                int64          `json:"lag"`                          // Total lag on the partitions of the topic
+	Partitions         []PartitionLag `json:"partitions,omitempty"`         // Lag per partition with a committed offset
+}
+
+// ActiveProducer is an idempotent or transactional producer that wrote to a partition
+// recently enough for the partition leader to keep its state
+type ActiveProducer struct {
+	Topic         string    `json:"topic"`
+	Partition     int32     `json:"partition"`
+	ProducerID    int64     `json:"producerId"`
+	ProducerEpoch int32     `json:"producerEpoch"`
+	LastTimestamp time.Time `json:"lastTimestamp"` // Timestamp of its last message
+}
+
+// Kinds of nodes and edges of a Topology
+const (
+	NodeTopic    = "topic"
+	NodeGroup    = "group"
+	NodeProducer = "producer"
+
+	EdgeProduces = "produces"
+	EdgeConsumes = "consumes"
+)
+
+// Topology is the graph of the producers, topics and consumer groups of a cluster, for
+// visualisation. Edges go from producers to topics and from topics to groups.
+type Topology struct {
+	Nodes []TopologyNode `json:"nodes"`
+	Edges []TopologyEdge `json:"edges"`
+}
+
+// TopologyNode is a topic, consumer group or producer of a Topology
+type TopologyNode struct {
+	ID    string `json:"id"`   // Kind and name, e.g. topic:orders or group:billing
+	Kind  string `json:"kind"` // topic, group or producer
+	Label string `json:"label"`
+	State string `json:"state,omitempty"` // State of a consumer group
+}
+
+// TopologyEdge is a producer writing to a topic or a consumer group reading it
+type TopologyEdge struct {
+	Source     string  `json:"source"`
+	Target     string  `json:"target"`
+	Kind       string  `json:"kind"` // produces or consumes
+	Partitions []int32 `json:"partitions,omitempty"`
+	Lag        *int64  `json:"lag,omitempty"` // Lag of a consumer group on the topic
+}
+
+// Targets of an offset reset
+const (
+	ResetToEarliest  = "earliest"
+	ResetToLatest    = "latest"
+	ResetToOffset    = "offset"
+	ResetToTimestamp = "timestamp"
+)
+
+// OffsetResetSpec describes a reset of the committed offsets of a consumer group on a topic
+type OffsetResetSpec struct {
+	Topic      string     `json:"topic" binding:"required"`
+	Partitions []int32    `json:"partitions,omitempty"`  // Empty selects all partitions
+	To         string     `json:"to" binding:"required"` // earliest, latest, offset or timestamp
+	Offset     *int64     `json:"offset,omitempty"`      // Target offset when To is "offset"
+	Timestamp  *time.Time `json:"timestamp,omitempty"`   // Target time when To is "timestamp"
+	DryRun     bool       `json:"dryRun,omitempty"`      // Compute the new offsets without committing them
+}
+
+// OffsetReset is the outcome of an offset reset on a partition. PreviousOffset is -1
+// when the group had no committed offset.
+type OffsetReset struct {
+	Topic          string `json:"topic"`
+	Partition      int32  `json:"partition"`
+	PreviousOffset int64  `json:"previousOffset"`
+	NewOffset      int64  `json:"newOffset"`
+}
+
+// OffsetDeletionSpec selects the committed offsets of a consumer group to delete
+type OffsetDeletionSpec struct {
+	Topic      string  `json:"topic" binding:"required"`
+	Partitions []int32 `json:"partitions,omitempty"` // Empty selects every partition with a committed offset
+}
+
+// CommittedOffset is the offset committed by a consumer group on a partition
+type CommittedOffset struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	Offset    int64  `json:"offset"`
+}
+
+// MemberRemoval is the outcome of the removal of a static member from a consumer group
+type MemberRemoval struct {
+	GroupInstanceID string `json:"groupInstanceId"`
+	MemberID        string `json:"memberId,omitempty"` // Member ID the instance had, when removed
+	Removed         bool   `json:"removed"`
+	Error           string `json:"error,omitempty"`
+}
+
+// GroupDeletionSpec selects the consumer groups removed by a bulk deletion. Only
+// groups without members are deleted; the others are reported as skipped. Like bulk
+// topic operations, the deletion requires the confirmation of its dry run.
+type GroupDeletionSpec struct {
+	Pattern string `json:"pattern" binding:"required"` // Glob pattern matched against group IDs, e.g. maestro-message-reader-*
+	BulkOptions
+}
+
+// GroupDeletion is the outcome of a bulk deletion for a consumer group matching its pattern
+type GroupDeletion struct {
+	GroupID string `json:"groupId"`
+	Deleted bool   `json:"deleted"`
+	Skipped string `json:"skipped,omitempty"` // Why the group was left in place
+	Error   string `json:"error,omitempty"`
+}
+
+// TopicSelector selects the topics of a bulk operation, either by name or with a
+// regular expression
+type TopicSelector struct {
+	Topics []string `json:"topics,omitempty"` // Names of the topics
+	// Pattern is a regular expression the whole topic name must match. It never matches
+	// internal topics, whose names start with an underscore; name them in Topics instead.
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// BulkOptions control the two steps of a bulk operation: a dry run previews the topics
+// or consumer groups the operation applies to and returns a confirmation, which the
+// operation then requires
+type BulkOptions struct {
+	DryRun       bool   `json:"dryRun,omitempty"`
+	Confirmation string `json:"confirmation,omitempty"` // Confirmation returned by the dry run
+}
+
+// BulkTopicDeletionSpec selects the topics removed by a bulk deletion
+type BulkTopicDeletionSpec struct {
+	TopicSelector
+	BulkOptions
+}
+
+// BulkTopicConfigSpec changes configuration overrides of the selected topics, leaving
+// their other overrides in place
+type BulkTopicConfigSpec struct {
+	TopicSelector
+	BulkOptions
+	Set    map[string]string `json:"set,omitempty"`    // Configuration entries to set
+	Delete []string          `json:"delete,omitempty"` // Overrides to remove, reverting the entries to their defaults
+}
+
+// BulkPartitionsSpec increases the number of partitions of the selected topics. Topics
+// with as many partitions already are skipped, as partitions cannot be removed.
+type BulkPartitionsSpec struct {
+	TopicSelector
+	BulkOptions
+	Partitions int32 `json:"partitions" binding:"required,min=1"` // New number of partitions
+}
+
+// BulkTopicResult is the outcome of a bulk operation for one of its topics
+type BulkTopicResult struct {
+	Topic   string `json:"topic"`
+	Applied bool   `json:"applied"`           // The operation succeeded, or would in a dry run
+	Skipped string `json:"skipped,omitempty"` // Why the operation left the topic alone
+	Error   string `json:"error,omitempty"`
+}
+
 // TopicMessage represents a single message from a Kafka topic
 type TopicMessage struct {
 	Topic     string            `json:"topic"`
@@ -64,4 +308,216 @@ type TopicMessage struct {
 	Key       string            `json:"key"`
 	Value     string            `json:"value"`
 	Headers   map[string]string `json:"headers,omitempty"`
+	TraceID   string            `json:"traceId,omitempty"` // Trace whose context the traceparent header carries
+}
+
+// EncodingBase64 marks records whose key, value and header values are base64 encoded,
+// as exports write messages that are not valid UTF-8
+const EncodingBase64 = "base64"
+
+// ProduceRecord represents a single record to be published as part of a batch.
+// Partition and Timestamp are optional: a nil partition lets the producer pick one
+// and a nil timestamp lets the broker assign the current time.
+type ProduceRecord struct {
+	Key       string            `json:"key"`
+	Value     string            `json:"value"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Partition *int32            `json:"partition,omitempty"`
+	Timestamp *time.Time        `json:"timestamp,omitempty"`
+	Encoding  string            `json:"encoding,omitempty"` // EncodingBase64, or empty for text
+}
+
+// ProduceResult represents the delivery outcome of a single record of a batch.
+// Index refers to the position of the record in the submitted batch.
+type ProduceResult struct {
+	Index     int    `json:"index"`
+	Partition int32  `json:"partition"`
+	Offset    int64  `json:"offset"`
+	Error     string `json:"error,omitempty"`
+}
+
+// BatchPublishSummary summarizes the outcome of a batch publish operation.
+type BatchPublishSummary struct {
+	Total      int   `json:"total"`
+	Succeeded  int   `json:"succeeded"`
+	Failed     int   `json:"failed"`
+	DurationMs int64 `json:"durationMs"`
+}
+
+// MessageRange selects a range of messages within a topic. Offsets and times are
+// applied per partition; the end bounds are exclusive. When both an offset and a
+// time are given for the same bound, the offset takes precedence.
+type MessageRange struct {
+	Partitions  []int32    `json:"partitions,omitempty"` // Empty selects all partitions
+	StartOffset *int64     `json:"startOffset,omitempty"`
+	EndOffset   *int64     `json:"endOffset,omitempty"`
+	StartTime   *time.Time `json:"startTime,omitempty"`
+	EndTime     *time.Time `json:"endTime,omitempty"`
+	Limit       int64      `json:"limit,omitempty"` // Zero means no limit
+}
+
+// MessageFilter is a condition a message must satisfy to be selected, e.g. for a replay.
+//
+// Field is one of key, value, partition, offset, timestamp or header:<name>.
+// Op is one of eq, neq, contains, prefix, suffix, regex, exists, gt, gte, lt or lte;
+// the ordering operators compare partitions and offsets numerically and timestamps
+// as RFC 3339 or epoch milliseconds.
+type MessageFilter struct {
+	Field string `json:"field" binding:"required"`
+	Op    string `json:"op" binding:"required"`
+	Value string `json:"value,omitempty"`
+}
+
+// ReplaySpec describes which messages a replay job copies and where it produces them.
+type ReplaySpec struct {
+	SourceTopic   string          `json:"sourceTopic" binding:"required"`
+	Range         MessageRange    `json:"range"`
+	TargetTopic   string          `json:"targetTopic" binding:"required"`
+	TargetCluster string          `json:"targetCluster,omitempty"` // Configured cluster to produce to; empty targets the source cluster
+	Filters       []MessageFilter `json:"filters,omitempty"`
+
+	// Key replaces the key of every replayed message when set
+	Key *string `json:"key,omitempty"`
+	// Partitioning is "keep" to reuse the source partition, "fixed" to use Partition,
+	// or "hash" (default) to let the producer partition by key
+	Partitioning string `json:"partitioning,omitempty"`
+	Partition    *int32 `json:"partition,omitempty"`
+
+	KeepHeaders        *bool `json:"keepHeaders,omitempty"`           // Default: true
+	AddReplayedFrom    *bool `json:"addReplayedFromHeader,omitempty"` // Default: true
+	PreserveTimestamps bool  `json:"preserveTimestamps,omitempty"`
+	RatePerSecond      int   `json:"ratePerSecond,omitempty"` // Zero means unlimited
+}
+
+// ExportSpec describes a range of a topic to be exported to a file by an export job.
+type ExportSpec struct {
+	Topic   string          `json:"topic" binding:"required"`
+	Format  string          `json:"format,omitempty"` // Default: ndjson
+	Range   MessageRange    `json:"range"`
+	Filters []MessageFilter `json:"filters,omitempty"`
+}
+
+// JobProgress reports how far a background job has come. Total is zero when the
+// amount of work is not known in advance.
+type JobProgress struct {
+	Done     int64            `json:"done"`
+	Total    int64            `json:"total,omitempty"`
+	Counters map[string]int64 `json:"counters,omitempty"`
+	Message  string           `json:"message,omitempty"`
+}
+
+// Job represents the state of a background job such as an export or a replay.
+type Job struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Cluster    string          `json:"cluster,omitempty"` // Kafka cluster the job runs against; empty is the default one
+	Status     string          `json:"status"`            // pending, running, completed, failed, cancelled
+	Params     json.RawMessage `json:"params,omitempty"`
+	Progress   JobProgress     `json:"progress"`
+	Result     json.RawMessage `json:"result,omitempty"`
+	ResultFile string          `json:"resultFile,omitempty"` // Name of the downloadable result file, if any
+	Error      string          `json:"error,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	StartedAt  *time.Time      `json:"startedAt,omitempty"`
+	FinishedAt *time.Time      `json:"finishedAt,omitempty"`
+}
+
+// AuditRecord is an entry of the audit trail of mutating API operations
+type AuditRecord struct {
+	ID          string          `json:"id"`
+	Time        time.Time       `json:"time"`
+	User        string          `json:"user"` // Empty when authentication is disabled
+	AuthMethod  string          `json:"authMethod,omitempty"`
+	ClientIP    string          `json:"clientIp"`
+	Action      string          `json:"action"` // Route of the operation, e.g. "DELETE /api/v1/topics/:topicName"
+	Resource    string          `json:"resource,omitempty"`
+	Path        string          `json:"path"`
+	Request     json.RawMessage `json:"request,omitempty"` // JSON request body, omitted when large or not JSON
+	RequestSize int64           `json:"requestSize"`
+	Status      int             `json:"status"`
+	Outcome     string          `json:"outcome"` // success, failure or denied
+	Response    json.RawMessage `json:"response,omitempty"`
+	Changes     []ConfigChange  `json:"changes,omitempty"`
+	DurationMs  int64           `json:"durationMs"`
+}
+
+// ConfigChange is the change of a single configuration entry. Before is nil for
+// added entries and After is nil for removed ones.
+type ConfigChange struct {
+	Key    string  `json:"key"`
+	Before *string `json:"before"`
+	After  *string `json:"after"`
+}
+
+// AuditQuery selects audit records. Empty fields match every record.
+type AuditQuery struct {
+	User     string
+	Action   string // Substring of the action
+	Resource string
+	Outcome  string
+	Since    *time.Time
+	Until    *time.Time
+	Limit    int
+}
+
+// PolicyViolation is a rule of a topic policy that a topic breaks
+type PolicyViolation struct {
+	Policy  string `json:"policy"`          // Name of the policy, or names of the policies for prefix rules
+	Rule    string `json:"rule"`            // e.g. name-pattern, min-partitions or required-config
+	Field   string `json:"field,omitempty"` // Request field breaking the rule, e.g. numPartitions or config.retention.ms
+	Message string `json:"message"`
+}
+
+// Sources of topic templates
+const (
+	TemplateSourceConfig  = "config"  // Defined in the configuration file; read-only
+	TemplateSourceStorage = "storage" // Created through the API
+)
+
+// TopicTemplate holds the defaults of the topics created from it, such as those of a
+// compacted changelog or a dead letter queue
+type TopicTemplate struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// TopicName names the topics created from the template. Its {placeholders} are replaced
+	// by the parameters of the request, e.g. "{team}.{entity}.changelog".
+	TopicName         string            `json:"topicName,omitempty"`
+	NumPartitions     int32             `json:"numPartitions"`
+	ReplicationFactor int16             `json:"replicationFactor"`
+	Config            map[string]string `json:"config,omitempty"`
+	Source            string            `json:"source"`
+	UpdatedAt         *time.Time        `json:"updatedAt,omitempty"` // Last change of a stored template
+}
+
+// Alert rule types
+const (
+	AlertConsumerLag     = "consumer-lag"     // The lag of a consumer group is above the threshold
+	AlertUnderReplicated = "under-replicated" // A topic has partitions whose in-sync replicas are fewer than its replicas
+	AlertTopicCreated    = "topic-created"    // A topic was created
+	AlertTopicDeleted    = "topic-deleted"    // A topic was deleted
+	AlertGroupEmpty      = "group-empty"      // A consumer group has no members
+)
+
+// Alert statuses
+const (
+	AlertPending  = "pending"  // The condition holds, but not yet for the duration of the rule
+	AlertFiring   = "firing"   // The condition has held for the duration of the rule
+	AlertResolved = "resolved" // The condition no longer holds
+)
+
+// Alert is a rule whose condition holds for a topic or consumer group of a cluster. It
+// is also the data of notification templates.
+type Alert struct {
+	Rule       string     `json:"rule"`
+	Type       string     `json:"type"`
+	Severity   string     `json:"severity"`
+	Cluster    string     `json:"cluster"`
+	Subject    string     `json:"subject"` // Topic or consumer group the condition holds for
+	Status     string     `json:"status"`
+	Summary    string     `json:"summary"`
+	Value      int64      `json:"value"` // Lag, or number of under-replicated partitions
+	Threshold  int64      `json:"threshold"`
+	ActiveAt   time.Time  `json:"activeAt"`             // When the condition was first seen
+	FiredAt    *time.Time `json:"firedAt,omitempty"`    // When the first notification was sent
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"` // When the condition stopped holding
 }
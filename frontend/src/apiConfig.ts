@@ -7,3 +7,10 @@ export const getApiUrl = (path: string): string => {
   const formattedPath = path.startsWith("/") ? path : `/${path}`;
   return `${API_BASE_URL}${formattedPath}`;
 };
+
+// Template of links to traces in a tracing UI, e.g. "https://jaeger.example.com/trace/{traceId}"
+export const TRACE_URL_TEMPLATE: string = import.meta.env.VITE_TRACE_URL || "";
+
+// Helper function to construct the link to a trace, or null when no tracing UI is configured
+export const getTraceUrl = (traceId: string): string | null =>
+  TRACE_URL_TEMPLATE ? TRACE_URL_TEMPLATE.replace("{traceId}", encodeURIComponent(traceId)) : null;
@@ -44,6 +44,9 @@ const ConsumerGroupDetails: React.FC = () => {
         <div className="p-4">
             <h2 className="text-xl font-semibold mb-4 text-pastel-purple">Consumer Group Details: {consumerGroup.groupId}</h2>
             <p>State: {consumerGroup.state || 'Unknown'}</p>
+            {consumerGroup.type && <p>Type: {consumerGroup.type}{consumerGroup.simple ? ' (simple)' : ''}</p>}
+            {consumerGroup.partitionAssignor && <p>Partition assignor: {consumerGroup.partitionAssignor}</p>}
+            <p>Members: {consumerGroup.memberCount}</p>
             {consumerGroup.coordinator && (
                 <p>Coordinator: Broker ID: {consumerGroup.coordinator.id}, Host: {consumerGroup.coordinator.host}:{consumerGroup.coordinator.port}</p>
             )}
@@ -93,6 +93,10 @@ const ConsumerGroupList: React.FC = () => {
                                 </Link>
                                 <p className="text-gray-600 mt-1">
                                     State: {group.state || 'Unknown'}
+                                    {group.type && <> · Type: {group.type}{group.simple ? ' (simple)' : ''}</>}
+                                    {group.partitionAssignor && <> · Assignor: {group.partitionAssignor}</>}
+                                    {' · '}Members: {group.memberCount}
+                                    {group.coordinator && <> · Coordinator: {group.coordinator.id}</>}
                                 </p>
                             </li>
                         ))}
@@ -3,7 +3,7 @@ import React, { useState, useEffect } from 'react';
 import axios from 'axios';
 import { useParams, Link, useNavigate } from 'react-router-dom';
 import { API_BASE_URL } from '../apiConfig';
-import { TopicInfo } from '../types';
+import { TopicConsumer, TopicInfo } from '../types';
 
 const TopicDetails: React.FC = () => {
   const { topicName } = useParams<{ topicName: string }>();
@@ -13,6 +13,8 @@ const TopicDetails: React.FC = () => {
   const [showDeleteModal, setShowDeleteModal] = useState(false);
   const [deleteError, setDeleteError] = useState<string | null>(null);
   const [isDeleting, setIsDeleting] = useState(false);
+  const [deleteConfirmation, setDeleteConfirmation] = useState('');
+  const [consumers, setConsumers] = useState<TopicConsumer[]>([]);
   const navigate = useNavigate();
 
   useEffect(() => {
@@ -25,17 +27,31 @@ const TopicDetails: React.FC = () => {
         setLoading(false);
       } catch (e: any) {
         console.error("Error fetching topic details:", e);
-        setError(e.response?.data?.error || e.message);
+        setError(e.response?.data?.detail || e.response?.data?.title || e.message);
         setLoading(false);
       }
     };
 
+    const fetchConsumers = async () => {
+      if (!topicName) return;
+
+      try {
+        const response = await axios.get<{ consumers: TopicConsumer[] }>(`${API_BASE_URL}/topics/${topicName}/consumers`);
+        setConsumers(response.data.consumers || []);
+      } catch (e: any) {
+        // The topic is still shown when its consumers cannot be listed
+        console.error("Error fetching topic consumers:", e);
+      }
+    };
+
     fetchTopicDetails();
+    fetchConsumers();
   }, [topicName]);
 
   const handleDeleteClick = () => {
     setShowDeleteModal(true);
     setDeleteError(null);
+    setDeleteConfirmation('');
   };
 
   const handleCancelDelete = () => {
@@ -50,13 +66,16 @@ const TopicDetails: React.FC = () => {
     setDeleteError(null);
     
     try {
-      await axios.delete(`${API_BASE_URL}/topics/${topicName}`);
+      // Protected topics are only deleted when the request names them again
+      await axios.delete(`${API_BASE_URL}/topics/${topicName}`, {
+        params: topic?.isProtected ? { confirm: deleteConfirmation } : undefined,
+      });
       setShowDeleteModal(false);
       // Redirect to topics list
       navigate('/topics');
     } catch (e: any) {
       console.error("Error deleting topic:", e);
-      setDeleteError(e.response?.data?.message || e.message);
+      setDeleteError(e.response?.data?.detail || e.response?.data?.title || e.message);
       setIsDeleting(false);
     }
   };
@@ -218,6 +237,52 @@ const TopicDetails: React.FC = () => {
             </div>
           </div>
         )}
+
+        {consumers.length > 0 && (
+          <div className="p-6">
+            <h3 className="text-lg font-medium text-gray-800 mb-4">Consumer Groups</h3>
+            <div className="overflow-x-auto">
+              <table className="min-w-full divide-y divide-gray-200">
+                <thead className="bg-gray-50">
+                  <tr>
+                    <th scope="col" className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">
+                      Group
+                    </th>
+                    <th scope="col" className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">
+                      State
+                    </th>
+                    <th scope="col" className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">
+                      Members
+                    </th>
+                    <th scope="col" className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">
+                      Lag
+                    </th>
+                  </tr>
+                </thead>
+                <tbody className="bg-white divide-y divide-gray-200">
+                  {consumers.map((consumer) => (
+                    <tr key={consumer.groupId}>
+                      <td className="px-6 py-4 whitespace-nowrap text-sm font-medium text-gray-900">
+                        <Link to={`/consumer-groups/${consumer.groupId}`} className="text-accent-blue hover:underline">
+                          {consumer.groupId}
+                        </Link>
+                      </td>
+                      <td className="px-6 py-4 whitespace-nowrap text-sm text-gray-900">
+                        {consumer.state || 'Unknown'}
+                      </td>
+                      <td className="px-6 py-4 whitespace-nowrap text-sm text-gray-900">
+                        {consumer.memberCount}
+                      </td>
+                      <td className="px-6 py-4 whitespace-nowrap text-sm text-gray-900">
+                        {consumer.lag}
+                      </td>
+                    </tr>
+                  ))}
+                </tbody>
+              </table>
+            </div>
+          </div>
+        )}
       </div>
 
       {/* Delete Confirmation Modal */}
@@ -229,6 +294,21 @@ const TopicDetails: React.FC = () => {
               Are you sure you want to delete the topic <span className="font-semibold">{topicName}</span>? 
               This action cannot be undone and will permanently delete all data in this topic.
             </p>
+
+            {topic.isProtected && (
+              <div className="mb-4">
+                <label htmlFor="deleteConfirmation" className="block text-sm font-medium text-gray-700 mb-1">
+                  This topic is protected. Type its name to confirm.
+                </label>
+                <input
+                  id="deleteConfirmation"
+                  type="text"
+                  value={deleteConfirmation}
+                  onChange={(e) => setDeleteConfirmation(e.target.value)}
+                  className="w-full px-3 py-2 border border-gray-300 rounded-md focus:outline-none focus:ring-2 focus:ring-red-500"
+                />
+              </div>
+            )}
             
             {deleteError && (
               <div className="mb-4 p-3 bg-red-50 border border-red-200 rounded text-red-600 text-sm">
@@ -248,8 +328,8 @@ const TopicDetails: React.FC = () => {
               <button
                 type="button"
                 onClick={handleConfirmDelete}
-                className="px-4 py-2 bg-red-500 text-white rounded-md hover:bg-red-600 focus:outline-none focus:ring-2 focus:ring-red-500 flex items-center"
-                disabled={isDeleting}
+                className="px-4 py-2 bg-red-500 text-white rounded-md hover:bg-red-600 focus:outline-none focus:ring-2 focus:ring-red-500 flex items-center disabled:opacity-50"
+                disabled={isDeleting || (topic.isProtected && deleteConfirmation !== topicName)}
               >
                 {isDeleting ? (
                   <>
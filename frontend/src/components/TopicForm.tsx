@@ -52,7 +52,7 @@ const TopicForm: React.FC = () => {
                 setError(`Failed to create topic. Status code: ${response.status}`);
             }
         } catch (e: any) {
-            setError(`Error creating topic: ${e.response?.data?.error || e.message}`);
+            setError(`Error creating topic: ${e.response?.data?.detail || e.response?.data?.title || e.message}`);
         }
     };
 
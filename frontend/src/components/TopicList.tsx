@@ -116,7 +116,7 @@ const TopicList: React.FC = () => {
         setFormError(`Failed to create topic. Status code: ${response.status}`);
       }
     } catch (e: any) {
-      setFormError(`Error creating topic: ${e.response?.data?.error || e.message}`);
+      setFormError(`Error creating topic: ${e.response?.data?.detail || e.response?.data?.title || e.message}`);
     }
   };
 
@@ -305,6 +305,12 @@ const TopicList: React.FC = () => {
                 <Link to={`/topics/${topic.name}`} className="text-accent-blue hover:underline font-medium">
                   {topic.name}
                 </Link>
+                {topic.isInternal && (
+                  <span className="ml-2 px-2 py-0.5 text-xs rounded bg-gray-100 text-gray-600">internal</span>
+                )}
+                {topic.isProtected && (
+                  <span className="ml-2 px-2 py-0.5 text-xs rounded bg-yellow-100 text-yellow-800">protected</span>
+                )}
                 <p className="text-gray-600 mt-1">
                   Partitions: {topic.numPartitions}, Replication Factor: {topic.replicationFactor}
                 </p>
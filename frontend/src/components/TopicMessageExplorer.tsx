@@ -1,7 +1,7 @@
 import React, { useState, useEffect } from 'react';
 import axios from 'axios';
 import { useParams, Link } from 'react-router-dom';
-import { API_BASE_URL } from '../apiConfig';
+import { API_BASE_URL, getTraceUrl } from '../apiConfig';
 import { TopicInfo, TopicMessage, PartitionInfo } from '../types';
 
 const TopicMessageExplorer: React.FC = () => {
@@ -49,7 +49,7 @@ const TopicMessageExplorer: React.FC = () => {
         }
       } catch (e: any) {
         console.error("Error fetching topic details:", e);
-        setError(e.response?.data?.message || e.message);
+        setError(e.response?.data?.detail || e.response?.data?.title || e.message);
       } finally {
         setLoading(false);
       }
@@ -113,7 +113,7 @@ const TopicMessageExplorer: React.FC = () => {
       } else if (e.message === 'Network Error' || e.message.includes('socket hangup')) {
         setMessageError("Network error occurred. Please check your connection to the server.");
       } else {
-        setMessageError(e.response?.data?.message || e.message);
+        setMessageError(e.response?.data?.detail || e.response?.data?.title || e.message);
       }
       
       setMessages([]);
@@ -269,7 +269,7 @@ const TopicMessageExplorer: React.FC = () => {
       }
     } catch (e: any) {
       console.error("Error publishing message:", e);
-      setPublishError(e.response?.data?.message || e.message);
+      setPublishError(e.response?.data?.detail || e.response?.data?.title || e.message);
     } finally {
       setIsPublishing(false);
     }
@@ -712,6 +712,9 @@ const TopicMessageExplorer: React.FC = () => {
                       <th scope="col" className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">
                         Key
                       </th>
+                      <th scope="col" className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">
+                        Trace
+                      </th>
                       <th scope="col" className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider w-1/2">
                         Value
                       </th>
@@ -738,6 +741,27 @@ const TopicMessageExplorer: React.FC = () => {
                               `${message.key.substring(0, 30)}...` : 
                               message.key || <span className="text-gray-400 italic">null</span>}
                           </td>
+                          <td className="px-6 py-4 whitespace-nowrap text-sm text-gray-500 font-mono">
+                            {message.traceId ? (
+                              getTraceUrl(message.traceId) ? (
+                                <a
+                                  href={getTraceUrl(message.traceId)!}
+                                  target="_blank"
+                                  rel="noopener noreferrer"
+                                  title={message.traceId}
+                                  className="text-accent-blue hover:text-blue-700"
+                                >
+                                  {isExpanded ? message.traceId : `${message.traceId.substring(0, 8)}...`}
+                                </a>
+                              ) : (
+                                <span title={message.traceId}>
+                                  {isExpanded ? message.traceId : `${message.traceId.substring(0, 8)}...`}
+                                </span>
+                              )
+                            ) : (
+                              <span className="text-gray-400 italic font-sans">none</span>
+                            )}
+                          </td>
                           <td className="px-6 py-4 text-sm text-gray-500">
                             {isExpanded ? (
                               <pre className="overflow-x-auto max-w-full whitespace-pre-wrap break-words bg-gray-50 p-3 rounded">
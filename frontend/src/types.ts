@@ -10,6 +10,8 @@ export interface TopicInfo {
   replicationFactor: number;
   config?: { [key: string]: string };
   partitions?: PartitionInfo[];
+  isInternal: boolean;
+  isProtected: boolean;
 }
 
 export interface PartitionInfo {
@@ -22,11 +24,20 @@ export interface PartitionInfo {
 export interface ConsumerGroupInfo {
   groupId: string;
   state?: string;
+  type?: string;
+  partitionAssignor?: string;
+  simple?: boolean;
+  memberCount: number;
+  coordinator?: BrokerInfo;
 }
 
 export interface ConsumerGroupDetails {
   groupId: string;
   state: string;
+  type?: string;
+  partitionAssignor?: string;
+  simple?: boolean;
+  memberCount: number;
   coordinator: BrokerInfo;
   members?: ConsumerGroupMemberInfo[];
   topics?: string[];
@@ -35,10 +46,28 @@ export interface ConsumerGroupDetails {
 export interface ConsumerGroupMemberInfo {
   clientId: string;
   consumerId: string;
+  groupInstanceId?: string;
   host: string;
   assignments?: TopicPartitionAssignment[];
 }
 
+export interface PartitionLag {
+  topic: string;
+  partition: number;
+  committedOffset: number;
+  logEndOffset: number;
+  lag: number;
+}
+
+export interface TopicConsumer {
+  groupId: string;
+  state?: string;
+  memberCount: number;
+  assignedPartitions?: number[];
+  lag: number;
+  partitions?: PartitionLag[];
+}
+
 export interface TopicPartitionAssignment {
   topic: string;
   partition: number;
@@ -52,4 +81,5 @@ export interface TopicMessage {
   key: string;
   value: string;
   headers?: { [key: string]: string };
+  traceId?: string; // Trace whose context the traceparent header carries
 }